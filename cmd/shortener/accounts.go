@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Adigezalov/shortener/internal/config"
+	"github.com/Adigezalov/shortener/internal/storage"
+)
+
+// accountsUsage описывает синтаксис подкоманды accounts
+const accountsUsage = "использование: shortener accounts set-role [флаги] <логин> <user|admin>"
+
+// runAccounts выполняет подкоманду accounts напрямую над хранилищем
+// из конфигурации, без запущенного сервера.
+//
+// Действия:
+//   - set-role: назначить роль учетной записи. Так назначается первый
+//     администратор: через API роль может менять только администратор.
+func runAccounts(ctx context.Context, cfg *config.Config, out io.Writer, action string, args []string) (err error) {
+	if action != "set-role" || len(args) != 2 || args[0] == "" {
+		return errors.New(accountsUsage)
+	}
+	login, role := args[0], args[1]
+
+	store, svc, err := openService(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := store.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if accountStore, ok := store.(storage.AccountStorage); ok {
+		svc.WithAccounts(accountStore)
+	}
+
+	account, err := svc.SetAccountRole(ctx, login, role)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "учетной записи %s (%s) назначена роль %s\n", login, account.ID, account.Role)
+	return err
+}
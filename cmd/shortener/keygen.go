@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+)
+
+// runKeygen выполняет подкоманду keygen: создает ключ подписи токенов
+// и выводит его в формате "<id>:<секрет>" для AUTH_KEYS или файла ключей.
+//
+// Флаги:
+//   - -id: ID ключа (по умолчанию момент создания, например 20250101120000)
+func runKeygen(out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	fs.SetOutput(out)
+	id := fs.String("id", "", "ID ключа (по умолчанию момент создания)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := auth.GenerateKey(*id)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, key.String())
+	return err
+}
@@ -2,28 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/Adigezalov/shortener/internal/analytics"
+	"github.com/Adigezalov/shortener/internal/auth"
 	"github.com/Adigezalov/shortener/internal/config"
 	"github.com/Adigezalov/shortener/internal/database"
 	"github.com/Adigezalov/shortener/internal/grpcserver"
 	"github.com/Adigezalov/shortener/internal/handlers"
 	"github.com/Adigezalov/shortener/internal/logger"
-	customMiddleware "github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/middleware"
 	"github.com/Adigezalov/shortener/internal/profiling"
+	"github.com/Adigezalov/shortener/internal/ratelimit"
 	"github.com/Adigezalov/shortener/internal/service"
 	"github.com/Adigezalov/shortener/internal/shortener"
 	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/tracing"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
 	pb "github.com/Adigezalov/shortener/pkg/proto"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -39,10 +45,13 @@ var (
 )
 
 func main() {
-	// Выводим информацию о сборке
-	fmt.Printf("Build version: %s\n", buildVersion)
-	fmt.Printf("Build date: %s\n", buildDate)
-	fmt.Printf("Build commit: %s\n", buildCommit)
+	// Выводим информацию о сборке. Подкоманды import и export могут выводить
+	// данные в stdout, поэтому для них информация о сборке не выводится
+	if len(os.Args) < 2 || (os.Args[1] != "import" && os.Args[1] != "export") {
+		fmt.Printf("Build version: %s\n", buildVersion)
+		fmt.Printf("Build date: %s\n", buildDate)
+		fmt.Printf("Build commit: %s\n", buildCommit)
+	}
 
 	// Инициализируем логгер
 	if err := logger.Initialize(); err != nil {
@@ -50,9 +59,83 @@ func main() {
 	}
 	defer logger.Sync()
 
+	// Подкоманды обслуживания выполняются вместо запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		// Убираем имя подкоманды, чтобы флаги конфигурации разбирались как обычно
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		cfg := config.NewConfig()
+		if err := runMigrate(context.Background(), os.Stdout, cfg.DatabaseDSN, flag.Args()); err != nil {
+			logger.Logger.Fatal("Ошибка выполнения миграций", zap.Error(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "keygen" {
+		if err := runKeygen(os.Stdout, os.Args[2:]); err != nil {
+			logger.Logger.Fatal("Ошибка создания ключа подписи", zap.Error(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "storage" {
+		if err := runStorage(context.Background(), os.Stdout, os.Args[2:]); err != nil {
+			logger.Logger.Fatal("Ошибка переноса хранилища", zap.Error(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "accounts" {
+		// Убираем имя подкоманды и действие, чтобы флаги конфигурации разбирались как обычно
+		action := ""
+		if len(os.Args) > 2 {
+			action = os.Args[2]
+			os.Args = append(os.Args[:1], os.Args[3:]...)
+		}
+		cfg := config.NewConfig()
+		if err := runAccounts(context.Background(), cfg, os.Stdout, action, flag.Args()); err != nil {
+			logger.Logger.Fatal("Ошибка изменения учетной записи", zap.Error(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		cfg := config.NewConfig()
+		if err := runImport(context.Background(), cfg, os.Stdin, os.Stdout, flag.Args()); err != nil {
+			logger.Logger.Fatal("Ошибка импорта URL", zap.Error(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		cfg := config.NewConfig()
+		if err := runExport(context.Background(), cfg, os.Stdout, flag.Args()); err != nil {
+			logger.Logger.Fatal("Ошибка выгрузки URL", zap.Error(err))
+		}
+		return
+	}
+
 	// Загружаем конфигурацию
 	cfg := config.NewConfig()
 
+	// Загружаем ключи подписи токенов пользователей
+	keyring, err := auth.LoadKeyring(cfg.AuthKeys, cfg.AuthKeysFile, cfg.AuthKeyID, cfg.AuthTokenTTL)
+	if errors.Is(err, auth.ErrNoKeys) {
+		logger.Logger.Warn("Ключи подписи не заданы (AUTH_KEYS, AUTH_KEYS_FILE): " +
+			"используется случайный ключ, токены пользователей не переживут перезапуск")
+		keyring, err = auth.NewEphemeralKeyring(cfg.AuthTokenTTL)
+	}
+	if err != nil {
+		logger.Logger.Fatal("Некорректная конфигурация ключей подписи", zap.Error(err))
+	}
+	auth.SetKeyring(keyring)
+
+	// Проверяем режим проверки аутентификации
+	authMode, err := auth.ParseMode(cfg.AuthMode)
+	if err != nil {
+		logger.Logger.Fatal("Некорректная конфигурация аутентификации", zap.Error(err))
+	}
+	if authMode == auth.ModeLegacy {
+		logger.Logger.Warn("Включен режим аутентификации legacy: подпись куки user_id " +
+			"на маршрутах /api/user не проверяется")
+	}
+
 	// Инициализируем сервер профилирования
 	profilingServer := profiling.NewServer(cfg)
 	if profilingServer != nil {
@@ -61,61 +144,83 @@ func main() {
 		}
 	}
 
+	// Подключаем выгрузку span'ов трассировки
+	traceExporter, err := tracing.NewExporter(cfg.TraceExporter, cfg.TraceOTLPEndpoint, cfg.TraceServiceName)
+	if err != nil {
+		logger.Logger.Fatal("Некорректная конфигурация трассировки", zap.Error(err))
+	}
+	tracing.SetErrorHandler(func(err error) {
+		logger.Logger.Warn("Ошибка выгрузки трассировки", zap.Error(err))
+	})
+	tracing.SetExporter(traceExporter)
+
 	// Инициализируем хранилище URL с помощью фабрики
-	store, err := storage.Factory(cfg.DatabaseDSN, cfg.FileStoragePath)
+	store, err := newStorage(cfg)
 	if err != nil {
 		logger.Logger.Fatal("Ошибка инициализации хранилища", zap.Error(err))
 	}
 
+	// Запускаем фоновую очистку ссылок с истекшим сроком действия
+	var reaper *storage.Reaper
+	if cfg.ReaperInterval > 0 {
+		reaper = storage.NewReaper(store, cfg.ReaperInterval)
+		reaper.Start()
+	}
+
 	// Инициализируем подключение к базе данных для хендлера /ping
-	var dbInterface handlers.Pinger
+	var dbInterface service.Pinger
 	if cfg.DatabaseDSN != "" {
 		db, err := database.New(cfg.DatabaseDSN)
 		if err != nil {
 			logger.Logger.Fatal("Ошибка подключения к базе данных", zap.Error(err))
 		}
 		dbInterface = db
-	} else {
-		// Если база данных не настроена, передаем nil
-		dbInterface = nil
 	}
 
-	// Инициализируем сервис сокращения URL
-	shortenerService := shortener.New(cfg.BaseURL)
+	// Создаем service слой, общий для HTTP и gRPC
+	svc, err := newService(cfg, store, dbInterface)
+	if err != nil {
+		logger.Logger.Fatal("Некорректная конфигурация сервиса", zap.Error(err))
+	}
 
 	// Инициализируем обработчик HTTP запросов
-	handler := handlers.New(store, shortenerService, dbInterface)
-
-	// Создаем service слой для gRPC
-	svc := service.NewShortenerService(store, shortenerService, dbInterface)
-
-	// Создаем новый роутер chi
-	r := chi.NewRouter()
-
-	// Добавляем глобальные middleware
-	r.Use(middleware.CleanPath)
-	r.Use(customMiddleware.LoggingRecoverer)
-	r.Use(customMiddleware.WithRequestID)
-	r.Use(customMiddleware.RequestLogger)
-	r.Use(customMiddleware.GzipMiddleware)
-	r.Use(customMiddleware.AuthMiddleware) // Добавляем middleware аутентификации
-
-	// Определяем маршруты
-	r.Get("/ping", handler.PingDB)
-	r.With(customMiddleware.TextPlainContentTypeMiddleware()).Post("/", handler.CreateShortURL)
-	r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/api/shorten", handler.ShortenURL)
-	r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/api/shorten/batch", handler.ShortenBatch)
-	r.Get("/{id}", handler.RedirectToURL)
-
-	// Маршруты, требующие аутентификации
-	r.Route("/api/user", func(r chi.Router) {
-		r.Use(customMiddleware.RequireAuth)
-		r.Get("/urls", handler.GetUserURLs)
-		r.Delete("/urls", handler.DeleteUserURLs)
-	})
+	handler := handlers.New(svc).WithAuthMode(authMode)
+
+	// Подключаем сбор статистики переходов, если хранилище его поддерживает
+	var clickRecorder *analytics.Recorder
+	if clickStore, ok := store.(storage.ClickStorage); ok {
+		clickRecorder = analytics.NewRecorder(clickStore,
+			analytics.DefaultBufferSize, analytics.DefaultBatchSize, analytics.DefaultFlushInterval)
+		handler.WithAnalytics(clickRecorder)
+		svc.WithClickStats(clickStore)
+	}
+
+	// Подключаем учетные записи, если хранилище их поддерживает
+	if accountStore, ok := store.(storage.AccountStorage); ok {
+		svc.WithAccounts(accountStore)
+	}
 
-	// Маршрут для внутренней статистики с проверкой IP
-	r.Get("/api/internal/stats", customMiddleware.IPAuthMiddleware(cfg.TrustedSubnet)(http.HandlerFunc(handler.GetStats)).ServeHTTP)
+	// Подключаем модерацию ссылок. Роль администратора назначается
+	// подкомандой accounts set-role или другим администратором
+	if adminStore, ok := store.(storage.AdminStorage); ok {
+		svc.WithAdmin(adminStore)
+	}
+
+	// Подключаем ограничение частоты запросов
+	limiter, err := newRateLimiter(cfg, dbInterface)
+	if err != nil {
+		logger.Logger.Fatal("Некорректная конфигурация ограничения запросов", zap.Error(err))
+	}
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		logger.Logger.Fatal("Некорректный список доверенных прокси", zap.Error(err))
+	}
+	handler.WithRateLimiter(limiter).
+		WithTrustedProxies(trustedProxies).
+		WithImportLimits(int64(cfg.ImportMaxBytes), cfg.ImportMaxRows)
+
+	// Создаем роутер со всеми маршрутами HTTP API
+	r := handlers.NewRouter(handler, cfg.TrustedSubnet)
 
 	// Настраиваем HTTP-сервер
 	srv := &http.Server{
@@ -134,10 +239,20 @@ func main() {
 		opts := []grpc.ServerOption{
 			grpc.ChainUnaryInterceptor(
 				grpcserver.RecoveryInterceptor(),
+				grpcserver.TracingInterceptor(),
 				grpcserver.LoggingInterceptor(),
-				grpcserver.AuthInterceptor(),
+				grpcserver.AuthInterceptor(svc, authMode),
+				grpcserver.AdminInterceptor(svc),
+				grpcserver.RateLimitInterceptor(limiter),
 				grpcserver.IPAuthInterceptor(cfg.TrustedSubnet),
 			),
+			grpc.ChainStreamInterceptor(
+				grpcserver.StreamRecoveryInterceptor(),
+				grpcserver.StreamTracingInterceptor(),
+				grpcserver.StreamLoggingInterceptor(),
+				grpcserver.StreamAuthInterceptor(svc, authMode),
+				grpcserver.StreamRateLimitInterceptor(limiter),
+			),
 		}
 
 		// Если есть сертификаты для gRPC TLS, используем их
@@ -152,6 +267,7 @@ func main() {
 
 		grpcSrv = grpc.NewServer(opts...)
 		pb.RegisterShortenerServiceServer(grpcSrv, grpcServer)
+		pb.RegisterAdminServiceServer(grpcSrv, grpcserver.NewAdminServer(svc))
 
 		// Создаем listener для gRPC
 		var err error
@@ -184,6 +300,13 @@ func main() {
 			zap.String("trusted_subnet", cfg.TrustedSubnet),
 			zap.Bool("grpc_enabled", cfg.EnableGRPC),
 			zap.String("grpc_address", cfg.GRPCAddress),
+			zap.Duration("reaper_interval", cfg.ReaperInterval),
+			zap.Int("compaction_threshold", cfg.CompactionThreshold),
+			zap.String("durability", cfg.Durability),
+			zap.String("trace_exporter", cfg.TraceExporter),
+			zap.String("auth_key_id", keyring.CurrentKeyID()),
+			zap.String("auth_mode", cfg.AuthMode),
+			zap.Bool("rate_limit_enabled", limiter != nil),
 		)
 
 		var err error
@@ -249,6 +372,19 @@ func main() {
 		}
 	}
 
+	// Записываем накопленные переходы до закрытия хранилища
+	if clickRecorder != nil {
+		clickRecorder.Close()
+		logger.Logger.Info("Статистика переходов сохранена",
+			zap.Int64("dropped", clickRecorder.Dropped()))
+	}
+
+	// Останавливаем фоновую очистку до закрытия хранилища
+	if reaper != nil {
+		reaper.Stop()
+		logger.Logger.Info("Фоновая очистка истекших URL остановлена")
+	}
+
 	// Закрываем хранилище для сохранения всех данных
 	logger.Logger.Info("Сохраняем данные в хранилище...")
 	if err := store.Close(); err != nil {
@@ -268,5 +404,113 @@ func main() {
 		}
 	}
 
+	// Выгружаем накопленные span'ы трассировки
+	if err := tracing.Shutdown(shutdownCtx); err != nil {
+		logger.Logger.Error("Ошибка при выгрузке трассировки", zap.Error(err))
+	}
+
 	logger.Logger.Info("Все серверы корректно завершили работу")
 }
+
+// newStorage создает хранилище URL из конфигурации с помощью storage.Factory
+func newStorage(cfg *config.Config) (storage.URLStorageV2, error) {
+	// Проверяем режим надежности записи файлового хранилища
+	durability, err := storage.ParseDurability(cfg.Durability)
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверяем область дедупликации оригинальных URL
+	dedup, err := storage.ParseDedupScope(cfg.DedupScope)
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверяем хранилище кэша ссылок
+	cacheBackend, err := storage.ParseCacheBackend(cfg.CacheBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.Factory(cfg.DatabaseDSN, cfg.FileStoragePath, storage.Options{
+		Dedup: dedup,
+		Cache: storage.CacheOptions{
+			Backend:   cacheBackend,
+			Size:      cfg.CacheSize,
+			TTL:       cfg.CacheTTL,
+			RedisAddr: cfg.CacheRedisAddr,
+		},
+		Durability:          durability,
+		CompactionThreshold: cfg.CompactionThreshold,
+	})
+}
+
+// newService создает сервис сокращения URL над хранилищем store
+// с генератором ID и политикой URL из конфигурации
+func newService(cfg *config.Config, store storage.URLStorageV2, db service.Pinger) (*service.ShortenerService, error) {
+	// Номера генераторов counter и sqids резервируются в хранилище, поэтому
+	// после перезапуска и удаления ссылок они не выдаются повторно
+	var reserve shortener.CounterReserver
+	if counters, ok := store.(storage.CounterStorage); ok {
+		reserve = func(count uint64) (uint64, error) {
+			return counters.ReserveCounter(context.Background(), count)
+		}
+	}
+	generator, err := shortener.NewGenerator(cfg.IDGenerator, cfg.IDLength, reserve)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная конфигурация генератора ID: %w", err)
+	}
+
+	// Подключаем политику проверки оригинальных URL
+	urlPolicy, err := newURLPolicy(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная конфигурация политики URL: %w", err)
+	}
+
+	svc := service.NewShortenerService(store, shortener.NewWithGenerator(cfg.BaseURL, generator), db)
+	return svc.WithURLPolicy(urlPolicy), nil
+}
+
+// newURLPolicy создает политику проверки оригинальных URL по конфигурации.
+// Хост BASE_URL запрещается, чтобы короткие ссылки не указывали на сам сервис.
+func newURLPolicy(cfg *config.Config) (*urlpolicy.Policy, error) {
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный BASE_URL: %w", err)
+	}
+	return urlpolicy.New(urlpolicy.Options{
+		Schemes:      urlpolicy.ParseList(cfg.URLAllowedSchemes),
+		MaxLength:    cfg.URLMaxLength,
+		AllowPrivate: cfg.URLAllowPrivate,
+		SelfHosts:    []string{base.Hostname()},
+		AllowHosts:   urlpolicy.ParseList(cfg.URLAllowHosts),
+		DenyHosts:    urlpolicy.ParseList(cfg.URLDenyHosts),
+		ResolveHosts: cfg.URLResolveHosts,
+	})
+}
+
+// newRateLimiter создает ограничитель частоты запросов по конфигурации.
+// Возвращает nil, если правила не заданы.
+func newRateLimiter(cfg *config.Config, pinger service.Pinger) (*ratelimit.Limiter, error) {
+	rules, err := ratelimit.ParseRules(cfg.RateLimits)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := ratelimit.ParseBackend(cfg.RateLimitStore)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if backend == ratelimit.BackendPostgres {
+		db, ok := pinger.(*database.DB)
+		if !ok {
+			return nil, errors.New("хранилище ограничения запросов postgres требует DATABASE_DSN")
+		}
+		store = ratelimit.NewDatabaseStore(db.DB)
+	}
+	return ratelimit.New(rules, store), nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/database"
+)
+
+// migrateUsage описывает синтаксис подкоманды migrate
+const migrateUsage = "использование: shortener migrate [флаги] up|down [N]|status"
+
+// runMigrate выполняет подкоманду migrate над базой данных dsn.
+//
+// Действия:
+//   - up: применить все непримененные миграции
+//   - down [N]: откатить N последних миграций (по умолчанию одну)
+//   - status: вывести состояние всех миграций
+func runMigrate(ctx context.Context, out io.Writer, dsn string, args []string) error {
+	if dsn == "" {
+		return errors.New("не задана строка подключения к базе данных (-d или DATABASE_DSN)")
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := database.Open(dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	return executeMigrate(ctx, out, migrator, args)
+}
+
+// executeMigrate выполняет действие над исполнителем миграций
+func executeMigrate(ctx context.Context, out io.Writer, migrator *database.Migrator, args []string) error {
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Fprintf(out, "применена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "схема актуальна")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("некорректное число шагов отката: %s", args[1])
+			}
+			steps = n
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Fprintf(out, "откачена %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(out, "нет примененных миграций")
+		}
+		return nil
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range status {
+			state, appliedAt := "pending", "-"
+			if s.Applied {
+				state = "applied"
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("неизвестное действие %q; %s", args[0], migrateUsage)
+	}
+}
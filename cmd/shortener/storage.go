@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Adigezalov/shortener/internal/config"
+	"github.com/Adigezalov/shortener/internal/storage"
+)
+
+// storageUsage описывает синтаксис подкоманды storage
+const storageUsage = "использование: shortener storage migrate -from file:<путь>|postgres://... -to file:<путь>|postgres://... [флаги]"
+
+// runStorage выполняет подкоманду storage.
+//
+// Действия:
+//   - migrate: перенести ссылки между хранилищами (см. runStorageMigrate)
+func runStorage(ctx context.Context, out io.Writer, args []string) error {
+	if len(args) == 0 || args[0] != "migrate" {
+		return errors.New(storageUsage)
+	}
+	return runStorageMigrate(ctx, out, args[1:])
+}
+
+// runStorageMigrate переносит ссылки из одного хранилища в другое
+// (storage.Migrate) и сверяет результат (storage.VerifyMigration).
+// Перенос выполняется при остановленном сервере.
+//
+// Флаги:
+//   - -from, -to: хранилища в виде file:<путь> или строки подключения postgres://...
+//   - -dedup-scope: область дедупликации целевого хранилища (по умолчанию DEDUP_SCOPE или per-user)
+//   - -batch: количество ссылок, записываемых одной операцией
+//   - -samples: количество ссылок, сверяемых после переноса
+//   - -checkpoint: файл с последним перенесенным коротким ID; если файл
+//     существует, перенос продолжается с этого ID, после успешной
+//     проверки файл удаляется
+func runStorageMigrate(ctx context.Context, out io.Writer, args []string) (err error) {
+	dedupScope := config.DefaultDedupScope
+	if envDedupScope := os.Getenv("DEDUP_SCOPE"); envDedupScope != "" {
+		dedupScope = envDedupScope
+	}
+
+	fs := flag.NewFlagSet("storage migrate", flag.ContinueOnError)
+	fs.SetOutput(out)
+	from := fs.String("from", "", "исходное хранилище: file:<путь> или postgres://...")
+	to := fs.String("to", "", "целевое хранилище: file:<путь> или postgres://...")
+	fs.StringVar(&dedupScope, "dedup-scope", dedupScope, "область дедупликации целевого хранилища: global, per-user, none")
+	batch := fs.Int("batch", storage.DefaultMigrateBatchSize, "количество ссылок, записываемых одной операцией")
+	samples := fs.Int("samples", storage.DefaultMigrateSamples, "количество ссылок, сверяемых после переноса")
+	checkpoint := fs.String("checkpoint", "", "файл для продолжения прерванного переноса")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" || fs.NArg() > 0 {
+		return errors.New(storageUsage)
+	}
+	if *from == *to {
+		return errors.New("исходное и целевое хранилища совпадают")
+	}
+
+	dedup, err := storage.ParseDedupScope(dedupScope)
+	if err != nil {
+		return err
+	}
+
+	source, err := openMigrationStorage(*from, dedup, true)
+	if err != nil {
+		return fmt.Errorf("исходное хранилище: %w", err)
+	}
+	defer source.Close()
+
+	target, err := openMigrationStorage(*to, dedup, false)
+	if err != nil {
+		return fmt.Errorf("целевое хранилище: %w", err)
+	}
+	defer func() {
+		if closeErr := target.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	opts := storage.MigrateOptions{BatchSize: *batch}
+	if *checkpoint != "" {
+		if opts.After, err = readCheckpoint(*checkpoint); err != nil {
+			return err
+		}
+		if opts.After != "" {
+			fmt.Fprintf(out, "продолжение после %s\n", opts.After)
+		}
+		opts.Progress = func(report storage.MigrateReport) error {
+			return writeCheckpoint(*checkpoint, report.LastID)
+		}
+	}
+
+	report, err := storage.Migrate(ctx, source, target, opts)
+	for _, id := range report.Failed {
+		fmt.Fprintf(out, "не перенесена %s: короткий ID занят другим URL\n", id)
+	}
+	fmt.Fprintf(out, "перенесено: %d, перенесены ранее: %d, с ошибкой: %d\n",
+		report.Created, report.Existing, len(report.Failed))
+	if err != nil {
+		return err
+	}
+
+	verify, err := storage.VerifyMigration(ctx, source, target, *samples)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки: %w", err)
+	}
+	for _, mismatch := range verify.Mismatches {
+		fmt.Fprintf(out, "расхождение: %s\n", mismatch)
+	}
+	fmt.Fprintf(out, "проверка: ссылок в исходном %d, в целевом %d, сверено %d\n",
+		verify.Source.Total, verify.Target.Total, verify.Sampled)
+
+	if len(report.Failed) > 0 || !verify.OK() {
+		return errors.New("перенос завершен с ошибками")
+	}
+	if *checkpoint != "" {
+		if err := os.Remove(*checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// openMigrationStorage открывает хранилище по адресу file:<путь> или
+// строке подключения PostgreSQL. Исходный файл должен существовать.
+// Запись в файл подтверждается синхронно, чтобы файл продолжения
+// не опережал сохраненные ссылки.
+func openMigrationStorage(uri string, dedup storage.DedupScope, source bool) (storage.URLStorageV2, error) {
+	switch {
+	case strings.HasPrefix(uri, "postgres://"), strings.HasPrefix(uri, "postgresql://"):
+		return storage.Factory(uri, "", storage.Options{Dedup: dedup})
+	case strings.HasPrefix(uri, "file:"):
+		path := strings.TrimPrefix(uri, "file:")
+		if path == "" {
+			return nil, errors.New("не указан путь к файлу хранения")
+		}
+		if source {
+			if _, err := os.Stat(path); err != nil {
+				return nil, err
+			}
+		}
+		return storage.Factory("", path, storage.Options{Dedup: dedup, Durability: storage.DurabilitySync})
+	default:
+		return nil, fmt.Errorf("неизвестное хранилище %q: ожидается file:<путь> или postgres://...", uri)
+	}
+}
+
+// readCheckpoint возвращает последний перенесенный короткий ID из файла
+// продолжения (пустой, если файла нет)
+func readCheckpoint(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeCheckpoint атомарно записывает последний перенесенный короткий ID
+func writeCheckpoint(path string, lastID string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(lastID+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Adigezalov/shortener/internal/bulk"
+	"github.com/Adigezalov/shortener/internal/config"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/storage"
+)
+
+// Синтаксис подкоманд import и export
+const (
+	importUsage = "использование: shortener import [флаги] <user-id> <файл|-> [csv|jsonl]"
+	exportUsage = "использование: shortener export [флаги] <user-id> <файл|-> [csv|jsonl]"
+)
+
+// transferArgs - аргументы подкоманд import и export
+type transferArgs struct {
+	userID string
+	path   string // "-" - stdin или stdout
+	format bulk.Format
+}
+
+// parseTransferArgs разбирает аргументы подкоманд import и export.
+// Формат по умолчанию определяется по расширению файла.
+func parseTransferArgs(args []string, usage string) (transferArgs, error) {
+	if len(args) < 2 || len(args) > 3 || args[0] == "" {
+		return transferArgs{}, errors.New(usage)
+	}
+
+	result := transferArgs{userID: args[0], path: args[1], format: bulk.FormatFromPath(args[1])}
+	if len(args) == 3 {
+		format, err := bulk.ParseFormat(args[2])
+		if err != nil {
+			return transferArgs{}, err
+		}
+		result.format = format
+	}
+	return result, nil
+}
+
+// runImport выполняет подкоманду import: импортирует URL из файла (или stdin)
+// в URL пользователя напрямую через хранилище из конфигурации, с теми же
+// проверками и пакетной записью, что и POST /api/user/urls/import.
+// Строки с ошибкой и итог импорта выводятся в out.
+func runImport(ctx context.Context, cfg *config.Config, stdin io.Reader, out io.Writer, args []string) (err error) {
+	transfer, err := parseTransferArgs(args, importUsage)
+	if err != nil {
+		return err
+	}
+
+	in := stdin
+	if transfer.path != "-" {
+		file, err := os.Open(transfer.path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	reader, err := bulk.NewReader(in, transfer.format)
+	if err != nil {
+		return err
+	}
+
+	store, svc, err := openService(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := store.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	summary, err := bulk.Import(ctx, svc, transfer.userID, reader, bulk.ImportOptions{}, func(result models.ImportResult) error {
+		if result.Status != string(storage.RecordFailed) {
+			return nil
+		}
+		_, err := fmt.Fprintf(out, "строка %d: %s\n", result.Line, result.Error)
+		return err
+	})
+	fmt.Fprintf(out, "создано: %d, уже существовали: %d, с ошибкой: %d\n",
+		summary.Created, summary.Existing, summary.Failed)
+	return err
+}
+
+// runExport выполняет подкоманду export: выгружает все URL пользователя
+// в файл (или stdout) напрямую из хранилища из конфигурации,
+// в том же формате, что и GET /api/user/urls/export.
+func runExport(ctx context.Context, cfg *config.Config, stdout io.Writer, args []string) (err error) {
+	transfer, err := parseTransferArgs(args, exportUsage)
+	if err != nil {
+		return err
+	}
+
+	store, svc, err := openService(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := store.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	out := stdout
+	if transfer.path != "-" {
+		file, err := os.Create(transfer.path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		out = file
+	}
+
+	writer := bulk.NewWriter(out, transfer.format)
+	if err := svc.ExportUserURLs(ctx, transfer.userID, service.UserURLsQuery{}, writer.Write); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// openService открывает хранилище из конфигурации и создает над ним
+// сервис сокращения URL так же, как при запуске сервера
+func openService(cfg *config.Config) (storage.URLStorageV2, *service.ShortenerService, error) {
+	store, err := newStorage(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svc, err := newService(cfg, store, nil)
+	if err != nil {
+		store.Close()
+		return nil, nil, err
+	}
+	return store, svc, nil
+}
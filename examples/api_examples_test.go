@@ -16,10 +16,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/Adigezalov/shortener/internal/handlers"
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
 	"github.com/Adigezalov/shortener/internal/shortener"
 	"github.com/Adigezalov/shortener/internal/storage"
 	"github.com/go-chi/chi/v5"
@@ -37,7 +39,7 @@ func setupTestServer() *httptest.Server {
 	shortenerService := shortener.New("http://localhost:8080")
 
 	// Создаем обработчик (не используется в упрощенных примерах)
-	_ = handlers.New(store, shortenerService, nil)
+	_ = handlers.New(service.NewShortenerService(store, shortenerService, nil))
 
 	// Настраиваем роутер без middleware для простоты примеров
 	r := chi.NewRouter()
@@ -52,7 +54,7 @@ func setupTestServer() *httptest.Server {
 		}
 		
 		shortID := shortenerService.Shorten(originalURL)
-		store.Add(shortID, originalURL)
+		store.Add(req.Context(), shortID, originalURL, "", time.Time{})
 		shortURL := shortenerService.BuildShortURL(shortID)
 		
 		w.WriteHeader(http.StatusCreated)
@@ -69,7 +71,7 @@ func setupTestServer() *httptest.Server {
 		}
 		
 		shortID := shortenerService.Shorten(request.URL)
-		store.Add(shortID, request.URL)
+		store.Add(req.Context(), shortID, request.URL, "", time.Time{})
 		shortURL := shortenerService.BuildShortURL(shortID)
 		
 		response := models.ShortenResponse{Result: shortURL}
@@ -85,7 +87,7 @@ func setupTestServer() *httptest.Server {
 		var batchResponse []models.BatchShortenResponse
 		for _, item := range batchRequest {
 			shortID := shortenerService.Shorten(item.OriginalURL)
-			store.Add(shortID, item.OriginalURL)
+			store.Add(req.Context(), shortID, item.OriginalURL, "", time.Time{})
 			shortURL := shortenerService.BuildShortURL(shortID)
 			
 			batchResponse = append(batchResponse, models.BatchShortenResponse{
@@ -101,8 +103,8 @@ func setupTestServer() *httptest.Server {
 	
 	r.Get("/{id}", func(w http.ResponseWriter, req *http.Request) {
 		id := chi.URLParam(req, "id")
-		originalURL, exists := store.Get(id)
-		if !exists {
+		originalURL, err := store.Get(req.Context(), id)
+		if err != nil {
 			http.Error(w, "URL не найден", http.StatusNotFound)
 			return
 		}
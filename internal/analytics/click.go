@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// DefaultStatsWindow определяет глубину почасовой истории по умолчанию.
+const DefaultStatsWindow = 7 * 24 * time.Hour
+
+// NewClick формирует событие перехода по HTTP запросу редиректа.
+func NewClick(shortID string, r *http.Request) models.Click {
+	return models.Click{
+		ShortID:         shortID,
+		ClickedAt:       time.Now().UTC(),
+		Referrer:        ReferrerHost(r.Referer()),
+		UserAgentFamily: UserAgentFamily(r.UserAgent()),
+	}
+}
+
+// ReferrerHost возвращает хост источника перехода без схемы, пути и порта.
+// Для пустого или некорректного заголовка Referer возвращается пустая строка.
+func ReferrerHost(referer string) string {
+	if referer == "" {
+		return ""
+	}
+
+	u, err := url.Parse(referer)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// UserAgentFamily определяет семейство клиента по заголовку User-Agent.
+//
+// Порядок проверок важен: Edge и Opera содержат маркер Chrome,
+// а Chrome в свою очередь содержит маркер Safari.
+func UserAgentFamily(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case ua == "":
+		return "Unknown"
+	case strings.Contains(ua, "bot") || strings.Contains(ua, "crawler") || strings.Contains(ua, "spider"):
+		return "Bot"
+	case strings.Contains(ua, "edg/"):
+		return "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		return "Opera"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		return "Chrome"
+	case strings.Contains(ua, "firefox/") || strings.Contains(ua, "fxios/"):
+		return "Firefox"
+	case strings.Contains(ua, "safari/"):
+		return "Safari"
+	case strings.HasPrefix(ua, "curl/"):
+		return "curl"
+	default:
+		return "Other"
+	}
+}
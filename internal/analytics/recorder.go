@@ -0,0 +1,143 @@
+// Package analytics реализует сбор статистики переходов по коротким ссылкам.
+//
+// Переходы регистрируются асинхронно: обработчик редиректа только кладет
+// событие в буферизованный канал, а фоновая горутина пакетами записывает
+// накопленные события в хранилище. Благодаря этому горячий путь редиректа
+// не блокируется на запросах к базе данных.
+package analytics
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"go.uber.org/zap"
+)
+
+// Параметры регистратора переходов по умолчанию.
+const (
+	DefaultBufferSize    = 4096        // Емкость очереди событий
+	DefaultBatchSize     = 256         // Максимальный размер пакета записи
+	DefaultFlushInterval = time.Second // Максимальная задержка записи пакета
+)
+
+// ClickWriter описывает хранилище, принимающее пакеты событий перехода.
+type ClickWriter interface {
+	RecordClicks(clicks []models.Click) error
+}
+
+// Recorder асинхронно накапливает события переходов и пакетами
+// передает их в ClickWriter.
+//
+// Если очередь переполнена, событие отбрасывается, а счетчик
+// отброшенных событий увеличивается: аналитика не должна замедлять редирект.
+type Recorder struct {
+	writer        ClickWriter
+	queue         chan models.Click
+	batchSize     int
+	flushInterval time.Duration
+	done          chan struct{}
+	dropped       atomic.Int64
+
+	mu     sync.RWMutex // защищает closed и отправку в queue
+	closed bool
+}
+
+// NewRecorder создает регистратор переходов и запускает фоновую запись.
+//
+// Пример использования:
+//
+//	recorder := analytics.NewRecorder(store, analytics.DefaultBufferSize,
+//		analytics.DefaultBatchSize, analytics.DefaultFlushInterval)
+//	defer recorder.Close()
+func NewRecorder(writer ClickWriter, bufferSize, batchSize int, flushInterval time.Duration) *Recorder {
+	r := &Recorder{
+		writer:        writer,
+		queue:         make(chan models.Click, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}
+
+	go r.worker()
+
+	return r
+}
+
+// Record ставит событие перехода в очередь без блокировки.
+func (r *Recorder) Record(click models.Click) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		return
+	}
+
+	select {
+	case r.queue <- click:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped возвращает количество событий, отброшенных из-за переполнения очереди.
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close прекращает прием событий, записывает остаток очереди
+// и дожидается завершения фоновой горутины.
+func (r *Recorder) Close() {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	<-r.done
+}
+
+// worker накапливает события и записывает их пакетами по размеру или по таймеру
+func (r *Recorder) worker() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.Click, 0, r.batchSize)
+	for {
+		select {
+		case click, ok := <-r.queue:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+			batch = append(batch, click)
+			if len(batch) >= r.batchSize {
+				r.flush(batch)
+				batch = make([]models.Click, 0, r.batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				r.flush(batch)
+				batch = make([]models.Click, 0, r.batchSize)
+			}
+		}
+	}
+}
+
+// flush записывает пакет событий в хранилище
+func (r *Recorder) flush(batch []models.Click) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := r.writer.RecordClicks(batch); err != nil {
+		logger.Logger.Error("Ошибка записи пакета переходов",
+			zap.Int("count", len(batch)),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Префиксы токенов учетных записей. По префиксу токен учетной записи
+// отличается от подписанного ID анонимного пользователя.
+const (
+	SessionTokenPrefix = "ss_" // Токен сессии, выданный при входе по паролю
+	APIKeyPrefix       = "sk_" // Именованный API-ключ
+)
+
+// SessionCookieName - имя куки с токеном сессии
+const SessionCookieName = "session"
+
+// tokenBytes - количество случайных байт в токене учетной записи
+const tokenBytes = 32
+
+// displayPrefixLen - длина начала токена, сохраняемого для отображения
+const displayPrefixLen = 10
+
+// PasswordHashCost - стоимость bcrypt для хешей паролей
+var PasswordHashCost = bcrypt.DefaultCost
+
+// ErrInvalidToken возвращается для неизвестного, отозванного или истекшего токена
+var ErrInvalidToken = errors.New("недействительный токен")
+
+// Identity описывает учетную запись, от имени которой выполняется запрос
+type Identity struct {
+	UserID       string // ID пользователя (владельца URL)
+	CredentialID string // ID сессии или API-ключа
+	Kind         string // Тип учетных данных: "session" или "api_key"
+}
+
+// identityKey - ключ контекста с учетной записью, предъявившей токен
+type identityKey struct{}
+
+// WithIdentity сохраняет в контексте учетную запись, чей токен проверен
+// в текущем запросе. Используется middleware HTTP и перехватчиками gRPC,
+// поэтому проверки ролей читают учетную запись из одного места.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext возвращает учетную запись текущего запроса.
+// Для анонимного пользователя (в том числе с подписанным ID) возвращает false.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// TokenVerifier проверяет токены учетных записей (сессии и API-ключи).
+// Реализуется service.ShortenerService.
+type TokenVerifier interface {
+	// VerifyToken возвращает учетную запись токена или ErrInvalidToken
+	VerifyToken(ctx context.Context, token string) (Identity, error)
+}
+
+// Роли учетных записей.
+const (
+	RoleUser  = "user"  // Пользователь: доступ к своим URL
+	RoleAdmin = "admin" // Администратор: модерация ссылок (/api/admin/*, AdminService)
+)
+
+// RoleChecker проверяет роль учетной записи. Реализуется service.ShortenerService.
+type RoleChecker interface {
+	// HasRole сообщает, есть ли у учетной записи пользователя userID роль role
+	HasRole(ctx context.Context, userID string, role string) (bool, error)
+}
+
+// IsAccountToken сообщает, является ли значение токеном учетной записи
+func IsAccountToken(token string) bool {
+	return strings.HasPrefix(token, SessionTokenPrefix) || strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// NewToken создает случайный токен с префиксом prefix.
+// Возвращает токен, его хеш для хранения и начало токена для отображения.
+func NewToken(prefix string) (token, hash, display string, err error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = prefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), token[:displayPrefixLen], nil
+}
+
+// HashToken возвращает хеш токена для хранения и поиска.
+// Токены содержат 256 бит случайных данных, поэтому достаточно SHA-256.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashPassword возвращает bcrypt хеш пароля
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PasswordHashCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword сравнивает пароль с bcrypt хешем
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// BearerToken извлекает токен из заголовка Authorization
+// (формат "Bearer <token>" или токен без префикса)
+func BearerToken(header string) string {
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return header[7:]
+	}
+	return header
+}
+
+// SetSessionCookie устанавливает куку с токеном сессии
+func SetSessionCookie(w http.ResponseWriter, token string, expiresAt time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  expiresAt,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+	})
+}
+
+// ClearSessionCookie удаляет куку с токеном сессии
+func ClearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+}
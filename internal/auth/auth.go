@@ -1,64 +1,30 @@
 package auth
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/google/uuid"
 )
 
-const (
-	CookieName = "user_id"
-	secretKey  = "your-secret-key-here" // В продакшене должен быть из конфига
-)
+// CookieName - имя куки с подписанным ID анонимного пользователя
+const CookieName = "user_id"
 
 // GenerateUserID создает новый уникальный ID пользователя
 func GenerateUserID() string {
 	return uuid.New().String()
 }
 
-// SignUserID создает подписанную куку с ID пользователя
+// SignUserID подписывает ID пользователя текущим ключом набора,
+// установленного SetKeyring
 func SignUserID(userID string) string {
-	h := hmac.New(sha256.New, []byte(secretKey))
-	h.Write([]byte(userID))
-	signature := hex.EncodeToString(h.Sum(nil))
-	return fmt.Sprintf("%s.%s", userID, signature)
+	return keyring.Load().Sign(userID)
 }
 
-// VerifyUserID проверяет подпись куки и возвращает ID пользователя
+// VerifyUserID проверяет подпись и срок действия токена и возвращает ID пользователя
 func VerifyUserID(signedUserID string) (string, error) {
-	// Разделяем userID и подпись по последней точке
-	lastDotIndex := strings.LastIndex(signedUserID, ".")
-	if lastDotIndex == -1 {
-		return "", errors.New("invalid signed user ID format: missing signature separator")
-	}
-
-	userID := signedUserID[:lastDotIndex]
-	signature := signedUserID[lastDotIndex+1:]
-
-	if userID == "" {
-		return "", errors.New("invalid signed user ID: empty user ID")
-	}
-	if signature == "" {
-		return "", errors.New("invalid signed user ID: empty signature")
-	}
-
-	// Проверяем подпись
-	h := hmac.New(sha256.New, []byte(secretKey))
-	h.Write([]byte(userID))
-	expectedSignature := hex.EncodeToString(h.Sum(nil))
-
-	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
-		return "", errors.New("invalid signature: user ID verification failed")
-	}
-
-	return userID, nil
+	return keyring.Load().Verify(signedUserID)
 }
 
 // GetUserIDFromRequest извлекает и проверяет ID пользователя из куки или заголовка Authorization
@@ -104,7 +70,7 @@ func SetUserIDCookie(w http.ResponseWriter, userID string) {
 		Value:    signedUserID,
 		Path:     "/",
 		HttpOnly: true,
-		MaxAge:   int((24 * time.Hour).Seconds()), // 24 часа
+		MaxAge:   int(keyring.Load().TTL().Seconds()), // Совпадает со сроком действия токена
 	}
 	http.SetCookie(w, cookie)
 }
@@ -114,3 +80,28 @@ func SetAuthorizationHeader(w http.ResponseWriter, userID string) {
 	signedUserID := SignUserID(userID)
 	w.Header().Set("Authorization", signedUserID)
 }
+
+// Mode - режим проверки аутентификации на маршрутах, требующих пользователя
+type Mode string
+
+const (
+	// ModeStrict - подпись и срок действия токена проверяются; при ошибке
+	// HTTP API возвращает 401 с заголовком WWW-Authenticate, gRPC API -
+	// код Unauthenticated.
+	ModeStrict Mode = "strict"
+
+	// ModeLegacy - режим совместимости со старыми автотестами: принимается
+	// кука user_id без проверки подписи, а при ее отсутствии создается новый
+	// пользователь. Не использовать в рабочем окружении.
+	ModeLegacy Mode = "legacy"
+)
+
+// ParseMode проверяет и преобразует строковое значение режима аутентификации
+func ParseMode(value string) (Mode, error) {
+	switch m := Mode(value); m {
+	case ModeStrict, ModeLegacy:
+		return m, nil
+	default:
+		return "", fmt.Errorf("неизвестный режим аутентификации %q (допустимо: strict, legacy)", value)
+	}
+}
@@ -0,0 +1,319 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTokenTTL - срок действия подписанного ID пользователя по умолчанию
+const DefaultTokenTTL = 30 * 24 * time.Hour
+
+// LegacyKeyID - ID ключа, проверяющего токены старого формата
+// <userID>.<подпись> без ключа и срока действия. Такой ключ только
+// проверяет токены и не может быть текущим.
+const LegacyKeyID = "legacy"
+
+// MinSecretLength - минимальная длина секрета ключа подписи
+const MinSecretLength = 16
+
+// maxClockSkew - допустимое расхождение часов для момента выпуска токена
+const maxClockSkew = 5 * time.Minute
+
+// Ошибки проверки подписанного ID пользователя
+var (
+	ErrMalformedToken   = errors.New("некорректный формат токена")
+	ErrUnknownKey       = errors.New("неизвестный ключ подписи")
+	ErrInvalidSignature = errors.New("неверная подпись токена")
+	ErrTokenExpired     = errors.New("срок действия токена истек")
+
+	// ErrNoKeys возвращается LoadKeyring, если ключи подписи не заданы
+	ErrNoKeys = errors.New("ключи подписи не заданы")
+)
+
+// Key - ключ подписи токенов
+type Key struct {
+	ID     string // Идентификатор ключа (kid), передается в токене
+	Secret string // Секрет HMAC-SHA256
+}
+
+// String возвращает ключ в формате "<id>:<секрет>", принятом в AUTH_KEYS
+// и файле ключей
+func (k Key) String() string {
+	return k.ID + ":" + k.Secret
+}
+
+// GenerateKey создает ключ со случайным 256-битным секретом.
+// Если id пустой, используется момент создания в формате 20060102150405,
+// поэтому ключи, добавляемые в конец файла, упорядочены по времени.
+func GenerateKey(id string) (Key, error) {
+	if id == "" {
+		id = time.Now().UTC().Format("20060102150405")
+	}
+	if err := validateKeyID(id); err != nil {
+		return Key{}, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return Key{}, err
+	}
+	return Key{ID: id, Secret: base64.RawURLEncoding.EncodeToString(b)}, nil
+}
+
+// ParseKeys разбирает список ключей в формате "<id>:<секрет>".
+// Ключи разделяются запятыми или переводами строк; пустые строки
+// и строки, начинающиеся с #, пропускаются.
+func ParseKeys(value string) ([]Key, error) {
+	var keys []Key
+	for _, line := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("некорректный ключ подписи %q: ожидается <id>:<секрет>", truncateKey(line))
+		}
+		keys = append(keys, Key{ID: strings.TrimSpace(id), Secret: strings.TrimSpace(secret)})
+	}
+	return keys, nil
+}
+
+// LoadKeysFile читает ключи из файла (формат ParseKeys)
+func LoadKeysFile(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл ключей подписи: %w", err)
+	}
+	keys, err := ParseKeys(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("файл ключей подписи %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// Keyring - набор действующих ключей подписи.
+//
+// Новые токены подписываются текущим ключом, а токены, подписанные
+// остальными ключами, проверяются, пока ключ не удален из набора.
+type Keyring struct {
+	keys    map[string][]byte
+	current string
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewKeyring создает набор ключей.
+//
+// currentID задает ключ подписи новых токенов; пустое значение выбирает
+// последний ключ списка (кроме LegacyKeyID). ttl задает срок действия
+// новых токенов; 0 соответствует DefaultTokenTTL.
+func NewKeyring(keys []Key, currentID string, ttl time.Duration) (*Keyring, error) {
+	if ttl < 0 {
+		return nil, fmt.Errorf("некорректный срок действия токена: %s", ttl)
+	}
+	if ttl == 0 {
+		ttl = DefaultTokenTTL
+	}
+
+	k := &Keyring{keys: make(map[string][]byte, len(keys)), ttl: ttl, now: time.Now}
+	for _, key := range keys {
+		if err := validateKeyID(key.ID); err != nil {
+			return nil, err
+		}
+		if _, exists := k.keys[key.ID]; exists {
+			return nil, fmt.Errorf("ключ подписи %q задан несколько раз", key.ID)
+		}
+		if len(key.Secret) < MinSecretLength {
+			return nil, fmt.Errorf("секрет ключа подписи %q короче %d символов", key.ID, MinSecretLength)
+		}
+		k.keys[key.ID] = []byte(key.Secret)
+		if currentID == "" && key.ID != LegacyKeyID {
+			k.current = key.ID
+		}
+	}
+
+	if currentID != "" {
+		if _, ok := k.keys[currentID]; !ok {
+			return nil, fmt.Errorf("текущий ключ подписи %q не найден", currentID)
+		}
+		k.current = currentID
+	}
+	if k.current == "" || k.current == LegacyKeyID {
+		return nil, errors.New("не задан ключ подписи новых токенов")
+	}
+	return k, nil
+}
+
+// LoadKeyring создает набор из ключей keys (формат ParseKeys) и ключей
+// файла keysFile. Если ключи не заданы, возвращает ErrNoKeys.
+func LoadKeyring(keys, keysFile, currentID string, ttl time.Duration) (*Keyring, error) {
+	parsed, err := ParseKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	if keysFile != "" {
+		fileKeys, err := LoadKeysFile(keysFile)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, fileKeys...)
+	}
+	if len(parsed) == 0 {
+		return nil, ErrNoKeys
+	}
+	return NewKeyring(parsed, currentID, ttl)
+}
+
+// NewEphemeralKeyring создает набор из одного случайного ключа.
+// Токены, подписанные таким ключом, не проверяются после перезапуска
+// процесса и другими экземплярами сервиса.
+func NewEphemeralKeyring(ttl time.Duration) (*Keyring, error) {
+	key, err := GenerateKey("ephemeral")
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring([]Key{key}, "", ttl)
+}
+
+// CurrentKeyID возвращает ID ключа подписи новых токенов
+func (k *Keyring) CurrentKeyID() string {
+	return k.current
+}
+
+// TTL возвращает срок действия новых токенов
+func (k *Keyring) TTL() time.Duration {
+	return k.ttl
+}
+
+// Sign подписывает ID пользователя текущим ключом.
+// Формат токена: <userID>.<kid>.<iat>.<exp>.<подпись>, где iat и exp -
+// моменты выпуска и истечения в секундах Unix.
+func (k *Keyring) Sign(userID string) string {
+	now := k.now()
+	payload := strings.Join([]string{
+		userID,
+		k.current,
+		strconv.FormatInt(now.Unix(), 10),
+		strconv.FormatInt(now.Add(k.ttl).Unix(), 10),
+	}, ".")
+	return payload + "." + sign(k.keys[k.current], payload)
+}
+
+// Verify проверяет подпись и срок действия токена и возвращает ID пользователя
+func (k *Keyring) Verify(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	switch len(parts) {
+	case 2:
+		return k.verifyLegacy(parts[0], parts[1])
+	case 5:
+	default:
+		return "", ErrMalformedToken
+	}
+
+	userID, kid, signature := parts[0], parts[1], parts[4]
+	if userID == "" || signature == "" {
+		return "", ErrMalformedToken
+	}
+	issuedAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", ErrMalformedToken
+	}
+	expiresAt, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return "", ErrMalformedToken
+	}
+
+	secret, ok := k.keys[kid]
+	if !ok || kid == LegacyKeyID {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	payload := token[:len(token)-len(signature)-1]
+	if !hmac.Equal([]byte(signature), []byte(sign(secret, payload))) {
+		return "", ErrInvalidSignature
+	}
+
+	now := k.now()
+	if now.Unix() >= expiresAt {
+		return "", ErrTokenExpired
+	}
+	if time.Unix(issuedAt, 0).After(now.Add(maxClockSkew)) {
+		return "", fmt.Errorf("%w: момент выпуска в будущем", ErrMalformedToken)
+	}
+	return userID, nil
+}
+
+// verifyLegacy проверяет токен старого формата ключом LegacyKeyID
+func (k *Keyring) verifyLegacy(userID, signature string) (string, error) {
+	if userID == "" || signature == "" {
+		return "", ErrMalformedToken
+	}
+	secret, ok := k.keys[LegacyKeyID]
+	if !ok {
+		return "", fmt.Errorf("%w: токен старого формата", ErrUnknownKey)
+	}
+	if !hmac.Equal([]byte(signature), []byte(sign(secret, userID))) {
+		return "", ErrInvalidSignature
+	}
+	return userID, nil
+}
+
+// sign вычисляет HMAC-SHA256 подпись данных
+func sign(secret []byte, payload string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// validateKeyID проверяет, что ID ключа можно передать в токене
+func validateKeyID(id string) error {
+	if id == "" {
+		return errors.New("пустой ID ключа подписи")
+	}
+	if strings.ContainsAny(id, ".:, \t\n") {
+		return fmt.Errorf("ID ключа подписи %q содержит недопустимые символы", id)
+	}
+	return nil
+}
+
+// truncateKey сокращает строку ключа для сообщения об ошибке,
+// чтобы не выводить секрет целиком
+func truncateKey(line string) string {
+	if len(line) > 8 {
+		return line[:8] + "..."
+	}
+	return line
+}
+
+// keyring - набор ключей, используемый SignUserID и VerifyUserID
+var keyring atomic.Pointer[Keyring]
+
+func init() {
+	// До вызова SetKeyring токены подписываются случайным ключом процесса
+	k, err := NewEphemeralKeyring(0)
+	if err != nil {
+		panic(err)
+	}
+	keyring.Store(k)
+}
+
+// SetKeyring устанавливает набор ключей для SignUserID и VerifyUserID
+func SetKeyring(k *Keyring) {
+	keyring.Store(k)
+}
+
+// CurrentKeyring возвращает набор ключей, установленный SetKeyring
+func CurrentKeyring() *Keyring {
+	return keyring.Load()
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSecretOld = "old-secret-0123456789"
+	testSecretNew = "new-secret-0123456789"
+)
+
+func TestKeyring_Rotation(t *testing.T) {
+	old, err := NewKeyring([]Key{{ID: "k1", Secret: testSecretOld}}, "", time.Hour)
+	require.NoError(t, err)
+	oldToken := old.Sign("user1")
+
+	// Новый ключ становится текущим, старый продолжает проверять токены
+	rotated, err := NewKeyring([]Key{
+		{ID: "k1", Secret: testSecretOld},
+		{ID: "k2", Secret: testSecretNew},
+	}, "", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rotated.CurrentKeyID())
+
+	userID, err := rotated.Verify(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", userID)
+
+	newToken := rotated.Sign("user2")
+	assert.Equal(t, "k2", strings.Split(newToken, ".")[1])
+	_, err = old.Verify(newToken)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// После удаления старого ключа его токены отклоняются
+	retired, err := NewKeyring([]Key{{ID: "k2", Secret: testSecretNew}}, "", time.Hour)
+	require.NoError(t, err)
+	_, err = retired.Verify(oldToken)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	_, err = retired.Verify(newToken)
+	assert.NoError(t, err)
+
+	// Текущий ключ можно выбрать явно, например до распространения нового ключа
+	pinned, err := NewKeyring([]Key{
+		{ID: "k1", Secret: testSecretOld},
+		{ID: "k2", Secret: testSecretNew},
+	}, "k1", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", pinned.CurrentKeyID())
+}
+
+func TestKeyring_Verify(t *testing.T) {
+	k, err := NewKeyring([]Key{{ID: "k1", Secret: testSecretOld}}, "", time.Hour)
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+	k.now = func() time.Time { return now }
+	token := k.Sign("user1")
+
+	tests := []struct {
+		name    string
+		token   string
+		at      time.Time
+		wantErr error
+	}{
+		{name: "действующий", token: token, at: now.Add(59 * time.Minute)},
+		{name: "истекший", token: token, at: now.Add(time.Hour), wantErr: ErrTokenExpired},
+		{name: "выпущен в будущем", token: token, at: now.Add(-time.Hour), wantErr: ErrMalformedToken},
+		{name: "подмененный пользователь", token: "user2" + strings.TrimPrefix(token, "user1"), at: now, wantErr: ErrInvalidSignature},
+		{name: "продленный срок", token: strings.Replace(token, ".1700003600.", ".1900003600.", 1), at: now, wantErr: ErrInvalidSignature},
+		{name: "без подписи", token: "user1", at: now, wantErr: ErrMalformedToken},
+		{name: "старый формат без ключа legacy", token: "user1.abcdef", at: now, wantErr: ErrUnknownKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k.now = func() time.Time { return tt.at }
+			userID, err := k.Verify(tt.token)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user1", userID)
+		})
+	}
+}
+
+func TestKeyring_Legacy(t *testing.T) {
+	const legacySecret = "your-secret-key-here"
+	h := hmac.New(sha256.New, []byte(legacySecret))
+	h.Write([]byte("user1"))
+	legacyToken := "user1." + hex.EncodeToString(h.Sum(nil))
+
+	k, err := NewKeyring([]Key{
+		{ID: LegacyKeyID, Secret: legacySecret},
+		{ID: "k1", Secret: testSecretNew},
+	}, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", k.CurrentKeyID())
+	assert.Equal(t, DefaultTokenTTL, k.TTL())
+
+	userID, err := k.Verify(legacyToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", userID)
+
+	// Ключом legacy нельзя подписывать новые токены
+	_, err = NewKeyring([]Key{{ID: LegacyKeyID, Secret: legacySecret}}, "", 0)
+	assert.Error(t, err)
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []Key
+		current string
+	}{
+		{name: "нет ключей"},
+		{name: "короткий секрет", keys: []Key{{ID: "k1", Secret: "short"}}},
+		{name: "точка в ID", keys: []Key{{ID: "k.1", Secret: testSecretOld}}},
+		{name: "повтор ID", keys: []Key{{ID: "k1", Secret: testSecretOld}, {ID: "k1", Secret: testSecretNew}}},
+		{name: "неизвестный текущий ключ", keys: []Key{{ID: "k1", Secret: testSecretOld}}, current: "k2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys, tt.current, time.Hour)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	generated, err := GenerateKey("")
+	require.NoError(t, err)
+	assert.Len(t, generated.ID, len("20060102150405"))
+
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# ключи подписи\nk1:" + testSecretOld + "\n\n" + generated.String() + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	k, err := LoadKeyring("k0:"+testSecretNew, path, "", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, generated.ID, k.CurrentKeyID())
+
+	_, err = LoadKeyring("", "", "", time.Hour)
+	assert.ErrorIs(t, err, ErrNoKeys)
+
+	_, err = LoadKeyring("no-separator", "", "", time.Hour)
+	assert.Error(t, err)
+}
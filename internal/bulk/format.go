@@ -0,0 +1,67 @@
+// Package bulk реализует импорт и выгрузку URL пользователя в форматах
+// CSV и JSONL. Используется HTTP API и подкомандами shortener import|export.
+package bulk
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Format - формат импорта и выгрузки
+type Format string
+
+const (
+	FormatCSV   Format = "csv"   // CSV с заголовком
+	FormatJSONL Format = "jsonl" // JSON-объект в каждой строке
+)
+
+// ParseFormat проверяет и преобразует строковое значение формата.
+// Значение ndjson равнозначно jsonl.
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(value) {
+	case "csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("неизвестный формат %q (допустимо: csv, jsonl)", value)
+	}
+}
+
+// FormatFromContentType определяет формат по заголовку Content-Type.
+// Возвращает false для типа, не соответствующего ни одному формату.
+func FormatFromContentType(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, true
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return FormatJSONL, true
+	default:
+		return "", false
+	}
+}
+
+// FormatFromPath определяет формат по расширению файла:
+// .jsonl и .ndjson соответствуют FormatJSONL, остальные - FormatCSV.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	default:
+		return FormatCSV
+	}
+}
+
+// ContentType возвращает значение заголовка Content-Type для формата
+func (f Format) ContentType() string {
+	if f == FormatJSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
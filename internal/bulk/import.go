@@ -0,0 +1,168 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
+)
+
+// ImportBatchSize - количество строк, записываемых в хранилище одной операцией
+const ImportBatchSize = 100
+
+// StatusAborted - статус результата, завершающего прерванный импорт
+const StatusAborted = "aborted"
+
+// ErrInvalidData оборачивает ошибку чтения входных данных, прервавшую импорт
+var ErrInvalidData = errors.New("ошибка чтения данных импорта")
+
+// ErrTooManyRows возвращается, когда данные содержат больше строк,
+// чем разрешает ImportOptions.MaxRows
+var ErrTooManyRows = errors.New("превышено количество строк импорта")
+
+// ImportOptions содержит ограничения импорта
+type ImportOptions struct {
+	// MaxRows - максимальное количество строк (0 - без ограничения).
+	// Строка сверх предела прерывает импорт с ErrTooManyRows
+	// до записи накопленного пакета.
+	MaxRows int
+
+	// BeforeBatch вызывается перед записью каждого пакета с количеством
+	// его строк; ошибка прерывает импорт до записи пакета. HTTP API
+	// учитывает так пакеты в ограничении частоты запросов.
+	BeforeBatch func(ctx context.Context, rows int) error
+}
+
+// Summary - итог импорта
+type Summary struct {
+	Created  int // Созданы новые короткие URL
+	Existing int // URL были сокращены ранее
+	Failed   int // Строки с ошибкой
+}
+
+// Import импортирует строки из r в URL пользователя userID и передает
+// результат каждой строки в emit в порядке входных строк.
+//
+// Строки записываются пакетами по ImportBatchSize через
+// service.CreateShortURLEach, поэтому проверки и дедупликация совпадают
+// с пакетным сокращением, а некорректная строка (ошибка разбора, пустой URL,
+// нарушение политики, недопустимый или занятый псевдоним) получает статус
+// error, не прерывая импорт. Ошибка чтения данных (ErrInvalidData),
+// превышение opts.MaxRows (ErrTooManyRows), ошибка opts.BeforeBatch, сбой
+// хранилища или ошибка emit прерывают импорт: строки, для которых emit уже
+// вызван, сохранены.
+func Import(ctx context.Context, svc *service.ShortenerService, userID string, r *Reader, opts ImportOptions, emit func(models.ImportResult) error) (Summary, error) {
+	var summary Summary
+	report := func(result models.ImportResult) error {
+		switch result.Status {
+		case string(storage.RecordCreated):
+			summary.Created++
+		case string(storage.RecordExisting):
+			summary.Existing++
+		default:
+			summary.Failed++
+		}
+		return emit(result)
+	}
+
+	rows := make([]Row, 0, ImportBatchSize)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		if opts.BeforeBatch != nil {
+			if err := opts.BeforeBatch(ctx, len(rows)); err != nil {
+				return err
+			}
+		}
+		err := importRows(ctx, svc, userID, rows, report)
+		rows = rows[:0]
+		return err
+	}
+
+	count := 0
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return summary, fmt.Errorf("%w: %w", ErrInvalidData, err)
+		}
+
+		count++
+		if opts.MaxRows > 0 && count > opts.MaxRows {
+			return summary, fmt.Errorf("%w: больше %d", ErrTooManyRows, opts.MaxRows)
+		}
+
+		rows = append(rows, row)
+		if len(rows) == ImportBatchSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	return summary, flush()
+}
+
+// importRows записывает пакет строк и передает результаты в report
+func importRows(ctx context.Context, svc *service.ShortenerService, userID string, rows []Row, report func(models.ImportResult) error) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	items := make([]service.BatchItem, 0, len(rows))
+	for _, row := range rows {
+		if row.Err != nil {
+			continue
+		}
+		items = append(items, service.BatchItem{
+			CorrelationID: strconv.Itoa(row.Line),
+			OriginalURL:   row.URL,
+			Alias:         row.Alias,
+			ExpiresAt:     row.ExpiresAt,
+		})
+	}
+
+	var results []service.BatchResult
+	if len(items) > 0 {
+		var err error
+		results, err = svc.CreateShortURLEach(ctx, items, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Результаты сервиса идут в порядке записанных строк,
+	// строки с ошибкой разбора вставляются на свои места
+	for _, row := range rows {
+		result := models.ImportResult{Line: row.Line, OriginalURL: row.URL}
+		if row.Err != nil {
+			result.Status = string(storage.RecordFailed)
+			result.Error = row.Err.Error()
+		} else {
+			batchResult := results[0]
+			results = results[1:]
+			result.ShortURL = batchResult.ShortURL
+			result.Status = string(batchResult.Status)
+			if batchResult.Error != nil {
+				result.Error = batchResult.Error.Error()
+			}
+			var policyErr *urlpolicy.Error
+			if errors.As(batchResult.Error, &policyErr) {
+				result.ErrorCode = string(policyErr.Code)
+			}
+		}
+		if err := report(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
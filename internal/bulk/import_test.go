@@ -0,0 +1,148 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestService создает сервис над хранилищем в памяти
+func newTestService(t *testing.T) *service.ShortenerService {
+	t.Helper()
+	logger.Logger = zap.NewNop()
+	store := storage.NewMemoryStorage("")
+	t.Cleanup(func() { store.Close() })
+	return service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+}
+
+// importString импортирует строки input и возвращает результаты строк
+func importString(t *testing.T, svc *service.ShortenerService, userID, input string, format Format) ([]models.ImportResult, Summary, error) {
+	t.Helper()
+	r, err := NewReader(strings.NewReader(input), format)
+	require.NoError(t, err)
+
+	var results []models.ImportResult
+	summary, err := Import(context.Background(), svc, userID, r, ImportOptions{}, func(result models.ImportResult) error {
+		results = append(results, result)
+		return nil
+	})
+	return results, summary, err
+}
+
+func TestImport(t *testing.T) {
+	svc := newTestService(t)
+
+	require.NoError(t, svc.CreateShortURL(context.Background(), "https://example.com/existing", "user1").Error)
+
+	input := "url,alias\n" +
+		"https://example.com/1,my-link\n" +
+		"https://example.com/existing,\n" +
+		"http://127.0.0.1/admin,\n" +
+		"https://example.com/2,bad alias\n" +
+		"\"broken\n"
+	results, summary, err := importString(t, svc, "user1", input, FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Created: 1, Existing: 1, Failed: 3}, summary)
+
+	// Результаты идут в порядке строк, ошибка строки не прерывает импорт
+	require.Len(t, results, 5)
+	assert.Equal(t, models.ImportResult{
+		Line:        2,
+		OriginalURL: "https://example.com/1",
+		ShortURL:    "http://localhost:8080/my-link",
+		Status:      string(storage.RecordCreated),
+	}, results[0])
+	assert.Equal(t, 3, results[1].Line)
+	assert.Equal(t, string(storage.RecordExisting), results[1].Status)
+	assert.Equal(t, string(urlpolicy.CodePrivateAddress), results[2].ErrorCode)
+	for _, result := range results[2:] {
+		assert.Equal(t, string(storage.RecordFailed), result.Status, result.Line)
+		assert.NotEmpty(t, result.Error, result.Line)
+		assert.Empty(t, result.ShortURL, result.Line)
+	}
+	assert.Equal(t, []int{4, 5, 6}, []int{results[2].Line, results[3].Line, results[4].Line})
+
+	// Повторный псевдоним в том же импорте отклоняется только у своей строки
+	results, summary, err = importString(t, svc, "user1",
+		`{"url":"https://example.com/3","alias":"my-link"}`+"\n"+`{"url":"https://example.com/4"}`+"\n", FormatJSONL)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Created: 1, Failed: 1}, summary)
+	assert.Equal(t, string(storage.RecordFailed), results[0].Status)
+	assert.Equal(t, string(storage.RecordCreated), results[1].Status)
+}
+
+func TestImport_Batches(t *testing.T) {
+	svc := newTestService(t)
+
+	var input strings.Builder
+	input.WriteString("url\n")
+	const count = ImportBatchSize*2 + 1
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&input, "https://example.com/%d\n", i)
+	}
+
+	results, summary, err := importString(t, svc, "user1", input.String(), FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Created: count}, summary)
+	for i, result := range results {
+		assert.Equal(t, i+2, result.Line)
+		assert.Equal(t, fmt.Sprintf("https://example.com/%d", i), result.OriginalURL)
+	}
+
+	// Ошибка emit прерывает импорт
+	r, err := NewReader(strings.NewReader(input.String()), FormatCSV)
+	require.NoError(t, err)
+	errStop := errors.New("stop")
+	_, err = Import(context.Background(), svc, "user2", r, ImportOptions{}, func(models.ImportResult) error { return errStop })
+	assert.ErrorIs(t, err, errStop)
+
+	// Ошибка чтения данных прерывает импорт с ErrInvalidData
+	_, _, err = importString(t, svc, "user3", strings.Repeat("x", MaxLineSize+1), FormatJSONL)
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestWriter_RoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			svc := newTestService(t)
+			ctx := context.Background()
+			for _, url := range []string{"https://example.com/1", "https://example.com/2"} {
+				require.NoError(t, svc.CreateShortURL(ctx, url, "user1").Error)
+			}
+
+			var buf bytes.Buffer
+			w := NewWriter(&buf, format)
+			require.NoError(t, svc.ExportUserURLs(ctx, "user1", service.UserURLsQuery{}, w.Write))
+			require.NoError(t, w.Flush())
+
+			// Выгрузка импортируется в другое хранилище без изменений
+			results, summary, err := importString(t, newTestService(t), "user1", buf.String(), format)
+			require.NoError(t, err)
+			assert.Equal(t, Summary{Created: 2}, summary)
+			var originals []string
+			for _, result := range results {
+				originals = append(originals, result.OriginalURL)
+			}
+			assert.ElementsMatch(t, []string{"https://example.com/1", "https://example.com/2"}, originals)
+		})
+	}
+
+	// Пустая выгрузка в CSV содержит заголовок
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatCSV)
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "short_url,original_url,created_at\n", buf.String())
+}
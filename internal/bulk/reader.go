@@ -0,0 +1,157 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MaxLineSize ограничивает длину строки JSONL
+const MaxLineSize = 1 << 20
+
+// ErrNoURLColumn возвращается для CSV без столбца url в заголовке
+var ErrNoURLColumn = errors.New("в заголовке CSV нет столбца url или original_url")
+
+// Row - строка импорта
+type Row struct {
+	Line      int        // Номер строки во входных данных (с 1)
+	URL       string     // Оригинальный URL
+	Alias     string     // Желаемый короткий ID (опционально)
+	ExpiresAt *time.Time // Момент истечения срока действия (опционально)
+
+	// Err - ошибка разбора строки; такая строка не импортируется,
+	// но получает результат с ошибкой
+	Err error
+}
+
+// Reader читает строки импорта из CSV или JSONL.
+//
+// CSV начинается с заголовка: столбец url (или original_url) обязателен,
+// alias и expires_at (RFC 3339) необязательны, остальные столбцы
+// игнорируются. Строка JSONL - объект с теми же полями; пустые строки
+// пропускаются. Поэтому выгрузка ExportUserURLs в любом формате
+// импортируется без изменений.
+type Reader struct {
+	next func() (Row, error)
+}
+
+// NewReader создает Reader для данных в формате format.
+// Для CSV сразу читает заголовок и возвращает ErrNoURLColumn,
+// если в нем нет столбца URL.
+func NewReader(r io.Reader, format Format) (*Reader, error) {
+	if format == FormatJSONL {
+		return newJSONLReader(r), nil
+	}
+	return newCSVReader(r)
+}
+
+// Read возвращает следующую строку. Ошибка разбора строки возвращается
+// в Row.Err, а ошибка чтения данных - вторым значением; в конце данных
+// возвращается io.EOF.
+func (r *Reader) Read() (Row, error) {
+	return r.next()
+}
+
+// newCSVReader создает Reader для CSV
+func newCSVReader(r io.Reader) (*Reader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrNoURLColumn
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения заголовка CSV: %w", err)
+	}
+
+	columns := map[string]int{"url": -1, "alias": -1, "expires_at": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "original_url" {
+			name = "url"
+		}
+		if index, ok := columns[name]; ok && index < 0 {
+			columns[name] = i
+		}
+	}
+	if columns["url"] < 0 {
+		return nil, ErrNoURLColumn
+	}
+
+	field := func(record []string, name string) string {
+		if i := columns[name]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	return &Reader{next: func() (Row, error) {
+		record, err := cr.Read()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Row{Line: parseErr.StartLine, Err: fmt.Errorf("некорректная строка CSV: %w", parseErr.Err)}, nil
+		}
+		if err != nil {
+			return Row{}, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := Row{Line: line, URL: field(record, "url"), Alias: field(record, "alias")}
+		if value := field(record, "expires_at"); value != "" {
+			expiresAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				row.Err = fmt.Errorf("некорректный expires_at %q: ожидается RFC 3339", value)
+				return row, nil
+			}
+			row.ExpiresAt = &expiresAt
+		}
+		return row, nil
+	}}, nil
+}
+
+// jsonlRow - строка JSONL
+type jsonlRow struct {
+	URL         string     `json:"url"`
+	OriginalURL string     `json:"original_url"`
+	Alias       string     `json:"alias"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// newJSONLReader создает Reader для JSONL
+func newJSONLReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+	line := 0
+
+	return &Reader{next: func() (Row, error) {
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			var value jsonlRow
+			if err := json.Unmarshal(data, &value); err != nil {
+				return Row{Line: line, Err: fmt.Errorf("некорректный JSON: %w", err)}, nil
+			}
+			row := Row{Line: line, URL: value.URL, Alias: value.Alias, ExpiresAt: value.ExpiresAt}
+			if row.URL == "" {
+				row.URL = value.OriginalURL
+			}
+			return row, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return Row{}, fmt.Errorf("ошибка чтения строки %d: %w", line+1, err)
+		}
+		return Row{}, io.EOF
+	}}
+}
@@ -0,0 +1,113 @@
+package bulk
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll читает все строки импорта
+func readAll(t *testing.T, r *Reader) []Row {
+	t.Helper()
+	var rows []Row
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestReader_CSV(t *testing.T) {
+	input := "\ufeffAlias, URL ,comment,expires_at\n" +
+		"my-link,https://example.com/1,first,2030-01-01T00:00:00Z\n" +
+		",https://example.com/2\n" +
+		"\n" +
+		"bad,https://example.com/3,,tomorrow\n" +
+		"\"broken,https://example.com/4\n"
+	r, err := NewReader(strings.NewReader(input), FormatCSV)
+	require.NoError(t, err)
+
+	rows := readAll(t, r)
+	require.Len(t, rows, 4)
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, Row{Line: 2, URL: "https://example.com/1", Alias: "my-link", ExpiresAt: &expiresAt}, rows[0])
+	assert.Equal(t, Row{Line: 3, URL: "https://example.com/2"}, rows[1])
+
+	// Некорректный expires_at и незакрытая кавычка - ошибки строк
+	assert.Equal(t, 5, rows[2].Line)
+	assert.Error(t, rows[2].Err)
+	assert.Equal(t, 6, rows[3].Line)
+	assert.Error(t, rows[3].Err)
+}
+
+func TestReader_CSVHeader(t *testing.T) {
+	// Выгрузка в CSV читается как импорт по столбцу original_url
+	r, err := NewReader(strings.NewReader("short_url,original_url,created_at\nhttp://s/1,https://example.com,\n"), FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, []Row{{Line: 2, URL: "https://example.com"}}, readAll(t, r))
+
+	for _, input := range []string{"", "alias,comment\nx,y\n"} {
+		_, err := NewReader(strings.NewReader(input), FormatCSV)
+		assert.ErrorIs(t, err, ErrNoURLColumn, input)
+	}
+}
+
+func TestReader_JSONL(t *testing.T) {
+	input := `{"url":"https://example.com/1","alias":"my-link","expires_at":"2030-01-01T00:00:00Z"}` + "\n" +
+		"\n" +
+		`{"short_url":"http://s/2","original_url":"https://example.com/2"}` + "\n" +
+		`{"url":` + "\n"
+	r, err := NewReader(strings.NewReader(input), FormatJSONL)
+	require.NoError(t, err)
+
+	rows := readAll(t, r)
+	require.Len(t, rows, 3)
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, Row{Line: 1, URL: "https://example.com/1", Alias: "my-link", ExpiresAt: &expiresAt}, rows[0])
+	assert.Equal(t, Row{Line: 3, URL: "https://example.com/2"}, rows[1])
+	assert.Equal(t, 4, rows[2].Line)
+	assert.Error(t, rows[2].Err)
+
+	// Слишком длинная строка прерывает чтение
+	r, err = NewReader(strings.NewReader(strings.Repeat("x", MaxLineSize+1)), FormatJSONL)
+	require.NoError(t, err)
+	_, err = r.Read()
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, io.EOF)
+}
+
+func TestFormat(t *testing.T) {
+	for value, want := range map[string]Format{"csv": FormatCSV, "JSONL": FormatJSONL, "ndjson": FormatJSONL} {
+		format, err := ParseFormat(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, format, value)
+	}
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+
+	for contentType, want := range map[string]Format{
+		"text/csv; charset=utf-8": FormatCSV,
+		"application/x-ndjson":    FormatJSONL,
+		"application/jsonl":       FormatJSONL,
+	} {
+		format, ok := FormatFromContentType(contentType)
+		assert.True(t, ok, contentType)
+		assert.Equal(t, want, format, contentType)
+	}
+	_, ok := FormatFromContentType("application/json")
+	assert.False(t, ok)
+
+	assert.Equal(t, FormatJSONL, FormatFromPath("urls.NDJSON"))
+	assert.Equal(t, FormatCSV, FormatFromPath("urls.txt"))
+	assert.Equal(t, FormatCSV, FormatFromPath("-"))
+}
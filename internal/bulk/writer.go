@@ -0,0 +1,69 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// exportHeader - заголовок выгрузки в CSV
+var exportHeader = []string{"short_url", "original_url", "created_at"}
+
+// Writer записывает выгрузку URL пользователя в CSV или JSONL.
+// Данные буферизуются: после последней записи нужно вызвать Flush.
+//
+// CSV содержит заголовок short_url,original_url,created_at, строка JSONL -
+// объект models.UserURL. Момент создания записывается в RFC 3339 и
+// отсутствует у ссылок, созданных до появления отметки времени создания.
+type Writer struct {
+	csv     *csv.Writer
+	jsonl   *bufio.Writer
+	encoder *json.Encoder
+	err     error
+}
+
+// NewWriter создает Writer для формата format. Заголовок CSV
+// записывается в буфер сразу, поэтому пустая выгрузка содержит заголовок.
+func NewWriter(w io.Writer, format Format) *Writer {
+	if format == FormatJSONL {
+		buffered := bufio.NewWriter(w)
+		return &Writer{jsonl: buffered, encoder: json.NewEncoder(buffered)}
+	}
+
+	cw := csv.NewWriter(w)
+	return &Writer{csv: cw, err: cw.Write(exportHeader)}
+}
+
+// Write записывает URL пользователя
+func (w *Writer) Write(userURL models.UserURL) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.encoder != nil {
+		w.err = w.encoder.Encode(userURL)
+		return w.err
+	}
+
+	var createdAt string
+	if userURL.CreatedAt != nil {
+		createdAt = userURL.CreatedAt.Format(time.RFC3339)
+	}
+	w.err = w.csv.Write([]string{userURL.ShortURL, userURL.OriginalURL, createdAt})
+	return w.err
+}
+
+// Flush записывает буферизованные данные
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.jsonl != nil {
+		return w.jsonl.Flush()
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
@@ -9,43 +9,92 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Константы для значений по умолчанию
 const (
-	DefaultServerAddress = ":8080"                 // Адрес HTTP сервера по умолчанию
-	DefaultBaseURL       = "http://localhost:8080" // Базовый URL для коротких ссылок
-	DefaultFileStorage   = "storage.json"          // Файл для хранения URL
-	DefaultDatabaseDSN   = ""                      // DSN базы данных (пустой = не используется)
-	DefaultProfilingPort = ":6060"                 // Порт для pprof endpoints
-	DefaultProfilesDir   = "benchmarks/profiles"   // Директория для профилей производительности
-	DefaultCertFile      = "cert.pem"              // Файл сертификата для HTTPS
-	DefaultKeyFile       = "key.pem"               // Файл приватного ключа для HTTPS
-	DefaultConfigFile    = ""                      // Файл конфигурации JSON (пустой = не используется)
-	DefaultGRPCAddress   = ":3200"                 // Адрес gRPC сервера по умолчанию
-	DefaultGRPCCertFile  = "grpc_cert.pem"         // Файл сертификата для gRPC TLS
-	DefaultGRPCKeyFile   = "grpc_key.pem"          // Файл приватного ключа для gRPC TLS
+	DefaultServerAddress       = ":8080"                 // Адрес HTTP сервера по умолчанию
+	DefaultBaseURL             = "http://localhost:8080" // Базовый URL для коротких ссылок
+	DefaultFileStorage         = "storage.json"          // Файл для хранения URL
+	DefaultDatabaseDSN         = ""                      // DSN базы данных (пустой = не используется)
+	DefaultProfilingPort       = ":6060"                 // Порт для pprof endpoints
+	DefaultProfilesDir         = "benchmarks/profiles"   // Директория для профилей производительности
+	DefaultCertFile            = "cert.pem"              // Файл сертификата для HTTPS
+	DefaultKeyFile             = "key.pem"               // Файл приватного ключа для HTTPS
+	DefaultConfigFile          = ""                      // Файл конфигурации JSON (пустой = не используется)
+	DefaultGRPCAddress         = ":3200"                 // Адрес gRPC сервера по умолчанию
+	DefaultGRPCCertFile        = "grpc_cert.pem"         // Файл сертификата для gRPC TLS
+	DefaultGRPCKeyFile         = "grpc_key.pem"          // Файл приватного ключа для gRPC TLS
+	DefaultReaperInterval      = time.Minute             // Интервал фоновой очистки истекших ссылок
+	DefaultCompactionThreshold = 10000                   // Количество записей журнала до автоматического компактирования
+	DefaultDurability          = "async"                 // Режим надежности записи файлового хранилища
+	DefaultIDGenerator         = "random"                // Стратегия генерации коротких ID
+	DefaultIDLength            = 8                       // Длина генерируемых коротких ID
+	DefaultDedupScope          = "per-user"              // Область дедупликации оригинальных URL
+	DefaultCacheBackend        = "none"                  // Хранилище кэша ссылок перед PostgreSQL
+	DefaultCacheSize           = 10000                   // Максимальное количество ссылок в LRU-кэше
+	DefaultCacheTTL            = 5 * time.Minute         // Время жизни ссылки в кэше
+	DefaultTraceExporter       = "none"                  // Экспортер span'ов трассировки
+	DefaultTraceServiceName    = "shortener"             // Имя сервиса в выгружаемых span'ах
+	DefaultAuthTokenTTL        = 30 * 24 * time.Hour     // Срок действия подписанного ID пользователя
+	DefaultAuthMode            = "strict"                // Режим проверки аутентификации
+	DefaultRateLimitStore      = "memory"                // Хранилище корзин ограничения запросов
+	DefaultURLAllowedSchemes   = "http,https"            // Разрешенные схемы оригинальных URL
+	DefaultURLMaxLength        = 2048                    // Максимальная длина оригинального URL
+	DefaultImportMaxBytes      = 10 << 20                // Максимальный размер тела импорта URL (10 МиБ)
+	DefaultImportMaxRows       = 10000                   // Максимальное количество строк импорта URL
 )
 
 // JSONConfig представляет структуру JSON файла конфигурации.
 // Все поля опциональны и используются только если заданы в файле.
 type JSONConfig struct {
-	ServerAddress    *string `json:"server_address,omitempty"`    // Адрес HTTP сервера
-	BaseURL          *string `json:"base_url,omitempty"`          // Базовый URL для коротких ссылок
-	FileStoragePath  *string `json:"file_storage_path,omitempty"` // Путь к файлу хранения URL
-	DatabaseDSN      *string `json:"database_dsn,omitempty"`      // DSN базы данных
-	ProfilingEnabled *bool   `json:"profiling_enabled,omitempty"` // Включить профилирование
-	ProfilingPort    *string `json:"profiling_port,omitempty"`    // Порт для pprof endpoints
-	ProfilesDir      *string `json:"profiles_dir,omitempty"`      // Директория для профилей
-	EnableHTTPS      *bool   `json:"enable_https,omitempty"`      // Включить HTTPS сервер
-	CertFile         *string `json:"cert_file,omitempty"`         // Путь к файлу сертификата
-	KeyFile          *string `json:"key_file,omitempty"`          // Путь к файлу приватного ключа
-	TrustedSubnet    *string `json:"trusted_subnet,omitempty"`    // Доверенная подсеть CIDR
-	EnableGRPC       *bool   `json:"enable_grpc,omitempty"`       // Включить gRPC сервер
-	GRPCAddress      *string `json:"grpc_address,omitempty"`      // Адрес gRPC сервера
-	GRPCCertFile     *string `json:"grpc_cert_file,omitempty"`    // Путь к файлу сертификата для gRPC
-	GRPCKeyFile      *string `json:"grpc_key_file,omitempty"`     // Путь к файлу приватного ключа для gRPC
+	ServerAddress       *string `json:"server_address,omitempty"`       // Адрес HTTP сервера
+	BaseURL             *string `json:"base_url,omitempty"`             // Базовый URL для коротких ссылок
+	FileStoragePath     *string `json:"file_storage_path,omitempty"`    // Путь к файлу хранения URL
+	DatabaseDSN         *string `json:"database_dsn,omitempty"`         // DSN базы данных
+	ProfilingEnabled    *bool   `json:"profiling_enabled,omitempty"`    // Включить профилирование
+	ProfilingPort       *string `json:"profiling_port,omitempty"`       // Порт для pprof endpoints
+	ProfilesDir         *string `json:"profiles_dir,omitempty"`         // Директория для профилей
+	EnableHTTPS         *bool   `json:"enable_https,omitempty"`         // Включить HTTPS сервер
+	CertFile            *string `json:"cert_file,omitempty"`            // Путь к файлу сертификата
+	KeyFile             *string `json:"key_file,omitempty"`             // Путь к файлу приватного ключа
+	TrustedSubnet       *string `json:"trusted_subnet,omitempty"`       // Доверенная подсеть CIDR
+	EnableGRPC          *bool   `json:"enable_grpc,omitempty"`          // Включить gRPC сервер
+	GRPCAddress         *string `json:"grpc_address,omitempty"`         // Адрес gRPC сервера
+	GRPCCertFile        *string `json:"grpc_cert_file,omitempty"`       // Путь к файлу сертификата для gRPC
+	GRPCKeyFile         *string `json:"grpc_key_file,omitempty"`        // Путь к файлу приватного ключа для gRPC
+	ReaperInterval      *string `json:"reaper_interval,omitempty"`      // Интервал очистки истекших ссылок ("1m")
+	CompactionThreshold *int    `json:"compaction_threshold,omitempty"` // Порог компактирования журнала файлового хранилища
+	Durability          *string `json:"durability,omitempty"`           // Режим надежности записи (sync, batch, async)
+	IDGenerator         *string `json:"id_generator,omitempty"`         // Стратегия генерации ID (random, counter, hash, sqids)
+	IDLength            *int    `json:"id_length,omitempty"`            // Длина генерируемых ID
+	DedupScope          *string `json:"dedup_scope,omitempty"`          // Область дедупликации (global, per-user, none)
+	CacheBackend        *string `json:"cache_backend,omitempty"`        // Хранилище кэша ссылок (none, memory, redis)
+	CacheSize           *int    `json:"cache_size,omitempty"`           // Размер LRU-кэша ссылок
+	CacheTTL            *string `json:"cache_ttl,omitempty"`            // Время жизни ссылки в кэше ("5m")
+	CacheRedisAddr      *string `json:"cache_redis_addr,omitempty"`     // Адрес сервера RESP для кэша
+	TraceExporter       *string `json:"trace_exporter,omitempty"`       // Экспортер span'ов (none, stdout, otlp)
+	TraceOTLPEndpoint   *string `json:"trace_otlp_endpoint,omitempty"`  // Адрес коллектора OTLP/HTTP
+	TraceServiceName    *string `json:"trace_service_name,omitempty"`   // Имя сервиса в span'ах
+	AuthKeys            *string `json:"auth_keys,omitempty"`            // Ключи подписи токенов ("<id>:<секрет>,...")
+	AuthKeysFile        *string `json:"auth_keys_file,omitempty"`       // Файл ключей подписи токенов
+	AuthKeyID           *string `json:"auth_key_id,omitempty"`          // ID ключа подписи новых токенов
+	AuthTokenTTL        *string `json:"auth_token_ttl,omitempty"`       // Срок действия токенов ("720h")
+	AuthMode            *string `json:"auth_mode,omitempty"`            // Режим проверки аутентификации (strict, legacy)
+	RateLimits          *string `json:"rate_limits,omitempty"`          // Правила ограничения частоты запросов
+	RateLimitStore      *string `json:"rate_limit_store,omitempty"`     // Хранилище корзин ограничения (memory, postgres)
+	TrustedProxies      *string `json:"trusted_proxies,omitempty"`      // Доверенные прокси ("10.0.0.0/8,192.0.2.1")
+	URLAllowedSchemes   *string `json:"url_allowed_schemes,omitempty"`  // Разрешенные схемы URL ("http,https")
+	URLMaxLength        *int    `json:"url_max_length,omitempty"`       // Максимальная длина URL
+	URLAllowPrivate     *bool   `json:"url_allow_private,omitempty"`    // Разрешить адреса внутренних сетей
+	URLAllowHosts       *string `json:"url_allow_hosts,omitempty"`      // Разрешенные хосты ("example.com,*.example.org")
+	URLDenyHosts        *string `json:"url_deny_hosts,omitempty"`       // Запрещенные хосты
+	URLResolveHosts     *bool   `json:"url_resolve_hosts,omitempty"`    // Проверять адреса хостов через DNS
+	ImportMaxBytes      *int    `json:"import_max_bytes,omitempty"`     // Максимальный размер тела импорта URL
+	ImportMaxRows       *int    `json:"import_max_rows,omitempty"`      // Максимальное количество строк импорта URL
 }
 
 // Config содержит все конфигурационные параметры приложения.
@@ -152,6 +201,201 @@ type Config struct {
 	// Переменная окружения: GRPC_KEY_FILE
 	// Флаг: -grpc-key
 	GRPCKeyFile string
+
+	// ReaperInterval определяет интервал фоновой очистки ссылок с истекшим сроком действия.
+	// Значение 0 отключает фоновую очистку.
+	// Формат: "30s", "1m", "1h"
+	// Переменная окружения: REAPER_INTERVAL
+	// Флаг: -reaper-interval
+	ReaperInterval time.Duration
+
+	// CompactionThreshold определяет количество записей в журнале файлового хранилища,
+	// после которого создается снимок состояния и журнал обрезается.
+	// Значение 0 отключает автоматическое компактирование.
+	// Переменная окружения: COMPACTION_THRESHOLD
+	// Флаг: -compaction-threshold
+	CompactionThreshold int
+
+	// Durability определяет режим надежности записи файлового хранилища:
+	//   - "sync": ответ отправляется только после записи и fsync
+	//   - "batch": асинхронная запись пакетами с fsync каждого пакета
+	//   - "async": асинхронная запись без fsync
+	// Переменная окружения: STORAGE_DURABILITY
+	// Флаг: -durability
+	Durability string
+
+	// IDGenerator определяет стратегию генерации коротких ID:
+	//   - "random": случайный ID длины IDLength
+	//   - "counter": последовательный счетчик в base62
+	//   - "hash": детерминированный ID из хеша URL длины IDLength
+	//   - "sqids": счетчик, обфусцированный в стиле Sqids (минимальная длина IDLength)
+	// Переменная окружения: ID_GENERATOR
+	// Флаг: -id-generator
+	IDGenerator string
+
+	// IDLength определяет длину генерируемых коротких ID.
+	// Переменная окружения: ID_LENGTH
+	// Флаг: -id-length
+	IDLength int
+
+	// DedupScope определяет, когда повторное сокращение URL возвращает существующую ссылку:
+	//   - "global": одна ссылка на URL для всех пользователей
+	//   - "per-user": у каждого пользователя своя ссылка на URL
+	//   - "none": каждое сокращение создает новую ссылку
+	// Переменная окружения: DEDUP_SCOPE
+	// Флаг: -dedup-scope
+	DedupScope string
+
+	// CacheBackend определяет кэш ссылок для переходов перед PostgreSQL:
+	//   - "none": кэш отключен
+	//   - "memory": LRU-кэш в памяти процесса
+	//   - "redis": общий кэш на сервере RESP (Redis, Valkey, KeyDB)
+	// Переменная окружения: CACHE_BACKEND
+	// Флаг: -cache-backend
+	CacheBackend string
+
+	// CacheSize определяет максимальное количество ссылок в LRU-кэше.
+	// Переменная окружения: CACHE_SIZE
+	// Флаг: -cache-size
+	CacheSize int
+
+	// CacheTTL определяет время жизни ссылки в кэше.
+	// Формат: "30s", "5m"
+	// Переменная окружения: CACHE_TTL
+	// Флаг: -cache-ttl
+	CacheTTL time.Duration
+
+	// CacheRedisAddr определяет адрес сервера RESP в формате host:port.
+	// Переменная окружения: CACHE_REDIS_ADDR
+	// Флаг: -cache-redis-addr
+	CacheRedisAddr string
+
+	// TraceExporter определяет выгрузку span'ов трассировки:
+	//   - "none": span'ы не выгружаются (traceparent все равно передается и пишется в логи)
+	//   - "stdout": JSON в стандартный вывод
+	//   - "otlp": коллектору OpenTelemetry по OTLP/HTTP
+	// Переменная окружения: TRACE_EXPORTER
+	// Флаг: -trace-exporter
+	TraceExporter string
+
+	// TraceOTLPEndpoint определяет адрес коллектора OTLP/HTTP.
+	// Пример: "http://localhost:4318"
+	// Переменная окружения: TRACE_OTLP_ENDPOINT
+	// Флаг: -trace-otlp-endpoint
+	TraceOTLPEndpoint string
+
+	// TraceServiceName определяет имя сервиса (service.name) в выгружаемых span'ах.
+	// Переменная окружения: TRACE_SERVICE_NAME
+	// Флаг: -trace-service-name
+	TraceServiceName string
+
+	// AuthKeys определяет ключи подписи токенов анонимных пользователей
+	// в формате "<id>:<секрет>", через запятую. Ключ с ID "legacy" проверяет
+	// токены старого формата без срока действия.
+	// Переменная окружения: AUTH_KEYS
+	// Флаг: -auth-keys
+	AuthKeys string
+
+	// AuthKeysFile определяет файл ключей подписи: по одному ключу
+	// "<id>:<секрет>" в строке. Ключи файла добавляются к AuthKeys.
+	// Переменная окружения: AUTH_KEYS_FILE
+	// Флаг: -auth-keys-file
+	AuthKeysFile string
+
+	// AuthKeyID определяет ключ подписи новых токенов.
+	// Пустое значение выбирает последний заданный ключ.
+	// Переменная окружения: AUTH_KEY_ID
+	// Флаг: -auth-key-id
+	AuthKeyID string
+
+	// AuthTokenTTL определяет срок действия подписанных ID пользователей.
+	// Формат: "24h", "720h"
+	// Переменная окружения: AUTH_TOKEN_TTL
+	// Флаг: -auth-token-ttl
+	AuthTokenTTL time.Duration
+
+	// AuthMode определяет проверку аутентификации для URL пользователя:
+	//   - "strict": подпись токена проверяется, при ошибке возвращается 401
+	//     (gRPC - Unauthenticated)
+	//   - "legacy": кука принимается без проверки подписи, вместо отказа
+	//     создается новый пользователь (только для старых автотестов)
+	// Переменная окружения: AUTH_MODE
+	// Флаг: -auth-mode
+	AuthMode string
+
+	// RateLimits определяет правила ограничения частоты запросов HTTP и gRPC,
+	// через точку с запятой: "<маршрут>=<count>/<период>[ burst=<n>][ by=user|ip]".
+	// Пустое значение отключает ограничение.
+	// Пример: "POST /api/shorten/batch=10/m burst=20 by=ip; *=100/s"
+	// Переменная окружения: RATE_LIMITS
+	// Флаг: -rate-limits
+	RateLimits string
+
+	// RateLimitStore определяет хранилище корзин токенов:
+	//   - "memory": в памяти процесса, у каждого экземпляра свои ограничения
+	//   - "postgres": в PostgreSQL (требует DATABASE_DSN), общее для экземпляров
+	// Переменная окружения: RATE_LIMIT_STORE
+	// Флаг: -rate-limit-store
+	RateLimitStore string
+
+	// TrustedProxies определяет прокси через запятую (подсети CIDR или
+	// IP-адреса), от которых принимаются заголовки X-Real-IP и
+	// X-Forwarded-For при определении IP клиента для ограничения частоты
+	// запросов и проверки TrustedSubnet. Клиентом считается X-Real-IP, а без
+	// него - самый правый недоверенный адрес X-Forwarded-For. Пустое значение:
+	// используется адрес соединения, заголовки игнорируются.
+	// Переменная окружения: TRUSTED_PROXIES
+	// Флаг: -trusted-proxies
+	TrustedProxies string
+
+	// URLAllowedSchemes определяет разрешенные схемы оригинальных URL
+	// через запятую. URL с другими схемами (javascript:, file: и т.п.)
+	// отклоняются с кодом scheme_not_allowed.
+	// Переменная окружения: URL_ALLOWED_SCHEMES
+	// Флаг: -url-allowed-schemes
+	URLAllowedSchemes string
+
+	// URLMaxLength определяет максимальную длину оригинального URL.
+	// Переменная окружения: URL_MAX_LENGTH
+	// Флаг: -url-max-length
+	URLMaxLength int
+
+	// URLAllowPrivate разрешает сокращать URL с адресами внутренних сетей
+	// (127.0.0.0/8, 10.0.0.0/8, 192.168.0.0/16, localhost и т.п.).
+	// Переменная окружения: URL_ALLOW_PRIVATE
+	// Флаг: -url-allow-private
+	URLAllowPrivate bool
+
+	// URLAllowHosts определяет хосты, единственно разрешенные для сокращения,
+	// через запятую. Шаблон "*.example.com" совпадает с поддоменами.
+	// Пустое значение разрешает все хосты.
+	// Переменная окружения: URL_ALLOW_HOSTS
+	// Флаг: -url-allow-hosts
+	URLAllowHosts string
+
+	// URLDenyHosts определяет запрещенные хосты через запятую
+	// в том же формате, что и URLAllowHosts.
+	// Переменная окружения: URL_DENY_HOSTS
+	// Флаг: -url-deny-hosts
+	URLDenyHosts string
+
+	// URLResolveHosts включает разрешение имени хоста через DNS: URL
+	// отклоняется, если хост разрешается в адрес внутренней сети.
+	// Переменная окружения: URL_RESOLVE_HOSTS
+	// Флаг: -url-resolve-hosts
+	URLResolveHosts bool
+
+	// ImportMaxBytes определяет максимальный размер тела запроса импорта
+	// URL пользователя в байтах (0 - без ограничения).
+	// Переменная окружения: IMPORT_MAX_BYTES
+	// Флаг: -import-max-bytes
+	ImportMaxBytes int
+
+	// ImportMaxRows определяет максимальное количество строк в одном
+	// запросе импорта URL пользователя (0 - без ограничения).
+	// Переменная окружения: IMPORT_MAX_ROWS
+	// Флаг: -import-max-rows
+	ImportMaxRows int
 }
 
 // loadJSONConfig загружает конфигурацию из JSON файла.
@@ -215,6 +459,24 @@ func NewConfig() *Config {
 	cfg.GRPCAddress = DefaultGRPCAddress
 	cfg.GRPCCertFile = DefaultGRPCCertFile
 	cfg.GRPCKeyFile = DefaultGRPCKeyFile
+	cfg.ReaperInterval = DefaultReaperInterval
+	cfg.CompactionThreshold = DefaultCompactionThreshold
+	cfg.Durability = DefaultDurability
+	cfg.IDGenerator = DefaultIDGenerator
+	cfg.IDLength = DefaultIDLength
+	cfg.DedupScope = DefaultDedupScope
+	cfg.CacheBackend = DefaultCacheBackend
+	cfg.CacheSize = DefaultCacheSize
+	cfg.CacheTTL = DefaultCacheTTL
+	cfg.TraceExporter = DefaultTraceExporter
+	cfg.TraceServiceName = DefaultTraceServiceName
+	cfg.AuthTokenTTL = DefaultAuthTokenTTL
+	cfg.AuthMode = DefaultAuthMode
+	cfg.RateLimitStore = DefaultRateLimitStore
+	cfg.URLAllowedSchemes = DefaultURLAllowedSchemes
+	cfg.URLMaxLength = DefaultURLMaxLength
+	cfg.ImportMaxBytes = DefaultImportMaxBytes
+	cfg.ImportMaxRows = DefaultImportMaxRows
 
 	// Шаг 2: Применяем переменные окружения (включая путь к конфигурационному файлу)
 	if envServerAddr := os.Getenv("SERVER_ADDRESS"); envServerAddr != "" {
@@ -271,6 +533,97 @@ func NewConfig() *Config {
 	if envGRPCKeyFile := os.Getenv("GRPC_KEY_FILE"); envGRPCKeyFile != "" {
 		cfg.GRPCKeyFile = envGRPCKeyFile
 	}
+	if envReaperInterval := os.Getenv("REAPER_INTERVAL"); envReaperInterval != "" {
+		cfg.ReaperInterval = mustParseDuration("REAPER_INTERVAL", envReaperInterval)
+	}
+	if envCompactionThreshold := os.Getenv("COMPACTION_THRESHOLD"); envCompactionThreshold != "" {
+		cfg.CompactionThreshold = mustParseInt("COMPACTION_THRESHOLD", envCompactionThreshold)
+	}
+	if envDurability := os.Getenv("STORAGE_DURABILITY"); envDurability != "" {
+		cfg.Durability = envDurability
+	}
+	if envIDGenerator := os.Getenv("ID_GENERATOR"); envIDGenerator != "" {
+		cfg.IDGenerator = envIDGenerator
+	}
+	if envIDLength := os.Getenv("ID_LENGTH"); envIDLength != "" {
+		cfg.IDLength = mustParseInt("ID_LENGTH", envIDLength)
+	}
+	if envDedupScope := os.Getenv("DEDUP_SCOPE"); envDedupScope != "" {
+		cfg.DedupScope = envDedupScope
+	}
+	if envCacheBackend := os.Getenv("CACHE_BACKEND"); envCacheBackend != "" {
+		cfg.CacheBackend = envCacheBackend
+	}
+	if envCacheSize := os.Getenv("CACHE_SIZE"); envCacheSize != "" {
+		cfg.CacheSize = mustParseInt("CACHE_SIZE", envCacheSize)
+	}
+	if envCacheTTL := os.Getenv("CACHE_TTL"); envCacheTTL != "" {
+		cfg.CacheTTL = mustParseDuration("CACHE_TTL", envCacheTTL)
+	}
+	if envCacheRedisAddr := os.Getenv("CACHE_REDIS_ADDR"); envCacheRedisAddr != "" {
+		cfg.CacheRedisAddr = envCacheRedisAddr
+	}
+	if envTraceExporter := os.Getenv("TRACE_EXPORTER"); envTraceExporter != "" {
+		cfg.TraceExporter = envTraceExporter
+	}
+	if envTraceOTLPEndpoint := os.Getenv("TRACE_OTLP_ENDPOINT"); envTraceOTLPEndpoint != "" {
+		cfg.TraceOTLPEndpoint = envTraceOTLPEndpoint
+	}
+	if envTraceServiceName := os.Getenv("TRACE_SERVICE_NAME"); envTraceServiceName != "" {
+		cfg.TraceServiceName = envTraceServiceName
+	}
+	if envAuthKeys := os.Getenv("AUTH_KEYS"); envAuthKeys != "" {
+		cfg.AuthKeys = envAuthKeys
+	}
+	if envAuthKeysFile := os.Getenv("AUTH_KEYS_FILE"); envAuthKeysFile != "" {
+		cfg.AuthKeysFile = envAuthKeysFile
+	}
+	if envAuthKeyID := os.Getenv("AUTH_KEY_ID"); envAuthKeyID != "" {
+		cfg.AuthKeyID = envAuthKeyID
+	}
+	if envAuthTokenTTL := os.Getenv("AUTH_TOKEN_TTL"); envAuthTokenTTL != "" {
+		cfg.AuthTokenTTL = mustParseDuration("AUTH_TOKEN_TTL", envAuthTokenTTL)
+	}
+	if envAuthMode := os.Getenv("AUTH_MODE"); envAuthMode != "" {
+		cfg.AuthMode = envAuthMode
+	}
+	if envRateLimits := os.Getenv("RATE_LIMITS"); envRateLimits != "" {
+		cfg.RateLimits = envRateLimits
+	}
+	if envRateLimitStore := os.Getenv("RATE_LIMIT_STORE"); envRateLimitStore != "" {
+		cfg.RateLimitStore = envRateLimitStore
+	}
+	if envTrustedProxies := os.Getenv("TRUSTED_PROXIES"); envTrustedProxies != "" {
+		cfg.TrustedProxies = envTrustedProxies
+	}
+	if envURLAllowedSchemes := os.Getenv("URL_ALLOWED_SCHEMES"); envURLAllowedSchemes != "" {
+		cfg.URLAllowedSchemes = envURLAllowedSchemes
+	}
+	if envURLMaxLength := os.Getenv("URL_MAX_LENGTH"); envURLMaxLength != "" {
+		cfg.URLMaxLength = mustParseInt("URL_MAX_LENGTH", envURLMaxLength)
+	}
+	if envURLAllowPrivate := os.Getenv("URL_ALLOW_PRIVATE"); envURLAllowPrivate == "true" {
+		cfg.URLAllowPrivate = true
+	} else if envURLAllowPrivate == "false" {
+		cfg.URLAllowPrivate = false
+	}
+	if envURLAllowHosts := os.Getenv("URL_ALLOW_HOSTS"); envURLAllowHosts != "" {
+		cfg.URLAllowHosts = envURLAllowHosts
+	}
+	if envURLDenyHosts := os.Getenv("URL_DENY_HOSTS"); envURLDenyHosts != "" {
+		cfg.URLDenyHosts = envURLDenyHosts
+	}
+	if envURLResolveHosts := os.Getenv("URL_RESOLVE_HOSTS"); envURLResolveHosts == "true" {
+		cfg.URLResolveHosts = true
+	} else if envURLResolveHosts == "false" {
+		cfg.URLResolveHosts = false
+	}
+	if envImportMaxBytes := os.Getenv("IMPORT_MAX_BYTES"); envImportMaxBytes != "" {
+		cfg.ImportMaxBytes = mustParseInt("IMPORT_MAX_BYTES", envImportMaxBytes)
+	}
+	if envImportMaxRows := os.Getenv("IMPORT_MAX_ROWS"); envImportMaxRows != "" {
+		cfg.ImportMaxRows = mustParseInt("IMPORT_MAX_ROWS", envImportMaxRows)
+	}
 
 	// Шаг 3: Регистрируем флаги командной строки
 	flag.StringVar(&cfg.ServerAddress, "a", cfg.ServerAddress, "адрес запуска HTTP-сервера")
@@ -290,6 +643,35 @@ func NewConfig() *Config {
 	flag.StringVar(&cfg.GRPCAddress, "grpc-address", cfg.GRPCAddress, "адрес gRPC сервера")
 	flag.StringVar(&cfg.GRPCCertFile, "grpc-cert", cfg.GRPCCertFile, "путь к файлу сертификата для gRPC")
 	flag.StringVar(&cfg.GRPCKeyFile, "grpc-key", cfg.GRPCKeyFile, "путь к файлу приватного ключа для gRPC")
+	flag.DurationVar(&cfg.ReaperInterval, "reaper-interval", cfg.ReaperInterval, "интервал очистки истекших ссылок (0 - отключить)")
+	flag.IntVar(&cfg.CompactionThreshold, "compaction-threshold", cfg.CompactionThreshold, "количество записей журнала до компактирования (0 - отключить)")
+	flag.StringVar(&cfg.Durability, "durability", cfg.Durability, "режим надежности записи файлового хранилища: sync, batch, async")
+	flag.StringVar(&cfg.IDGenerator, "id-generator", cfg.IDGenerator, "стратегия генерации коротких ID: random, counter, hash, sqids")
+	flag.IntVar(&cfg.IDLength, "id-length", cfg.IDLength, "длина генерируемых коротких ID")
+	flag.StringVar(&cfg.DedupScope, "dedup-scope", cfg.DedupScope, "область дедупликации URL: global, per-user, none")
+	flag.StringVar(&cfg.CacheBackend, "cache-backend", cfg.CacheBackend, "кэш ссылок перед PostgreSQL: none, memory, redis")
+	flag.IntVar(&cfg.CacheSize, "cache-size", cfg.CacheSize, "максимальное количество ссылок в LRU-кэше")
+	flag.DurationVar(&cfg.CacheTTL, "cache-ttl", cfg.CacheTTL, "время жизни ссылки в кэше")
+	flag.StringVar(&cfg.CacheRedisAddr, "cache-redis-addr", cfg.CacheRedisAddr, "адрес сервера RESP для кэша ссылок")
+	flag.StringVar(&cfg.TraceExporter, "trace-exporter", cfg.TraceExporter, "экспортер span'ов трассировки: none, stdout, otlp")
+	flag.StringVar(&cfg.TraceOTLPEndpoint, "trace-otlp-endpoint", cfg.TraceOTLPEndpoint, "адрес коллектора OTLP/HTTP")
+	flag.StringVar(&cfg.TraceServiceName, "trace-service-name", cfg.TraceServiceName, "имя сервиса в span'ах трассировки")
+	flag.StringVar(&cfg.AuthKeys, "auth-keys", cfg.AuthKeys, "ключи подписи токенов: <id>:<секрет>[,...]")
+	flag.StringVar(&cfg.AuthKeysFile, "auth-keys-file", cfg.AuthKeysFile, "файл ключей подписи токенов")
+	flag.StringVar(&cfg.AuthKeyID, "auth-key-id", cfg.AuthKeyID, "ID ключа подписи новых токенов (по умолчанию последний)")
+	flag.DurationVar(&cfg.AuthTokenTTL, "auth-token-ttl", cfg.AuthTokenTTL, "срок действия подписанных ID пользователей")
+	flag.StringVar(&cfg.AuthMode, "auth-mode", cfg.AuthMode, "режим проверки аутентификации: strict, legacy")
+	flag.StringVar(&cfg.RateLimits, "rate-limits", cfg.RateLimits, "правила ограничения частоты запросов")
+	flag.StringVar(&cfg.RateLimitStore, "rate-limit-store", cfg.RateLimitStore, "хранилище корзин ограничения запросов: memory, postgres")
+	flag.StringVar(&cfg.TrustedProxies, "trusted-proxies", cfg.TrustedProxies, "доверенные прокси для X-Real-IP и X-Forwarded-For через запятую")
+	flag.StringVar(&cfg.URLAllowedSchemes, "url-allowed-schemes", cfg.URLAllowedSchemes, "разрешенные схемы оригинальных URL через запятую")
+	flag.IntVar(&cfg.URLMaxLength, "url-max-length", cfg.URLMaxLength, "максимальная длина оригинального URL")
+	flag.BoolVar(&cfg.URLAllowPrivate, "url-allow-private", cfg.URLAllowPrivate, "разрешить URL с адресами внутренних сетей")
+	flag.StringVar(&cfg.URLAllowHosts, "url-allow-hosts", cfg.URLAllowHosts, "разрешенные хосты URL через запятую (*.example.com - поддомены)")
+	flag.StringVar(&cfg.URLDenyHosts, "url-deny-hosts", cfg.URLDenyHosts, "запрещенные хосты URL через запятую (*.example.com - поддомены)")
+	flag.BoolVar(&cfg.URLResolveHosts, "url-resolve-hosts", cfg.URLResolveHosts, "проверять адреса хостов URL через DNS")
+	flag.IntVar(&cfg.ImportMaxBytes, "import-max-bytes", cfg.ImportMaxBytes, "максимальный размер тела импорта URL в байтах (0 - без ограничения)")
+	flag.IntVar(&cfg.ImportMaxRows, "import-max-rows", cfg.ImportMaxRows, "максимальное количество строк импорта URL (0 - без ограничения)")
 
 	// Шаг 4: Парсим флаги командной строки
 	flag.Parse()
@@ -349,6 +731,93 @@ func NewConfig() *Config {
 		if jsonConfig.GRPCKeyFile != nil && !isFlagSet("grpc-key") && os.Getenv("GRPC_KEY_FILE") == "" {
 			cfg.GRPCKeyFile = *jsonConfig.GRPCKeyFile
 		}
+		if jsonConfig.ReaperInterval != nil && !isFlagSet("reaper-interval") && os.Getenv("REAPER_INTERVAL") == "" {
+			cfg.ReaperInterval = mustParseDuration("reaper_interval", *jsonConfig.ReaperInterval)
+		}
+		if jsonConfig.CompactionThreshold != nil && !isFlagSet("compaction-threshold") && os.Getenv("COMPACTION_THRESHOLD") == "" {
+			cfg.CompactionThreshold = *jsonConfig.CompactionThreshold
+		}
+		if jsonConfig.Durability != nil && !isFlagSet("durability") && os.Getenv("STORAGE_DURABILITY") == "" {
+			cfg.Durability = *jsonConfig.Durability
+		}
+		if jsonConfig.IDGenerator != nil && !isFlagSet("id-generator") && os.Getenv("ID_GENERATOR") == "" {
+			cfg.IDGenerator = *jsonConfig.IDGenerator
+		}
+		if jsonConfig.IDLength != nil && !isFlagSet("id-length") && os.Getenv("ID_LENGTH") == "" {
+			cfg.IDLength = *jsonConfig.IDLength
+		}
+		if jsonConfig.DedupScope != nil && !isFlagSet("dedup-scope") && os.Getenv("DEDUP_SCOPE") == "" {
+			cfg.DedupScope = *jsonConfig.DedupScope
+		}
+		if jsonConfig.CacheBackend != nil && !isFlagSet("cache-backend") && os.Getenv("CACHE_BACKEND") == "" {
+			cfg.CacheBackend = *jsonConfig.CacheBackend
+		}
+		if jsonConfig.CacheSize != nil && !isFlagSet("cache-size") && os.Getenv("CACHE_SIZE") == "" {
+			cfg.CacheSize = *jsonConfig.CacheSize
+		}
+		if jsonConfig.CacheTTL != nil && !isFlagSet("cache-ttl") && os.Getenv("CACHE_TTL") == "" {
+			cfg.CacheTTL = mustParseDuration("cache_ttl", *jsonConfig.CacheTTL)
+		}
+		if jsonConfig.CacheRedisAddr != nil && !isFlagSet("cache-redis-addr") && os.Getenv("CACHE_REDIS_ADDR") == "" {
+			cfg.CacheRedisAddr = *jsonConfig.CacheRedisAddr
+		}
+		if jsonConfig.TraceExporter != nil && !isFlagSet("trace-exporter") && os.Getenv("TRACE_EXPORTER") == "" {
+			cfg.TraceExporter = *jsonConfig.TraceExporter
+		}
+		if jsonConfig.TraceOTLPEndpoint != nil && !isFlagSet("trace-otlp-endpoint") && os.Getenv("TRACE_OTLP_ENDPOINT") == "" {
+			cfg.TraceOTLPEndpoint = *jsonConfig.TraceOTLPEndpoint
+		}
+		if jsonConfig.TraceServiceName != nil && !isFlagSet("trace-service-name") && os.Getenv("TRACE_SERVICE_NAME") == "" {
+			cfg.TraceServiceName = *jsonConfig.TraceServiceName
+		}
+		if jsonConfig.AuthKeys != nil && !isFlagSet("auth-keys") && os.Getenv("AUTH_KEYS") == "" {
+			cfg.AuthKeys = *jsonConfig.AuthKeys
+		}
+		if jsonConfig.AuthKeysFile != nil && !isFlagSet("auth-keys-file") && os.Getenv("AUTH_KEYS_FILE") == "" {
+			cfg.AuthKeysFile = *jsonConfig.AuthKeysFile
+		}
+		if jsonConfig.AuthKeyID != nil && !isFlagSet("auth-key-id") && os.Getenv("AUTH_KEY_ID") == "" {
+			cfg.AuthKeyID = *jsonConfig.AuthKeyID
+		}
+		if jsonConfig.AuthTokenTTL != nil && !isFlagSet("auth-token-ttl") && os.Getenv("AUTH_TOKEN_TTL") == "" {
+			cfg.AuthTokenTTL = mustParseDuration("auth_token_ttl", *jsonConfig.AuthTokenTTL)
+		}
+		if jsonConfig.AuthMode != nil && !isFlagSet("auth-mode") && os.Getenv("AUTH_MODE") == "" {
+			cfg.AuthMode = *jsonConfig.AuthMode
+		}
+		if jsonConfig.RateLimits != nil && !isFlagSet("rate-limits") && os.Getenv("RATE_LIMITS") == "" {
+			cfg.RateLimits = *jsonConfig.RateLimits
+		}
+		if jsonConfig.RateLimitStore != nil && !isFlagSet("rate-limit-store") && os.Getenv("RATE_LIMIT_STORE") == "" {
+			cfg.RateLimitStore = *jsonConfig.RateLimitStore
+		}
+		if jsonConfig.TrustedProxies != nil && !isFlagSet("trusted-proxies") && os.Getenv("TRUSTED_PROXIES") == "" {
+			cfg.TrustedProxies = *jsonConfig.TrustedProxies
+		}
+		if jsonConfig.URLAllowedSchemes != nil && !isFlagSet("url-allowed-schemes") && os.Getenv("URL_ALLOWED_SCHEMES") == "" {
+			cfg.URLAllowedSchemes = *jsonConfig.URLAllowedSchemes
+		}
+		if jsonConfig.URLMaxLength != nil && !isFlagSet("url-max-length") && os.Getenv("URL_MAX_LENGTH") == "" {
+			cfg.URLMaxLength = *jsonConfig.URLMaxLength
+		}
+		if jsonConfig.URLAllowPrivate != nil && !isFlagSet("url-allow-private") && os.Getenv("URL_ALLOW_PRIVATE") == "" {
+			cfg.URLAllowPrivate = *jsonConfig.URLAllowPrivate
+		}
+		if jsonConfig.URLAllowHosts != nil && !isFlagSet("url-allow-hosts") && os.Getenv("URL_ALLOW_HOSTS") == "" {
+			cfg.URLAllowHosts = *jsonConfig.URLAllowHosts
+		}
+		if jsonConfig.URLDenyHosts != nil && !isFlagSet("url-deny-hosts") && os.Getenv("URL_DENY_HOSTS") == "" {
+			cfg.URLDenyHosts = *jsonConfig.URLDenyHosts
+		}
+		if jsonConfig.URLResolveHosts != nil && !isFlagSet("url-resolve-hosts") && os.Getenv("URL_RESOLVE_HOSTS") == "" {
+			cfg.URLResolveHosts = *jsonConfig.URLResolveHosts
+		}
+		if jsonConfig.ImportMaxBytes != nil && !isFlagSet("import-max-bytes") && os.Getenv("IMPORT_MAX_BYTES") == "" {
+			cfg.ImportMaxBytes = *jsonConfig.ImportMaxBytes
+		}
+		if jsonConfig.ImportMaxRows != nil && !isFlagSet("import-max-rows") && os.Getenv("IMPORT_MAX_ROWS") == "" {
+			cfg.ImportMaxRows = *jsonConfig.ImportMaxRows
+		}
 	}
 
 	// Валидируем и нормализуем конфигурацию
@@ -357,6 +826,26 @@ func NewConfig() *Config {
 	return cfg
 }
 
+// mustParseDuration разбирает длительность или завершает программу с ошибкой
+func mustParseDuration(name, value string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки конфигурации: некорректное значение %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return d
+}
+
+// mustParseInt разбирает целое число или завершает программу с ошибкой
+func mustParseInt(name, value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки конфигурации: некорректное значение %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return n
+}
+
 // isFlagSet проверяет, был ли установлен флаг командной строки
 func isFlagSet(name string) bool {
 	found := false
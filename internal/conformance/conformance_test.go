@@ -0,0 +1,395 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// transports перечисляет проверяемые транспорты
+var transports = map[string]func(*testing.T, *service.ShortenerService) transport{
+	"http": newHTTPTransport,
+	"grpc": newGRPCTransport,
+}
+
+// scenario - сценарий, который выполняется против каждого транспорта
+type scenario struct {
+	name string
+	// store создает хранилище для сценария (по умолчанию - в памяти)
+	store func(t *testing.T) storage.URLStorageV2
+	run   func(t *testing.T, store storage.URLStorageV2, c transport)
+}
+
+func TestConformance(t *testing.T) {
+	for _, sc := range scenarios {
+		for name, newTransport := range transports {
+			t.Run(sc.name+"/"+name, func(t *testing.T) {
+				newStore := sc.store
+				if newStore == nil {
+					newStore = memoryStore
+				}
+				store := newStore(t)
+				svc := service.NewShortenerService(store, shortener.New(baseURL), nil)
+				sc.run(t, store, newTransport(t, svc))
+			})
+		}
+	}
+}
+
+func memoryStore(t *testing.T) storage.URLStorageV2 {
+	store := storage.NewMemoryStorage("")
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// dedupStore создает хранилище в памяти с заданной областью дедупликации
+func dedupStore(scope storage.DedupScope) func(t *testing.T) storage.URLStorageV2 {
+	return func(t *testing.T) storage.URLStorageV2 {
+		store := storage.NewMemoryStorageWithOptions("", storage.Options{Dedup: scope})
+		t.Cleanup(func() { store.Close() })
+		return store
+	}
+}
+
+// cachedStore создает хранилище в памяти за кэширующим декоратором
+func cachedStore(t *testing.T) storage.URLStorageV2 {
+	store := storage.NewCachedStorage(storage.NewMemoryStorage(""), storage.NewLRUCache(100, time.Minute))
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+var scenarios = []scenario{
+	{
+		name: "shorten_and_resolve",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			shortURL, result := c.Shorten(t, "alice", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+			assert.Contains(t, shortURL, baseURL+"/")
+
+			originalURL, result := c.Resolve(t, idFromShortURL(shortURL))
+			assert.Equal(t, outcomeOK, result)
+			assert.Equal(t, "https://example.com/a", originalURL)
+		},
+	},
+	{
+		name: "duplicate_url_returns_existing",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			first, result := c.Shorten(t, "alice", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+
+			second, result := c.Shorten(t, "bob", "https://example.com/a", "")
+			assert.Equal(t, outcomeExists, result)
+			assert.Equal(t, first, second)
+		},
+	},
+	{
+		name:  "per_user_ownership",
+		store: dedupStore(storage.DedupPerUser),
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			aliceURL, result := c.Shorten(t, "alice", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+
+			// Другой пользователь получает собственную ссылку на тот же URL
+			bobURL, result := c.Shorten(t, "bob", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+			assert.NotEqual(t, aliceURL, bobURL)
+
+			// Повторное сокращение возвращает ссылку этого же пользователя
+			again, result := c.Shorten(t, "bob", "https://example.com/a", "")
+			assert.Equal(t, outcomeExists, result)
+			assert.Equal(t, bobURL, again)
+
+			urls, result := c.UserURLs(t, "bob")
+			require.Equal(t, outcomeOK, result)
+			assert.Equal(t, []models.UserURL{{ShortURL: bobURL, OriginalURL: "https://example.com/a"}}, urls)
+
+			// Удаление своей ссылки не затрагивает ссылку другого пользователя
+			require.Equal(t, outcomeOK, c.Delete(t, "bob", []string{idFromShortURL(bobURL)}))
+			require.Eventually(t, func() bool {
+				_, result := c.Resolve(t, idFromShortURL(bobURL))
+				return result == outcomeGone
+			}, 2*time.Second, 10*time.Millisecond)
+
+			_, result = c.Resolve(t, idFromShortURL(aliceURL))
+			assert.Equal(t, outcomeOK, result)
+		},
+	},
+	{
+		name:  "no_dedup",
+		store: dedupStore(storage.DedupNone),
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			first, result := c.Shorten(t, "alice", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+
+			second, result := c.Shorten(t, "alice", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+			assert.NotEqual(t, first, second)
+		},
+	},
+	{
+		name: "alias",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			shortURL, result := c.Shorten(t, "alice", "https://example.com/a", "promo")
+			require.Equal(t, outcomeOK, result)
+			assert.Equal(t, baseURL+"/promo", shortURL)
+
+			_, result = c.Shorten(t, "alice", "https://example.com/b", "promo")
+			assert.Equal(t, outcomeAliasTaken, result)
+
+			_, result = c.Shorten(t, "alice", "https://example.com/c", "bad/alias")
+			assert.Equal(t, outcomeInvalid, result)
+		},
+	},
+	{
+		name: "empty_url",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			_, result := c.Shorten(t, "alice", "", "")
+			assert.Equal(t, outcomeInvalid, result)
+		},
+	},
+	{
+		name: "url_policy",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			for _, url := range []string{"javascript:alert(1)", "http://127.0.0.1/", "example.com"} {
+				_, result := c.Shorten(t, "alice", url, "")
+				assert.Equal(t, outcomeInvalid, result, url)
+			}
+
+			// Сохраняется нормализованный URL
+			shortURL, result := c.Shorten(t, "alice", "HTTPS://Example.COM/Path", "")
+			require.Equal(t, outcomeOK, result)
+			originalURL, result := c.Resolve(t, idFromShortURL(shortURL))
+			assert.Equal(t, outcomeOK, result)
+			assert.Equal(t, "https://example.com/Path", originalURL)
+		},
+	},
+	{
+		name: "unknown_id",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			_, result := c.Resolve(t, "missing1")
+			assert.Equal(t, outcomeNotFound, result)
+		},
+	},
+	{
+		name: "expired",
+		run: func(t *testing.T, store storage.URLStorageV2, c transport) {
+			_, err := store.Add(context.Background(), "expired1", "https://example.com/old", "alice",
+				time.Now().Add(-time.Minute))
+			require.NoError(t, err)
+
+			_, result := c.Resolve(t, "expired1")
+			assert.Equal(t, outcomeGone, result)
+		},
+	},
+	{
+		name: "delete_only_own_urls",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			shortURL, result := c.Shorten(t, "alice", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+			id := idFromShortURL(shortURL)
+
+			// Чужой пользователь не может удалить ссылку
+			require.Equal(t, outcomeOK, c.Delete(t, "bob", []string{id}))
+			require.Equal(t, outcomeOK, c.Delete(t, "alice", []string{id}))
+
+			// Удаление асинхронное, дожидаемся его применения
+			require.Eventually(t, func() bool {
+				_, result := c.Resolve(t, id)
+				return result == outcomeGone
+			}, 2*time.Second, 10*time.Millisecond)
+
+			urls, result := c.UserURLs(t, "alice")
+			assert.Equal(t, outcomeOK, result)
+			assert.Empty(t, urls)
+		},
+	},
+	{
+		name:  "cached_delete",
+		store: cachedStore,
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			shortURL, result := c.Shorten(t, "alice", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+			id := idFromShortURL(shortURL)
+
+			// Первый переход кэширует ссылку, удаление должно очистить кэш
+			_, result = c.Resolve(t, id)
+			require.Equal(t, outcomeOK, result)
+			require.Equal(t, outcomeOK, c.Delete(t, "alice", []string{id}))
+
+			require.Eventually(t, func() bool {
+				_, result := c.Resolve(t, id)
+				return result == outcomeGone
+			}, 2*time.Second, 10*time.Millisecond)
+		},
+	},
+	{
+		name: "user_urls",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			first, result := c.Shorten(t, "alice", "https://example.com/a", "")
+			require.Equal(t, outcomeOK, result)
+			second, result := c.Shorten(t, "alice", "https://example.com/b", "")
+			require.Equal(t, outcomeOK, result)
+
+			urls, result := c.UserURLs(t, "alice")
+			require.Equal(t, outcomeOK, result)
+			assert.ElementsMatch(t, []models.UserURL{
+				{ShortURL: first, OriginalURL: "https://example.com/a"},
+				{ShortURL: second, OriginalURL: "https://example.com/b"},
+			}, urls)
+
+			urls, result = c.UserURLs(t, "bob")
+			assert.Equal(t, outcomeOK, result)
+			assert.Empty(t, urls)
+		},
+	},
+	{
+		name:  "user_urls_pagination",
+		store: cachedStore,
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			var created []string
+			for _, u := range []string{"https://a.example.com/", "https://b.example.org/", "https://c.example.com/", "https://d.example.com/"} {
+				shortURL, result := c.Shorten(t, "alice", u, "")
+				require.Equal(t, outcomeOK, result)
+				created = append(created, shortURL)
+			}
+
+			// Страницы по три URL от старых к новым покрывают все URL без повторов
+			var all []string
+			request := pageRequest{Limit: 3, Order: "asc"}
+			for {
+				page, result := c.UserURLPage(t, "alice", request)
+				require.Equal(t, outcomeOK, result)
+				assert.Equal(t, 4, page.Total)
+				all = append(all, page.ShortURLs...)
+				if page.NextCursor == "" {
+					break
+				}
+				request.Cursor = page.NextCursor
+			}
+			assert.Equal(t, created, all)
+
+			// Фильтр по хосту учитывается в количестве URL
+			page, result := c.UserURLPage(t, "alice", pageRequest{Limit: 1, Host: "EXAMPLE.com"})
+			require.Equal(t, outcomeOK, result)
+			assert.Equal(t, 3, page.Total)
+			assert.Equal(t, []string{created[3]}, page.ShortURLs)
+			assert.NotEmpty(t, page.NextCursor)
+
+			_, result = c.UserURLPage(t, "alice", pageRequest{Limit: 1, Cursor: "not a cursor"})
+			assert.Equal(t, outcomeInvalid, result)
+			_, result = c.UserURLPage(t, "alice", pageRequest{Limit: 1, Order: "random"})
+			assert.Equal(t, outcomeInvalid, result)
+		},
+	},
+	{
+		name: "batch",
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			results, result := c.Batch(t, "alice", []batchItem{
+				{CorrelationID: "1", URL: "https://example.com/a"},
+				{CorrelationID: "2", URL: "https://example.com/b", Alias: "second"},
+			})
+			require.Equal(t, outcomeOK, result)
+			assert.Len(t, results, 2)
+			assert.Equal(t, batchResult{ShortURL: baseURL + "/second", Status: "created"}, results["2"])
+
+			firstURL := results["1"].ShortURL
+			originalURL, result := c.Resolve(t, idFromShortURL(firstURL))
+			assert.Equal(t, outcomeOK, result)
+			assert.Equal(t, "https://example.com/a", originalURL)
+
+			// Занятый псевдоним и пустой URL получают статус ошибки,
+			// остальные элементы пакета обрабатываются
+			results, result = c.Batch(t, "alice", []batchItem{
+				{CorrelationID: "3", URL: "https://example.com/c", Alias: "second"},
+				{CorrelationID: "4", URL: ""},
+				{CorrelationID: "5", URL: "https://example.com/a"},
+			})
+			require.Equal(t, outcomeOK, result)
+			assert.Equal(t, batchResult{Status: "error"}, results["3"])
+			assert.Equal(t, batchResult{Status: "error"}, results["4"])
+			assert.Equal(t, batchResult{ShortURL: firstURL, Status: "existing"}, results["5"])
+
+			_, result = c.Batch(t, "alice", []batchItem{
+				{CorrelationID: "6", URL: "https://example.com/d", Alias: "dup"},
+				{CorrelationID: "7", URL: "https://example.com/e", Alias: "dup"},
+			})
+			assert.Equal(t, outcomeInvalid, result)
+		},
+	},
+	{
+		name:  "storage_unavailable",
+		store: func(*testing.T) storage.URLStorageV2 { return failingStorage{} },
+		run: func(t *testing.T, _ storage.URLStorageV2, c transport) {
+			// Сбой хранилища не должен выглядеть как отсутствие URL
+			_, result := c.Resolve(t, "abc123")
+			assert.Equal(t, outcomeUnavailable, result)
+
+			_, result = c.Shorten(t, "alice", "https://example.com/a", "")
+			assert.Equal(t, outcomeUnavailable, result)
+
+			_, result = c.Batch(t, "alice", []batchItem{{CorrelationID: "1", URL: "https://example.com/a"}})
+			assert.Equal(t, outcomeUnavailable, result)
+
+			_, result = c.UserURLs(t, "alice")
+			assert.Equal(t, outcomeUnavailable, result)
+		},
+	},
+}
+
+// errUnavailable имитирует недоступность базы данных
+var errUnavailable = errors.New("connection refused")
+
+// failingStorage - хранилище, все операции которого завершаются сбоем
+type failingStorage struct{}
+
+func (failingStorage) Add(context.Context, string, string, string, time.Time) (string, error) {
+	return "", errUnavailable
+}
+
+func (failingStorage) Get(context.Context, string) (string, error) {
+	return "", errUnavailable
+}
+
+func (failingStorage) FindByOriginalURL(context.Context, string, string) (string, error) {
+	return "", errUnavailable
+}
+
+func (failingStorage) GetUserURLs(context.Context, string) ([]models.UserURL, error) {
+	return nil, errUnavailable
+}
+
+func (failingStorage) ListUserURLs(context.Context, string, storage.UserURLQuery) (storage.UserURLPage, error) {
+	return storage.UserURLPage{}, errUnavailable
+}
+
+func (failingStorage) DeleteUserURLs(context.Context, string, []string) error {
+	return errUnavailable
+}
+
+func (failingStorage) PurgeExpired(context.Context, time.Time) (int, error) {
+	return 0, errUnavailable
+}
+
+func (failingStorage) Stats(context.Context) (storage.Stats, error) {
+	return storage.Stats{}, errUnavailable
+}
+
+func (failingStorage) Close() error {
+	return nil
+}
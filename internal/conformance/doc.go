@@ -0,0 +1,8 @@
+// Package conformance содержит набор сценариев, которые выполняются
+// одинаково против HTTP и gRPC транспортов сервиса сокращения URL.
+//
+// Оба транспорта используют общий service.ShortenerService, поэтому
+// на одни и те же действия они обязаны давать эквивалентные результаты:
+// отсутствие URL, удаление, истечение срока действия, конфликт
+// и недоступность хранилища различаются одинаково.
+package conformance
@@ -0,0 +1,389 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/grpcserver"
+	"github.com/Adigezalov/shortener/internal/handlers"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	pb "github.com/Adigezalov/shortener/pkg/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// baseURL - базовый адрес коротких ссылок в тестах
+const baseURL = "http://short.test"
+
+// outcome - результат операции, не зависящий от транспорта
+type outcome string
+
+const (
+	outcomeOK          outcome = "ok"          // Операция выполнена
+	outcomeExists      outcome = "exists"      // URL уже был сокращен, возвращена существующая ссылка
+	outcomeAliasTaken  outcome = "alias_taken" // Псевдоним занят
+	outcomeInvalid     outcome = "invalid"     // Некорректный запрос
+	outcomeNotFound    outcome = "not_found"   // URL не найден
+	outcomeGone        outcome = "gone"        // URL удален или срок его действия истек
+	outcomeUnavailable outcome = "unavailable" // Хранилище недоступно
+)
+
+// batchItem - элемент пакетного запроса
+type batchItem struct {
+	CorrelationID string
+	URL           string
+	Alias         string
+}
+
+// batchResult - результат элемента пакетного запроса
+type batchResult struct {
+	ShortURL string
+	Status   string // created, existing или error
+}
+
+// pageRequest - параметры страницы списка URL пользователя
+type pageRequest struct {
+	Limit  int
+	Cursor string
+	Order  string
+	Host   string
+}
+
+// pageResult - страница списка URL пользователя
+type pageResult struct {
+	ShortURLs  []string
+	Total      int
+	NextCursor string
+}
+
+// transport описывает клиента, выполняющего сценарии через конкретный API
+type transport interface {
+	Shorten(t *testing.T, user, url, alias string) (string, outcome)
+	Resolve(t *testing.T, id string) (string, outcome)
+	Batch(t *testing.T, user string, items []batchItem) (map[string]batchResult, outcome)
+	UserURLs(t *testing.T, user string) ([]models.UserURL, outcome)
+	UserURLPage(t *testing.T, user string, page pageRequest) (pageResult, outcome)
+	Delete(t *testing.T, user string, ids []string) outcome
+}
+
+// idFromShortURL извлекает короткий ID из полной ссылки
+func idFromShortURL(shortURL string) string {
+	return strings.TrimPrefix(shortURL, baseURL+"/")
+}
+
+// httpTransport выполняет сценарии через HTTP API
+type httpTransport struct {
+	server *httptest.Server
+	client *http.Client
+}
+
+// newHTTPTransport поднимает HTTP сервер с рабочим роутером приложения
+func newHTTPTransport(t *testing.T, svc *service.ShortenerService) transport {
+	server := httptest.NewServer(handlers.NewRouter(handlers.New(svc), ""))
+	t.Cleanup(server.Close)
+
+	return &httpTransport{
+		server: server,
+		client: &http.Client{
+			// Редирект проверяется по ответу, а не по целевой странице
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (h *httpTransport) do(t *testing.T, method, path, user, contentType string, body []byte) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, h.server.URL+path, bytes.NewReader(body))
+	require.NoError(t, err)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if user != "" {
+		req.AddCookie(&http.Cookie{Name: auth.CookieName, Value: auth.SignUserID(user)})
+	}
+
+	resp, err := h.client.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func httpOutcome(code int) outcome {
+	switch code {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent, http.StatusTemporaryRedirect:
+		return outcomeOK
+	case http.StatusBadRequest:
+		return outcomeInvalid
+	case http.StatusNotFound:
+		return outcomeNotFound
+	case http.StatusGone:
+		return outcomeGone
+	case http.StatusConflict:
+		return outcomeAliasTaken
+	default:
+		return outcomeUnavailable
+	}
+}
+
+func (h *httpTransport) Shorten(t *testing.T, user, url, alias string) (string, outcome) {
+	body, err := json.Marshal(models.ShortenRequest{URL: url, Alias: alias})
+	require.NoError(t, err)
+
+	resp := h.do(t, http.MethodPost, "/api/shorten", user, "application/json", body)
+	result := httpOutcome(resp.StatusCode)
+
+	// 409 с JSON телом означает уже сокращенный URL, без него - занятый псевдоним
+	isJSON := strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json")
+	if resp.StatusCode == http.StatusConflict && isJSON {
+		result = outcomeExists
+	}
+	if result != outcomeOK && result != outcomeExists {
+		return "", result
+	}
+
+	var response models.ShortenResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response.Result, result
+}
+
+func (h *httpTransport) Resolve(t *testing.T, id string) (string, outcome) {
+	resp := h.do(t, http.MethodGet, "/"+id, "", "", nil)
+	return resp.Header.Get("Location"), httpOutcome(resp.StatusCode)
+}
+
+func (h *httpTransport) Batch(t *testing.T, user string, items []batchItem) (map[string]batchResult, outcome) {
+	request := make([]models.BatchShortenRequest, 0, len(items))
+	for _, item := range items {
+		request = append(request, models.BatchShortenRequest{
+			CorrelationID: item.CorrelationID,
+			OriginalURL:   item.URL,
+			Alias:         item.Alias,
+		})
+	}
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	resp := h.do(t, http.MethodPost, "/api/shorten/batch", user, "application/json", body)
+	if result := httpOutcome(resp.StatusCode); result != outcomeOK {
+		return nil, result
+	}
+
+	var response []models.BatchShortenResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+	results := make(map[string]batchResult, len(response))
+	for _, item := range response {
+		results[item.CorrelationID] = batchResult{ShortURL: item.ShortURL, Status: item.Status}
+	}
+	return results, outcomeOK
+}
+
+func (h *httpTransport) UserURLs(t *testing.T, user string) ([]models.UserURL, outcome) {
+	resp := h.do(t, http.MethodGet, "/api/user/urls", user, "", nil)
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, outcomeOK
+	}
+	if result := httpOutcome(resp.StatusCode); result != outcomeOK {
+		return nil, result
+	}
+
+	var urls []models.UserURL
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&urls))
+	for i := range urls {
+		require.NotNil(t, urls[i].CreatedAt)
+		urls[i].CreatedAt = nil
+	}
+	return urls, outcomeOK
+}
+
+func (h *httpTransport) UserURLPage(t *testing.T, user string, page pageRequest) (pageResult, outcome) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(page.Limit))
+	for key, value := range map[string]string{"cursor": page.Cursor, "order": page.Order, "host": page.Host} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	resp := h.do(t, http.MethodGet, "/api/user/urls?"+query.Encode(), user, "", nil)
+	if result := httpOutcome(resp.StatusCode); result != outcomeOK {
+		return pageResult{}, result
+	}
+
+	total, err := strconv.Atoi(resp.Header.Get(handlers.TotalCountHeader))
+	require.NoError(t, err)
+	result := pageResult{Total: total, NextCursor: resp.Header.Get(handlers.NextCursorHeader)}
+	if resp.StatusCode == http.StatusNoContent {
+		return result, outcomeOK
+	}
+
+	var urls []models.UserURL
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&urls))
+	for _, userURL := range urls {
+		result.ShortURLs = append(result.ShortURLs, userURL.ShortURL)
+	}
+	return result, outcomeOK
+}
+
+func (h *httpTransport) Delete(t *testing.T, user string, ids []string) outcome {
+	body, err := json.Marshal(ids)
+	require.NoError(t, err)
+
+	resp := h.do(t, http.MethodDelete, "/api/user/urls", user, "application/json", body)
+	return httpOutcome(resp.StatusCode)
+}
+
+// grpcTransport выполняет сценарии через gRPC API
+type grpcTransport struct {
+	client pb.ShortenerServiceClient
+}
+
+// newGRPCTransport поднимает gRPC сервер в памяти с перехватчиками приложения
+func newGRPCTransport(t *testing.T, svc *service.ShortenerService) transport {
+	listener := bufconn.Listen(1 << 20)
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcserver.RecoveryInterceptor(),
+		grpcserver.TracingInterceptor(),
+		grpcserver.AuthInterceptor(svc, auth.ModeStrict),
+		grpcserver.IPAuthInterceptor(""),
+	))
+	pb.RegisterShortenerServiceServer(server, grpcserver.NewServer(svc))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return &grpcTransport{client: pb.NewShortenerServiceClient(conn)}
+}
+
+// ctx формирует контекст вызова с токеном пользователя
+func (g *grpcTransport) ctx(user string) context.Context {
+	ctx := context.Background()
+	if user == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+auth.SignUserID(user))
+}
+
+func grpcOutcome(err error) outcome {
+	switch status.Code(err) {
+	case codes.OK:
+		return outcomeOK
+	case codes.InvalidArgument:
+		return outcomeInvalid
+	case codes.NotFound:
+		return outcomeNotFound
+	case codes.FailedPrecondition:
+		return outcomeGone
+	case codes.AlreadyExists:
+		return outcomeAliasTaken
+	default:
+		return outcomeUnavailable
+	}
+}
+
+func (g *grpcTransport) Shorten(t *testing.T, user, url, alias string) (string, outcome) {
+	resp, err := g.client.ShortenURL(g.ctx(user), &pb.ShortenURLRequest{Url: url, Alias: alias})
+	if err != nil {
+		return "", grpcOutcome(err)
+	}
+	if resp.Conflict {
+		return resp.Result, outcomeExists
+	}
+	return resp.Result, outcomeOK
+}
+
+func (g *grpcTransport) Resolve(t *testing.T, id string) (string, outcome) {
+	resp, err := g.client.GetOriginalURL(g.ctx(""), &pb.GetOriginalURLRequest{Id: id})
+	if err != nil {
+		return "", grpcOutcome(err)
+	}
+	if resp.Deleted {
+		return "", outcomeGone
+	}
+	return resp.OriginalUrl, outcomeOK
+}
+
+func (g *grpcTransport) Batch(t *testing.T, user string, items []batchItem) (map[string]batchResult, outcome) {
+	request := &pb.ShortenBatchRequest{}
+	for _, item := range items {
+		request.Items = append(request.Items, &pb.BatchShortenItem{
+			CorrelationId: item.CorrelationID,
+			OriginalUrl:   item.URL,
+			Alias:         item.Alias,
+		})
+	}
+
+	resp, err := g.client.ShortenBatch(g.ctx(user), request)
+	if err != nil {
+		return nil, grpcOutcome(err)
+	}
+
+	results := make(map[string]batchResult, len(resp.Items))
+	for _, item := range resp.Items {
+		results[item.CorrelationId] = batchResult{ShortURL: item.ShortUrl, Status: item.Status}
+	}
+	return results, outcomeOK
+}
+
+func (g *grpcTransport) UserURLs(t *testing.T, user string) ([]models.UserURL, outcome) {
+	resp, err := g.client.GetUserURLs(g.ctx(user), &pb.GetUserURLsRequest{})
+	if err != nil {
+		return nil, grpcOutcome(err)
+	}
+
+	var urls []models.UserURL
+	for _, item := range resp.Urls {
+		require.NotNil(t, item.CreatedAt)
+		urls = append(urls, models.UserURL{ShortURL: item.ShortUrl, OriginalURL: item.OriginalUrl})
+	}
+	return urls, outcomeOK
+}
+
+func (g *grpcTransport) UserURLPage(t *testing.T, user string, page pageRequest) (pageResult, outcome) {
+	resp, err := g.client.GetUserURLs(g.ctx(user), &pb.GetUserURLsRequest{
+		Limit:  int32(page.Limit),
+		Cursor: page.Cursor,
+		Order:  page.Order,
+		Host:   page.Host,
+	})
+	if err != nil {
+		return pageResult{}, grpcOutcome(err)
+	}
+
+	result := pageResult{Total: int(resp.Total), NextCursor: resp.NextCursor}
+	for _, item := range resp.Urls {
+		result.ShortURLs = append(result.ShortURLs, item.ShortUrl)
+	}
+	return result, outcomeOK
+}
+
+func (g *grpcTransport) Delete(t *testing.T, user string, ids []string) outcome {
+	_, err := g.client.DeleteUserURLs(g.ctx(user), &pb.DeleteUserURLsRequest{ShortUrls: ids})
+	return grpcOutcome(err)
+}
@@ -1,8 +1,8 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"embed"
 	"errors"
 	"strings"
 
@@ -10,71 +10,50 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
-//go:embed schema.sql
-var schemaFS embed.FS
-
 // ErrURLConflict ошибка при попытке добавить существующий URL
 var ErrURLConflict = errors.New("url already exists")
 
+// ErrShortIDConflict ошибка при попытке добавить URL с уже занятым коротким ID
+var ErrShortIDConflict = errors.New("short id already exists")
+
 // DB представляет обертку над sql.DB с дополнительной функциональностью
 type DB struct {
 	*sql.DB
 }
 
-// New создает новое подключение к базе данных и инициализирует схему
+// New создает новое подключение к базе данных и применяет
+// непримененные миграции схемы
 func New(dsn string) (*DB, error) {
-	db, err := sql.Open("pgx", dsn)
+	database, err := Open(dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	// Создаем экземпляр DB
-	database := &DB{DB: db}
+	migrator, err := NewMigrator(database)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
 
-	// Инициализируем схему базы данных
-	if err = database.initSchema(); err != nil {
+	// Миграции выполняются под advisory lock, поэтому одновременный
+	// запуск нескольких реплик не приводит к гонке
+	if _, err = migrator.Up(context.Background()); err != nil {
+		database.Close()
 		return nil, err
 	}
 
 	return database, nil
 }
 
-// initSchema инициализирует схему базы данных
-func (db *DB) initSchema() error {
-	// Читаем SQL-файл
-	schemaSQL, err := schemaFS.ReadFile("schema.sql")
-	if err != nil {
-		return err
-	}
-
-	// Разделяем на отдельные команды
-	commands := strings.Split(string(schemaSQL), ";")
-
-	// Начинаем транзакцию
-	tx, err := db.Begin()
+// Open создает подключение к базе данных без применения миграций.
+// Используется командой shortener migrate.
+func Open(dsn string) (*DB, error) {
+	db, err := sql.Open("pgx", dsn)
 	if err != nil {
-		return err
-	}
-	// Отложенный откат транзакции в случае ошибки
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Выполняем каждую команду в транзакции
-	for _, command := range commands {
-		command = strings.TrimSpace(command)
-		if command == "" {
-			continue
-		}
-		if _, err := tx.Exec(command); err != nil {
-			return err
-		}
+		return nil, err
 	}
 
-	// Подтверждаем транзакцию
-	return tx.Commit()
+	return &DB{DB: db}, nil
 }
 
 // AddURL добавляет новый URL в базу данных
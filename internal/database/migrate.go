@@ -0,0 +1,359 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationsLockKey - ключ advisory lock, под которым выполняются миграции.
+// Одинаковый для всех реплик, поэтому миграции применяет только одна из них.
+const migrationsLockKey int64 = 0x73686f72746e72 // "shortnr"
+
+// ErrMigrationNotFound возвращается, когда в базе применена миграция,
+// отсутствующая в текущей сборке, и ее нельзя откатить.
+var ErrMigrationNotFound = errors.New("миграция не найдена в сборке")
+
+// Migration описывает одну версию схемы базы данных.
+//
+// Файлы миграций хранятся в каталоге migrations и называются
+// NNNN_описание.up.sql и NNNN_описание.down.sql.
+type Migration struct {
+	Version int64  // Номер версии
+	Name    string // Описание из имени файла
+	Up      string // SQL применения
+	Down    string // SQL отката
+}
+
+// MigrationStatus описывает состояние миграции в базе данных
+type MigrationStatus struct {
+	Migration
+	Applied   bool      // Миграция применена
+	AppliedAt time.Time // Момент применения (для примененных миграций)
+}
+
+// MigrationDB описывает операции базы данных, необходимые для выполнения миграций.
+//
+// Реализуется для PostgreSQL типом DB, а в тестах - MockMigrationDB.
+type MigrationDB interface {
+	// LockMigrations захватывает блокировку миграций и возвращает функцию ее освобождения
+	LockMigrations(ctx context.Context) (func() error, error)
+
+	// EnsureMigrationsTable создает таблицу schema_migrations, если ее нет
+	EnsureMigrationsTable(ctx context.Context) error
+
+	// AppliedMigrations возвращает примененные версии и моменты их применения
+	AppliedMigrations(ctx context.Context) (map[int64]time.Time, error)
+
+	// ApplyMigration выполняет SQL миграции и фиксирует версию в одной транзакции.
+	// Параметр up определяет направление: применение или откат.
+	ApplyMigration(ctx context.Context, m Migration, up bool) error
+}
+
+// Migrator применяет и откатывает миграции схемы
+type Migrator struct {
+	db         MigrationDB
+	migrations []Migration
+}
+
+// NewMigrator создает исполнитель миграций для встроенного набора миграций
+func NewMigrator(db MigrationDB) (*Migrator, error) {
+	migrations, err := LoadMigrations(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return NewMigratorWithMigrations(db, migrations), nil
+}
+
+// NewMigratorWithMigrations создает исполнитель с заданным набором миграций
+func NewMigratorWithMigrations(db MigrationDB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// LoadMigrations читает миграции из каталога dir файловой системы fsys.
+// Каждая версия должна иметь файлы up и down, номера версий не повторяются.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		version, name, up, err := parseMigrationName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("миграция %d: разные описания %q и %q", version, m.Name, name)
+		}
+		if up {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("миграция %d_%s: нужны файлы up и down", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// parseMigrationName разбирает имя файла вида 0001_create_urls.up.sql
+func parseMigrationName(filename string) (int64, string, bool, error) {
+	base := strings.TrimSuffix(filename, ".sql")
+
+	var up bool
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		up = true
+		base = strings.TrimSuffix(base, ".up")
+	case strings.HasSuffix(base, ".down"):
+		base = strings.TrimSuffix(base, ".down")
+	default:
+		return 0, "", false, fmt.Errorf("файл миграции %s: ожидается суффикс .up.sql или .down.sql", filename)
+	}
+
+	number, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", false, fmt.Errorf("файл миграции %s: ожидается имя NNNN_описание", filename)
+	}
+
+	version, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", false, fmt.Errorf("файл миграции %s: некорректный номер версии", filename)
+	}
+
+	return version, name, up, nil
+}
+
+// Up применяет все непримененные миграции по возрастанию версии.
+// Возвращает список примененных миграций.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(done map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if err := m.db.ApplyMigration(ctx, migration, true); err != nil {
+				return fmt.Errorf("применение миграции %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down откатывает steps последних примененных миграций.
+// Возвращает список откаченных миграций в порядке отката.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	byVersion := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	err := m.withLock(ctx, func(done map[int64]time.Time) error {
+		versions := make([]int64, 0, len(done))
+		for version := range done {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			migration, ok := byVersion[versions[i]]
+			if !ok {
+				return fmt.Errorf("откат версии %d: %w", versions[i], ErrMigrationNotFound)
+			}
+			if err := m.db.ApplyMigration(ctx, migration, false); err != nil {
+				return fmt.Errorf("откат миграции %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status возвращает состояние всех известных миграций по возрастанию версии
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.db.EnsureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+
+	done, err := m.db.AppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, applied := done[migration.Version]
+		result = append(result, MigrationStatus{
+			Migration: migration,
+			Applied:   applied,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// withLock выполняет fn под блокировкой миграций, передавая примененные версии
+func (m *Migrator) withLock(ctx context.Context, fn func(done map[int64]time.Time) error) (err error) {
+	unlock, err := m.db.LockMigrations(ctx)
+	if err != nil {
+		return fmt.Errorf("блокировка миграций: %w", err)
+	}
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil && err == nil {
+			err = fmt.Errorf("снятие блокировки миграций: %w", unlockErr)
+		}
+	}()
+
+	if err := m.db.EnsureMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	// Список примененных версий читается под блокировкой, чтобы
+	// не применить миграцию, которую только что применила другая реплика
+	done, err := m.db.AppliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return fn(done)
+}
+
+// LockMigrations захватывает сессионный advisory lock PostgreSQL.
+//
+// Блокировка удерживается на выделенном соединении пула до вызова
+// возвращенной функции освобождения.
+func (db *DB) LockMigrations(ctx context.Context) (func() error, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockKey); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return func() error {
+		// Снимаем блокировку даже при отмененном контексте вызывающего
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationsLockKey)
+		if closeErr := conn.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
+
+// EnsureMigrationsTable создает таблицу schema_migrations, если ее нет
+func (db *DB) EnsureMigrationsTable(ctx context.Context) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+		)
+	`)
+	return err
+}
+
+// AppliedMigrations возвращает примененные версии схемы
+func (db *DB) AppliedMigrations(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// ApplyMigration выполняет миграцию и обновляет schema_migrations в одной транзакции
+func (db *DB) ApplyMigration(ctx context.Context, m Migration, up bool) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	script := m.Down
+	if up {
+		script = m.Up
+	}
+
+	// Скрипт выполняется целиком без параметров (простой протокол),
+	// поэтому может содержать несколько команд
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// compile-time проверка реализации интерфейса
+var _ MigrationDB = (*DB)(nil)
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "create_a", Up: "CREATE TABLE a", Down: "DROP TABLE a"},
+		{Version: 2, Name: "create_b", Up: "CREATE TABLE b", Down: "DROP TABLE b"},
+		{Version: 3, Name: "create_c", Up: "CREATE TABLE c", Down: "DROP TABLE c"},
+	}
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := LoadMigrations(migrationsFS, "migrations")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version, "версии должны идти подряд")
+		assert.NotEmpty(t, m.Up)
+		assert.NotEmpty(t, m.Down)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name: "сортировка_по_версии",
+			files: fstest.MapFS{
+				"m/0010_b.up.sql":   {Data: []byte("B")},
+				"m/0010_b.down.sql": {Data: []byte("-B")},
+				"m/0002_a.up.sql":   {Data: []byte("A")},
+				"m/0002_a.down.sql": {Data: []byte("-A")},
+				"m/README.md":       {Data: []byte("пропускается")},
+			},
+			want: []int64{2, 10},
+		},
+		{
+			name: "нет_файла_down",
+			files: fstest.MapFS{
+				"m/0001_a.up.sql": {Data: []byte("A")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "некорректное_имя",
+			files: fstest.MapFS{
+				"m/init.sql": {Data: []byte("A")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "одна_версия_с_разными_описаниями",
+			files: fstest.MapFS{
+				"m/0001_a.up.sql":   {Data: []byte("A")},
+				"m/0001_b.down.sql": {Data: []byte("-A")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.files, "m")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var versions []int64
+			for _, m := range migrations {
+				versions = append(versions, m.Version)
+			}
+			assert.Equal(t, tt.want, versions)
+		})
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	ctx := context.Background()
+	db := NewMockMigrationDB()
+	migrator := NewMigratorWithMigrations(db, testMigrations())
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, 3)
+	assert.Equal(t, []string{"CREATE TABLE a", "CREATE TABLE b", "CREATE TABLE c"}, db.Executed)
+	assert.False(t, db.Locked, "блокировка должна быть снята")
+
+	// Повторный запуск ничего не применяет
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Len(t, db.Executed, 3)
+	assert.Equal(t, 2, db.LockCount)
+}
+
+func TestMigrator_UpAppliesOnlyPending(t *testing.T) {
+	db := NewMockMigrationDB()
+	migrator := NewMigratorWithMigrations(db, testMigrations())
+
+	_, err := NewMigratorWithMigrations(db, testMigrations()[:1]).Up(context.Background())
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	assert.Equal(t, int64(2), applied[0].Version)
+	assert.Equal(t, int64(3), applied[1].Version)
+}
+
+func TestMigrator_UpFailure(t *testing.T) {
+	db := NewMockMigrationDB()
+	db.FailVersion = 2
+	db.FailErr = errors.New("syntax error")
+	migrator := NewMigratorWithMigrations(db, testMigrations())
+
+	applied, err := migrator.Up(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, db.FailErr)
+	assert.Len(t, applied, 1)
+	assert.Contains(t, db.Applied, int64(1))
+	assert.NotContains(t, db.Applied, int64(2))
+	assert.NotContains(t, db.Applied, int64(3))
+	assert.False(t, db.Locked, "блокировка должна быть снята после ошибки")
+}
+
+func TestMigrator_LockError(t *testing.T) {
+	db := NewMockMigrationDB()
+	db.LockErr = errors.New("lock timeout")
+	migrator := NewMigratorWithMigrations(db, testMigrations())
+
+	_, err := migrator.Up(context.Background())
+	assert.ErrorIs(t, err, db.LockErr)
+	assert.Empty(t, db.Executed)
+}
+
+func TestMigrator_Down(t *testing.T) {
+	ctx := context.Background()
+	db := NewMockMigrationDB()
+	migrator := NewMigratorWithMigrations(db, testMigrations())
+
+	_, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	reverted, err := migrator.Down(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, reverted, 2)
+	assert.Equal(t, int64(3), reverted[0].Version)
+	assert.Equal(t, int64(2), reverted[1].Version)
+	assert.Equal(t, "DROP TABLE b", db.Executed[len(db.Executed)-1])
+	assert.Contains(t, db.Applied, int64(1))
+	assert.Len(t, db.Applied, 1)
+
+	// Откат большего числа шагов, чем применено, не является ошибкой
+	reverted, err = migrator.Down(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, reverted, 1)
+	assert.Empty(t, db.Applied)
+}
+
+func TestMigrator_DownUnknownVersion(t *testing.T) {
+	db := NewMockMigrationDB()
+	migrator := NewMigratorWithMigrations(db, testMigrations())
+	db.Applied[99] = time.Now()
+
+	_, err := migrator.Down(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrMigrationNotFound)
+	assert.False(t, db.Locked)
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db := NewMockMigrationDB()
+	migrator := NewMigratorWithMigrations(db, testMigrations())
+
+	_, err := NewMigratorWithMigrations(db, testMigrations()[:2]).Up(context.Background())
+	require.NoError(t, err)
+
+	status, err := migrator.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, status, 3)
+	assert.True(t, status[0].Applied)
+	assert.False(t, status[0].AppliedAt.IsZero())
+	assert.True(t, status[1].Applied)
+	assert.False(t, status[2].Applied)
+	assert.True(t, status[2].AppliedAt.IsZero())
+}
@@ -0,0 +1,5 @@
+-- Добавляем срок действия ссылок
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+-- Создаем частичный индекс для фоновой очистки истекших ссылок
+CREATE INDEX IF NOT EXISTS idx_urls_expires_at ON urls (expires_at) WHERE expires_at IS NOT NULL;
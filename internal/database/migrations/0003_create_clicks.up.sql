@@ -0,0 +1,11 @@
+-- Создаем таблицу событий перехода по коротким ссылкам
+CREATE TABLE IF NOT EXISTS clicks (
+    id BIGSERIAL PRIMARY KEY,
+    short_id VARCHAR(64) NOT NULL,
+    clicked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    referrer TEXT NOT NULL DEFAULT '',
+    user_agent_family VARCHAR(32) NOT NULL DEFAULT ''
+);
+
+-- Создаем индекс для выборки статистики по ссылке за период
+CREATE INDEX IF NOT EXISTS idx_clicks_short_id_clicked_at ON clicks (short_id, clicked_at);
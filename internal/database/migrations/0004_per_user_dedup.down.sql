@@ -0,0 +1,6 @@
+-- Откат возможен, только если у каждого URL осталась одна ссылка
+CREATE UNIQUE INDEX IF NOT EXISTS idx_urls_original_url_unique ON urls (original_url);
+
+DROP INDEX IF EXISTS idx_urls_original_url_dedup;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS dedup_owner;
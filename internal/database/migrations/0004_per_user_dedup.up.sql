@@ -0,0 +1,12 @@
+-- Владелец группы дедупликации: ссылки на один URL с одинаковым владельцем
+-- группы считаются дубликатами. Пустая строка - глобальная дедупликация,
+-- ID пользователя - дедупликация в пределах пользователя, NULL - без дедупликации
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS dedup_owner TEXT DEFAULT '';
+
+-- Существующие ссылки создавались с глобальной дедупликацией
+UPDATE urls SET dedup_owner = '' WHERE dedup_owner IS NULL;
+
+-- Уникальность URL теперь действует только внутри группы дедупликации
+CREATE UNIQUE INDEX IF NOT EXISTS idx_urls_original_url_dedup ON urls (original_url, dedup_owner);
+
+DROP INDEX IF EXISTS idx_urls_original_url_unique;
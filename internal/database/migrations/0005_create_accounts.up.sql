@@ -0,0 +1,24 @@
+-- Учетные записи пользователей. id совпадает с user_id ссылок в таблице urls
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(36) PRIMARY KEY,
+    login VARCHAR(64) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Выданные учетным записям токены: сессии и именованные API-ключи.
+-- Хранится только SHA-256 хеш токена
+CREATE TABLE IF NOT EXISTS credentials (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    prefix VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Создаем индекс для списка учетных данных пользователя
+CREATE INDEX IF NOT EXISTS idx_credentials_user_id_kind ON credentials (user_id, kind);
@@ -0,0 +1,12 @@
+-- Корзины токенов ограничения частоты запросов (RATE_LIMIT_STORE=postgres).
+-- full_at - момент полного восстановления корзины, после которого
+-- строку можно удалить
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    full_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Создаем индекс для удаления восстановленных корзин
+CREATE INDEX IF NOT EXISTS idx_rate_limits_full_at ON rate_limits (full_at);
@@ -0,0 +1,5 @@
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+DROP INDEX IF EXISTS idx_urls_created_at;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS is_disabled;
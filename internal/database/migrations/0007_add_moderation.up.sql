@@ -0,0 +1,8 @@
+-- Ссылки, отключенные модератором: не перенаправляют, но не удаляются
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS is_disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Создаем индекс для поиска ссылок по дате создания
+CREATE INDEX IF NOT EXISTS idx_urls_created_at ON urls (created_at);
+
+-- Роль учетной записи: user или admin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'user';
@@ -0,0 +1,3 @@
+-- Создаем индекс для постраничного вывода URL пользователя по дате создания
+CREATE INDEX IF NOT EXISTS idx_urls_user_id_created_at
+    ON urls (user_id, (COALESCE(created_at, to_timestamp(0))), short_id);
@@ -0,0 +1,11 @@
+-- Граница номеров, выданных генераторам ID counter и sqids. Значение только
+-- растет, поэтому номера не повторяются после удаления ссылок и перезапуска.
+-- Для уже существующих ссылок граница начинается с последнего номера urls.id
+CREATE TABLE IF NOT EXISTS id_counter (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    value BIGINT NOT NULL
+);
+
+INSERT INTO id_counter (value)
+SELECT COALESCE(MAX(id), 0) FROM urls
+ON CONFLICT (id) DO NOTHING;
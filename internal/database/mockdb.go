@@ -1,5 +1,12 @@
 package database
 
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
 // MockDB мок для базы данных, используется в тестах
 type MockDB struct{}
 
@@ -25,3 +32,86 @@ func (m *MockDB) FindByOriginalURL(originalURL string) (string, bool, error) {
 	// Для тестов возвращаем фиктивный ID
 	return "abc123", true, nil
 }
+
+// MockMigrationDB мок базы данных для тестов исполнителя миграций.
+//
+// Хранит примененные версии в памяти, отслеживает состояние блокировки
+// и позволяет имитировать сбой выполнения отдельной миграции.
+type MockMigrationDB struct {
+	mu sync.Mutex
+
+	// Applied - примененные версии и моменты их применения
+	Applied map[int64]time.Time
+	// Executed - выполненные скрипты в порядке выполнения
+	Executed []string
+	// FailVersion - версия, выполнение которой завершается ошибкой FailErr
+	FailVersion int64
+	// FailErr - ошибка, возвращаемая для FailVersion
+	FailErr error
+	// LockErr - ошибка захвата блокировки
+	LockErr error
+
+	// Locked - блокировка удерживается в данный момент
+	Locked bool
+	// LockCount - сколько раз блокировка была захвачена
+	LockCount int
+}
+
+// NewMockMigrationDB создает мок с пустой историей миграций
+func NewMockMigrationDB() *MockMigrationDB {
+	return &MockMigrationDB{Applied: make(map[int64]time.Time)}
+}
+
+func (m *MockMigrationDB) LockMigrations(ctx context.Context) (func() error, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.LockErr != nil {
+		return nil, m.LockErr
+	}
+	if m.Locked {
+		return nil, errors.New("блокировка миграций уже захвачена")
+	}
+	m.Locked = true
+	m.LockCount++
+
+	return func() error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.Locked = false
+		return nil
+	}, nil
+}
+
+func (m *MockMigrationDB) EnsureMigrationsTable(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (m *MockMigrationDB) AppliedMigrations(ctx context.Context) (map[int64]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	applied := make(map[int64]time.Time, len(m.Applied))
+	for version, at := range m.Applied {
+		applied[version] = at
+	}
+	return applied, nil
+}
+
+func (m *MockMigrationDB) ApplyMigration(ctx context.Context, migration Migration, up bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if migration.Version == m.FailVersion && m.FailErr != nil {
+		return m.FailErr
+	}
+
+	if up {
+		m.Executed = append(m.Executed, migration.Up)
+		m.Applied[migration.Version] = time.Now()
+	} else {
+		m.Executed = append(m.Executed, migration.Down)
+		delete(m.Applied, migration.Version)
+	}
+	return nil
+}
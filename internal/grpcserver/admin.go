@@ -0,0 +1,231 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/storage"
+	pb "github.com/Adigezalov/shortener/pkg/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// adminMethodPrefix - префикс полных имен методов AdminService
+const adminMethodPrefix = "/shortener.AdminService/"
+
+// AdminInterceptor перехватчик для проверки роли администратора.
+// Применяется только к методам AdminService и подключается после
+// AuthInterceptor. Как и на HTTP маршрутах модерации, роль проверяется
+// только у учетной записи, предъявившей токен сессии или API-ключ: вызов
+// без такого токена (в том числе с подписанным анонимным ID) отклоняется
+// с кодом Unauthenticated, вызов учетной записи без роли auth.RoleAdmin -
+// с кодом PermissionDenied.
+func AdminInterceptor(roles auth.RoleChecker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, adminMethodPrefix) {
+			return handler(ctx, req)
+		}
+
+		identity, ok := auth.IdentityFromContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "требуется вход в учетную запись")
+		}
+		userID := identity.UserID
+
+		ok, err := roles.HasRole(ctx, userID, auth.RoleAdmin)
+		if err != nil {
+			logger.Ctx(ctx).Error("gRPC: ошибка проверки роли", zap.Error(err))
+			return nil, storageStatus(err, "ошибка проверки роли")
+		}
+		if !ok {
+			logger.Ctx(ctx).Warn("gRPC: доступ к модерации запрещен",
+				zap.String("method", info.FullMethod),
+				zap.String("user_id", userID))
+			return nil, status.Error(codes.PermissionDenied, "доступ запрещен")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AdminServer реализует gRPC сервер для AdminService.
+type AdminServer struct {
+	pb.UnimplementedAdminServiceServer
+	service *service.ShortenerService
+}
+
+// NewAdminServer создает новый экземпляр gRPC сервера модерации.
+func NewAdminServer(svc *service.ShortenerService) *AdminServer {
+	return &AdminServer{
+		service: svc,
+	}
+}
+
+// adminStatus преобразует ошибку сервиса модерации в gRPC статус.
+func adminStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrEmptyList),
+		errors.Is(err, service.ErrTooManyLinks),
+		errors.Is(err, service.ErrInvalidFilter),
+		errors.Is(err, service.ErrInvalidOwner),
+		errors.Is(err, service.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrAccountNotFound),
+		errors.Is(err, storage.ErrNotFound):
+		return status.Error(codes.NotFound, "не найдено")
+	case errors.Is(err, service.ErrAdminNotConfigured),
+		errors.Is(err, service.ErrAccountsNotConfigured):
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		return storageStatus(err, "ошибка модерации ссылок")
+	}
+}
+
+// SearchLinks ищет ссылки по фильтру.
+func (s *AdminServer) SearchLinks(ctx context.Context, req *pb.SearchLinksRequest) (*pb.SearchLinksResponse, error) {
+	linkStatus, err := storage.ParseLinkStatus(req.Status)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	search := service.LinkSearch{
+		LinkFilter: storage.LinkFilter{
+			ShortID:     req.ShortId,
+			URLContains: req.Query,
+			Host:        req.Host,
+			UserID:      req.UserId,
+			Status:      linkStatus,
+		},
+		OwnerLogin: req.Login,
+		Offset:     int(req.Offset),
+		Limit:      int(req.Limit),
+	}
+	if req.CreatedFrom != nil {
+		search.CreatedFrom = req.CreatedFrom.AsTime()
+	}
+	if req.CreatedTo != nil {
+		search.CreatedTo = req.CreatedTo.AsTime()
+	}
+
+	result, err := s.service.SearchLinks(ctx, search)
+	if err != nil {
+		return nil, adminStatus(err)
+	}
+
+	links := make([]*pb.AdminLink, len(result.Links))
+	for i, link := range result.Links {
+		links[i] = adminLinkToProto(link)
+	}
+
+	return &pb.SearchLinksResponse{
+		Links:  links,
+		Total:  int32(result.Total),
+		Offset: int32(result.Offset),
+		Limit:  int32(result.Limit),
+	}, nil
+}
+
+// GetLink возвращает ссылку по короткому ID.
+func (s *AdminServer) GetLink(ctx context.Context, req *pb.GetLinkRequest) (*pb.AdminLink, error) {
+	link, err := s.service.GetLink(ctx, req.Id)
+	if err != nil {
+		return nil, adminStatus(err)
+	}
+	return adminLinkToProto(link), nil
+}
+
+// adminLinkToProto преобразует ссылку модерации в сообщение gRPC
+func adminLinkToProto(link models.AdminLink) *pb.AdminLink {
+	result := &pb.AdminLink{
+		ShortId:     link.ShortID,
+		ShortUrl:    link.ShortURL,
+		OriginalUrl: link.OriginalURL,
+		UserId:      link.UserID,
+		Deleted:     link.Deleted,
+		Disabled:    link.Disabled,
+	}
+	if link.CreatedAt != nil {
+		result.CreatedAt = timestamppb.New(*link.CreatedAt)
+	}
+	if link.ExpiresAt != nil {
+		result.ExpiresAt = timestamppb.New(*link.ExpiresAt)
+	}
+	return result
+}
+
+// DisableLinks отключает ссылки.
+func (s *AdminServer) DisableLinks(ctx context.Context, req *pb.AdminLinksRequest) (*pb.AdminLinksResponse, error) {
+	return s.moderate(ctx, "disable", req.Ids, func() (int, error) {
+		return s.service.DisableLinks(ctx, req.Ids)
+	})
+}
+
+// EnableLinks включает ранее отключенные ссылки.
+func (s *AdminServer) EnableLinks(ctx context.Context, req *pb.AdminLinksRequest) (*pb.AdminLinksResponse, error) {
+	return s.moderate(ctx, "enable", req.Ids, func() (int, error) {
+		return s.service.EnableLinks(ctx, req.Ids)
+	})
+}
+
+// DeleteLinks безвозвратно удаляет ссылки.
+func (s *AdminServer) DeleteLinks(ctx context.Context, req *pb.AdminLinksRequest) (*pb.AdminLinksResponse, error) {
+	return s.moderate(ctx, "delete", req.Ids, func() (int, error) {
+		return s.service.PurgeLinks(ctx, req.Ids)
+	})
+}
+
+// ReassignLinks передает ссылки другому владельцу.
+func (s *AdminServer) ReassignLinks(ctx context.Context, req *pb.ReassignLinksRequest) (*pb.AdminLinksResponse, error) {
+	return s.moderate(ctx, "reassign", req.Ids, func() (int, error) {
+		return s.service.ReassignLinks(ctx, req.Ids, service.LinkOwner{
+			UserID: req.UserId,
+			Login:  req.Login,
+		})
+	})
+}
+
+// moderate выполняет групповую операцию и записывает в журнал,
+// кто из администраторов и над какими ссылками ее выполнил
+func (s *AdminServer) moderate(ctx context.Context, action string, ids []string, apply func() (int, error)) (*pb.AdminLinksResponse, error) {
+	affected, err := apply()
+	if err != nil {
+		return nil, adminStatus(err)
+	}
+
+	adminID, _ := getUserIDFromContext(ctx)
+	logger.Ctx(ctx).Info("gRPC: модерация ссылок",
+		zap.String("action", action),
+		zap.String("admin_id", adminID),
+		zap.Strings("ids", ids),
+		zap.Int("affected", affected))
+
+	return &pb.AdminLinksResponse{Affected: int32(affected)}, nil
+}
+
+// SetAccountRole назначает роль учетной записи.
+func (s *AdminServer) SetAccountRole(ctx context.Context, req *pb.SetAccountRoleRequest) (*pb.SetAccountRoleResponse, error) {
+	account, err := s.service.SetAccountRole(ctx, req.Login, req.Role)
+	if err != nil {
+		return nil, adminStatus(err)
+	}
+
+	adminID, _ := getUserIDFromContext(ctx)
+	logger.Ctx(ctx).Info("gRPC: изменена роль учетной записи",
+		zap.String("admin_id", adminID),
+		zap.String("user_id", account.ID),
+		zap.String("role", account.Role))
+
+	return &pb.SetAccountRoleResponse{
+		UserId: account.ID,
+		Login:  account.Login,
+		Role:   account.Role,
+	}, nil
+}
@@ -0,0 +1,124 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	pb "github.com/Adigezalov/shortener/pkg/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAdminServer(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	ctx := context.Background()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil).
+		WithAccounts(store).
+		WithAdmin(store)
+
+	require.NoError(t, store.CreateAccount(ctx, storage.Account{ID: "acc1", Login: "admin"}))
+	require.NoError(t, store.CreateAccount(ctx, storage.Account{ID: "acc2", Login: "alice"}))
+	_, err := svc.SetAccountRole(ctx, "admin", auth.RoleAdmin)
+	require.NoError(t, err)
+	adminKey, err := svc.CreateAPIKey(ctx, "acc1", "admin")
+	require.NoError(t, err)
+	aliceKey, err := svc.CreateAPIKey(ctx, "acc2", "alice")
+	require.NoError(t, err)
+
+	_, err = store.Add(ctx, "spam", "https://spam.example.com/", "acc2", time.Time{})
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		AuthInterceptor(svc, auth.ModeStrict),
+		AdminInterceptor(svc),
+	))
+	pb.RegisterShortenerServiceServer(server, NewServer(svc))
+	pb.RegisterAdminServiceServer(server, NewAdminServer(svc))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewAdminServiceClient(conn)
+	shortenerClient := pb.NewShortenerServiceClient(conn)
+
+	// Без токена и без роли доступ запрещен
+	_, err = client.SearchLinks(ctx, &pb.SearchLinksRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	aliceCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+aliceKey.Token)
+	_, err = client.SearchLinks(aliceCtx, &pb.SearchLinksRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Подписанный анонимный ID администратора не заменяет токен учетной записи
+	anonymousCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+auth.SignUserID("acc1"))
+	_, err = client.SearchLinks(anonymousCtx, &pb.SearchLinksRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	adminCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+adminKey.Token)
+	found, err := client.SearchLinks(adminCtx, &pb.SearchLinksRequest{Login: "alice", Host: "example.com"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, found.Total)
+	require.Len(t, found.Links, 1)
+	assert.Equal(t, "spam", found.Links[0].ShortId)
+	assert.NotNil(t, found.Links[0].CreatedAt)
+
+	_, err = client.SearchLinks(adminCtx, &pb.SearchLinksRequest{Status: "unknown"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Отключенная ссылка возвращает FailedPrecondition с reason LINK_DISABLED
+	res, err := client.DisableLinks(adminCtx, &pb.AdminLinksRequest{Ids: []string{"spam"}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.Affected)
+
+	_, err = shortenerClient.GetOriginalURL(ctx, &pb.GetOriginalURLRequest{Id: "spam"})
+	st := status.Convert(err)
+	require.Equal(t, codes.FailedPrecondition, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "LINK_DISABLED", info.Reason)
+
+	link, err := client.GetLink(adminCtx, &pb.GetLinkRequest{Id: "spam"})
+	require.NoError(t, err)
+	assert.True(t, link.Disabled)
+
+	res, err = client.ReassignLinks(adminCtx, &pb.ReassignLinksRequest{Ids: []string{"spam"}, Login: "admin"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.Affected)
+
+	res, err = client.DeleteLinks(adminCtx, &pb.AdminLinksRequest{Ids: []string{"spam"}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.Affected)
+	_, err = client.GetLink(adminCtx, &pb.GetLinkRequest{Id: "spam"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	role, err := client.SetAccountRole(adminCtx, &pb.SetAccountRoleRequest{Login: "alice", Role: auth.RoleAdmin})
+	require.NoError(t, err)
+	assert.Equal(t, "acc2", role.UserId)
+	_, err = client.SearchLinks(aliceCtx, &pb.SearchLinksRequest{})
+	assert.NoError(t, err)
+}
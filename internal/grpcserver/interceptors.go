@@ -8,6 +8,10 @@ import (
 
 	"github.com/Adigezalov/shortener/internal/auth"
 	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/metrics"
+	"github.com/Adigezalov/shortener/internal/ratelimit"
+	"github.com/Adigezalov/shortener/internal/tracing"
+	pb "github.com/Adigezalov/shortener/pkg/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -16,97 +20,323 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// LoggingInterceptor перехватчик для логирования gRPC запросов.
-func LoggingInterceptor() grpc.UnaryServerInterceptor {
+// Метрики gRPC вызовов по полному имени метода
+var (
+	grpcRequests = metrics.Register(metrics.NewCounterVec(
+		"shortener_grpc_requests_total",
+		"Количество обработанных gRPC вызовов",
+		"method", "code"))
+	grpcDuration = metrics.Register(metrics.NewHistogramVec(
+		"shortener_grpc_request_duration_seconds",
+		"Длительность обработки gRPC вызовов в секундах",
+		nil, "method"))
+)
+
+// TracingInterceptor перехватчик для трассировки gRPC вызовов.
+// Продолжает трассировку из метаданных traceparent (или начинает новую)
+// и создает серверный span с именем метода. Ошибкой span'а считаются
+// только сбои сервера, но не ошибки клиента (NotFound, InvalidArgument и т.п.).
+func TracingInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		start := time.Now()
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endServerSpan(span, err)
+		return resp, err
+	}
+}
 
-		// Получаем IP клиента
-		clientIP := ""
-		if p, ok := peer.FromContext(ctx); ok {
-			clientIP = p.Addr.String()
+// StreamTracingInterceptor - аналог TracingInterceptor для потоковых вызовов.
+// Span охватывает весь поток.
+func StreamTracingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		endServerSpan(span, err)
+		return err
+	}
+}
+
+// startServerSpan создает серверный span вызова method
+func startServerSpan(ctx context.Context, method string) (context.Context, *tracing.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(tracing.TraceparentHeader); len(values) > 0 {
+			ctx = tracing.Extract(ctx, values[0])
 		}
+	}
+
+	return tracing.Start(ctx, method,
+		tracing.WithKind(tracing.SpanKindServer),
+		tracing.WithAttributes(
+			tracing.String("rpc.system", "grpc"),
+			tracing.String("rpc.method", method),
+		))
+}
+
+// endServerSpan записывает код статуса вызова и завершает span
+func endServerSpan(span *tracing.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(tracing.String("rpc.grpc.status_code", code.String()))
+	if isServerError(code) {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// serverStream подменяет контекст потока, чтобы перехватчики потоковых
+// вызовов передавали обработчику дополненный контекст
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает дополненный контекст потока
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// isServerError сообщает, что код статуса означает сбой сервера
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
 
-		logger.Logger.Info("gRPC запрос начат",
-			zap.String("method", info.FullMethod),
-			zap.String("client_ip", clientIP))
+// LoggingInterceptor перехватчик для логирования gRPC запросов.
+// Также учитывает вызов в метриках shortener_grpc_requests_total
+// и shortener_grpc_request_duration_seconds.
+func LoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done := logCall(ctx, info.FullMethod)
 
 		// Вызываем handler
 		resp, err := handler(ctx, req)
+		done(err)
 
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor - аналог LoggingInterceptor для потоковых вызовов.
+// Длительность вызова - время от открытия до закрытия потока.
+func StreamLoggingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done := logCall(ss.Context(), info.FullMethod)
+		err := handler(srv, ss)
+		done(err)
+		return err
+	}
+}
+
+// logCall логирует начало вызова method и возвращает функцию,
+// которая логирует его результат и учитывает вызов в метриках
+func logCall(ctx context.Context, method string) func(err error) {
+	start := time.Now()
+
+	// Получаем IP клиента
+	clientIP := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = p.Addr.String()
+	}
+
+	logger.Ctx(ctx).Info("gRPC запрос начат",
+		zap.String("method", method),
+		zap.String("client_ip", clientIP))
+
+	return func(err error) {
 		// Логируем результат
 		duration := time.Since(start)
+		grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+		grpcDuration.WithLabelValues(method).Observe(duration.Seconds())
 		if err != nil {
 			st, _ := status.FromError(err)
-			logger.Logger.Error("gRPC запрос завершен с ошибкой",
-				zap.String("method", info.FullMethod),
+			logger.Ctx(ctx).Error("gRPC запрос завершен с ошибкой",
+				zap.String("method", method),
 				zap.Duration("duration", duration),
 				zap.String("error", st.Message()),
 				zap.String("code", st.Code().String()))
 		} else {
-			logger.Logger.Info("gRPC запрос завершен успешно",
-				zap.String("method", info.FullMethod),
+			logger.Ctx(ctx).Info("gRPC запрос завершен успешно",
+				zap.String("method", method),
 				zap.Duration("duration", duration))
 		}
-
-		return resp, err
 	}
 }
 
+// userMethods - методы, работающие с URL пользователя. В режиме
+// auth.ModeStrict они требуют действительного токена.
+var userMethods = map[string]bool{
+	pb.ShortenerService_GetUserURLs_FullMethodName:    true,
+	pb.ShortenerService_ExportUserURLs_FullMethodName: true,
+	pb.ShortenerService_DeleteUserURLs_FullMethodName: true,
+	pb.ShortenerService_GetURLStats_FullMethodName:    true,
+}
+
 // AuthInterceptor перехватчик для аутентификации пользователей.
-func AuthInterceptor() grpc.UnaryServerInterceptor {
+//
+// Принимает в метаданных authorization подписанный ID анонимного
+// пользователя или токен учетной записи (API-ключ или сессию), который
+// проверяется через tokens. Недействительный токен учетной записи
+// отклоняется с кодом Unauthenticated. При tokens == nil проверяются
+// только анонимные ID.
+//
+// В режиме auth.ModeStrict недействительный подписанный ID также отклоняется
+// с кодом Unauthenticated, а методы URL пользователя без токена недоступны.
+// Клиенту без токена выдается новый анонимный ID в заголовке ответа
+// authorization. В режиме auth.ModeLegacy вместо недействительного ID
+// всегда выдается новый.
+func AuthInterceptor(tokens auth.TokenVerifier, mode auth.Mode) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		// Получаем метаданные из контекста
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			// Создаем метаданные, если их нет
-			md = metadata.New(nil)
+		newCtx, err := authenticate(ctx, tokens, mode, info.FullMethod)
+		if err != nil {
+			return nil, err
 		}
 
-		// Проверяем, есть ли токен авторизации
-		tokens := md.Get("authorization")
-		var userID string
+		// Вызываем handler с обновленным контекстом
+		return handler(newCtx, req)
+	}
+}
 
-		if len(tokens) > 0 {
-			// Извлекаем токен (формат: Bearer <signed_user_id>)
-			signedUserID := tokens[0]
-			if len(signedUserID) > 7 && signedUserID[:7] == "Bearer " {
-				signedUserID = signedUserID[7:]
-			}
+// StreamAuthInterceptor - аналог AuthInterceptor для потоковых вызовов.
+// Токен проверяется один раз при открытии потока.
+func StreamAuthInterceptor(tokens auth.TokenVerifier, mode auth.Mode) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), tokens, mode, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate определяет пользователя вызова method и возвращает контекст
+// с его ID в метаданных user-id (см. AuthInterceptor).
+func authenticate(ctx context.Context, tokens auth.TokenVerifier, mode auth.Mode, method string) (context.Context, error) {
+	// Получаем метаданные из контекста
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		// Создаем метаданные, если их нет
+		md = metadata.New(nil)
+	}
+	md = md.Copy()
+
+	values := md.Get("authorization")
 
-			// Верифицируем подписанный user ID
-			var err error
-			userID, err = auth.VerifyUserID(signedUserID)
+	// Токен учетной записи определяет пользователя без анонимного ID
+	if len(values) > 0 && tokens != nil {
+		if token := auth.BearerToken(values[0]); auth.IsAccountToken(token) {
+			identity, err := tokens.VerifyToken(ctx, token)
 			if err != nil {
-				logger.Logger.Warn("gRPC: невалидный токен",
-					zap.String("method", info.FullMethod),
+				logger.Ctx(ctx).Warn("gRPC: недействительный токен учетной записи",
+					zap.String("method", method),
 					zap.Error(err))
-				// Генерируем новый user ID для анонимного пользователя
-				userID = auth.GenerateUserID()
-				signedUserID = auth.SignUserID(userID)
-				// Добавляем новый токен в исходящие метаданные
-				header := metadata.Pairs("authorization", "Bearer "+signedUserID)
-				grpc.SendHeader(ctx, header)
+				return nil, status.Error(codes.Unauthenticated, "недействительный токен")
 			}
-		} else {
-			// Если токена нет, генерируем новый для анонимного пользователя
-			userID = auth.GenerateUserID()
-			signedUserID := auth.SignUserID(userID)
 
-			// Добавляем новый токен в исходящие метаданные
-			header := metadata.Pairs("authorization", "Bearer "+signedUserID)
-			grpc.SendHeader(ctx, header)
+			md.Set("user-id", identity.UserID)
+			return metadata.NewIncomingContext(auth.WithIdentity(ctx, identity), md), nil
 		}
+	}
 
-		// Добавляем user ID в метаданные контекста
-		md.Set("user-id", userID)
-		newCtx := metadata.NewIncomingContext(ctx, md)
+	var userID string
+	if len(values) > 0 {
+		// Верифицируем подписанный user ID (формат: Bearer <signed_user_id>)
+		var err error
+		userID, err = auth.VerifyUserID(auth.BearerToken(values[0]))
+		if err != nil {
+			logger.Ctx(ctx).Warn("gRPC: невалидный токен",
+				zap.String("method", method),
+				zap.Error(err))
+			if mode != auth.ModeLegacy {
+				return nil, status.Error(codes.Unauthenticated, "недействительный токен")
+			}
+		}
+	} else if mode != auth.ModeLegacy && userMethods[method] {
+		return nil, status.Error(codes.Unauthenticated, "требуется аутентификация")
+	}
 
-		// Вызываем handler с обновленным контекстом
-		return handler(newCtx, req)
+	if userID == "" {
+		// Генерируем новый user ID для анонимного пользователя
+		// и добавляем токен в исходящие метаданные
+		userID = auth.GenerateUserID()
+		header := metadata.Pairs("authorization", "Bearer "+auth.SignUserID(userID))
+		grpc.SetHeader(ctx, header)
+	}
+
+	// Добавляем user ID в метаданные контекста
+	md.Set("user-id", userID)
+	return metadata.NewIncomingContext(ctx, md), nil
+}
+
+// RateLimitInterceptor перехватчик для ограничения частоты вызовов.
+// Подключается после AuthInterceptor: клиент определяется ID пользователя
+// учетной записи, а для анонимного вызова - IP-адресом.
+// Состояние ограничения передается в заголовках ответа ratelimit-*,
+// при превышении возвращается код ResourceExhausted с заголовком retry-after.
+// При limiter == nil вызовы не ограничиваются.
+func RateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := allowCall(ctx, limiter, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor - аналог RateLimitInterceptor для потоковых
+// вызовов: открытие потока учитывается как один вызов независимо
+// от количества сообщений в нем.
+func StreamRateLimitInterceptor(limiter *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allowCall(ss.Context(), limiter, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
 	}
 }
 
+// allowCall учитывает вызов method в ограничении частоты и возвращает
+// ошибку ResourceExhausted, если лимит превышен
+func allowCall(ctx context.Context, limiter *ratelimit.Limiter, method string) error {
+	if limiter == nil {
+		return nil
+	}
+
+	// Анонимный ID можно получить на каждый вызов, поэтому по пользователю
+	// различаются только вызовы с токеном учетной записи
+	request := ratelimit.Request{Route: method, IP: peerIP(ctx)}
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		request.UserID = identity.UserID
+	}
+
+	res, ok := limiter.Allow(ctx, request)
+	if !ok {
+		return nil
+	}
+
+	grpc.SetHeader(ctx, metadata.New(res.Headers()))
+	if !res.Allowed {
+		return status.Error(codes.ResourceExhausted, "превышен лимит запросов")
+	}
+	return nil
+}
+
+// peerIP возвращает IP-адрес клиента gRPC без порта
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
 // IPAuthInterceptor перехватчик для проверки доверенной подсети.
 // Применяется только к методам, требующим проверки IP (например, GetStats).
 func IPAuthInterceptor(trustedSubnet string) grpc.UnaryServerInterceptor {
@@ -122,19 +352,11 @@ func IPAuthInterceptor(trustedSubnet string) grpc.UnaryServerInterceptor {
 		}
 
 		// Получаем IP клиента
-		var clientIP string
-		if p, ok := peer.FromContext(ctx); ok {
-			host, _, err := net.SplitHostPort(p.Addr.String())
-			if err != nil {
-				clientIP = p.Addr.String()
-			} else {
-				clientIP = host
-			}
-		}
+		clientIP := peerIP(ctx)
 
 		// Проверяем, находится ли IP в доверенной подсети
 		if !isIPInSubnet(clientIP, trustedSubnet) {
-			logger.Logger.Warn("gRPC: доступ запрещен - IP не в доверенной подсети",
+			logger.Ctx(ctx).Warn("gRPC: доступ запрещен - IP не в доверенной подсети",
 				zap.String("client_ip", clientIP),
 				zap.String("trusted_subnet", trustedSubnet))
 			return nil, status.Error(codes.PermissionDenied, "доступ запрещен")
@@ -175,14 +397,26 @@ func isIPInSubnet(ipStr, cidr string) bool {
 // RecoveryInterceptor перехватчик для восстановления после паники.
 func RecoveryInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				logger.Logger.Error("gRPC: паника в обработчике",
-					zap.String("method", info.FullMethod),
-					zap.Any("panic", r))
-				err = status.Error(codes.Internal, fmt.Sprintf("внутренняя ошибка сервера: %v", r))
-			}
-		}()
+		defer recoverPanic(ctx, info.FullMethod, &err)
 		return handler(ctx, req)
 	}
 }
+
+// StreamRecoveryInterceptor - аналог RecoveryInterceptor для потоковых вызовов.
+func StreamRecoveryInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverPanic(ss.Context(), info.FullMethod, &err)
+		return handler(srv, ss)
+	}
+}
+
+// recoverPanic перехватывает панику обработчика method и заменяет
+// результат вызова ошибкой Internal. Вызывается через defer.
+func recoverPanic(ctx context.Context, method string, err *error) {
+	if r := recover(); r != nil {
+		logger.Ctx(ctx).Error("gRPC: паника в обработчике",
+			zap.String("method", method),
+			zap.Any("panic", r))
+		*err = status.Error(codes.Internal, fmt.Sprintf("внутренняя ошибка сервера: %v", r))
+	}
+}
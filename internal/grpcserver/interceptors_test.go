@@ -0,0 +1,304 @@
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/ratelimit"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/tracing"
+	pb "github.com/Adigezalov/shortener/pkg/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestTracingInterceptor(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger.Logger = zap.New(core)
+
+	var spansOut bytes.Buffer
+	tracing.SetExporter(tracing.NewStdoutExporter(&spansOut))
+	t.Cleanup(func() { tracing.SetExporter(nil) })
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		RecoveryInterceptor(),
+		TracingInterceptor(),
+		LoggingInterceptor(),
+		AuthInterceptor(svc, auth.ModeStrict),
+	))
+	pb.RegisterShortenerServiceServer(server, NewServer(svc))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		tracing.TraceparentHeader, "00-"+traceID+"-"+parentSpanID+"-01")
+	_, err = pb.NewShortenerServiceClient(conn).GetOriginalURL(ctx, &pb.GetOriginalURLRequest{Id: "missing1"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// Логи вызова содержат идентификатор входящей трассировки
+	entries := logs.FilterMessage("gRPC запрос завершен с ошибкой").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, traceID, entries[0].ContextMap()["trace_id"])
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	tracing.ForceFlush(flushCtx)
+
+	type exportedSpan struct {
+		Name         string         `json:"name"`
+		Kind         string         `json:"kind"`
+		TraceID      string         `json:"trace_id"`
+		SpanID       string         `json:"span_id"`
+		ParentSpanID string         `json:"parent_span_id"`
+		Attributes   map[string]any `json:"attributes"`
+		Error        string         `json:"error"`
+	}
+	spans := make(map[string]exportedSpan)
+	for _, line := range strings.Split(strings.TrimSpace(spansOut.String()), "\n") {
+		var span exportedSpan
+		require.NoError(t, json.Unmarshal([]byte(line), &span))
+		assert.Equal(t, traceID, span.TraceID)
+		spans[span.Name] = span
+	}
+
+	rpc, ok := spans["/shortener.ShortenerService/GetOriginalURL"]
+	require.True(t, ok, "нет серверного span'а: %v", spans)
+	assert.Equal(t, "server", rpc.Kind)
+	assert.Equal(t, parentSpanID, rpc.ParentSpanID)
+	assert.Equal(t, "NotFound", rpc.Attributes["rpc.grpc.status_code"])
+	assert.Empty(t, rpc.Error, "ошибка клиента не считается сбоем сервера")
+
+	svcSpan, ok := spans["ShortenerService.GetOriginalURL"]
+	require.True(t, ok, "нет span'а сервиса: %v", spans)
+	assert.Equal(t, rpc.SpanID, svcSpan.ParentSpanID)
+}
+
+func TestAuthInterceptor_APIKey(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil).WithAccounts(store)
+
+	require.NoError(t, store.CreateAccount(context.Background(), storage.Account{ID: "acc1", Login: "alice"}))
+	key, err := svc.CreateAPIKey(context.Background(), "acc1", "ci")
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthInterceptor(svc, auth.ModeStrict)))
+	pb.RegisterShortenerServiceServer(server, NewServer(svc))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewShortenerServiceClient(conn)
+
+	// Ссылка, созданная с API-ключом, принадлежит учетной записи
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key.Token)
+	_, err = client.ShortenURL(ctx, &pb.ShortenURLRequest{Url: "https://example.com/key"})
+	require.NoError(t, err)
+
+	urls, err := store.GetUserURLs(context.Background(), "acc1")
+	require.NoError(t, err)
+	assert.Len(t, urls, 1)
+
+	// Отозванный ключ отклоняется
+	require.NoError(t, svc.RevokeAPIKey(context.Background(), "acc1", key.Key.ID))
+	_, err = client.GetUserURLs(ctx, &pb.GetUserURLsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer sk_forged")
+	_, err = client.GetUserURLs(ctx, &pb.GetUserURLsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthInterceptor_Modes(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+
+	result := svc.CreateShortURL(context.Background(), "https://example.com/victim", "victim")
+	require.NoError(t, result.Error)
+
+	dial := func(mode auth.Mode) pb.ShortenerServiceClient {
+		listener := bufconn.Listen(1 << 20)
+		server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthInterceptor(svc, mode)))
+		pb.RegisterShortenerServiceServer(server, NewServer(svc))
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return pb.NewShortenerServiceClient(conn)
+	}
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	strict := dial(auth.ModeStrict)
+
+	// Подписанный ID владельца дает доступ к его ссылкам
+	resp, err := strict.GetUserURLs(withToken(auth.SignUserID("victim")), &pb.GetUserURLsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.Urls, 1)
+
+	// Поддельная подпись и отсутствие токена отклоняются
+	_, err = strict.GetUserURLs(withToken("victim.forged"), &pb.GetUserURLsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = strict.ShortenURL(withToken("victim.forged"), &pb.ShortenURLRequest{Url: "https://example.com/forged"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = strict.GetUserURLs(context.Background(), &pb.GetUserURLsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Анонимному клиенту без токена выдается новый ID
+	var header metadata.MD
+	_, err = strict.ShortenURL(context.Background(), &pb.ShortenURLRequest{Url: "https://example.com/anon"}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.NotEmpty(t, header.Get("authorization"))
+
+	// В режиме legacy вместо поддельного токена выдается новый ID,
+	// но ссылки владельца по-прежнему недоступны
+	legacy := dial(auth.ModeLegacy)
+	header = nil
+	resp, err = legacy.GetUserURLs(withToken("victim.forged"), &pb.GetUserURLsRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Empty(t, resp.Urls)
+	assert.NotEmpty(t, header.Get("authorization"))
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	previousCost := auth.PasswordHashCost
+	auth.PasswordHashCost = bcrypt.MinCost
+	t.Cleanup(func() { auth.PasswordHashCost = previousCost })
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil).WithAccounts(store)
+	account, err := svc.Register(context.Background(), "alice", "correct horse", "")
+	require.NoError(t, err)
+
+	rules, err := ratelimit.ParseRules(pb.ShortenerService_ShortenURL_FullMethodName + "=1/h")
+	require.NoError(t, err)
+	limiter := ratelimit.New(rules, ratelimit.NewMemoryStore())
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		AuthInterceptor(svc, auth.ModeStrict),
+		RateLimitInterceptor(limiter),
+	))
+	pb.RegisterShortenerServiceServer(server, NewServer(svc))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewShortenerServiceClient(conn)
+
+	// Первый вызов разрешен; в заголовках - состояние ограничения и новый ID
+	var header metadata.MD
+	_, err = client.ShortenURL(context.Background(), &pb.ShortenURLRequest{Url: "https://example.com/1"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, header.Get(ratelimit.HeaderLimit))
+	assert.Equal(t, []string{"0"}, header.Get(ratelimit.HeaderRemaining))
+	require.NotEmpty(t, header.Get("authorization"))
+
+	// Повторный анонимный вызов с того же адреса отклоняется
+	header = nil
+	_, err = client.ShortenURL(context.Background(), &pb.ShortenURLRequest{Url: "https://example.com/2"}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"3600"}, header.Get(ratelimit.HeaderRetryAfter))
+
+	// Подписанный анонимный ID не дает новую корзину: его можно получить на каждый вызов
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+auth.SignUserID("user1"))
+	_, err = client.ShortenURL(ctx, &pb.ShortenURLRequest{Url: "https://example.com/3"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Учетная запись ограничивается отдельно
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+account.Token)
+	_, err = client.ShortenURL(ctx, &pb.ShortenURLRequest{Url: "https://example.com/4"})
+	require.NoError(t, err)
+
+	// Метод без правила не ограничивается
+	_, err = client.GetOriginalURL(context.Background(), &pb.GetOriginalURLRequest{Id: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	info := &grpc.StreamServerInfo{FullMethod: pb.ShortenerService_ShortenStream_FullMethodName}
+	ss := &serverStream{ctx: context.Background()}
+
+	// Паника обработчика потока превращается в ошибку Internal
+	err := StreamRecoveryInterceptor()(nil, ss, info, func(interface{}, grpc.ServerStream) error {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// Открытие потока учитывается как один вызов
+	rules, err := ratelimit.ParseRules(info.FullMethod + "=1/h")
+	require.NoError(t, err)
+	limit := StreamRateLimitInterceptor(ratelimit.New(rules, ratelimit.NewMemoryStore()))
+	calls := 0
+	handler := func(interface{}, grpc.ServerStream) error {
+		calls++
+		return nil
+	}
+	require.NoError(t, limit(nil, ss, info, handler))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(limit(nil, ss, info, handler)))
+	assert.Equal(t, 1, calls)
+}
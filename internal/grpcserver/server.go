@@ -3,15 +3,25 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"time"
 
+	"github.com/Adigezalov/shortener/internal/analytics"
 	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/metrics"
+	"github.com/Adigezalov/shortener/internal/models"
 	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
 	pb "github.com/Adigezalov/shortener/pkg/proto"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Server реализует gRPC сервер для ShortenerService.
@@ -57,29 +67,102 @@ func getClientIPFromContext(ctx context.Context) string {
 	return ""
 }
 
+// timestampToTime преобразует необязательный protobuf Timestamp в *time.Time.
+func timestampToTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// expiredStatus формирует ошибку FailedPrecondition с деталями ErrorInfo
+// для ссылки с истекшим сроком действия (аналог HTTP 410 Gone).
+func expiredStatus(id string) error {
+	return goneStatus(id, "LINK_EXPIRED", "срок действия URL истек")
+}
+
+// disabledStatus формирует ошибку FailedPrecondition с деталями ErrorInfo
+// для ссылки, отключенной модератором (аналог HTTP 410 Gone).
+func disabledStatus(id string) error {
+	return goneStatus(id, "LINK_DISABLED", "URL отключен")
+}
+
+// goneStatus формирует ошибку FailedPrecondition для недоступной ссылки
+func goneStatus(id string, reason string, message string) error {
+	st := status.New(codes.FailedPrecondition, message)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   "shortener",
+		Metadata: map[string]string{"id": id},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// policyStatus формирует ошибку InvalidArgument с деталями ErrorInfo
+// для URL, нарушающего политику. Reason содержит код нарушения в верхнем
+// регистре (PRIVATE_ADDRESS, SCHEME_NOT_ALLOWED и т.д.).
+func policyStatus(policyErr *urlpolicy.Error) error {
+	st := status.New(codes.InvalidArgument, policyErr.Message)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: strings.ToUpper(string(policyErr.Code)),
+		Domain: "shortener",
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// storageStatus преобразует ошибку хранилища в gRPC статус.
+//
+// Отмена и истечение контекста передаются клиенту как есть, остальные
+// ошибки означают недоступность хранилища и возвращаются с кодом
+// Unavailable, чтобы клиент мог повторить запрос.
+func storageStatus(err error, msg string) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "запрос отменен")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "истек срок выполнения запроса")
+	case errors.Is(err, service.ErrIDCollision):
+		// Хранилище доступно, но свободный ID не найден за отведенные попытки
+		return status.Error(codes.Internal, err.Error())
+	default:
+		return status.Error(codes.Unavailable, msg)
+	}
+}
+
 // CreateShortURL создает короткий URL из текста.
 func (s *Server) CreateShortURL(ctx context.Context, req *pb.CreateShortURLRequest) (*pb.CreateShortURLResponse, error) {
-	logger.Logger.Info("gRPC: CreateShortURL вызван",
+	logger.Ctx(ctx).Info("gRPC: CreateShortURL вызван",
 		zap.String("url", req.Url))
 
 	// Получаем user ID из контекста
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
-		logger.Logger.Error("gRPC: ошибка получения user ID", zap.Error(err))
+		logger.Ctx(ctx).Error("gRPC: ошибка получения user ID", zap.Error(err))
 		return nil, err
 	}
 
 	// Вызываем бизнес-логику
-	result := s.service.CreateShortURL(req.Url, userID)
+	result := s.service.CreateShortURL(ctx, req.Url, userID)
 	if result.Error != nil {
 		if result.Error == service.ErrEmptyURL {
 			return nil, status.Error(codes.InvalidArgument, "URL не может быть пустым")
 		}
-		logger.Logger.Error("gRPC: ошибка создания короткого URL", zap.Error(result.Error))
-		return nil, status.Error(codes.Internal, "ошибка сохранения URL")
+		var policyErr *urlpolicy.Error
+		if errors.As(result.Error, &policyErr) {
+			return nil, policyStatus(policyErr)
+		}
+		logger.Ctx(ctx).Error("gRPC: ошибка создания короткого URL", zap.Error(result.Error))
+		return nil, storageStatus(result.Error, "ошибка сохранения URL")
 	}
 
-	logger.Logger.Info("gRPC: короткий URL создан",
+	logger.Ctx(ctx).Info("gRPC: короткий URL создан",
 		zap.String("short_url", result.ShortURL),
 		zap.Bool("exists", result.Exists))
 
@@ -91,27 +174,44 @@ func (s *Server) CreateShortURL(ctx context.Context, req *pb.CreateShortURLReque
 
 // ShortenURL сокращает URL (JSON API аналог).
 func (s *Server) ShortenURL(ctx context.Context, req *pb.ShortenURLRequest) (*pb.ShortenURLResponse, error) {
-	logger.Logger.Info("gRPC: ShortenURL вызван",
+	logger.Ctx(ctx).Info("gRPC: ShortenURL вызван",
 		zap.String("url", req.Url))
 
 	// Получаем user ID из контекста
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
-		logger.Logger.Error("gRPC: ошибка получения user ID", zap.Error(err))
+		logger.Ctx(ctx).Error("gRPC: ошибка получения user ID", zap.Error(err))
 		return nil, err
 	}
 
 	// Вызываем бизнес-логику
-	result := s.service.CreateShortURL(req.Url, userID)
+	result := s.service.CreateShortURLWithOptions(ctx, req.Url, userID, service.ShortenOptions{
+		Alias:      req.Alias,
+		ExpiresAt:  timestampToTime(req.ExpiresAt),
+		TTLSeconds: req.TtlSeconds,
+	})
 	if result.Error != nil {
 		if result.Error == service.ErrEmptyURL {
 			return nil, status.Error(codes.InvalidArgument, "URL не может быть пустым")
 		}
-		logger.Logger.Error("gRPC: ошибка сокращения URL", zap.Error(result.Error))
-		return nil, status.Error(codes.Internal, "ошибка сохранения URL")
+		var policyErr *urlpolicy.Error
+		if errors.As(result.Error, &policyErr) {
+			return nil, policyStatus(policyErr)
+		}
+		if errors.Is(result.Error, shortener.ErrInvalidAlias) || errors.Is(result.Error, service.ErrInvalidExpiration) {
+			return nil, status.Error(codes.InvalidArgument, result.Error.Error())
+		}
+		if errors.Is(result.Error, service.ErrAliasTaken) {
+			return nil, status.Error(codes.AlreadyExists, result.Error.Error())
+		}
+		if errors.Is(result.Error, service.ErrAliasURLExists) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s: %s", result.Error, result.ShortURL)
+		}
+		logger.Ctx(ctx).Error("gRPC: ошибка сокращения URL", zap.Error(result.Error))
+		return nil, storageStatus(result.Error, "ошибка сохранения URL")
 	}
 
-	logger.Logger.Info("gRPC: URL сокращен",
+	logger.Ctx(ctx).Info("gRPC: URL сокращен",
 		zap.String("result", result.ShortURL),
 		zap.Bool("conflict", result.Exists))
 
@@ -122,8 +222,9 @@ func (s *Server) ShortenURL(ctx context.Context, req *pb.ShortenURLRequest) (*pb
 }
 
 // ShortenBatch выполняет пакетное сокращение URL.
+// Результат каждого элемента (created, existing или error) возвращается в поле status.
 func (s *Server) ShortenBatch(ctx context.Context, req *pb.ShortenBatchRequest) (*pb.ShortenBatchResponse, error) {
-	logger.Logger.Info("gRPC: ShortenBatch вызван",
+	logger.Ctx(ctx).Info("gRPC: ShortenBatch вызван",
 		zap.Int("items_count", len(req.Items)))
 
 	if len(req.Items) == 0 {
@@ -133,32 +234,27 @@ func (s *Server) ShortenBatch(ctx context.Context, req *pb.ShortenBatchRequest)
 	// Получаем user ID из контекста
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
-		logger.Logger.Error("gRPC: ошибка получения user ID", zap.Error(err))
+		logger.Ctx(ctx).Error("gRPC: ошибка получения user ID", zap.Error(err))
 		return nil, err
 	}
 
-	// Преобразуем proto запрос в service запрос
-	items := make([]service.BatchItem, 0, len(req.Items))
-	for _, item := range req.Items {
-		items = append(items, service.BatchItem{
-			CorrelationID: item.CorrelationId,
-			OriginalURL:   item.OriginalUrl,
-		})
-	}
-
 	// Вызываем бизнес-логику
-	results := s.service.CreateShortURLBatch(items, userID)
+	results, err := s.service.CreateShortURLBatch(ctx, batchItems(req.Items), userID)
+	if err != nil {
+		if isBatchItemError(err) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		logger.Ctx(ctx).Error("gRPC: ошибка пакетного сокращения URL", zap.Error(err))
+		return nil, storageStatus(err, "ошибка сохранения URL")
+	}
 
 	// Преобразуем результаты в proto ответ
 	pbResults := make([]*pb.BatchShortenResultItem, 0, len(results))
 	for _, result := range results {
-		pbResults = append(pbResults, &pb.BatchShortenResultItem{
-			CorrelationId: result.CorrelationID,
-			ShortUrl:      result.ShortURL,
-		})
+		pbResults = append(pbResults, batchResultToProto(result))
 	}
 
-	logger.Logger.Info("gRPC: пакет URL сокращен",
+	logger.Ctx(ctx).Info("gRPC: пакет URL сокращен",
 		zap.Int("results_count", len(pbResults)))
 
 	return &pb.ShortenBatchResponse{
@@ -166,9 +262,48 @@ func (s *Server) ShortenBatch(ctx context.Context, req *pb.ShortenBatchRequest)
 	}, nil
 }
 
+// batchItems преобразует элементы пакетного запроса в элементы сервиса.
+func batchItems(items []*pb.BatchShortenItem) []service.BatchItem {
+	result := make([]service.BatchItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, service.BatchItem{
+			CorrelationID: item.CorrelationId,
+			OriginalURL:   item.OriginalUrl,
+			Alias:         item.Alias,
+			ExpiresAt:     timestampToTime(item.ExpiresAt),
+			TTLSeconds:    item.TtlSeconds,
+		})
+	}
+	return result
+}
+
+// isBatchItemError сообщает, что пакет отклонен из-за некорректного элемента
+// (псевдонима или срока действия), а не из-за сбоя хранилища.
+func isBatchItemError(err error) bool {
+	return errors.Is(err, shortener.ErrInvalidAlias) || errors.Is(err, service.ErrDuplicateAlias) ||
+		errors.Is(err, service.ErrInvalidExpiration)
+}
+
+// batchResultToProto преобразует результат элемента пакета в proto сообщение.
+func batchResultToProto(result service.BatchResult) *pb.BatchShortenResultItem {
+	item := &pb.BatchShortenResultItem{
+		CorrelationId: result.CorrelationID,
+		ShortUrl:      result.ShortURL,
+		Status:        string(result.Status),
+	}
+	if result.Error != nil {
+		item.Error = result.Error.Error()
+	}
+	var policyErr *urlpolicy.Error
+	if errors.As(result.Error, &policyErr) {
+		item.ErrorCode = string(policyErr.Code)
+	}
+	return item
+}
+
 // GetOriginalURL получает оригинальный URL по короткому ID.
 func (s *Server) GetOriginalURL(ctx context.Context, req *pb.GetOriginalURLRequest) (*pb.GetOriginalURLResponse, error) {
-	logger.Logger.Info("gRPC: GetOriginalURL вызван",
+	logger.Ctx(ctx).Info("gRPC: GetOriginalURL вызван",
 		zap.String("id", req.Id))
 
 	// Извлекаем ID из полного URL, если передан полный URL
@@ -179,18 +314,28 @@ func (s *Server) GetOriginalURL(ctx context.Context, req *pb.GetOriginalURLReque
 	}
 
 	// Вызываем бизнес-логику
-	result := s.service.GetOriginalURL(id)
+	result := s.service.GetOriginalURL(ctx, id)
 	if result.Error != nil {
-		logger.Logger.Error("gRPC: ошибка получения оригинального URL", zap.Error(result.Error))
-		return nil, status.Error(codes.Internal, "ошибка получения URL")
+		logger.Ctx(ctx).Error("gRPC: ошибка получения оригинального URL", zap.Error(result.Error))
+		return nil, storageStatus(result.Error, "ошибка получения URL")
 	}
 
 	if !result.Found {
-		logger.Logger.Warn("gRPC: URL не найден", zap.String("id", id))
+		logger.Ctx(ctx).Warn("gRPC: URL не найден", zap.String("id", id))
 		return nil, status.Error(codes.NotFound, "URL не найден")
 	}
 
-	logger.Logger.Info("gRPC: оригинальный URL получен",
+	if result.Expired {
+		logger.Ctx(ctx).Info("gRPC: срок действия URL истек", zap.String("id", id))
+		return nil, expiredStatus(id)
+	}
+
+	if result.Disabled {
+		logger.Ctx(ctx).Info("gRPC: URL отключен", zap.String("id", id))
+		return nil, disabledStatus(id)
+	}
+
+	logger.Ctx(ctx).Info("gRPC: оригинальный URL получен",
 		zap.String("original_url", result.OriginalURL),
 		zap.Bool("deleted", result.Deleted))
 
@@ -200,45 +345,81 @@ func (s *Server) GetOriginalURL(ctx context.Context, req *pb.GetOriginalURLReque
 	}, nil
 }
 
-// GetUserURLs получает все URL пользователя.
+// GetUserURLs получает страницу URL пользователя.
 func (s *Server) GetUserURLs(ctx context.Context, req *pb.GetUserURLsRequest) (*pb.GetUserURLsResponse, error) {
-	logger.Logger.Info("gRPC: GetUserURLs вызван")
+	logger.Ctx(ctx).Info("gRPC: GetUserURLs вызван",
+		zap.Int32("limit", req.Limit))
 
 	// Получаем user ID из контекста
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
-		logger.Logger.Error("gRPC: ошибка получения user ID", zap.Error(err))
+		logger.Ctx(ctx).Error("gRPC: ошибка получения user ID", zap.Error(err))
 		return nil, err
 	}
 
+	query := userURLsQuery(req.Query, req.Host, req.Order, req.CreatedAfter, req.CreatedBefore)
+	query.Cursor = req.Cursor
+	query.Limit = int(req.Limit)
+
 	// Вызываем бизнес-логику
-	result := s.service.GetUserURLs(userID)
+	result := s.service.GetUserURLs(ctx, userID, query)
+	if errors.Is(result.Error, service.ErrInvalidFilter) {
+		return nil, status.Error(codes.InvalidArgument, result.Error.Error())
+	}
 	if result.Error != nil {
-		logger.Logger.Error("gRPC: ошибка получения URL пользователя", zap.Error(result.Error))
-		return nil, status.Error(codes.Internal, "ошибка получения URL пользователя")
+		logger.Ctx(ctx).Error("gRPC: ошибка получения URL пользователя", zap.Error(result.Error))
+		return nil, storageStatus(result.Error, "ошибка получения URL пользователя")
 	}
 
 	// Преобразуем результат в proto ответ
 	pbURLs := make([]*pb.UserURLItem, 0, len(result.URLs))
 	for _, url := range result.URLs {
-		pbURLs = append(pbURLs, &pb.UserURLItem{
-			ShortUrl:    url.ShortURL,
-			OriginalUrl: url.OriginalURL,
-		})
+		pbURLs = append(pbURLs, userURLToProto(url))
 	}
 
-	logger.Logger.Info("gRPC: URL пользователя получены",
+	logger.Ctx(ctx).Info("gRPC: URL пользователя получены",
 		zap.String("user_id", userID),
-		zap.Int("count", len(pbURLs)))
+		zap.Int("count", len(pbURLs)),
+		zap.Int("total", result.Total))
 
 	return &pb.GetUserURLsResponse{
-		Urls: pbURLs,
+		Urls:       pbURLs,
+		Total:      int32(result.Total),
+		NextCursor: result.NextCursor,
 	}, nil
 }
 
+// userURLsQuery формирует фильтр списка URL пользователя из полей запроса.
+func userURLsQuery(contains, host, order string, createdAfter, createdBefore *timestamppb.Timestamp) service.UserURLsQuery {
+	query := service.UserURLsQuery{
+		URLContains: contains,
+		Host:        host,
+		Order:       storage.SortOrder(order),
+	}
+	if createdAfter != nil {
+		query.CreatedFrom = createdAfter.AsTime()
+	}
+	if createdBefore != nil {
+		query.CreatedTo = createdBefore.AsTime()
+	}
+	return query
+}
+
+// userURLToProto преобразует URL пользователя в proto сообщение.
+func userURLToProto(url models.UserURL) *pb.UserURLItem {
+	item := &pb.UserURLItem{
+		ShortUrl:    url.ShortURL,
+		OriginalUrl: url.OriginalURL,
+	}
+	if url.CreatedAt != nil {
+		item.CreatedAt = timestamppb.New(*url.CreatedAt)
+	}
+	return item
+}
+
 // DeleteUserURLs удаляет URL пользователя.
 func (s *Server) DeleteUserURLs(ctx context.Context, req *pb.DeleteUserURLsRequest) (*pb.DeleteUserURLsResponse, error) {
-	logger.Logger.Info("gRPC: DeleteUserURLs вызван",
+	logger.Ctx(ctx).Info("gRPC: DeleteUserURLs вызван",
 		zap.Int("urls_count", len(req.ShortUrls)))
 
 	if len(req.ShortUrls) == 0 {
@@ -248,18 +429,22 @@ func (s *Server) DeleteUserURLs(ctx context.Context, req *pb.DeleteUserURLsReque
 	// Получаем user ID из контекста
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
-		logger.Logger.Error("gRPC: ошибка получения user ID", zap.Error(err))
+		logger.Ctx(ctx).Error("gRPC: ошибка получения user ID", zap.Error(err))
 		return nil, err
 	}
 
-	// Асинхронно удаляем URL
+	// Асинхронно удаляем URL: удаление не должно прерываться
+	// вместе с завершившимся вызовом
+	deleteCtx := context.WithoutCancel(ctx)
+	metrics.AsyncDeletes.WithLabelValues("grpc").Inc()
 	go func() {
-		if err := s.service.DeleteUserURLs(userID, req.ShortUrls); err != nil {
-			logger.Logger.Error("gRPC: ошибка удаления URL",
+		defer metrics.AsyncDeletes.WithLabelValues("grpc").Dec()
+		if err := s.service.DeleteUserURLs(deleteCtx, userID, req.ShortUrls); err != nil {
+			logger.Ctx(deleteCtx).Error("gRPC: ошибка удаления URL",
 				zap.String("user_id", userID),
 				zap.Error(err))
 		} else {
-			logger.Logger.Info("gRPC: URL успешно удалены",
+			logger.Ctx(deleteCtx).Info("gRPC: URL успешно удалены",
 				zap.String("user_id", userID),
 				zap.Int("count", len(req.ShortUrls)))
 		}
@@ -272,41 +457,129 @@ func (s *Server) DeleteUserURLs(ctx context.Context, req *pb.DeleteUserURLsReque
 
 // Ping проверяет состояние базы данных.
 func (s *Server) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
-	logger.Logger.Info("gRPC: Ping вызван")
+	logger.Ctx(ctx).Info("gRPC: Ping вызван")
 
 	err := s.service.PingDB()
 	if err != nil {
 		if err == service.ErrDBNotConfigured {
 			return nil, status.Error(codes.Unimplemented, "база данных не настроена")
 		}
-		logger.Logger.Error("gRPC: ошибка проверки БД", zap.Error(err))
+		logger.Ctx(ctx).Error("gRPC: ошибка проверки БД", zap.Error(err))
 		return &pb.PingResponse{Ok: false}, nil
 	}
 
-	logger.Logger.Info("gRPC: БД доступна")
+	logger.Ctx(ctx).Info("gRPC: БД доступна")
 	return &pb.PingResponse{Ok: true}, nil
 }
 
 // GetStats получает статистику сервиса.
 func (s *Server) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
-	logger.Logger.Info("gRPC: GetStats вызван")
+	logger.Ctx(ctx).Info("gRPC: GetStats вызван")
 
 	// Проверяем IP адрес клиента (если настроена доверенная подсеть)
 	// Это будет реализовано в middleware
 
 	// Вызываем бизнес-логику
-	result := s.service.GetStats()
+	result := s.service.GetStats(ctx)
 	if result.Error != nil {
-		logger.Logger.Error("gRPC: ошибка получения статистики", zap.Error(result.Error))
-		return nil, status.Error(codes.Internal, "ошибка получения статистики")
+		logger.Ctx(ctx).Error("gRPC: ошибка получения статистики", zap.Error(result.Error))
+		return nil, storageStatus(result.Error, "ошибка получения статистики")
 	}
 
-	logger.Logger.Info("gRPC: статистика получена",
+	logger.Ctx(ctx).Info("gRPC: статистика получена",
 		zap.Int("urls", result.URLs),
 		zap.Int("users", result.Users))
 
-	return &pb.GetStatsResponse{
+	resp := &pb.GetStatsResponse{
 		Urls:  int32(result.URLs),
 		Users: int32(result.Users),
-	}, nil
+	}
+	if result.Cache != nil {
+		resp.CacheHits = result.Cache.Hits
+		resp.CacheMisses = result.Cache.Misses
+	}
+	return resp, nil
+}
+
+// GetURLStats получает статистику переходов по URL пользователя.
+func (s *Server) GetURLStats(ctx context.Context, req *pb.GetURLStatsRequest) (*pb.GetURLStatsResponse, error) {
+	logger.Ctx(ctx).Info("gRPC: GetURLStats вызван",
+		zap.String("id", req.Id))
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID не может быть пустым")
+	}
+
+	if req.Hours < 0 || req.Hours > maxStatsHours {
+		return nil, status.Error(codes.InvalidArgument, "некорректное значение hours")
+	}
+
+	// Получаем user ID из контекста
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error("gRPC: ошибка получения user ID", zap.Error(err))
+		return nil, err
+	}
+
+	window := analytics.DefaultStatsWindow
+	if req.Hours > 0 {
+		window = time.Duration(req.Hours) * time.Hour
+	}
+
+	// Вызываем бизнес-логику
+	result := s.service.GetURLStats(userID, req.Id, time.Now().Add(-window))
+	if errors.Is(result.Error, service.ErrStatsNotConfigured) {
+		return nil, status.Error(codes.Unimplemented, "статистика переходов не настроена")
+	}
+	if result.Error != nil {
+		logger.Ctx(ctx).Error("gRPC: ошибка получения статистики переходов", zap.Error(result.Error))
+		return nil, storageStatus(result.Error, "ошибка получения статистики переходов")
+	}
+	if !result.Found {
+		return nil, status.Error(codes.NotFound, "URL не найден")
+	}
+
+	return urlStatsToProto(result.Stats), nil
+}
+
+// maxStatsHours ограничивает глубину почасовой истории в запросе статистики
+const maxStatsHours = 24 * 90
+
+// urlStatsToProto преобразует статистику переходов в proto ответ.
+func urlStatsToProto(stats models.URLStats) *pb.GetURLStatsResponse {
+	resp := &pb.GetURLStatsResponse{
+		ShortUrl:   stats.ShortURL,
+		Clicks:     stats.Clicks,
+		Referrers:  countersToProto(stats.Referrers),
+		UserAgents: countersToProto(stats.UserAgents),
+		Hourly:     make([]*pb.HourlyClicks, 0, len(stats.Hourly)),
+	}
+
+	if stats.FirstClickAt != nil {
+		resp.FirstClickAt = timestamppb.New(*stats.FirstClickAt)
+	}
+	if stats.LastClickAt != nil {
+		resp.LastClickAt = timestamppb.New(*stats.LastClickAt)
+	}
+
+	for _, item := range stats.Hourly {
+		resp.Hourly = append(resp.Hourly, &pb.HourlyClicks{
+			Hour:   timestamppb.New(item.Hour),
+			Clicks: item.Clicks,
+		})
+	}
+
+	return resp
+}
+
+// countersToProto преобразует счетчики статистики в proto сообщения.
+func countersToProto(counters []models.StatsCounter) []*pb.StatsCounter {
+	result := make([]*pb.StatsCounter, 0, len(counters))
+	for _, counter := range counters {
+		result = append(result, &pb.StatsCounter{
+			Value:  counter.Value,
+			Clicks: counter.Clicks,
+		})
+	}
+	return result
 }
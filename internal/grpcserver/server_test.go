@@ -0,0 +1,163 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	pb "github.com/Adigezalov/shortener/pkg/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer_URLPolicy(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	s := NewServer(service.NewShortenerService(store, shortener.New("http://short.test"), nil))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-id", "alice"))
+
+	// errorReason возвращает reason из деталей ErrorInfo
+	errorReason := func(err error) string {
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok {
+				assert.Equal(t, "shortener", info.Domain)
+				return info.Reason
+			}
+		}
+		return ""
+	}
+
+	_, err := s.CreateShortURL(ctx, &pb.CreateShortURLRequest{Url: "file:///etc/passwd"})
+	assert.Equal(t, "SCHEME_NOT_ALLOWED", errorReason(err))
+
+	_, err = s.ShortenURL(ctx, &pb.ShortenURLRequest{Url: "http://10.0.0.1/admin"})
+	assert.Equal(t, "PRIVATE_ADDRESS", errorReason(err))
+
+	resp, err := s.ShortenBatch(ctx, &pb.ShortenBatchRequest{Items: []*pb.BatchShortenItem{
+		{CorrelationId: "1", OriginalUrl: "https://example.com/ok"},
+		{CorrelationId: "2", OriginalUrl: "http://[::1]/"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 2)
+	assert.Equal(t, string(storage.RecordCreated), resp.Items[0].Status)
+	assert.Empty(t, resp.Items[0].ErrorCode)
+	assert.Equal(t, string(storage.RecordFailed), resp.Items[1].Status)
+	assert.Equal(t, "private_address", resp.Items[1].ErrorCode)
+}
+
+func TestServer_Streams(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://short.test"), nil)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainStreamInterceptor(
+		StreamRecoveryInterceptor(),
+		StreamTracingInterceptor(),
+		StreamLoggingInterceptor(),
+		StreamAuthInterceptor(svc, auth.ModeStrict),
+	))
+	pb.RegisterShortenerServiceServer(server, NewServer(svc))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewShortenerServiceClient(conn)
+
+	// Без токена выгрузка недоступна, а анонимному клиенту потокового
+	// сокращения выдается новый ID
+	export, err := client.ExportUserURLs(context.Background(), &pb.ExportUserURLsRequest{})
+	require.NoError(t, err)
+	_, err = export.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+auth.SignUserID("alice"))
+
+	// Поток больше части и страницы выгрузки; некорректные элементы
+	// получают ошибку, не прерывая поток
+	const count = service.ExportPageSize + streamChunkSize + 1
+	stream, err := client.ShortenStream(ctx)
+	require.NoError(t, err)
+	go func() {
+		for i := 0; i < count; i++ {
+			item := &pb.BatchShortenItem{CorrelationId: fmt.Sprint(i), OriginalUrl: fmt.Sprintf("https://example.com/%d", i)}
+			switch i {
+			case 1:
+				item.Alias = "bad alias!"
+			case 2:
+				item.OriginalUrl = "http://10.0.0.1/"
+			}
+			if err := stream.Send(item); err != nil {
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	var results []*pb.BatchShortenResultItem
+	for {
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+	require.Len(t, results, count)
+	for i, result := range results {
+		assert.Equal(t, fmt.Sprint(i), result.CorrelationId)
+	}
+	assert.Equal(t, string(storage.RecordCreated), results[0].Status)
+	assert.Equal(t, string(storage.RecordFailed), results[1].Status)
+	assert.NotEmpty(t, results[1].Error)
+	assert.Equal(t, "private_address", results[2].ErrorCode)
+
+	// Выгрузка возвращает все созданные URL без повторов
+	export, err = client.ExportUserURLs(ctx, &pb.ExportUserURLsRequest{Order: "asc"})
+	require.NoError(t, err)
+	seen := make(map[string]bool)
+	for {
+		item, err := export.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.False(t, seen[item.ShortUrl], "повтор %s", item.ShortUrl)
+		seen[item.ShortUrl] = true
+	}
+	assert.Len(t, seen, count-2)
+
+	export, err = client.ExportUserURLs(ctx, &pb.ExportUserURLsRequest{Order: "random"})
+	require.NoError(t, err)
+	_, err = export.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
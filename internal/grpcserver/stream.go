@@ -0,0 +1,159 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	pb "github.com/Adigezalov/shortener/pkg/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// streamChunkSize ограничивает количество элементов ShortenStream,
+// записываемых в хранилище одной операцией.
+const streamChunkSize = 100
+
+// ShortenStream выполняет потоковое сокращение URL.
+//
+// Элементы принимаются в отдельной горутине: пока очередная часть
+// записывается в хранилище, следующие элементы накапливаются и затем
+// записываются одной операцией (не более streamChunkSize элементов).
+// Результаты отправляются в порядке элементов сразу после записи части.
+// Сбой хранилища прерывает поток.
+func (s *Server) ShortenStream(stream pb.ShortenerService_ShortenStreamServer) error {
+	ctx := stream.Context()
+	logger.Ctx(ctx).Info("gRPC: ShortenStream вызван")
+
+	// Получаем user ID из контекста
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error("gRPC: ошибка получения user ID", zap.Error(err))
+		return err
+	}
+
+	items := make(chan *pb.BatchShortenItem, streamChunkSize)
+	recvErr := make(chan error, 1)
+	go func() {
+		defer close(items)
+		for {
+			item, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					recvErr <- err
+				}
+				return
+			}
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	count := 0
+	for item := range items {
+		chunk := append(make([]*pb.BatchShortenItem, 0, streamChunkSize), item)
+		chunk = drainItems(items, chunk)
+
+		results, err := s.shortenChunk(ctx, chunk, userID)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if err := stream.Send(result); err != nil {
+				return err
+			}
+		}
+		count += len(chunk)
+	}
+
+	select {
+	case err := <-recvErr:
+		return err
+	default:
+	}
+
+	logger.Ctx(ctx).Info("gRPC: поток URL сокращен",
+		zap.Int("items_count", count))
+	return nil
+}
+
+// drainItems добавляет в chunk уже полученные элементы без ожидания новых.
+func drainItems(items <-chan *pb.BatchShortenItem, chunk []*pb.BatchShortenItem) []*pb.BatchShortenItem {
+	for len(chunk) < streamChunkSize {
+		select {
+		case item, ok := <-items:
+			if !ok {
+				return chunk
+			}
+			chunk = append(chunk, item)
+		default:
+			return chunk
+		}
+	}
+	return chunk
+}
+
+// shortenChunk сокращает часть элементов потока. Некорректный элемент
+// получает ошибку в поле status, не отклоняя остальные элементы части.
+func (s *Server) shortenChunk(ctx context.Context, chunk []*pb.BatchShortenItem, userID string) ([]*pb.BatchShortenResultItem, error) {
+	results, err := s.service.CreateShortURLEach(ctx, batchItems(chunk), userID)
+	if err != nil {
+		logger.Ctx(ctx).Error("gRPC: ошибка потокового сокращения URL", zap.Error(err))
+		return nil, storageStatus(err, "ошибка сохранения URL")
+	}
+
+	pbResults := make([]*pb.BatchShortenResultItem, 0, len(results))
+	for _, result := range results {
+		pbResults = append(pbResults, batchResultToProto(result))
+	}
+	return pbResults, nil
+}
+
+// ExportUserURLs выгружает все URL пользователя потоком.
+// URL читаются из хранилища страницами и отправляются по одному,
+// поэтому размер выгрузки не ограничен размером сообщения gRPC.
+func (s *Server) ExportUserURLs(req *pb.ExportUserURLsRequest, stream pb.ShortenerService_ExportUserURLsServer) error {
+	ctx := stream.Context()
+	logger.Ctx(ctx).Info("gRPC: ExportUserURLs вызван")
+
+	// Получаем user ID из контекста
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error("gRPC: ошибка получения user ID", zap.Error(err))
+		return err
+	}
+
+	query := userURLsQuery(req.Query, req.Host, req.Order, req.CreatedAfter, req.CreatedBefore)
+
+	// Ошибка отправки означает, что клиент закрыл поток, и возвращается как есть
+	count := 0
+	var sendErr error
+	err = s.service.ExportUserURLs(ctx, userID, query, func(url models.UserURL) error {
+		if sendErr = stream.Send(userURLToProto(url)); sendErr != nil {
+			return sendErr
+		}
+		count++
+		return nil
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidFilter):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case sendErr != nil:
+		return sendErr
+	case err != nil:
+		logger.Ctx(ctx).Error("gRPC: ошибка выгрузки URL пользователя", zap.Error(err))
+		return storageStatus(err, "ошибка получения URL пользователя")
+	}
+
+	logger.Ctx(ctx).Info("gRPC: URL пользователя выгружены",
+		zap.String("user_id", userID),
+		zap.Int("count", count))
+	return nil
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// SearchLinks ищет ссылки для модерации.
+//
+// Эндпоинт: GET /api/admin/links
+// Параметры запроса (все необязательные):
+//   - short_id: короткий ID;
+//   - q: подстрока оригинального URL без учета регистра;
+//   - host: хост оригинального URL, включая поддомены;
+//   - user_id или login: владелец ссылки;
+//   - created_from, created_to: период создания (RFC 3339 или 2006-01-02);
+//   - status: active, disabled или deleted;
+//   - offset, limit: страница (по умолчанию 0 и 50, limit не более 500).
+//
+// Ответы:
+//   - 200 OK: JSON models.AdminLinksResponse
+//   - 400 Bad Request: некорректные параметры
+//   - 404 Not Found: учетная запись с логином login не найдена
+//   - 501 Not Implemented: хранилище не поддерживает модерацию
+func (h *Handler) SearchLinks(w http.ResponseWriter, r *http.Request) {
+	search, err := parseLinkSearch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.SearchLinks(r.Context(), search)
+	if err != nil {
+		h.writeAdminError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, models.AdminLinksResponse{
+		Links:  result.Links,
+		Total:  result.Total,
+		Offset: result.Offset,
+		Limit:  result.Limit,
+	})
+}
+
+// parseLinkSearch разбирает параметры поиска ссылок
+func parseLinkSearch(r *http.Request) (service.LinkSearch, error) {
+	query := r.URL.Query()
+
+	status, err := storage.ParseLinkStatus(query.Get("status"))
+	if err != nil {
+		return service.LinkSearch{}, err
+	}
+
+	search := service.LinkSearch{
+		LinkFilter: storage.LinkFilter{
+			ShortID:     query.Get("short_id"),
+			URLContains: query.Get("q"),
+			Host:        query.Get("host"),
+			UserID:      query.Get("user_id"),
+			Status:      status,
+		},
+		OwnerLogin: query.Get("login"),
+	}
+
+	if search.CreatedFrom, err = parseTimeParam(query.Get("created_from")); err != nil {
+		return service.LinkSearch{}, err
+	}
+	if search.CreatedTo, err = parseTimeParam(query.Get("created_to")); err != nil {
+		return service.LinkSearch{}, err
+	}
+	if search.Offset, err = parseIntParam(query.Get("offset")); err != nil {
+		return service.LinkSearch{}, err
+	}
+	if search.Limit, err = parseIntParam(query.Get("limit")); err != nil {
+		return service.LinkSearch{}, err
+	}
+	return search, nil
+}
+
+// parseTimeParam разбирает момент времени в формате RFC 3339 или дату
+// 2006-01-02 (начало суток UTC). Пустое значение - нулевое время.
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("некорректная дата %q", value)
+	}
+	return t, nil
+}
+
+// parseIntParam разбирает неотрицательное целое число. Пустое значение - 0.
+func parseIntParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("некорректное число %q", value)
+	}
+	return n, nil
+}
+
+// GetLink возвращает ссылку для модерации вместе с владельцем и состоянием.
+//
+// Эндпоинт: GET /api/admin/links/{id}
+//
+// Ответы:
+//   - 200 OK: JSON models.AdminLink
+//   - 404 Not Found: ссылка не найдена
+//   - 501 Not Implemented: хранилище не поддерживает модерацию
+func (h *Handler) GetLink(w http.ResponseWriter, r *http.Request) {
+	link, err := h.service.GetLink(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeAdminError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, link)
+}
+
+// DisableLinks отключает ссылки: переход по ним возвращает 410 Gone.
+//
+// Эндпоинт: POST /api/admin/links/disable
+// Тело запроса: JSON models.AdminLinksRequest с полем "ids"
+//
+// Ответы:
+//   - 200 OK: JSON models.AdminLinksResult
+//   - 400 Bad Request: некорректный JSON, пустой или слишком длинный список
+//   - 501 Not Implemented: хранилище не поддерживает модерацию
+func (h *Handler) DisableLinks(w http.ResponseWriter, r *http.Request) {
+	h.moderateLinks(w, r, "disable", func(request models.AdminLinksRequest) (int, error) {
+		return h.service.DisableLinks(r.Context(), request.IDs)
+	})
+}
+
+// EnableLinks включает ранее отключенные ссылки.
+//
+// Эндпоинт: POST /api/admin/links/enable
+// Тело запроса и ответы совпадают с DisableLinks.
+func (h *Handler) EnableLinks(w http.ResponseWriter, r *http.Request) {
+	h.moderateLinks(w, r, "enable", func(request models.AdminLinksRequest) (int, error) {
+		return h.service.EnableLinks(r.Context(), request.IDs)
+	})
+}
+
+// PurgeLinks безвозвратно удаляет ссылки вместе со статистикой переходов.
+// В отличие от DELETE /api/user/urls короткий ID удаленной ссылки
+// снова становится свободным.
+//
+// Эндпоинт: POST /api/admin/links/delete
+// Тело запроса и ответы совпадают с DisableLinks.
+func (h *Handler) PurgeLinks(w http.ResponseWriter, r *http.Request) {
+	h.moderateLinks(w, r, "delete", func(request models.AdminLinksRequest) (int, error) {
+		return h.service.PurgeLinks(r.Context(), request.IDs)
+	})
+}
+
+// ReassignLinks передает ссылки другому владельцу.
+//
+// Эндпоинт: POST /api/admin/links/reassign
+// Тело запроса: JSON models.AdminLinksRequest с полем "ids" и одним
+// из полей "user_id" или "login"
+//
+// Ответы:
+//   - 200 OK: JSON models.AdminLinksResult
+//   - 400 Bad Request: некорректный JSON, список или владелец
+//   - 404 Not Found: учетная запись с логином login не найдена
+//   - 501 Not Implemented: хранилище не поддерживает модерацию
+func (h *Handler) ReassignLinks(w http.ResponseWriter, r *http.Request) {
+	h.moderateLinks(w, r, "reassign", func(request models.AdminLinksRequest) (int, error) {
+		return h.service.ReassignLinks(r.Context(), request.IDs, service.LinkOwner{
+			UserID: request.UserID,
+			Login:  request.Login,
+		})
+	})
+}
+
+// moderateLinks разбирает групповую операцию, выполняет ее и записывает
+// в журнал, кто из администраторов и над какими ссылками ее выполнил
+func (h *Handler) moderateLinks(w http.ResponseWriter, r *http.Request, action string, apply func(models.AdminLinksRequest) (int, error)) {
+	var request models.AdminLinksRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Неверный формат JSON", http.StatusBadRequest)
+		return
+	}
+
+	affected, err := apply(request)
+	if err != nil {
+		h.writeAdminError(w, r, err)
+		return
+	}
+
+	admin, _ := auth.IdentityFromContext(r.Context())
+	logger.Ctx(r.Context()).Info("Модерация ссылок",
+		zap.String("action", action),
+		zap.String("admin_id", admin.UserID),
+		zap.Strings("ids", request.IDs),
+		zap.String("to_user_id", request.UserID),
+		zap.String("to_login", request.Login),
+		zap.Int("affected", affected))
+
+	writeJSON(w, r, http.StatusOK, models.AdminLinksResult{Affected: affected})
+}
+
+// SetAccountRole назначает роль учетной записи.
+//
+// Эндпоинт: PUT /api/admin/accounts/{login}/role
+// Тело запроса: JSON models.AccountRoleRequest с полем "role" ("user" или "admin")
+//
+// Ответы:
+//   - 200 OK: JSON models.AccountRoleResponse
+//   - 400 Bad Request: некорректный JSON или роль
+//   - 404 Not Found: учетная запись не найдена
+//   - 501 Not Implemented: хранилище не поддерживает учетные записи
+func (h *Handler) SetAccountRole(w http.ResponseWriter, r *http.Request) {
+	var request models.AccountRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Неверный формат JSON", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.service.SetAccountRole(r.Context(), chi.URLParam(r, "login"), request.Role)
+	if err != nil {
+		h.writeAdminError(w, r, err)
+		return
+	}
+
+	admin, _ := auth.IdentityFromContext(r.Context())
+	logger.Ctx(r.Context()).Info("Изменена роль учетной записи",
+		zap.String("admin_id", admin.UserID),
+		zap.String("user_id", account.ID),
+		zap.String("role", account.Role))
+
+	writeJSON(w, r, http.StatusOK, models.AccountRoleResponse{
+		UserID: account.ID,
+		Login:  account.Login,
+		Role:   account.Role,
+	})
+}
+
+// writeAdminError преобразует ошибку сервиса модерации в HTTP ответ
+func (h *Handler) writeAdminError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrEmptyList),
+		errors.Is(err, service.ErrTooManyLinks),
+		errors.Is(err, service.ErrInvalidFilter),
+		errors.Is(err, service.ErrInvalidOwner),
+		errors.Is(err, service.ErrInvalidRole):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrAccountNotFound),
+		errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Не найдено", http.StatusNotFound)
+	case errors.Is(err, service.ErrAdminNotConfigured),
+		errors.Is(err, service.ErrAccountsNotConfigured):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		logger.Ctx(r.Context()).Error("Ошибка модерации ссылок", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// register регистрирует учетную запись и возвращает заголовок с ее сессией
+func (c authClient) register(login string) []string {
+	rec := c.do(http.MethodPost, "/api/auth/register", `{"login":"`+login+`","password":"correct horse"}`)
+	require.Equal(c.t, http.StatusCreated, rec.Code, rec.Body.String())
+	var registered models.AuthResponse
+	require.NoError(c.t, json.NewDecoder(rec.Body).Decode(&registered))
+	return []string{"Authorization", "Bearer " + registered.Token}
+}
+
+func TestRouter_Admin(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	previousCost := auth.PasswordHashCost
+	auth.PasswordHashCost = bcrypt.MinCost
+	t.Cleanup(func() { auth.PasswordHashCost = previousCost })
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil).
+		WithAccounts(store).
+		WithAdmin(store)
+	c := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	admin := c.register("admin")
+	_, err := svc.SetAccountRole(context.Background(), "admin", auth.RoleAdmin)
+	require.NoError(t, err)
+	alice := c.register("alice")
+
+	rec := c.do(http.MethodPost, "/api/shorten", `{"url":"https://spam.example.com/offer","alias":"spam"}`, alice...)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	// Без токена учетной записи и без роли доступ запрещен
+	rec = c.do(http.MethodGet, "/api/admin/links", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = c.do(http.MethodGet, "/api/admin/links", "", alice...)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// Поиск по хосту и логину владельца
+	rec = c.do(http.MethodGet, "/api/admin/links?host=Example.COM&login=alice&status=active", "", admin...)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var found models.AdminLinksResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&found))
+	assert.Equal(t, 1, found.Total)
+	assert.Equal(t, service.DefaultAdminPageSize, found.Limit)
+	require.Len(t, found.Links, 1)
+	assert.Equal(t, "spam", found.Links[0].ShortID)
+	assert.Equal(t, "http://localhost:8080/spam", found.Links[0].ShortURL)
+	assert.NotNil(t, found.Links[0].CreatedAt)
+
+	for _, query := range []string{"status=unknown", "limit=-1", "limit=1000", "created_from=yesterday"} {
+		rec = c.do(http.MethodGet, "/api/admin/links?"+query, "", admin...)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+	rec = c.do(http.MethodGet, "/api/admin/links?login=nobody", "", admin...)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Отключенная ссылка возвращает 410 Gone
+	rec = c.do(http.MethodPost, "/api/admin/links/disable", `{"ids":["spam","missing"]}`, admin...)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"affected":1}`, rec.Body.String())
+	rec = c.do(http.MethodGet, "/spam", "")
+	assert.Equal(t, http.StatusGone, rec.Code)
+
+	rec = c.do(http.MethodGet, "/api/admin/links/spam", "", admin...)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var link models.AdminLink
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&link))
+	assert.True(t, link.Disabled)
+
+	rec = c.do(http.MethodPost, "/api/admin/links/enable", `{"ids":["spam"]}`, admin...)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = c.do(http.MethodGet, "/spam", "")
+	assert.Equal(t, http.StatusTemporaryRedirect, rec.Code)
+
+	// Передача ссылки другому владельцу
+	rec = c.do(http.MethodPost, "/api/admin/links/reassign", `{"ids":["spam"],"login":"admin"}`, admin...)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"affected":1}`, rec.Body.String())
+	assert.Empty(t, c.userURLs(alice...))
+	assert.Len(t, c.userURLs(admin...), 1)
+
+	rec = c.do(http.MethodPost, "/api/admin/links/reassign", `{"ids":["spam"]}`, admin...)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = c.do(http.MethodPost, "/api/admin/links/disable", `{"ids":[]}`, admin...)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Безвозвратное удаление
+	rec = c.do(http.MethodPost, "/api/admin/links/delete", `{"ids":["spam"]}`, admin...)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = c.do(http.MethodGet, "/spam", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = c.do(http.MethodGet, "/api/admin/links/spam", "", admin...)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Назначение роли
+	rec = c.do(http.MethodPut, "/api/admin/accounts/alice/role", `{"role":"superuser"}`, admin...)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = c.do(http.MethodPut, "/api/admin/accounts/nobody/role", `{"role":"admin"}`, admin...)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = c.do(http.MethodPut, "/api/admin/accounts/alice/role", `{"role":"admin"}`, admin...)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = c.do(http.MethodGet, "/api/admin/links", "", alice...)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRouter_AdminNotConfigured(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	previousCost := auth.PasswordHashCost
+	auth.PasswordHashCost = bcrypt.MinCost
+	t.Cleanup(func() { auth.PasswordHashCost = previousCost })
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil).WithAccounts(store)
+	c := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	admin := c.register("admin")
+	_, err := svc.SetAccountRole(context.Background(), "admin", auth.RoleAdmin)
+	require.NoError(t, err)
+
+	rec := c.do(http.MethodGet, "/api/admin/links", "", admin...)
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Register обрабатывает регистрацию учетной записи.
+//
+// Эндпоинт: POST /api/auth/register
+// Тело запроса: JSON с полями "login" и "password"
+//
+// URL текущего анонимного пользователя переносятся в созданную учетную
+// запись. Токен сессии возвращается в теле ответа и в куке session.
+//
+// Ответы:
+//   - 201 Created: JSON models.AuthResponse
+//   - 400 Bad Request: некорректный JSON, логин или пароль
+//   - 409 Conflict: логин уже занят
+//   - 501 Not Implemented: хранилище не поддерживает учетные записи
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var request models.AuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Неверный формат JSON", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.Register(r.Context(), request.Login, request.Password, anonymousUserID(r))
+	if err != nil {
+		h.writeAuthError(w, r, err)
+		return
+	}
+
+	writeSession(w, r, http.StatusCreated, result)
+}
+
+// Login обрабатывает вход в учетную запись.
+//
+// Эндпоинт: POST /api/auth/login
+// Тело запроса: JSON с полями "login" и "password"
+//
+// URL текущего анонимного пользователя переносятся в учетную запись.
+//
+// Ответы:
+//   - 200 OK: JSON models.AuthResponse
+//   - 400 Bad Request: некорректный JSON
+//   - 401 Unauthorized: неверный логин или пароль
+//   - 501 Not Implemented: хранилище не поддерживает учетные записи
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var request models.AuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Неверный формат JSON", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.Login(r.Context(), request.Login, request.Password, anonymousUserID(r))
+	if err != nil {
+		h.writeAuthError(w, r, err)
+		return
+	}
+
+	writeSession(w, r, http.StatusOK, result)
+}
+
+// Logout завершает текущую сессию и удаляет куку session.
+//
+// Эндпоинт: POST /api/auth/logout
+//
+// Ответы:
+//   - 204 No Content: сессия завершена
+//   - 401 Unauthorized: запрос не аутентифицирован учетной записью
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.IdentityFromContext(r.Context())
+
+	err := h.service.Logout(r.Context(), identity)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		h.writeAuthError(w, r, err)
+		return
+	}
+
+	auth.ClearSessionCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Claim переносит URL анонимного пользователя в учетную запись.
+//
+// Эндпоинт: POST /api/auth/claim
+// Тело запроса: JSON с полем "token" - подписанным ID анонимного пользователя
+//
+// Ответы:
+//   - 200 OK: JSON с количеством перенесенных URL в поле "claimed"
+//   - 400 Bad Request: некорректный JSON или токен
+//   - 401 Unauthorized: запрос не аутентифицирован учетной записью
+func (h *Handler) Claim(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.IdentityFromContext(r.Context())
+
+	var request models.ClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Неверный формат JSON", http.StatusBadRequest)
+		return
+	}
+
+	claimed, err := h.service.ClaimAnonymous(r.Context(), identity.UserID, request.Token)
+	if err != nil {
+		h.writeAuthError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, models.ClaimResponse{Claimed: claimed})
+}
+
+// ListAPIKeys возвращает API-ключи учетной записи, включая отозванные.
+//
+// Эндпоинт: GET /api/auth/keys
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.IdentityFromContext(r.Context())
+
+	keys, err := h.service.ListAPIKeys(r.Context(), identity.UserID)
+	if err != nil {
+		h.writeAuthError(w, r, err)
+		return
+	}
+
+	response := make([]models.APIKey, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, apiKeyResponse(key))
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+// CreateAPIKey создает именованный API-ключ.
+//
+// Эндпоинт: POST /api/auth/keys
+// Тело запроса: JSON с полем "name"
+//
+// Полный ключ возвращается только в этом ответе.
+//
+// Ответы:
+//   - 201 Created: JSON models.APIKey с полем "key"
+//   - 400 Bad Request: некорректный JSON или имя ключа
+//   - 401 Unauthorized: запрос не аутентифицирован учетной записью
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.IdentityFromContext(r.Context())
+
+	var request models.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Неверный формат JSON", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.CreateAPIKey(r.Context(), identity.UserID, request.Name)
+	if err != nil {
+		h.writeAuthError(w, r, err)
+		return
+	}
+
+	response := apiKeyResponse(result.Key)
+	response.Key = result.Token
+	writeJSON(w, r, http.StatusCreated, response)
+}
+
+// RevokeAPIKey отзывает API-ключ.
+//
+// Эндпоинт: DELETE /api/auth/keys/{id}
+//
+// Ответы:
+//   - 204 No Content: ключ отозван
+//   - 401 Unauthorized: запрос не аутентифицирован учетной записью
+//   - 404 Not Found: ключ не найден
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	identity, _ := auth.IdentityFromContext(r.Context())
+
+	err := h.service.RevokeAPIKey(r.Context(), identity.UserID, chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeAuthError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// anonymousUserID возвращает ID анонимного пользователя запроса.
+// Для запроса от учетной записи возвращает пустую строку.
+func anonymousUserID(r *http.Request) string {
+	if _, ok := auth.IdentityFromContext(r.Context()); ok {
+		return ""
+	}
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	return userID
+}
+
+// writeSession устанавливает куку сессии и отправляет результат входа
+func writeSession(w http.ResponseWriter, r *http.Request, status int, result service.AuthResult) {
+	auth.SetSessionCookie(w, result.Token, result.ExpiresAt)
+	writeJSON(w, r, status, models.AuthResponse{
+		UserID:    result.UserID,
+		Login:     result.Login,
+		Token:     result.Token,
+		ExpiresAt: result.ExpiresAt,
+		Claimed:   result.Claimed,
+	})
+}
+
+// apiKeyResponse преобразует API-ключ хранилища в ответ API
+func apiKeyResponse(key storage.Credential) models.APIKey {
+	response := models.APIKey{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		CreatedAt: key.CreatedAt,
+	}
+	if !key.RevokedAt.IsZero() {
+		revokedAt := key.RevokedAt.In(time.UTC)
+		response.RevokedAt = &revokedAt
+	}
+	return response
+}
+
+// writeJSON отправляет ответ в формате JSON
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Ctx(r.Context()).Error("Ошибка кодирования ответа", zap.Error(err))
+	}
+}
+
+// writeAuthError преобразует ошибку сервиса учетных записей в HTTP ответ
+func (h *Handler) writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidLogin),
+		errors.Is(err, service.ErrInvalidPassword),
+		errors.Is(err, service.ErrInvalidAPIKeyName),
+		errors.Is(err, service.ErrInvalidClaim):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrInvalidCredentials):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, service.ErrLoginTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Не найдено", http.StatusNotFound)
+	case errors.Is(err, service.ErrAccountsNotConfigured):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		logger.Ctx(r.Context()).Error("Ошибка обработки учетной записи", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// authClient выполняет запросы к роутеру с заданными заголовками
+type authClient struct {
+	t      *testing.T
+	router chi.Router
+}
+
+func (c authClient) do(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	c.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// anonymous создает ссылку от имени нового анонимного пользователя
+// и возвращает его куку user_id
+func (c authClient) anonymous(url string) string {
+	rec := c.do(http.MethodPost, "/api/shorten", `{"url":"`+url+`"}`)
+	require.Equal(c.t, http.StatusCreated, rec.Code)
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == auth.CookieName {
+			return cookie.Value
+		}
+	}
+	c.t.Fatal("кука user_id не установлена")
+	return ""
+}
+
+func (c authClient) userURLs(headers ...string) []models.UserURL {
+	rec := c.do(http.MethodGet, "/api/user/urls", "", headers...)
+	if rec.Code == http.StatusNoContent {
+		return nil
+	}
+	require.Equal(c.t, http.StatusOK, rec.Code)
+	var urls []models.UserURL
+	require.NoError(c.t, json.NewDecoder(rec.Body).Decode(&urls))
+	return urls
+}
+
+func TestRouter_Accounts(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	previousCost := auth.PasswordHashCost
+	auth.PasswordHashCost = bcrypt.MinCost
+	t.Cleanup(func() { auth.PasswordHashCost = previousCost })
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil).WithAccounts(store)
+	c := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	// Регистрация переносит ссылки текущего анонимного пользователя
+	anonCookie := c.anonymous("https://example.com/anon")
+	rec := c.do(http.MethodPost, "/api/auth/register", `{"login":"Alice","password":"correct horse"}`,
+		"Cookie", auth.CookieName+"="+anonCookie)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var registered models.AuthResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&registered))
+	assert.Equal(t, "alice", registered.Login)
+	assert.Equal(t, 1, registered.Claimed)
+	assert.True(t, strings.HasPrefix(registered.Token, auth.SessionTokenPrefix))
+	assert.Contains(t, rec.Header().Get("Set-Cookie"), auth.SessionCookieName+"="+registered.Token)
+
+	session := []string{"Authorization", "Bearer " + registered.Token}
+	assert.Len(t, c.userURLs(session...), 1)
+	assert.Len(t, c.userURLs("Cookie", auth.SessionCookieName+"="+registered.Token), 1)
+
+	// Повторная регистрация и ошибки входа
+	rec = c.do(http.MethodPost, "/api/auth/register", `{"login":"alice","password":"another one"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = c.do(http.MethodPost, "/api/auth/register", `{"login":"al ice","password":"another one"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = c.do(http.MethodPost, "/api/auth/register", `{"login":"bob","password":"short"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = c.do(http.MethodPost, "/api/auth/login", `{"login":"alice","password":"wrong password"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = c.do(http.MethodPost, "/api/auth/login", `{"login":"nobody","password":"wrong password"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// API-ключ работает вместо сессии до отзыва
+	rec = c.do(http.MethodPost, "/api/auth/keys", `{"name":"ci"}`, session...)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var key models.APIKey
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&key))
+	assert.True(t, strings.HasPrefix(key.Key, auth.APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(key.Key, key.Prefix))
+
+	apiKey := []string{"Authorization", "Bearer " + key.Key}
+	assert.Len(t, c.userURLs(apiKey...), 1)
+
+	rec = c.do(http.MethodGet, "/api/auth/keys", "", apiKey...)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var keys []models.APIKey
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&keys))
+	require.Len(t, keys, 1)
+	assert.Empty(t, keys[0].Key)
+	assert.Nil(t, keys[0].RevokedAt)
+
+	rec = c.do(http.MethodDelete, "/api/auth/keys/"+key.ID, "", session...)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = c.do(http.MethodDelete, "/api/auth/keys/unknown", "", session...)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = c.do(http.MethodGet, "/api/user/urls", "", apiKey...)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Анонимному пользователю управление ключами недоступно
+	rec = c.do(http.MethodGet, "/api/auth/keys", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Явный перенос ссылок другого анонимного пользователя после входа
+	otherCookie := c.anonymous("https://example.com/other")
+	rec = c.do(http.MethodPost, "/api/auth/login", `{"login":"alice","password":"correct horse"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var loggedIn models.AuthResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&loggedIn))
+	assert.Equal(t, registered.UserID, loggedIn.UserID)
+
+	second := []string{"Authorization", "Bearer " + loggedIn.Token}
+	rec = c.do(http.MethodPost, "/api/auth/claim", `{"token":"`+otherCookie+`"}`, second...)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"claimed":1}`, rec.Body.String())
+	assert.Len(t, c.userURLs(second...), 2)
+
+	rec = c.do(http.MethodPost, "/api/auth/claim", `{"token":"forged.signature"}`, second...)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Выход завершает только текущую сессию
+	rec = c.do(http.MethodPost, "/api/auth/logout", "", session...)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = c.do(http.MethodGet, "/api/user/urls", "", session...)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Len(t, c.userURLs(second...), 2)
+}
+
+func TestRouter_AccountsNotConfigured(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+	c := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	rec := c.do(http.MethodPost, "/api/auth/register", `{"login":"alice","password":"correct horse"}`)
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+
+	// Токен учетной записи без хранилища учетных записей недействителен
+	rec = c.do(http.MethodGet, "/api/user/urls", "", "Authorization", "Bearer sk_unknown")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRouter_UserURLsForgedCookie(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+	strict := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	victimCookie := strict.anonymous("https://example.com/victim")
+	victimID, err := auth.VerifyUserID(victimCookie)
+	require.NoError(t, err)
+	assert.Len(t, strict.userURLs("Cookie", auth.CookieName+"="+victimCookie), 1)
+
+	// Кука с ID жертвы и поддельной подписью отклоняется
+	for _, forged := range []string{victimID + ".forged", victimID} {
+		rec := strict.do(http.MethodGet, "/api/user/urls", "", "Cookie", auth.CookieName+"="+forged)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, forged)
+		assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+		assert.NotContains(t, rec.Body.String(), "example.com/victim")
+
+		rec = strict.do(http.MethodDelete, "/api/user/urls", `["x"]`, "Cookie", auth.CookieName+"="+forged)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, forged)
+	}
+
+	// Без учетных данных ответ содержит только вызов аутентификации
+	rec := strict.do(http.MethodGet, "/api/user/urls", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, middleware.AuthChallenge, rec.Header().Get("WWW-Authenticate"))
+
+	// Режим legacy сохраняет прежнее поведение: ID из куки принимается без проверки
+	legacy := authClient{t: t, router: NewRouter(New(svc).WithAuthMode(auth.ModeLegacy), "")}
+	assert.Len(t, legacy.userURLs("Cookie", auth.CookieName+"="+victimID+".forged"), 1)
+}
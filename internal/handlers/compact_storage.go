@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"go.uber.org/zap"
+)
+
+// CompactionResponse представляет ответ на запрос компактирования журнала
+type CompactionResponse struct {
+	SnapshotRecords int   `json:"snapshot_records"` // Количество записей в снимке
+	TailRecords     int   `json:"tail_records"`     // Количество записей, оставшихся в журнале
+	DurationMs      int64 `json:"duration_ms"`      // Длительность компактирования в миллисекундах
+}
+
+// CompactStorage запускает компактирование журнала файлового хранилища
+func (h *Handler) CompactStorage(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.CompactStorage()
+	if errors.Is(err, storage.ErrCompactionUnsupported) {
+		http.Error(w, "Компактирование не поддерживается хранилищем", http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		logger.Ctx(r.Context()).Error("Ошибка компактирования журнала", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := CompactionResponse{
+		SnapshotRecords: result.SnapshotRecords,
+		TailRecords:     result.TailRecords,
+		DurationMs:      result.Duration.Milliseconds(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Ctx(r.Context()).Error("Ошибка кодирования ответа компактирования", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -1,11 +1,9 @@
 package handlers
 
 import (
-	"errors"
 	"io"
 	"net/http"
 
-	"github.com/Adigezalov/shortener/internal/database"
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
 	"go.uber.org/zap"
@@ -19,7 +17,8 @@ import (
 //
 // Ответы:
 //   - 201 Created: короткий URL в теле ответа
-//   - 400 Bad Request: некорректный запрос (пустой URL)
+//   - 400 Bad Request: некорректный запрос (пустой URL); для URL, нарушающего
+//     политику, - JSON models.ErrorResponse с кодом нарушения
 //   - 409 Conflict: URL уже существует (возвращает существующий короткий URL)
 //   - 500 Internal Server Error: внутренняя ошибка сервера
 //
@@ -40,7 +39,7 @@ func (h *Handler) CreateShortURL(w http.ResponseWriter, r *http.Request) {
 	// Читаем оригинальный URL из тела запроса
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		logger.Logger.Error("Ошибка чтения тела запроса", zap.Error(err))
+		logger.Ctx(r.Context()).Error("Ошибка чтения тела запроса", zap.Error(err))
 		http.Error(w, "Ошибка чтения запроса", http.StatusBadRequest)
 		return
 	}
@@ -58,35 +57,31 @@ func (h *Handler) CreateShortURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Генерируем новый ID и пытаемся добавить URL с привязкой к пользователю
-	id := h.shortener.Shorten(originalURL)
-	id, exists, err := h.storage.AddWithUser(id, originalURL, userID)
-
-	if err != nil {
-		if errors.Is(err, database.ErrURLConflict) {
-			// Если URL уже существует, возвращаем короткий URL с кодом конфликта
-			shortURL := h.shortener.BuildShortURL(id)
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusConflict)
-			w.Write([]byte(shortURL))
+	// Создаем короткий URL с привязкой к пользователю
+	result := h.service.CreateShortURL(r.Context(), originalURL, userID)
+	if result.Error != nil {
+		if writeURLPolicyError(w, r, result.Error) {
 			return
 		}
-		logger.Logger.Error("Ошибка добавления URL", zap.Error(err))
+		logger.Ctx(r.Context()).Error("Ошибка добавления URL", zap.Error(result.Error))
 		http.Error(w, "Ошибка сохранения URL", http.StatusInternalServerError)
 		return
 	}
 
-	// Строим полный короткий URL
-	shortURL := h.shortener.BuildShortURL(id)
+	// Если URL уже существует, возвращаем короткий URL с кодом конфликта
+	status := http.StatusCreated
+	if result.Exists {
+		status = http.StatusConflict
+	}
 
 	// Отправляем результат
 	w.Header().Set("Content-Type", "text/plain")
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(shortURL))
+	w.WriteHeader(status)
+	w.Write([]byte(result.ShortURL))
 
-	logger.Logger.Info("URL сокращен",
+	logger.Ctx(r.Context()).Info("URL сокращен",
 		zap.String("original_url", originalURL),
-		zap.String("short_url", shortURL),
-		zap.Bool("existing", exists),
+		zap.String("short_url", result.ShortURL),
+		zap.Bool("existing", result.Exists),
 	)
 }
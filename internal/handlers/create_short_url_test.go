@@ -6,10 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Adigezalov/shortener/internal/database"
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/service"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -35,7 +37,7 @@ func TestHandler_CreateShortURL(t *testing.T) {
 			inputURL: "https://example.com",
 			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
 				msh.On("Shorten", "https://example.com").Return("abc123")
-				ms.On("AddWithUser", "abc123", "https://example.com", "test-user").Return("abc123", false, nil)
+				ms.On("Add", "abc123", "https://example.com", "test-user", time.Time{}).Return("abc123", nil)
 				msh.On("BuildShortURL", "abc123").Return("http://short.url/abc123")
 			},
 			expectedStatus: http.StatusCreated,
@@ -46,7 +48,7 @@ func TestHandler_CreateShortURL(t *testing.T) {
 			inputURL: "https://example.com",
 			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
 				msh.On("Shorten", "https://example.com").Return("abc123")
-				ms.On("AddWithUser", "abc123", "https://example.com", "test-user").Return("abc123", true, database.ErrURLConflict)
+				ms.On("Add", "abc123", "https://example.com", "test-user", time.Time{}).Return("abc123", database.ErrURLConflict)
 				msh.On("BuildShortURL", "abc123").Return("http://short.url/abc123")
 			},
 			expectedStatus: http.StatusConflict,
@@ -71,10 +73,7 @@ func TestHandler_CreateShortURL(t *testing.T) {
 			tt.mockSetup(mockStorage, mockShortener)
 
 			// Создаем обработчик с моками
-			handler := &Handler{
-				storage:   mockStorage,
-				shortener: mockShortener,
-			}
+			handler := New(service.NewShortenerService(mockStorage, mockShortener, nil))
 
 			// Создаем тестовый запрос
 			req := httptest.NewRequest("POST", "/", bytes.NewBufferString(tt.inputURL))
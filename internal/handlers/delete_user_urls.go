@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/metrics"
 	"github.com/Adigezalov/shortener/internal/middleware"
 	"go.uber.org/zap"
 )
@@ -31,22 +33,26 @@ func (h *Handler) DeleteUserURLs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Запускаем асинхронное удаление URL
-	go h.asyncDeleteURLs(userID, shortURLs)
+	// Запускаем асинхронное удаление URL. Контекст отвязан от отмены:
+	// удаление продолжается после отправки ответа клиенту
+	metrics.AsyncDeletes.WithLabelValues("http").Inc()
+	go h.asyncDeleteURLs(context.WithoutCancel(r.Context()), userID, shortURLs)
 
 	// Возвращаем статус 202 Accepted
 	w.WriteHeader(http.StatusAccepted)
 
-	logger.Logger.Info("Принят запрос на удаление URL",
+	logger.Ctx(r.Context()).Info("Принят запрос на удаление URL",
 		zap.String("user_id", userID),
 		zap.Int("count", len(shortURLs)))
 }
 
 // asyncDeleteURLs асинхронно удаляет URL пользователя с использованием паттерна fanIn
-func (h *Handler) asyncDeleteURLs(userID string, shortURLs []string) {
+func (h *Handler) asyncDeleteURLs(ctx context.Context, userID string, shortURLs []string) {
+	defer metrics.AsyncDeletes.WithLabelValues("http").Dec()
+
 	// Выполняем пакетное удаление в хранилище
-	if err := h.storage.DeleteUserURLs(userID, shortURLs); err != nil {
-		logger.Logger.Error("Ошибка удаления URL пользователя",
+	if err := h.service.DeleteUserURLs(ctx, userID, shortURLs); err != nil {
+		logger.Ctx(ctx).Error("Ошибка удаления URL пользователя",
 			zap.String("user_id", userID),
 			zap.Strings("short_urls", shortURLs),
 			zap.Error(err),
@@ -54,7 +60,7 @@ func (h *Handler) asyncDeleteURLs(userID string, shortURLs []string) {
 		return
 	}
 
-	logger.Logger.Info("URL пользователя успешно удалены",
+	logger.Ctx(ctx).Info("URL пользователя успешно удалены",
 		zap.String("user_id", userID),
 		zap.Int("count", len(shortURLs)))
 }
@@ -8,9 +8,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/service"
 	"github.com/Adigezalov/shortener/internal/storage"
 	"go.uber.org/zap"
 )
@@ -29,7 +31,7 @@ func BenchmarkHandler_DeleteUserURLs(b *testing.B) {
 	defer store.Close()
 
 	// Создаем хендлер
-	handler := New(store, nil, nil)
+	handler := New(service.NewShortenerService(store, nil, nil))
 
 	userID := "bench-user"
 
@@ -39,7 +41,7 @@ func BenchmarkHandler_DeleteUserURLs(b *testing.B) {
 		shortID := fmt.Sprintf("bench%d", i)
 		shortURLs[i] = shortID
 		url := fmt.Sprintf("http://example%d.com", i)
-		_, _, err := store.AddWithUser(shortID, url, userID)
+		_, err := store.Add(context.Background(), shortID, url, userID, time.Time{})
 		if err != nil {
 			b.Fatalf("Ошибка добавления URL: %v", err)
 		}
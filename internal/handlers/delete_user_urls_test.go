@@ -10,6 +10,7 @@ import (
 
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/service"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -66,7 +67,7 @@ func TestHandler_DeleteUserURLs(t *testing.T) {
 			tt.mockSetup(mockStorage)
 
 			// Создаем хендлер
-			handler := New(mockStorage, nil, nil)
+			handler := New(service.NewShortenerService(mockStorage, nil, nil))
 
 			// Создаем тело запроса
 			body, _ := json.Marshal(tt.requestBody)
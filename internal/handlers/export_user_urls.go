@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Adigezalov/shortener/internal/bulk"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"go.uber.org/zap"
+)
+
+// ExportUserURLs выгружает все URL пользователя в CSV или JSONL.
+//
+// Эндпоинт: GET /api/user/urls/export?format=csv|jsonl (по умолчанию csv)
+// Фильтры q, host, created_after, created_before и порядок order - как
+// у GET /api/user/urls. URL читаются из хранилища страницами и отправляются
+// потоком (см. bulk.Writer).
+//
+// Ответы:
+//   - 200 OK: файл выгрузки; если хранилище отказало после начала отправки,
+//     соединение обрывается, чтобы неполный файл не был принят за полный
+//   - 400 Bad Request: некорректный формат или параметры
+//   - 401 Unauthorized: пользователь не определен
+//   - 500 Internal Server Error: хранилище недоступно
+func (h *Handler) ExportUserURLs(w http.ResponseWriter, r *http.Request) {
+	// Получаем ID пользователя из контекста
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	format := bulk.FormatCSV
+	if value := r.URL.Query().Get("format"); value != "" {
+		var err error
+		if format, err = bulk.ParseFormat(value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	query, err := parseUserURLsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="urls.`+string(format)+`"`)
+
+	out := &startedWriter{w: w}
+	writer := bulk.NewWriter(out, format)
+	count := 0
+	err = h.service.ExportUserURLs(r.Context(), userID, query, func(userURL models.UserURL) error {
+		count++
+		return writer.Write(userURL)
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	switch {
+	case err == nil:
+		logger.Ctx(r.Context()).Info("URL пользователя выгружены",
+			zap.String("user_id", userID),
+			zap.Int("count", count))
+	case errors.Is(err, service.ErrInvalidFilter):
+		w.Header().Del("Content-Disposition")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case !out.started:
+		logger.Ctx(r.Context()).Error("Ошибка выгрузки URL пользователя",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	default:
+		logger.Ctx(r.Context()).Error("Выгрузка URL пользователя прервана",
+			zap.String("user_id", userID),
+			zap.Int("count", count),
+			zap.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// startedWriter отмечает начало отправки ответа: после него ошибку
+// уже нельзя вернуть кодом статуса
+type startedWriter struct {
+	w       io.Writer
+	started bool
+}
+
+// Write передает данные в ответ
+func (s *startedWriter) Write(p []byte) (int, error) {
+	s.started = true
+	return s.w.Write(p)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRouter_ExportUserURLs(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+	c := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	cookie := auth.CookieName + "=" + c.anonymous("https://example.com/1")
+	rec := c.do(http.MethodPost, "/api/shorten", `{"url":"https://other.org/2"}`, "Cookie", cookie)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	c.anonymous("https://example.com/foreign")
+
+	// CSV по умолчанию
+	rec = c.do(http.MethodGet, "/api/user/urls/export?order=asc", "", "Cookie", cookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="urls.csv"`, rec.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"short_url", "original_url", "created_at"}, records[0])
+	assert.Equal(t, "https://example.com/1", records[1][1])
+	assert.Equal(t, "https://other.org/2", records[2][1])
+	assert.NotEmpty(t, records[1][2])
+
+	// JSONL с фильтром по хосту
+	rec = c.do(http.MethodGet, "/api/user/urls/export?format=jsonl&host=other.org", "", "Cookie", cookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 1)
+	var userURL models.UserURL
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &userURL))
+	assert.Equal(t, "https://other.org/2", userURL.OriginalURL)
+
+	for _, query := range []string{"format=xml", "order=random", "created_after=yesterday"} {
+		rec := c.do(http.MethodGet, "/api/user/urls/export?"+query, "", "Cookie", cookie)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Empty(t, rec.Header().Get("Content-Disposition"), query)
+	}
+}
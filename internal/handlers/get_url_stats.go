@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/analytics"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// maxStatsHours ограничивает глубину почасовой истории в запросе статистики
+const maxStatsHours = 24 * 90
+
+// GetURLStats возвращает статистику переходов по URL пользователя.
+//
+// Необязательный параметр запроса hours задает глубину почасовой истории
+// (по умолчанию 168 часов). Для чужих и несуществующих URL возвращается 404.
+func (h *Handler) GetURLStats(w http.ResponseWriter, r *http.Request) {
+	// Получаем ID пользователя из контекста
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		http.Error(w, "ID не может быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	window := analytics.DefaultStatsWindow
+	if hoursParam := r.URL.Query().Get("hours"); hoursParam != "" {
+		hours, err := strconv.Atoi(hoursParam)
+		if err != nil || hours <= 0 || hours > maxStatsHours {
+			http.Error(w, "Некорректное значение параметра hours", http.StatusBadRequest)
+			return
+		}
+		window = time.Duration(hours) * time.Hour
+	}
+
+	result := h.service.GetURLStats(userID, id, time.Now().Add(-window))
+	if errors.Is(result.Error, service.ErrStatsNotConfigured) {
+		// Без сбора статистики эндпоинт ведет себя как для неизвестного URL
+		http.Error(w, "URL не найден", http.StatusNotFound)
+		return
+	}
+	if result.Error != nil {
+		logger.Ctx(r.Context()).Error("Ошибка получения статистики переходов",
+			zap.String("user_id", userID),
+			zap.String("id", id),
+			zap.Error(result.Error))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if !result.Found {
+		http.Error(w, "URL не найден", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result.Stats); err != nil {
+		logger.Ctx(r.Context()).Error("Ошибка кодирования ответа",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_GetURLStats(t *testing.T) {
+	clickedAt := time.Date(2025, 1, 1, 10, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		userID         string
+		query          string
+		stats          models.URLStats
+		found          bool
+		storageError   error
+		expectStats    bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "успешное_получение_статистики",
+			userID: "user123",
+			stats: models.URLStats{
+				Clicks:       1,
+				FirstClickAt: &clickedAt,
+				LastClickAt:  &clickedAt,
+				Referrers:    []models.StatsCounter{{Value: "t.me", Clicks: 1}},
+				UserAgents:   []models.StatsCounter{{Value: "Chrome", Clicks: 1}},
+				Hourly:       []models.HourlyClicks{{Hour: clickedAt.Truncate(time.Hour), Clicks: 1}},
+			},
+			found:          true,
+			expectStats:    true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"short_url":"http://localhost:8080/abc123","clicks":1,"first_click_at":"2025-01-01T10:15:00Z","last_click_at":"2025-01-01T10:15:00Z","referrers":[{"value":"t.me","clicks":1}],"user_agents":[{"value":"Chrome","clicks":1}],"hourly":[{"hour":"2025-01-01T10:00:00Z","clicks":1}]}`,
+		},
+		{
+			name:           "чужой_или_несуществующий_URL",
+			userID:         "user123",
+			found:          false,
+			expectStats:    true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "URL не найден\n",
+		},
+		{
+			name:           "ошибка_хранилища",
+			userID:         "user123",
+			storageError:   errors.New("db error"),
+			expectStats:    true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error\n",
+		},
+		{
+			name:           "некорректный_параметр_hours",
+			userID:         "user123",
+			query:          "?hours=abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Некорректное значение параметра hours\n",
+		},
+		{
+			name:           "отсутствие_ID_пользователя_в_контексте",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &MockURLStorage{}
+			mockShortener := &MockURLShortener{}
+			mockStats := &MockClickStatsProvider{}
+
+			if tt.expectStats {
+				mockStats.On("GetClickStats", tt.userID, "abc123", mock.AnythingOfType("time.Time")).
+					Return(tt.stats, tt.found, tt.storageError)
+			}
+			mockShortener.On("BuildShortURL", "abc123").Return("http://localhost:8080/abc123")
+
+			handler := New(service.NewShortenerService(mockStorage, mockShortener, nil).WithClickStats(mockStats))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/abc123/stats"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "abc123")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			if tt.userID != "" {
+				ctx = context.WithValue(ctx, middleware.UserIDKey, tt.userID)
+			}
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler.GetURLStats(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			} else {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+
+			mockStats.AssertExpectations(t)
+		})
+	}
+}
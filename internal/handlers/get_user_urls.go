@@ -2,15 +2,41 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
-	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/storage"
 	"go.uber.org/zap"
 )
 
-// GetUserURLs возвращает все URL пользователя
+// Заголовки ответа со списком URL пользователя
+const (
+	TotalCountHeader = "X-Total-Count" // Количество URL, удовлетворяющих фильтру
+	NextCursorHeader = "X-Next-Cursor" // Курсор следующей страницы
+)
+
+// GetUserURLs возвращает URL пользователя.
+//
+// Эндпоинт: GET /api/user/urls
+// Параметры запроса (все необязательные):
+//   - limit: размер страницы (не более 1000; без limit возвращаются все URL);
+//   - cursor: значение заголовка X-Next-Cursor предыдущей страницы;
+//   - order: desc (сначала новые, по умолчанию) или asc;
+//   - q: подстрока оригинального URL без учета регистра;
+//   - host: хост оригинального URL, включая поддомены;
+//   - created_after, created_before: период создания (RFC 3339 или 2006-01-02).
+//
+// Ответы:
+//   - 200 OK: JSON массив models.UserURL; заголовок X-Total-Count содержит
+//     количество URL, удовлетворяющих фильтру, X-Next-Cursor - курсор
+//     следующей страницы (отсутствует на последней странице)
+//   - 204 No Content: на странице нет URL
+//   - 400 Bad Request: некорректные параметры
+//   - 401 Unauthorized: пользователь не определен
 func (h *Handler) GetUserURLs(w http.ResponseWriter, r *http.Request) {
 	// Получаем ID пользователя из контекста
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -19,29 +45,36 @@ func (h *Handler) GetUserURLs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Получаем URL пользователя из хранилища
-	userURLs, err := h.storage.GetUserURLs(userID)
+	query, err := parseUserURLsQuery(r)
 	if err != nil {
-		logger.Logger.Error("Ошибка получения URL пользователя",
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Получаем URL пользователя (сервис возвращает полные короткие ссылки)
+	userURLs := h.service.GetUserURLs(r.Context(), userID, query)
+	if errors.Is(userURLs.Error, service.ErrInvalidFilter) {
+		http.Error(w, userURLs.Error.Error(), http.StatusBadRequest)
+		return
+	}
+	if userURLs.Error != nil {
+		logger.Ctx(r.Context()).Error("Ошибка получения URL пользователя",
 			zap.String("user_id", userID),
-			zap.Error(err))
+			zap.Error(userURLs.Error))
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	// Если у пользователя нет URL, возвращаем 204 No Content
-	if len(userURLs) == 0 {
-		w.WriteHeader(http.StatusNoContent)
-		return
+	w.Header().Set(TotalCountHeader, strconv.Itoa(userURLs.Total))
+	if userURLs.NextCursor != "" {
+		w.Header().Set(NextCursorHeader, userURLs.NextCursor)
 	}
 
-	// Преобразуем URL в полные ссылки
-	result := make([]models.UserURL, len(userURLs))
-	for i, userURL := range userURLs {
-		result[i] = models.UserURL{
-			ShortURL:    h.shortener.BuildShortURL(userURL.ShortURL),
-			OriginalURL: userURL.OriginalURL,
-		}
+	// Если на странице нет URL, возвращаем 204 No Content
+	result := userURLs.URLs
+	if len(result) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
 	}
 
 	// Устанавливаем заголовок Content-Type
@@ -49,14 +82,42 @@ func (h *Handler) GetUserURLs(w http.ResponseWriter, r *http.Request) {
 
 	// Кодируем и отправляем ответ
 	if err := json.NewEncoder(w).Encode(result); err != nil {
-		logger.Logger.Error("Ошибка кодирования ответа",
+		logger.Ctx(r.Context()).Error("Ошибка кодирования ответа",
 			zap.String("user_id", userID),
 			zap.Error(err))
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	logger.Logger.Info("Возвращены URL пользователя",
+	logger.Ctx(r.Context()).Info("Возвращены URL пользователя",
 		zap.String("user_id", userID),
-		zap.Int("count", len(result)))
+		zap.Int("count", len(result)),
+		zap.Int("total", userURLs.Total))
+}
+
+// parseUserURLsQuery разбирает параметры списка URL пользователя
+func parseUserURLsQuery(r *http.Request) (service.UserURLsQuery, error) {
+	values := r.URL.Query()
+
+	order, err := storage.ParseSortOrder(values.Get("order"))
+	if err != nil {
+		return service.UserURLsQuery{}, err
+	}
+
+	query := service.UserURLsQuery{
+		URLContains: values.Get("q"),
+		Host:        values.Get("host"),
+		Order:       order,
+		Cursor:      values.Get("cursor"),
+	}
+	if query.CreatedFrom, err = parseTimeParam(values.Get("created_after")); err != nil {
+		return service.UserURLsQuery{}, err
+	}
+	if query.CreatedTo, err = parseTimeParam(values.Get("created_before")); err != nil {
+		return service.UserURLsQuery{}, err
+	}
+	if query.Limit, err = parseIntParam(values.Get("limit")); err != nil {
+		return service.UserURLsQuery{}, err
+	}
+	return query, nil
 }
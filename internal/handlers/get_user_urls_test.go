@@ -7,12 +7,21 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
 	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestHandler_GetUserURLs(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
 	tests := []struct {
 		name           string
 		userID         string
@@ -54,14 +63,15 @@ func TestHandler_GetUserURLs(t *testing.T) {
 
 			// Настраиваем мок
 			if tt.userID != "" {
-				mockStorage.On("GetUserURLs", tt.userID).Return(tt.userURLs, tt.storageError)
+				page := storage.UserURLPage{URLs: tt.userURLs, Total: len(tt.userURLs)}
+				mockStorage.On("ListUserURLs", tt.userID, storage.UserURLQuery{Order: storage.SortNewest}).Return(page, tt.storageError)
 				for _, userURL := range tt.userURLs {
 					mockShortener.On("BuildShortURL", userURL.ShortURL).Return("http://localhost:8080/" + userURL.ShortURL)
 				}
 			}
 
 			// Создаем хендлер
-			handler := New(mockStorage, mockShortener, nil)
+			handler := New(service.NewShortenerService(mockStorage, mockShortener, nil))
 
 			// Создаем запрос
 			req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
@@ -106,3 +116,63 @@ func TestHandler_GetUserURLs(t *testing.T) {
 		})
 	}
 }
+
+func TestRouter_GetUserURLs_Pagination(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+	c := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	cookie := auth.CookieName + "=" + c.anonymous("https://example.com/1")
+	for _, url := range []string{"https://news.example.com/2", "https://other.org/3"} {
+		rec := c.do(http.MethodPost, "/api/shorten", `{"url":"`+url+`"}`, "Cookie", cookie)
+		require.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	// Обход страниц по два URL возвращает все URL без повторов
+	var originals []string
+	path := "/api/user/urls?limit=2&order=asc"
+	for {
+		rec := c.do(http.MethodGet, path, "", "Cookie", cookie)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get(TotalCountHeader))
+
+		var urls []models.UserURL
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&urls))
+		for _, userURL := range urls {
+			require.NotNil(t, userURL.CreatedAt)
+			originals = append(originals, userURL.OriginalURL)
+		}
+
+		next := rec.Header().Get(NextCursorHeader)
+		if next == "" {
+			break
+		}
+		path = "/api/user/urls?limit=2&order=asc&cursor=" + next
+	}
+	assert.ElementsMatch(t, []string{"https://example.com/1", "https://news.example.com/2", "https://other.org/3"}, originals)
+
+	// Фильтр без совпадений возвращает 204 с нулевым количеством
+	rec := c.do(http.MethodGet, "/api/user/urls?host=missing.org", "", "Cookie", cookie)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get(TotalCountHeader))
+
+	rec = c.do(http.MethodGet, "/api/user/urls?host=example.com&q=NEWS", "", "Cookie", cookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get(TotalCountHeader))
+
+	for _, query := range []string{
+		"limit=-1",
+		"limit=1001",
+		"limit=abc",
+		"order=random",
+		"cursor=not-a-cursor",
+		"created_after=yesterday",
+		"created_after=2026-02-01&created_before=2026-01-01",
+	} {
+		rec := c.do(http.MethodGet, "/api/user/urls?"+query, "", "Cookie", cookie)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -6,102 +6,104 @@
 //   - Редирект по короткому URL (GET /{id})
 //   - Получение URL пользователя (GET /api/user/urls)
 //   - Удаление URL пользователя (DELETE /api/user/urls)
+//   - Статистика переходов по URL пользователя (GET /api/user/urls/{id}/stats)
+//   - Учетные записи и API-ключи (/api/auth/*)
+//   - Модерация ссылок (/api/admin/*)
 //   - Проверка состояния БД (GET /ping)
 package handlers
 
 import (
+	"net/netip"
+
+	"github.com/Adigezalov/shortener/internal/auth"
 	"github.com/Adigezalov/shortener/internal/models"
-	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/ratelimit"
+	"github.com/Adigezalov/shortener/internal/service"
 )
 
-// URLStorage определяет интерфейс для хранения и управления URL.
+// ClickRecorder определяет интерфейс регистрации переходов по коротким ссылкам.
 //
-// Интерфейс поддерживает различные типы хранилищ: в памяти, файловое и базу данных.
-// Все методы должны быть потокобезопасными.
-type URLStorage interface {
-	// Add добавляет новый URL в хранилище.
-	// Возвращает ID, флаг существования URL и ошибку.
-	Add(id string, url string) (string, bool, error)
-
-	// AddWithUser добавляет URL с привязкой к пользователю.
-	// Возвращает ID, флаг существования URL и ошибку.
-	AddWithUser(id string, url string, userID string) (string, bool, error)
-
-	// Get возвращает оригинальный URL по короткому ID.
-	// Возвращает URL и флаг существования.
-	Get(id string) (string, bool)
-
-	// FindByOriginalURL ищет короткий ID по оригинальному URL.
-	// Возвращает ID и флаг существования.
-	FindByOriginalURL(url string) (string, bool)
+// Реализация не должна блокировать обработчик редиректа.
+type ClickRecorder interface {
+	// Record регистрирует событие перехода.
+	Record(click models.Click)
+}
 
-	// GetUserURLs возвращает все URL пользователя.
-	GetUserURLs(userID string) ([]models.UserURL, error)
+// Handler содержит обработчики HTTP запросов для сервиса сокращения URL.
+//
+// Вся бизнес-логика выполняется в service.ShortenerService, который
+// используется и gRPC сервером. Обработчики отвечают только за разбор
+// запроса и преобразование результата сервиса в HTTP ответ.
+type Handler struct {
+	service *service.ShortenerService
 
-	// DeleteUserURLs помечает URL как удаленные для указанного пользователя.
-	DeleteUserURLs(userID string, shortURLs []string) error
+	clicks ClickRecorder // регистратор переходов (может быть nil)
 
-	// IsDeleted проверяет, помечен ли URL как удаленный.
-	IsDeleted(shortURL string) (bool, error)
+	authMode auth.Mode // режим проверки аутентификации маршрутов /api/user
 
-	// Stats возвращает статистику хранилища.
-	Stats() (storage.Stats, error)
+	limiter        *ratelimit.Limiter // ограничение частоты запросов (может быть nil)
+	trustedProxies []netip.Prefix     // прокси, которым доверяется X-Forwarded-For
 
-	// Close закрывает хранилище и освобождает ресурсы.
-	Close() error
+	importMaxBytes int64 // максимальный размер тела импорта (0 - без ограничения)
+	importMaxRows  int   // максимальное количество строк импорта (0 - без ограничения)
 }
 
-// URLShortener определяет интерфейс для сокращения URL.
+// New создает новый экземпляр обработчика HTTP запросов.
 //
-// Интерфейс предоставляет методы для генерации коротких идентификаторов
-// и построения полных коротких URL.
-type URLShortener interface {
-	// Shorten генерирует короткий идентификатор для URL.
-	// Возвращает уникальный короткий ID.
-	Shorten(url string) string
-
-	// BuildShortURL строит полный короткий URL из идентификатора.
-	// Возвращает полный URL вида "http://example.com/abc123".
-	BuildShortURL(id string) string
+// Пример использования:
+//
+//	store := storage.NewMemoryStorage("")
+//	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+//	handler := handlers.New(svc)
+func New(svc *service.ShortenerService) *Handler {
+	return &Handler{
+		service:  svc,
+		authMode: auth.ModeStrict,
+	}
 }
 
-// Pinger определяет интерфейс для проверки подключения к базе данных.
+// WithAnalytics подключает к обработчику регистрацию переходов.
 //
-// Используется для реализации health check эндпоинта.
-type Pinger interface {
-	// Ping проверяет доступность базы данных.
-	// Возвращает ошибку, если подключение недоступно.
-	Ping() error
+// Без вызова WithAnalytics переходы не регистрируются. Статистика
+// переходов выдается сервисом (см. service.ShortenerService.WithClickStats).
+func (h *Handler) WithAnalytics(recorder ClickRecorder) *Handler {
+	h.clicks = recorder
+	return h
 }
 
-// Handler содержит обработчики HTTP запросов для сервиса сокращения URL.
+// WithAuthMode задает режим проверки аутентификации маршрутов /api/user.
 //
-// Структура инкапсулирует зависимости: хранилище URL, сервис сокращения
-// и интерфейс для проверки БД. Все обработчики являются методами этой структуры.
-type Handler struct {
-	storage   URLStorage
-	shortener URLShortener
-	db        Pinger
+// По умолчанию используется auth.ModeStrict. Режим auth.ModeLegacy
+// предназначен только для совместимости со старыми автотестами.
+func (h *Handler) WithAuthMode(mode auth.Mode) *Handler {
+	h.authMode = mode
+	return h
 }
 
-// New создает новый экземпляр обработчика HTTP запросов.
-//
-// Параметры:
-//   - storage: реализация интерфейса URLStorage для хранения URL
-//   - shortener: реализация интерфейса URLShortener для сокращения URL
-//   - db: реализация интерфейса Pinger для проверки БД (может быть nil)
+// WithRateLimiter подключает ограничение частоты запросов.
 //
-// Возвращает готовый к использованию Handler.
+// Без вызова WithRateLimiter запросы не ограничиваются.
+func (h *Handler) WithRateLimiter(limiter *ratelimit.Limiter) *Handler {
+	h.limiter = limiter
+	return h
+}
+
+// WithTrustedProxies задает прокси, от которых принимаются заголовки
+// X-Real-IP и X-Forwarded-For при определении IP клиента для ограничения
+// частоты запросов и проверки доверенной подсети (см. middleware.ClientIP).
 //
-// Пример использования:
+// Без вызова WithTrustedProxies клиент определяется адресом соединения.
+func (h *Handler) WithTrustedProxies(proxies []netip.Prefix) *Handler {
+	h.trustedProxies = proxies
+	return h
+}
+
+// WithImportLimits ограничивает импорт URL пользователя размером тела
+// запроса maxBytes и количеством строк maxRows (0 - без ограничения).
 //
-//	storage := memory.NewMemoryStorage("")
-//	shortener := shortener.New("http://localhost:8080")
-//	handler := handlers.New(storage, shortener, nil)
-func New(storage URLStorage, shortener URLShortener, db Pinger) *Handler {
-	return &Handler{
-		storage:   storage,
-		shortener: shortener,
-		db:        db,
-	}
+// Без вызова WithImportLimits размер импорта не ограничивается.
+func (h *Handler) WithImportLimits(maxBytes int64, maxRows int) *Handler {
+	h.importMaxBytes = maxBytes
+	h.importMaxRows = maxRows
+	return h
 }
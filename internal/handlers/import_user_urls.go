@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Adigezalov/shortener/internal/bulk"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/ratelimit"
+	"go.uber.org/zap"
+)
+
+// importBatchRoute - маршрут, по правилу которого ограничивается частота
+// записи пакетов импорта: пакет из bulk.ImportBatchSize строк учитывается
+// как пакетное сокращение
+const importBatchRoute = "POST /api/shorten/batch"
+
+// errImportRateLimited прерывает импорт при превышении ограничения частоты
+var errImportRateLimited = errors.New("слишком много запросов")
+
+// ImportUserURLs импортирует URL пользователя из CSV или JSONL.
+//
+// Эндпоинт: POST /api/user/urls/import[?format=csv|jsonl]
+// Формат задается параметром format или заголовком Content-Type
+// (text/csv, application/x-ndjson). Строка содержит url и необязательные
+// alias и expires_at (см. bulk.Reader). Тело запроса читается потоком,
+// а результаты строк отправляются по мере записи пакетов.
+//
+// Размер тела и количество строк ограничиваются WithImportLimits, а каждый
+// пакет строк забирает токен ограничения частоты маршрута importBatchRoute,
+// поэтому импорт не позволяет создать больше ссылок, чем пакетное сокращение.
+// Если предел достигнут до отправки первых результатов, возвращается код
+// ошибки, иначе ответ завершается строкой со статусом aborted.
+//
+// Ответы:
+//   - 200 OK: JSONL с models.ImportResult для каждой строки; при сбое
+//     хранилища или превышении ограничения ответ завершается строкой
+//     со статусом aborted
+//   - 400 Bad Request: формат не указан, заголовок CSV без столбца url
+//     или превышено количество строк
+//   - 401 Unauthorized: пользователь не определен
+//   - 413 Request Entity Too Large: превышен размер тела запроса
+//   - 429 Too Many Requests: превышено ограничение частоты пакетов
+func (h *Handler) ImportUserURLs(w http.ResponseWriter, r *http.Request) {
+	// Получаем ID пользователя из контекста
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	format, err := importFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body := r.Body
+	if h.importMaxBytes > 0 {
+		if r.ContentLength > h.importMaxBytes {
+			http.Error(w, importTooLarge(h.importMaxBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		body = http.MaxBytesReader(w, r.Body, h.importMaxBytes)
+	}
+
+	reader, err := bulk.NewReader(body, format)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, importTooLarge(maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Результаты отправляются до окончания чтения тела запроса. HTTP/2
+	// позволяет это всегда, для HTTP/1.1 режим включается явно
+	_ = http.NewResponseController(w).EnableFullDuplex()
+
+	// Заголовок ответа отправляется с первым результатом, чтобы ошибка,
+	// возникшая раньше, вернулась кодом ответа
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	started := false
+	start := func() {
+		if !started {
+			w.Header().Set("Content-Type", bulk.FormatJSONL.ContentType())
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+	}
+
+	var limited ratelimit.Result
+	opts := bulk.ImportOptions{
+		MaxRows: h.importMaxRows,
+		BeforeBatch: func(ctx context.Context, rows int) error {
+			res, ok := middleware.ChargeRateLimit(h.limiter, r, importBatchRoute)
+			if ok && !res.Allowed {
+				limited = res
+				return errImportRateLimited
+			}
+			return nil
+		},
+	}
+	summary, err := bulk.Import(r.Context(), h.service, userID, reader, opts, func(result models.ImportResult) error {
+		start()
+		return encoder.Encode(result)
+	})
+	if err != nil {
+		logger.Ctx(r.Context()).Error("Импорт URL прерван",
+			zap.String("user_id", userID),
+			zap.Error(err))
+
+		status, message := importErrorStatus(err)
+		if !started {
+			if errors.Is(err, errImportRateLimited) {
+				for name, value := range limited.Headers() {
+					w.Header().Set(name, value)
+				}
+			}
+			http.Error(w, message, status)
+			return
+		}
+		encoder.Encode(models.ImportResult{Status: bulk.StatusAborted, Error: message})
+	}
+	start()
+	if err := buffered.Flush(); err != nil {
+		logger.Ctx(r.Context()).Error("Ошибка отправки результатов импорта",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return
+	}
+
+	logger.Ctx(r.Context()).Info("URL пользователя импортированы",
+		zap.String("user_id", userID),
+		zap.Int("created", summary.Created),
+		zap.Int("existing", summary.Existing),
+		zap.Int("failed", summary.Failed))
+}
+
+// importErrorStatus возвращает код ответа и сообщение для ошибки,
+// прервавшей импорт
+func importErrorStatus(err error) (int, string) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge, importTooLarge(maxBytesErr.Limit)
+	case errors.Is(err, errImportRateLimited):
+		return http.StatusTooManyRequests, errImportRateLimited.Error()
+	case errors.Is(err, bulk.ErrTooManyRows), errors.Is(err, bulk.ErrInvalidData):
+		return http.StatusBadRequest, err.Error()
+	default:
+		return http.StatusInternalServerError, "ошибка сохранения URL"
+	}
+}
+
+// importTooLarge возвращает сообщение о превышении размера импорта
+func importTooLarge(limit int64) string {
+	return fmt.Sprintf("размер импорта превышает %d байт", limit)
+}
+
+// importFormat определяет формат импорта по параметру format
+// или заголовку Content-Type
+func importFormat(r *http.Request) (bulk.Format, error) {
+	if value := r.URL.Query().Get("format"); value != "" {
+		return bulk.ParseFormat(value)
+	}
+	if format, ok := bulk.FormatFromContentType(r.Header.Get("Content-Type")); ok {
+		return format, nil
+	}
+	return "", errors.New("не указан формат: параметр format=csv|jsonl или Content-Type text/csv, application/x-ndjson")
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/bulk"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/ratelimit"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// importResults разбирает JSONL-ответ импорта
+func importResults(t *testing.T, rec *http.Response) []models.ImportResult {
+	t.Helper()
+	var results []models.ImportResult
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var result models.ImportResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &result))
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestRouter_ImportUserURLs(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+	c := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	cookie := auth.CookieName + "=" + c.anonymous("https://example.com/existing")
+
+	// CSV: формат по Content-Type, результат для каждой строки
+	rec := c.do(http.MethodPost, "/api/user/urls/import",
+		"url,alias\nhttps://example.com/1,my-link\nhttps://example.com/existing,\nftp://example.com/2,\n",
+		"Content-Type", "text/csv", "Cookie", cookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	results := importResults(t, rec.Result())
+	require.Len(t, results, 3)
+	assert.Equal(t, models.ImportResult{
+		Line:        2,
+		OriginalURL: "https://example.com/1",
+		ShortURL:    "http://localhost:8080/my-link",
+		Status:      string(storage.RecordCreated),
+	}, results[0])
+	assert.Equal(t, string(storage.RecordExisting), results[1].Status)
+	assert.Equal(t, string(storage.RecordFailed), results[2].Status)
+	assert.Equal(t, "scheme_not_allowed", results[2].ErrorCode)
+
+	// JSONL: формат по параметру format
+	rec = c.do(http.MethodPost, "/api/user/urls/import?format=jsonl",
+		`{"url":"https://example.com/3"}`+"\n"+`not json`+"\n", "Cookie", cookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	results = importResults(t, rec.Result())
+	require.Len(t, results, 2)
+	assert.Equal(t, string(storage.RecordCreated), results[0].Status)
+	assert.Equal(t, models.ImportResult{Line: 2, Status: string(storage.RecordFailed), Error: results[1].Error}, results[1])
+
+	// Импортированные URL принадлежат пользователю
+	assert.Len(t, c.userURLs("Cookie", cookie), 3)
+
+	// Слишком длинная строка до отправки результатов возвращает 400,
+	// после отправки - завершает ответ строкой aborted
+	rec = c.do(http.MethodPost, "/api/user/urls/import?format=jsonl",
+		strings.Repeat("x", bulk.MaxLineSize+1), "Cookie", cookie)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var input strings.Builder
+	for i := 0; i < bulk.ImportBatchSize; i++ {
+		fmt.Fprintf(&input, `{"url":"https://example.com/long/%d"}`+"\n", i)
+	}
+	input.WriteString(strings.Repeat("x", bulk.MaxLineSize+1))
+	rec = c.do(http.MethodPost, "/api/user/urls/import?format=jsonl", input.String(), "Cookie", cookie)
+	require.Equal(t, http.StatusOK, rec.Code)
+	results = importResults(t, rec.Result())
+	require.Len(t, results, bulk.ImportBatchSize+1)
+	assert.Equal(t, bulk.StatusAborted, results[bulk.ImportBatchSize].Status)
+
+	for _, tt := range []struct {
+		path, body, contentType string
+	}{
+		{"/api/user/urls/import", "url\nhttps://example.com\n", "application/json"},
+		{"/api/user/urls/import?format=xml", "url\nhttps://example.com\n", "text/csv"},
+		{"/api/user/urls/import?format=csv", "alias\nmy-link\n", "text/csv"},
+	} {
+		rec := c.do(http.MethodPost, tt.path, tt.body, "Content-Type", tt.contentType, "Cookie", cookie)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, tt.path)
+	}
+}
+
+func TestRouter_ImportUserURLs_Limits(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+	cookie := auth.CookieName + "=" + auth.SignUserID("user1")
+
+	rows := func(count int) string {
+		var input strings.Builder
+		input.WriteString("url\n")
+		for i := 0; i < count; i++ {
+			fmt.Fprintf(&input, "https://example.com/%d\n", i)
+		}
+		return input.String()
+	}
+	importCSV := func(c authClient, body string) *httptest.ResponseRecorder {
+		return c.do(http.MethodPost, "/api/user/urls/import", body, "Content-Type", "text/csv", "Cookie", cookie)
+	}
+
+	// Превышение размера тела и количества строк отклоняется до записи
+	c := authClient{t: t, router: NewRouter(New(svc).WithImportLimits(64<<10, 10), "")}
+	rec := importCSV(c, "url\nhttps://example.com/"+strings.Repeat("x", 64<<10)+"\n")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	rec = importCSV(c, rows(11))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, c.userURLs("Cookie", cookie))
+	rec = importCSV(c, rows(10))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Каждый пакет забирает токен пакетного сокращения: второй пакет
+	// превышает ограничение, и ответ завершается строкой aborted
+	rules, err := ratelimit.ParseRules("POST /api/shorten/batch=1/h")
+	require.NoError(t, err)
+	h := New(svc).WithRateLimiter(ratelimit.New(rules, ratelimit.NewMemoryStore()))
+	c = authClient{t: t, router: NewRouter(h, "")}
+	rec = importCSV(c, rows(bulk.ImportBatchSize+1))
+	require.Equal(t, http.StatusOK, rec.Code)
+	results := importResults(t, rec.Result())
+	require.Len(t, results, bulk.ImportBatchSize+1)
+	assert.Equal(t, bulk.StatusAborted, results[bulk.ImportBatchSize].Status)
+	assert.Len(t, c.userURLs("Cookie", cookie), bulk.ImportBatchSize)
+
+	// Пакетное сокращение и импорт расходуют одну корзину
+	rec = importCSV(c, rows(1))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(ratelimit.HeaderRetryAfter))
+	rec = c.do(http.MethodPost, "/api/shorten/batch",
+		`[{"correlation_id":"1","original_url":"https://example.com/batch"}]`, "Cookie", cookie)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
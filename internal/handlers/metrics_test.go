@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRouter_Metrics(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+	// Запросы httptest приходят с адреса 192.0.2.1, он считается прокси
+	proxies, err := middleware.ParseTrustedProxies("192.0.2.0/24")
+	require.NoError(t, err)
+	r := NewRouter(New(svc).WithTrustedProxies(proxies), "192.168.1.0/24")
+
+	// Запрос к несуществующей ссылке учитывается по шаблону маршрута
+	req := httptest.NewRequest(http.MethodGet, "/missing1", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	t.Run("Доверенный_IP", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("X-Real-IP", "192.168.1.10")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+		body := rec.Body.String()
+		assert.Contains(t, body, `shortener_http_requests_total{method="GET",route="/{id}",status="404"}`)
+		assert.Contains(t, body, `shortener_http_request_duration_seconds_count{method="GET",route="/{id}"}`)
+		assert.NotContains(t, body, "/missing1")
+	})
+
+	t.Run("Недоверенный_IP", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("X-Real-IP", "10.0.0.1")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("X-Real-IP_не_от_прокси", func(t *testing.T) {
+		// Без доверенных прокси заголовок задает клиент, он игнорируется
+		r := NewRouter(New(svc), "192.168.1.0/24")
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("X-Real-IP", "192.168.1.10")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"time"
+
 	"github.com/Adigezalov/shortener/internal/models"
 	"github.com/Adigezalov/shortener/internal/storage"
 	"github.com/stretchr/testify/mock"
@@ -11,47 +14,47 @@ type MockURLStorage struct {
 	mock.Mock
 }
 
-func (m *MockURLStorage) Add(id, url string) (string, bool, error) {
-	args := m.Called(id, url)
-	return args.String(0), args.Bool(1), args.Error(2)
-}
-
-func (m *MockURLStorage) AddWithUser(id, url, userID string) (string, bool, error) {
-	args := m.Called(id, url, userID)
-	return args.String(0), args.Bool(1), args.Error(2)
+func (m *MockURLStorage) Add(ctx context.Context, id, url, userID string, expiresAt time.Time) (string, error) {
+	args := m.Called(id, url, userID, expiresAt)
+	return args.String(0), args.Error(1)
 }
 
-func (m *MockURLStorage) Get(id string) (string, bool) {
+func (m *MockURLStorage) Get(ctx context.Context, id string) (string, error) {
 	args := m.Called(id)
-	return args.String(0), args.Bool(1)
+	return args.String(0), args.Error(1)
 }
 
-func (m *MockURLStorage) FindByOriginalURL(url string) (string, bool) {
-	args := m.Called(url)
-	return args.String(0), args.Bool(1)
+func (m *MockURLStorage) FindByOriginalURL(ctx context.Context, url string, userID string) (string, error) {
+	args := m.Called(url, userID)
+	return args.String(0), args.Error(1)
 }
 
-func (m *MockURLStorage) GetUserURLs(userID string) ([]models.UserURL, error) {
+func (m *MockURLStorage) GetUserURLs(ctx context.Context, userID string) ([]models.UserURL, error) {
 	args := m.Called(userID)
 	return args.Get(0).([]models.UserURL), args.Error(1)
 }
 
+func (m *MockURLStorage) ListUserURLs(ctx context.Context, userID string, query storage.UserURLQuery) (storage.UserURLPage, error) {
+	args := m.Called(userID, query)
+	return args.Get(0).(storage.UserURLPage), args.Error(1)
+}
+
 func (m *MockURLStorage) Close() error {
 	args := m.Called()
 	return args.Error(0)
 }
 
-func (m *MockURLStorage) DeleteUserURLs(userID string, shortURLs []string) error {
+func (m *MockURLStorage) DeleteUserURLs(ctx context.Context, userID string, shortURLs []string) error {
 	args := m.Called(userID, shortURLs)
 	return args.Error(0)
 }
 
-func (m *MockURLStorage) IsDeleted(shortURL string) (bool, error) {
-	args := m.Called(shortURL)
-	return args.Bool(0), args.Error(1)
+func (m *MockURLStorage) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
 }
 
-func (m *MockURLStorage) Stats() (storage.Stats, error) {
+func (m *MockURLStorage) Stats(ctx context.Context) (storage.Stats, error) {
 	args := m.Called()
 	return args.Get(0).(storage.Stats), args.Error(1)
 }
@@ -80,3 +83,22 @@ func (m *MockPinger) Ping() error {
 	args := m.Called()
 	return args.Error(0)
 }
+
+// MockClickRecorder - мок для регистратора переходов
+type MockClickRecorder struct {
+	mock.Mock
+}
+
+func (m *MockClickRecorder) Record(click models.Click) {
+	m.Called(click)
+}
+
+// MockClickStatsProvider - мок для источника статистики переходов
+type MockClickStatsProvider struct {
+	mock.Mock
+}
+
+func (m *MockClickStatsProvider) GetClickStats(userID, shortURL string, since time.Time) (models.URLStats, bool, error) {
+	args := m.Called(userID, shortURL, since)
+	return args.Get(0).(models.URLStats), args.Bool(1), args.Error(2)
+}
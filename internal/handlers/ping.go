@@ -1,18 +1,23 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+
+	"github.com/Adigezalov/shortener/internal/service"
 )
 
 // PingDB проверяет подключение к базе данных
 func (h *Handler) PingDB(w http.ResponseWriter, r *http.Request) {
+	err := h.service.PingDB()
+
 	// Если база данных не настроена, возвращаем OK
-	if h.db == nil {
+	if errors.Is(err, service.ErrDBNotConfigured) {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	if err := h.db.Ping(); err != nil {
+	if err != nil {
 		http.Error(w, "Database connection error", http.StatusInternalServerError)
 		return
 	}
@@ -1,17 +1,20 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Adigezalov/shortener/internal/service"
+
 	"github.com/stretchr/testify/assert"
 )
 
 func TestHandler_PingDB(t *testing.T) {
 	tests := []struct {
 		name           string
-		db             Pinger
+		db             service.Pinger
 		expectedStatus int
 		prepareMock    func(p *MockPinger)
 	}{
@@ -20,6 +23,14 @@ func TestHandler_PingDB(t *testing.T) {
 			db:             nil,
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "база_данных_недоступна",
+			db:             &MockPinger{},
+			expectedStatus: http.StatusInternalServerError,
+			prepareMock: func(p *MockPinger) {
+				p.On("Ping").Return(errors.New("connection refused"))
+			},
+		},
 		{
 			name:           "база_данных_работает",
 			db:             &MockPinger{},
@@ -36,9 +47,7 @@ func TestHandler_PingDB(t *testing.T) {
 				tt.prepareMock(mock)
 			}
 
-			h := &Handler{
-				db: tt.db,
-			}
+			h := New(service.NewShortenerService(nil, nil, tt.db))
 
 			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
 			w := httptest.NewRecorder()
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/Adigezalov/shortener/internal/ratelimit"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRouter_RateLimit(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	previousCost := auth.PasswordHashCost
+	auth.PasswordHashCost = bcrypt.MinCost
+	t.Cleanup(func() { auth.PasswordHashCost = previousCost })
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil).WithAccounts(store)
+	account, err := svc.Register(context.Background(), "alice", "correct horse", "")
+	require.NoError(t, err)
+
+	rules, err := ratelimit.ParseRules("POST /api/shorten=2/h; /api/user/urls=1/h; /{id}=1/h by=ip")
+	require.NoError(t, err)
+
+	// Запросы httptest приходят с адреса 192.0.2.1, он считается прокси
+	proxies, err := middleware.ParseTrustedProxies("192.0.2.0/24")
+	require.NoError(t, err)
+	h := New(svc).
+		WithRateLimiter(ratelimit.New(rules, ratelimit.NewMemoryStore())).
+		WithTrustedProxies(proxies)
+	c := authClient{t: t, router: NewRouter(h, "")}
+
+	// Анонимные запросы без куки ограничиваются по IP из X-Forwarded-For
+	shorten := func(url string, headers ...string) int {
+		rec := c.do(http.MethodPost, "/api/shorten", `{"url":"`+url+`"}`, headers...)
+		if rec.Code != http.StatusTooManyRequests {
+			assert.NotEmpty(t, rec.Header().Get(ratelimit.HeaderRemaining))
+		}
+		return rec.Code
+	}
+	ip := []string{"X-Forwarded-For", "203.0.113.1"}
+	assert.Equal(t, http.StatusCreated, shorten("https://example.com/1", ip...))
+	assert.Equal(t, http.StatusCreated, shorten("https://example.com/2", ip...))
+
+	rec := c.do(http.MethodPost, "/api/shorten", `{"url":"https://example.com/3"}`, ip...)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(ratelimit.HeaderLimit))
+	assert.Equal(t, "0", rec.Header().Get(ratelimit.HeaderRemaining))
+	assert.Equal(t, "1800", rec.Header().Get(ratelimit.HeaderRetryAfter))
+	assert.Equal(t, "2;w=3600;burst=2", rec.Header().Get(ratelimit.HeaderPolicy))
+
+	// Адрес, дописанный клиентом левее, не меняет корзину
+	rec = c.do(http.MethodPost, "/api/shorten", `{"url":"https://example.com/3"}`,
+		"X-Forwarded-For", "198.51.100.7, 203.0.113.1, 192.0.2.10")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Другой IP и учетная запись имеют свои корзины;
+	// X-Real-IP от доверенного прокси важнее X-Forwarded-For
+	assert.Equal(t, http.StatusCreated, shorten("https://example.com/4", "X-Forwarded-For", "203.0.113.2"))
+	assert.Equal(t, http.StatusCreated, shorten("https://example.com/6", append(ip, "X-Real-IP", "203.0.113.4")...))
+	session := []string{"Authorization", "Bearer " + account.Token}
+	assert.Equal(t, http.StatusCreated, shorten("https://example.com/5", append(ip, session...)...))
+
+	// Шаблон без метода ограничивает маршрут /api/user/urls
+	rec = c.do(http.MethodGet, "/api/user/urls", "", session...)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = c.do(http.MethodGet, "/api/user/urls", "", session...)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Правило by=ip не различает пользователей
+	rec = c.do(http.MethodGet, "/missing", "", session...)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = c.do(http.MethodGet, "/missing", "", "Cookie", auth.CookieName+"="+auth.SignUserID("user2"))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Маршрут без правила не ограничивается
+	rec = c.do(http.MethodGet, "/ping", "")
+	assert.Empty(t, rec.Header().Get(ratelimit.HeaderLimit))
+}
+
+func TestRouter_RateLimitIgnoresUntrustedForwardedFor(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+
+	rules, err := ratelimit.ParseRules("POST /api/shorten=1/h by=ip")
+	require.NoError(t, err)
+	h := New(svc).WithRateLimiter(ratelimit.New(rules, ratelimit.NewMemoryStore()))
+	c := authClient{t: t, router: NewRouter(h, "")}
+
+	// Без доверенных прокси клиент определяется адресом соединения,
+	// подставленные заголовки не дают новую корзину
+	rec := c.do(http.MethodPost, "/api/shorten", `{"url":"https://example.com/1"}`,
+		"X-Forwarded-For", "203.0.113.1")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = c.do(http.MethodPost, "/api/shorten", `{"url":"https://example.com/2"}`,
+		"X-Forwarded-For", "203.0.113.2")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	rec = c.do(http.MethodPost, "/api/shorten", `{"url":"https://example.com/3"}`,
+		"X-Real-IP", "203.0.113.3")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestRouter_RateLimitRotatedAnonymousIDs(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+
+	rules, err := ratelimit.ParseRules("POST /api/shorten/batch=2/h; *=3/h")
+	require.NoError(t, err)
+	h := New(svc).WithRateLimiter(ratelimit.New(rules, ratelimit.NewMemoryStore()))
+	c := authClient{t: t, router: NewRouter(h, "")}
+
+	// Подписанный анонимный ID выдается на каждый запрос, поэтому новая
+	// кука или запрос без куки не дают новую корзину правила by=user
+	batch := `[{"correlation_id":"1","original_url":"https://example.com/batch"}]`
+	rec := c.do(http.MethodPost, "/api/shorten/batch", batch)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = c.do(http.MethodPost, "/api/shorten/batch", batch,
+		"Cookie", auth.CookieName+"="+auth.SignUserID(auth.GenerateUserID()))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = c.do(http.MethodPost, "/api/shorten/batch", batch,
+		"Cookie", auth.CookieName+"="+auth.SignUserID(auth.GenerateUserID()))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// То же для правила по умолчанию
+	for i := range 4 {
+		rec = c.do(http.MethodGet, "/api/user/urls", "",
+			"Cookie", auth.CookieName+"="+auth.SignUserID(auth.GenerateUserID()))
+		if i < 3 {
+			assert.NotEqual(t, http.StatusTooManyRequests, rec.Code)
+		} else {
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		}
+	}
+}
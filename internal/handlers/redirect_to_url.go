@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/Adigezalov/shortener/internal/analytics"
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -17,35 +18,46 @@ func (h *Handler) RedirectToURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Проверяем, не удален ли URL
-	isDeleted, err := h.storage.IsDeleted(id)
-	if err != nil {
-		logger.Logger.Error("Ошибка проверки статуса удаления URL",
+	// Ищем оригинальный URL
+	result := h.service.GetOriginalURL(r.Context(), id)
+	switch {
+	case result.Error != nil:
+		logger.Ctx(r.Context()).Error("Ошибка получения URL",
 			zap.String("id", id),
-			zap.Error(err))
+			zap.Error(result.Error))
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
-	}
-
-	if isDeleted {
-		logger.Logger.Info("Попытка доступа к удаленному URL",
+	case !result.Found:
+		http.Error(w, "URL не найден", http.StatusNotFound)
+		return
+	case result.Expired:
+		logger.Ctx(r.Context()).Info("Попытка доступа к URL с истекшим сроком действия",
+			zap.String("id", id))
+		http.Error(w, "Gone", http.StatusGone)
+		return
+	case result.Disabled:
+		logger.Ctx(r.Context()).Info("Попытка доступа к отключенному URL",
+			zap.String("id", id))
+		http.Error(w, "Gone", http.StatusGone)
+		return
+	case result.Deleted:
+		logger.Ctx(r.Context()).Info("Попытка доступа к удаленному URL",
 			zap.String("id", id))
 		http.Error(w, "Gone", http.StatusGone)
 		return
 	}
+	originalURL := result.OriginalURL
 
-	// Ищем оригинальный URL в хранилище
-	originalURL, found := h.storage.Get(id)
-	if !found {
-		http.Error(w, "URL не найден", http.StatusNotFound)
-		return
+	// Регистрируем переход для статистики
+	if h.clicks != nil {
+		h.clicks.Record(analytics.NewClick(id, r))
 	}
 
 	// Перенаправляем на оригинальный URL
 	w.Header().Set("Location", originalURL)
 	w.WriteHeader(http.StatusTemporaryRedirect)
 
-	logger.Logger.Info("Перенаправление по короткому URL",
+	logger.Ctx(r.Context()).Info("Перенаправление по короткому URL",
 		zap.String("id", id),
 		zap.String("original_url", originalURL),
 	)
@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 )
 
@@ -31,8 +36,7 @@ func TestHandler_RedirectToURL(t *testing.T) {
 			name:  "Успешное_перенаправление",
 			urlID: "abc123",
 			mockSetup: func(ms *MockURLStorage) {
-				ms.On("IsDeleted", "abc123").Return(false, nil)
-				ms.On("Get", "abc123").Return("https://example.com", true)
+				ms.On("Get", "abc123").Return("https://example.com", nil)
 			},
 			expectedStatus: http.StatusTemporaryRedirect,
 			expectedURL:    "https://example.com",
@@ -41,7 +45,16 @@ func TestHandler_RedirectToURL(t *testing.T) {
 			name:  "URL_удален",
 			urlID: "deleted123",
 			mockSetup: func(ms *MockURLStorage) {
-				ms.On("IsDeleted", "deleted123").Return(true, nil)
+				ms.On("Get", "deleted123").Return("", storage.ErrGone)
+			},
+			expectedStatus: http.StatusGone,
+			expectedURL:    "",
+		},
+		{
+			name:  "Срок_действия_URL_истек",
+			urlID: "expired123",
+			mockSetup: func(ms *MockURLStorage) {
+				ms.On("Get", "expired123").Return("", storage.ErrExpired)
 			},
 			expectedStatus: http.StatusGone,
 			expectedURL:    "",
@@ -50,12 +63,20 @@ func TestHandler_RedirectToURL(t *testing.T) {
 			name:  "URL_не_найден",
 			urlID: "notfound",
 			mockSetup: func(ms *MockURLStorage) {
-				ms.On("IsDeleted", "notfound").Return(false, nil)
-				ms.On("Get", "notfound").Return("", false)
+				ms.On("Get", "notfound").Return("", storage.ErrNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedURL:    "",
 		},
+		{
+			name:  "Сбой_хранилища",
+			urlID: "abc123",
+			mockSetup: func(ms *MockURLStorage) {
+				ms.On("Get", "abc123").Return("", errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedURL:    "",
+		},
 		{
 			name:           "Некорректный_ID",
 			urlID:          "//", // Некорректный ID, который вызовет 404 в Chi router
@@ -74,9 +95,7 @@ func TestHandler_RedirectToURL(t *testing.T) {
 			tt.mockSetup(mockStorage)
 
 			// Создаем обработчик с моком
-			handler := &Handler{
-				storage: mockStorage,
-			}
+			handler := New(service.NewShortenerService(mockStorage, nil, nil))
 
 			// Создаем роутер Chi для тестирования с параметрами URL
 			r := chi.NewRouter()
@@ -101,3 +120,30 @@ func TestHandler_RedirectToURL(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_RedirectToURL_RecordsClick(t *testing.T) {
+	mockStorage := new(MockURLStorage)
+	mockStorage.On("Get", "abc123").Return("https://example.com", nil)
+
+	mockRecorder := new(MockClickRecorder)
+	mockRecorder.On("Record", mock.MatchedBy(func(click models.Click) bool {
+		return click.ShortID == "abc123" &&
+			click.Referrer == "t.me" &&
+			click.UserAgentFamily == "Firefox"
+	})).Return()
+
+	handler := New(service.NewShortenerService(mockStorage, nil, nil)).WithAnalytics(mockRecorder)
+
+	r := chi.NewRouter()
+	r.Get("/{id}", handler.RedirectToURL)
+
+	req := httptest.NewRequest("GET", "/abc123", nil)
+	req.Header.Set("Referer", "https://t.me/channel/42")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	mockRecorder.AssertExpectations(t)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/metrics"
+	customMiddleware "github.com/Adigezalov/shortener/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// NewRouter создает chi роутер со всеми маршрутами HTTP API.
+//
+// Параметр trustedSubnet ограничивает доступ к внутренним эндпоинтам
+// /api/internal/* и метрикам /metrics (пустая строка запрещает доступ).
+func NewRouter(h *Handler, trustedSubnet string) chi.Router {
+	r := chi.NewRouter()
+
+	// Добавляем глобальные middleware
+	r.Use(middleware.CleanPath)
+	r.Use(customMiddleware.LoggingRecoverer)
+	r.Use(customMiddleware.Tracing)
+	r.Use(customMiddleware.WithRequestID)
+	r.Use(customMiddleware.RequestLogger)
+	r.Use(customMiddleware.GzipMiddleware)
+	r.Use(customMiddleware.Authentication(h.service))  // Анонимные ID, сессии и API-ключи
+	r.Use(customMiddleware.ClientIP(h.trustedProxies)) // IP клиента с учетом доверенных прокси
+	r.Use(customMiddleware.RateLimit(h.limiter))       // Ограничение частоты по маршруту и клиенту
+
+	// Определяем маршруты
+	r.Get("/ping", h.PingDB)
+	r.With(customMiddleware.TextPlainContentTypeMiddleware()).Post("/", h.CreateShortURL)
+	r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/api/shorten", h.ShortenURL)
+	r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/api/shorten/batch", h.ShortenBatch)
+	r.Get("/{id}", h.RedirectToURL)
+
+	// Метрики Prometheus доступны только из доверенной подсети
+	r.With(customMiddleware.IPAuthMiddleware(trustedSubnet)).Handle("/metrics", metrics.Handler())
+
+	// Учетные записи: регистрация, вход и API-ключи
+	r.Route("/api/auth", func(r chi.Router) {
+		r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/register", h.Register)
+		r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/login", h.Login)
+
+		r.Group(func(r chi.Router) {
+			r.Use(customMiddleware.RequireAccount)
+			r.Post("/logout", h.Logout)
+			r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/claim", h.Claim)
+			r.Get("/keys", h.ListAPIKeys)
+			r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/keys", h.CreateAPIKey)
+			r.Delete("/keys/{id}", h.RevokeAPIKey)
+		})
+	})
+
+	// Маршруты, требующие аутентификации
+	r.Route("/api/user", func(r chi.Router) {
+		r.Use(customMiddleware.RequireAuthMode(h.authMode))
+		r.Get("/urls", h.GetUserURLs)
+		r.Delete("/urls", h.DeleteUserURLs)
+		r.Post("/urls/import", h.ImportUserURLs)
+		r.Get("/urls/export", h.ExportUserURLs)
+		r.Get("/urls/{id}/stats", h.GetURLStats)
+	})
+
+	// Модерация ссылок: только для учетных записей с ролью admin
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(customMiddleware.RequireRole(h.service, auth.RoleAdmin))
+		r.Get("/links", h.SearchLinks)
+		r.Get("/links/{id}", h.GetLink)
+		r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/links/disable", h.DisableLinks)
+		r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/links/enable", h.EnableLinks)
+		r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/links/delete", h.PurgeLinks)
+		r.With(customMiddleware.JSONContentTypeMiddleware()).Post("/links/reassign", h.ReassignLinks)
+		r.With(customMiddleware.JSONContentTypeMiddleware()).Put("/accounts/{login}/role", h.SetAccountRole)
+	})
+
+	// Внутренние маршруты с проверкой IP: статистика сервиса
+	// и ручное компактирование журнала файлового хранилища
+	r.Route("/api/internal", func(r chi.Router) {
+		r.Use(customMiddleware.IPAuthMiddleware(trustedSubnet))
+		r.Get("/stats", h.GetStats)
+		r.Post("/compact", h.CompactStorage)
+	})
+
+	return r
+}
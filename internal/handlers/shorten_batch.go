@@ -2,17 +2,25 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
 	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
 	"go.uber.org/zap"
-
-	"github.com/Adigezalov/shortener/internal/database"
 )
 
-// ShortenBatch обрабатывает POST запрос на пакетное создание сокращенных URL
+// ShortenBatch обрабатывает POST запрос на пакетное создание сокращенных URL.
+//
+// Псевдонимы и сроки действия всех элементов проверяются сервисом до записи,
+// некорректный элемент возвращает 400 Bad Request. Для каждого элемента ответ
+// содержит статус: created, existing или error (например, занятый псевдоним,
+// пустой URL или URL, нарушающий политику, - с кодом нарушения в error_code).
+// Сбой хранилища возвращает 500 Internal Server Error.
 func (h *Handler) ShortenBatch(w http.ResponseWriter, r *http.Request) {
 	// Читаем запрос
 	var request []models.BatchShortenRequest
@@ -35,42 +43,52 @@ func (h *Handler) ShortenBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Создаем слайс для ответа
-	response := make([]models.BatchShortenResponse, 0, len(request))
-
-	// Обрабатываем каждый URL в батче
+	// Преобразуем запрос в элементы сервиса
+	items := make([]service.BatchItem, 0, len(request))
 	for _, item := range request {
-		// Проверяем URL
-		if item.OriginalURL == "" {
-			logger.Logger.Warn("Пустой URL в батче", zap.String("correlation_id", item.CorrelationID))
-			continue
-		}
-
-		// Генерируем новый ID и пытаемся добавить URL с привязкой к пользователю
-		id := h.shortener.Shorten(item.OriginalURL)
-		id, exists, err := h.storage.AddWithUser(id, item.OriginalURL, userID)
-		if err != nil && err != database.ErrURLConflict {
-			logger.Logger.Error("Ошибка добавления URL",
-				zap.String("correlation_id", item.CorrelationID),
-				zap.Error(err))
-			continue
-		}
-
-		// Строим полный короткий URL
-		shortURL := h.shortener.BuildShortURL(id)
-
-		// Добавляем результат в ответ
-		response = append(response, models.BatchShortenResponse{
+		items = append(items, service.BatchItem{
 			CorrelationID: item.CorrelationID,
-			ShortURL:      shortURL,
+			OriginalURL:   item.OriginalURL,
+			Alias:         item.Alias,
+			ExpiresAt:     item.ExpiresAt,
+			TTLSeconds:    item.TTLSeconds,
 		})
+	}
 
-		logger.Logger.Info("URL сокращен (Batch API)",
-			zap.String("correlation_id", item.CorrelationID),
-			zap.String("original_url", item.OriginalURL),
-			zap.String("short_url", shortURL),
-			zap.Bool("existing", exists),
-		)
+	results, err := h.service.CreateShortURLBatch(r.Context(), items, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, shortener.ErrInvalidAlias),
+			errors.Is(err, service.ErrDuplicateAlias),
+			errors.Is(err, service.ErrInvalidExpiration):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrAliasTaken):
+			// Занятый псевдоним - явная ошибка клиента, а не пропуск элемента
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			// Сбой хранилища не должен превращаться в молчаливый пропуск элемента
+			logger.Ctx(r.Context()).Error("Ошибка пакетного добавления URL", zap.Error(err))
+			http.Error(w, "Ошибка сохранения URL", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Формируем ответ
+	response := make([]models.BatchShortenResponse, 0, len(results))
+	for _, result := range results {
+		item := models.BatchShortenResponse{
+			CorrelationID: result.CorrelationID,
+			ShortURL:      result.ShortURL,
+			Status:        string(result.Status),
+		}
+		if result.Error != nil {
+			item.Error = result.Error.Error()
+		}
+		var policyErr *urlpolicy.Error
+		if errors.As(result.Error, &policyErr) {
+			item.ErrorCode = string(policyErr.Code)
+		}
+		response = append(response, item)
 	}
 
 	// Отправляем результат
@@ -78,8 +96,12 @@ func (h *Handler) ShortenBatch(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		logger.Logger.Error("Ошибка кодирования JSON", zap.Error(err))
+		logger.Ctx(r.Context()).Error("Ошибка кодирования JSON", zap.Error(err))
 		http.Error(w, "Ошибка формирования ответа", http.StatusInternalServerError)
 		return
 	}
+
+	logger.Ctx(r.Context()).Info("URL сокращены (Batch API)",
+		zap.String("user_id", userID),
+		zap.Int("count", len(response)))
 }
@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"github.com/Adigezalov/shortener/internal/database"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
 	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -48,12 +52,12 @@ func TestHandler_ShortenBatch(t *testing.T) {
 			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
 				// Первый URL
 				msh.On("Shorten", "https://example1.com").Return("abc123")
-				ms.On("AddWithUser", "abc123", "https://example1.com", "test-user").Return("abc123", false, nil)
+				ms.On("Add", "abc123", "https://example1.com", "test-user", time.Time{}).Return("abc123", nil)
 				msh.On("BuildShortURL", "abc123").Return("http://short.url/abc123")
 
 				// Второй URL
 				msh.On("Shorten", "https://example2.com").Return("def456")
-				ms.On("AddWithUser", "def456", "https://example2.com", "test-user").Return("def456", false, nil)
+				ms.On("Add", "def456", "https://example2.com", "test-user", time.Time{}).Return("def456", nil)
 				msh.On("BuildShortURL", "def456").Return("http://short.url/def456")
 			},
 			expectedStatus: http.StatusCreated,
@@ -61,10 +65,12 @@ func TestHandler_ShortenBatch(t *testing.T) {
 				{
 					CorrelationID: "1",
 					ShortURL:      "http://short.url/abc123",
+					Status:        "created",
 				},
 				{
 					CorrelationID: "2",
 					ShortURL:      "http://short.url/def456",
+					Status:        "created",
 				},
 			},
 		},
@@ -84,12 +90,12 @@ func TestHandler_ShortenBatch(t *testing.T) {
 			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
 				// Первый URL (существующий)
 				msh.On("Shorten", "https://example1.com").Return("existing123")
-				ms.On("AddWithUser", "existing123", "https://example1.com", "test-user").Return("existing123", true, nil)
+				ms.On("Add", "existing123", "https://example1.com", "test-user", time.Time{}).Return("existing123", database.ErrURLConflict)
 				msh.On("BuildShortURL", "existing123").Return("http://short.url/existing123")
 
 				// Второй URL (новый)
 				msh.On("Shorten", "https://example2.com").Return("def456")
-				ms.On("AddWithUser", "def456", "https://example2.com", "test-user").Return("def456", false, nil)
+				ms.On("Add", "def456", "https://example2.com", "test-user", time.Time{}).Return("def456", nil)
 				msh.On("BuildShortURL", "def456").Return("http://short.url/def456")
 			},
 			expectedStatus: http.StatusCreated,
@@ -97,13 +103,123 @@ func TestHandler_ShortenBatch(t *testing.T) {
 				{
 					CorrelationID: "1",
 					ShortURL:      "http://short.url/existing123",
+					Status:        "existing",
 				},
 				{
 					CorrelationID: "2",
 					ShortURL:      "http://short.url/def456",
+					Status:        "created",
 				},
 			},
 		},
+		{
+			name: "Ошибки_отдельных_элементов",
+			request: []models.BatchShortenRequest{
+				{
+					CorrelationID: "1",
+					OriginalURL:   "https://example1.com",
+					Alias:         "taken",
+				},
+				{
+					CorrelationID: "2",
+					OriginalURL:   "",
+				},
+				{
+					CorrelationID: "3",
+					OriginalURL:   "https://example3.com",
+				},
+			},
+			contentType: "application/json",
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				// Занятый псевдоним и пустой URL не прерывают обработку пакета
+				ms.On("Add", "taken", "https://example1.com", "test-user", time.Time{}).Return("", database.ErrShortIDConflict)
+				msh.On("Shorten", "https://example3.com").Return("ghi789")
+				ms.On("Add", "ghi789", "https://example3.com", "test-user", time.Time{}).Return("ghi789", nil)
+				msh.On("BuildShortURL", "ghi789").Return("http://short.url/ghi789")
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResult: []models.BatchShortenResponse{
+				{
+					CorrelationID: "1",
+					Status:        "error",
+					Error:         "псевдоним уже занят",
+				},
+				{
+					CorrelationID: "2",
+					Status:        "error",
+					Error:         "URL не может быть пустым",
+				},
+				{
+					CorrelationID: "3",
+					ShortURL:      "http://short.url/ghi789",
+					Status:        "created",
+				},
+			},
+		},
+		{
+			name: "Псевдоним_для_уже_сокращенного_URL",
+			request: []models.BatchShortenRequest{
+				{
+					CorrelationID: "1",
+					OriginalURL:   "https://example1.com",
+					Alias:         "first",
+				},
+			},
+			contentType: "application/json",
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				// Существующая ссылка без псевдонима не выдается за созданную
+				ms.On("Add", "first", "https://example1.com", "test-user", time.Time{}).Return("abc123", database.ErrURLConflict)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResult: []models.BatchShortenResponse{
+				{
+					CorrelationID: "1",
+					Status:        "error",
+					Error:         "URL уже сокращен под другим коротким ID",
+				},
+			},
+		},
+		{
+			name: "Повтор_при_коллизии_сгенерированного_ID",
+			request: []models.BatchShortenRequest{
+				{
+					CorrelationID: "1",
+					OriginalURL:   "https://example1.com",
+				},
+			},
+			contentType: "application/json",
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				msh.On("Shorten", "https://example1.com").Return("taken1").Once()
+				msh.On("Shorten", "https://example1.com").Return("free22").Once()
+				ms.On("Add", "taken1", "https://example1.com", "test-user", time.Time{}).Return("", database.ErrShortIDConflict)
+				ms.On("Add", "free22", "https://example1.com", "test-user", time.Time{}).Return("free22", nil)
+				msh.On("BuildShortURL", "free22").Return("http://short.url/free22")
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResult: []models.BatchShortenResponse{
+				{
+					CorrelationID: "1",
+					ShortURL:      "http://short.url/free22",
+					Status:        "created",
+				},
+			},
+		},
+		{
+			name: "Сбой_хранилища",
+			request: []models.BatchShortenRequest{
+				{
+					CorrelationID: "1",
+					OriginalURL:   "https://example1.com",
+				},
+			},
+			contentType: "application/json",
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				msh.On("Shorten", "https://example1.com").Return("abc123")
+				ms.On("Add", "abc123", "https://example1.com", "test-user", time.Time{}).Return("", errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResult: nil,
+		},
 		{
 			name:           "Пустой_список_URL",
 			request:        []models.BatchShortenRequest{},
@@ -124,10 +240,7 @@ func TestHandler_ShortenBatch(t *testing.T) {
 			tt.mockSetup(mockStorage, mockShortener)
 
 			// Создаем обработчик с моками
-			handler := &Handler{
-				storage:   mockStorage,
-				shortener: mockShortener,
-			}
+			handler := New(service.NewShortenerService(mockStorage, mockShortener, nil))
 
 			// Создаем тело запроса
 			body, _ := json.Marshal(tt.request)
@@ -137,7 +250,7 @@ func TestHandler_ShortenBatch(t *testing.T) {
 			req.Header.Set("Content-Type", tt.contentType)
 
 			// Добавляем userID в контекст для тестов, которые требуют аутентификации
-			if tt.expectedStatus == http.StatusCreated {
+			if tt.expectedStatus != http.StatusBadRequest {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, "test-user")
 				req = req.WithContext(ctx)
 			}
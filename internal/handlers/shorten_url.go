@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
-	"github.com/Adigezalov/shortener/internal/database"
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
 	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
 	"go.uber.org/zap"
 )
 
@@ -15,12 +18,15 @@ import (
 //
 // Эндпоинт: POST /api/shorten
 // Content-Type: application/json
-// Тело запроса: JSON объект с полем "url"
+// Тело запроса: JSON объект с полем "url" и необязательными полями
+// "alias", "expires_at" (RFC 3339) и "ttl_seconds"
 //
 // Ответы:
 //   - 201 Created: JSON с коротким URL в поле "result"
-//   - 400 Bad Request: некорректный JSON или пустой URL
+//   - 400 Bad Request: некорректный JSON, пустой URL, недопустимый псевдоним или срок действия;
+//     для URL, нарушающего политику, - JSON models.ErrorResponse с кодом нарушения
 //   - 409 Conflict: URL уже существует (возвращает существующий короткий URL)
+//     или запрошенный псевдоним уже занят
 //   - 415 Unsupported Media Type: неправильный Content-Type
 //   - 500 Internal Server Error: внутренняя ошибка сервера
 //
@@ -57,12 +63,6 @@ func (h *Handler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Проверяем URL
-	if request.URL == "" {
-		http.Error(w, "URL не может быть пустым", http.StatusBadRequest)
-		return
-	}
-
 	// Получаем ID пользователя из контекста
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
@@ -70,52 +70,72 @@ func (h *Handler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Генерируем новый ID и пытаемся добавить URL с привязкой к пользователю
-	id := h.shortener.Shorten(request.URL)
-	id, exists, err := h.storage.AddWithUser(id, request.URL, userID)
-
-	if err != nil {
-		if err == database.ErrURLConflict {
-			// Если URL уже существует, возвращаем короткий URL с кодом конфликта
-			shortURL := h.shortener.BuildShortURL(id)
-			response := models.ShortenResponse{
-				Result: shortURL,
-			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
-			encoder := json.NewEncoder(w)
-			if err := encoder.Encode(response); err != nil {
-				logger.Logger.Error("Ошибка кодирования JSON", zap.Error(err))
-				http.Error(w, "Ошибка формирования ответа", http.StatusInternalServerError)
-			}
+	// Создаем короткий URL: сервис проверяет URL, псевдоним и срок действия
+	result := h.service.CreateShortURLWithOptions(r.Context(), request.URL, userID, service.ShortenOptions{
+		Alias:      request.Alias,
+		ExpiresAt:  request.ExpiresAt,
+		TTLSeconds: request.TTLSeconds,
+	})
+	if result.Error != nil {
+		if writeURLPolicyError(w, r, result.Error) {
 			return
 		}
-		logger.Logger.Error("Ошибка добавления URL", zap.Error(err))
-		http.Error(w, "Ошибка сохранения URL", http.StatusInternalServerError)
+		switch {
+		case errors.Is(result.Error, service.ErrEmptyURL),
+			errors.Is(result.Error, shortener.ErrInvalidAlias),
+			errors.Is(result.Error, service.ErrInvalidExpiration):
+			http.Error(w, result.Error.Error(), http.StatusBadRequest)
+		case errors.Is(result.Error, service.ErrAliasTaken):
+			// Псевдоним уже занят другой ссылкой
+			http.Error(w, "Псевдоним уже занят", http.StatusConflict)
+		case errors.Is(result.Error, service.ErrAliasURLExists):
+			// URL уже сокращен без псевдонима, сообщаем существующую ссылку
+			http.Error(w, "URL уже сокращен: "+result.ShortURL, http.StatusConflict)
+		default:
+			logger.Ctx(r.Context()).Error("Ошибка добавления URL", zap.Error(result.Error))
+			http.Error(w, "Ошибка сохранения URL", http.StatusInternalServerError)
+		}
 		return
 	}
 
-	// Строим полный короткий URL
-	shortURL := h.shortener.BuildShortURL(id)
+	// Если URL уже существует, возвращаем короткий URL с кодом конфликта
+	status := http.StatusCreated
+	if result.Exists {
+		status = http.StatusConflict
+	}
 
 	// Формируем ответ
 	response := models.ShortenResponse{
-		Result: shortURL,
+		Result: result.ShortURL,
 	}
 
 	// Отправляем результат
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(status)
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
-		logger.Logger.Error("Ошибка кодирования JSON", zap.Error(err))
+		logger.Ctx(r.Context()).Error("Ошибка кодирования JSON", zap.Error(err))
 		http.Error(w, "Ошибка формирования ответа", http.StatusInternalServerError)
 		return
 	}
 
-	logger.Logger.Info("URL сокращен (JSON API)",
+	logger.Ctx(r.Context()).Info("URL сокращен (JSON API)",
 		zap.String("original_url", request.URL),
-		zap.String("short_url", shortURL),
-		zap.Bool("existing", exists),
+		zap.String("short_url", result.ShortURL),
+		zap.Bool("existing", result.Exists),
 	)
 }
+
+// writeURLPolicyError отвечает 400 Bad Request с кодом нарушения, если err -
+// нарушение политики URL. Возвращает false для остальных ошибок.
+func writeURLPolicyError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var policyErr *urlpolicy.Error
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	writeJSON(w, r, http.StatusBadRequest, models.ErrorResponse{
+		Code:    string(policyErr.Code),
+		Message: policyErr.Message,
+	})
+	return true
+}
@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Adigezalov/shortener/internal/database"
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/middleware"
 	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -41,7 +43,7 @@ func TestHandler_ShortenURL(t *testing.T) {
 			contentType: "application/json",
 			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
 				msh.On("Shorten", "https://example.com").Return("abc123")
-				ms.On("AddWithUser", "abc123", "https://example.com", "test-user").Return("abc123", false, nil)
+				ms.On("Add", "abc123", "https://example.com", "test-user", time.Time{}).Return("abc123", nil)
 				msh.On("BuildShortURL", "abc123").Return("http://short.url/abc123")
 			},
 			expectedStatus: http.StatusCreated,
@@ -55,12 +57,43 @@ func TestHandler_ShortenURL(t *testing.T) {
 			contentType: "application/json",
 			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
 				msh.On("Shorten", "https://example.com").Return("abc123")
-				ms.On("AddWithUser", "abc123", "https://example.com", "test-user").Return("abc123", true, database.ErrURLConflict)
+				ms.On("Add", "abc123", "https://example.com", "test-user", time.Time{}).Return("abc123", database.ErrURLConflict)
 				msh.On("BuildShortURL", "abc123").Return("http://short.url/abc123")
 			},
 			expectedStatus: http.StatusConflict,
 			expectedResult: "http://short.url/abc123",
 		},
+		{
+			name: "Коллизия_сгенерированного_ID",
+			request: models.ShortenRequest{
+				URL: "https://example.com",
+			},
+			contentType: "application/json",
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				// Первый сгенерированный ID занят другим URL, сервис генерирует новый
+				msh.On("Shorten", "https://example.com").Return("taken1").Once()
+				msh.On("Shorten", "https://example.com").Return("free22").Once()
+				ms.On("Add", "taken1", "https://example.com", "test-user", time.Time{}).Return("", database.ErrShortIDConflict)
+				ms.On("Add", "free22", "https://example.com", "test-user", time.Time{}).Return("free22", nil)
+				msh.On("BuildShortURL", "free22").Return("http://short.url/free22")
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResult: "http://short.url/free22",
+		},
+		{
+			name: "Попытки_генерации_ID_исчерпаны",
+			request: models.ShortenRequest{
+				URL: "https://example.com",
+			},
+			contentType: "application/json",
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				msh.On("Shorten", "https://example.com").Return("taken1").Times(service.MaxIDAttempts)
+				ms.On("Add", "taken1", "https://example.com", "test-user", time.Time{}).
+					Return("", database.ErrShortIDConflict).Times(service.MaxIDAttempts)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResult: "",
+		},
 		{
 			name: "Пустой_URL",
 			request: models.ShortenRequest{
@@ -93,10 +126,7 @@ func TestHandler_ShortenURL(t *testing.T) {
 			tt.mockSetup(mockStorage, mockShortener)
 
 			// Создаем обработчик с моками
-			handler := &Handler{
-				storage:   mockStorage,
-				shortener: mockShortener,
-			}
+			handler := New(service.NewShortenerService(mockStorage, mockShortener, nil))
 
 			// Создаем тело запроса
 			body, _ := json.Marshal(tt.request)
@@ -105,11 +135,9 @@ func TestHandler_ShortenURL(t *testing.T) {
 			req := httptest.NewRequest("POST", "/api/shorten", bytes.NewBuffer(body))
 			req.Header.Set("Content-Type", tt.contentType)
 
-			// Добавляем userID в контекст для тестов, которые требуют аутентификации
-			if tt.expectedStatus == http.StatusCreated || tt.expectedStatus == http.StatusConflict {
-				ctx := context.WithValue(req.Context(), middleware.UserIDKey, "test-user")
-				req = req.WithContext(ctx)
-			}
+			// Добавляем userID в контекст, как это делает AuthMiddleware
+			ctx := context.WithValue(req.Context(), middleware.UserIDKey, "test-user")
+			req = req.WithContext(ctx)
 
 			w := httptest.NewRecorder()
 
@@ -133,3 +161,114 @@ func TestHandler_ShortenURL(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ShortenURL_Alias(t *testing.T) {
+	// Инициализируем тестовый логгер
+	testLogger, err := zap.NewDevelopment()
+	if err != nil {
+		t.Fatalf("Не удалось создать тестовый логгер: %v", err)
+	}
+	logger.Logger = testLogger
+	defer logger.Logger.Sync()
+
+	tests := []struct {
+		name           string
+		request        models.ShortenRequest
+		mockSetup      func(*MockURLStorage, *MockURLShortener)
+		expectedStatus int
+		expectedResult string
+		expectedBody   string
+	}{
+		{
+			name: "Создание_URL_с_псевдонимом",
+			request: models.ShortenRequest{
+				URL:   "https://example.com/report",
+				Alias: "q3-report",
+			},
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				ms.On("Add", "q3-report", "https://example.com/report", "test-user", time.Time{}).Return("q3-report", nil)
+				msh.On("BuildShortURL", "q3-report").Return("http://short.url/q3-report")
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResult: "http://short.url/q3-report",
+		},
+		{
+			name: "Псевдоним_уже_занят",
+			request: models.ShortenRequest{
+				URL:   "https://example.com/other",
+				Alias: "q3-report",
+			},
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				ms.On("Add", "q3-report", "https://example.com/other", "test-user", time.Time{}).Return("", database.ErrShortIDConflict)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Псевдоним_для_уже_сокращенного_URL",
+			request: models.ShortenRequest{
+				URL:   "https://example.com/report",
+				Alias: "q4-report",
+			},
+			mockSetup: func(ms *MockURLStorage, msh *MockURLShortener) {
+				// Псевдоним не создан: URL уже сокращен под другим ID
+				ms.On("Add", "q4-report", "https://example.com/report", "test-user", time.Time{}).Return("abc123", database.ErrURLConflict)
+				msh.On("BuildShortURL", "abc123").Return("http://short.url/abc123")
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "URL уже сокращен: http://short.url/abc123\n",
+		},
+		{
+			name: "Зарезервированный_псевдоним",
+			request: models.ShortenRequest{
+				URL:   "https://example.com",
+				Alias: "API",
+			},
+			mockSetup:      func(ms *MockURLStorage, msh *MockURLShortener) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Псевдоним_с_недопустимыми_символами",
+			request: models.ShortenRequest{
+				URL:   "https://example.com",
+				Alias: "q3/report",
+			},
+			mockSetup:      func(ms *MockURLStorage, msh *MockURLShortener) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockURLStorage)
+			mockShortener := new(MockURLShortener)
+			tt.mockSetup(mockStorage, mockShortener)
+
+			handler := New(service.NewShortenerService(mockStorage, mockShortener, nil))
+
+			body, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest("POST", "/api/shorten", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx := context.WithValue(req.Context(), middleware.UserIDKey, "test-user")
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			handler.ShortenURL(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResult != "" {
+				var response models.ShortenResponse
+				err := json.NewDecoder(w.Body).Decode(&response)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, response.Result)
+			}
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+
+			// Для псевдонима генератор ID не вызывается
+			mockShortener.AssertNotCalled(t, "Shorten", tt.request.URL)
+			mockStorage.AssertExpectations(t)
+			mockShortener.AssertExpectations(t)
+		})
+	}
+}
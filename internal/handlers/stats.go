@@ -10,16 +10,23 @@ import (
 
 // StatsResponse представляет ответ статистики
 type StatsResponse struct {
-	URLs  int `json:"urls"`  // Количество сокращённых URL в сервисе
-	Users int `json:"users"` // Количество пользователей в сервисе
+	URLs  int                 `json:"urls"`            // Количество сокращённых URL в сервисе
+	Users int                 `json:"users"`           // Количество пользователей в сервисе
+	Cache *CacheStatsResponse `json:"cache,omitempty"` // Счетчики кэша ссылок (если кэш включен)
+}
+
+// CacheStatsResponse представляет счетчики кэша ссылок
+type CacheStatsResponse struct {
+	Hits   int64 `json:"hits"`   // Переходы, обслуженные из кэша
+	Misses int64 `json:"misses"` // Переходы, потребовавшие обращения к хранилищу
 }
 
 // GetStats возвращает статистику сервиса
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
-	// Получаем статистику из хранилища
-	stats, err := h.storage.Stats()
-	if err != nil {
-		logger.Logger.Error("Ошибка получения статистики", zap.Error(err))
+	// Получаем статистику сервиса
+	stats := h.service.GetStats(r.Context())
+	if stats.Error != nil {
+		logger.Ctx(r.Context()).Error("Ошибка получения статистики", zap.Error(stats.Error))
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
@@ -29,18 +36,24 @@ func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 		URLs:  stats.URLs,
 		Users: stats.Users,
 	}
+	if stats.Cache != nil {
+		statsResp.Cache = &CacheStatsResponse{
+			Hits:   stats.Cache.Hits,
+			Misses: stats.Cache.Misses,
+		}
+	}
 
 	// Устанавливаем заголовок Content-Type
 	w.Header().Set("Content-Type", "application/json")
 
 	// Кодируем и отправляем ответ
 	if err := json.NewEncoder(w).Encode(statsResp); err != nil {
-		logger.Logger.Error("Ошибка кодирования ответа статистики", zap.Error(err))
+		logger.Ctx(r.Context()).Error("Ошибка кодирования ответа статистики", zap.Error(err))
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	logger.Logger.Info("Статистика успешно получена",
+	logger.Ctx(r.Context()).Info("Статистика успешно получена",
 		zap.Int("urls", statsResp.URLs),
 		zap.Int("users", statsResp.Users))
 }
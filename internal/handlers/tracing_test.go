@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRouter_Tracing(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger.Logger = zap.New(core)
+
+	var spansOut bytes.Buffer
+	tracing.SetExporter(tracing.NewStdoutExporter(&spansOut))
+	t.Cleanup(func() { tracing.SetExporter(nil) })
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	svc := service.NewShortenerService(store, shortener.New("http://localhost:8080"), nil)
+	r := NewRouter(New(svc), "")
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/missing1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	// ID запроса совпадает с идентификатором входящей трассировки
+	assert.Equal(t, traceID, rec.Header().Get("X-Request-ID"))
+
+	// Логи запроса содержат идентификаторы трассировки
+	entries := logs.FilterMessage("HTTP request").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, traceID, entries[0].ContextMap()["trace_id"])
+	assert.NotEmpty(t, entries[0].ContextMap()["span_id"])
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	tracing.ForceFlush(ctx)
+
+	type exportedSpan struct {
+		Name         string         `json:"name"`
+		TraceID      string         `json:"trace_id"`
+		SpanID       string         `json:"span_id"`
+		ParentSpanID string         `json:"parent_span_id"`
+		Attributes   map[string]any `json:"attributes"`
+	}
+	spans := make(map[string]exportedSpan)
+	for _, line := range strings.Split(strings.TrimSpace(spansOut.String()), "\n") {
+		var span exportedSpan
+		require.NoError(t, json.Unmarshal([]byte(line), &span))
+		// Фоновые удаления из других тестов выгружают span'ы своих трассировок
+		if span.TraceID != traceID {
+			continue
+		}
+		spans[span.Name] = span
+	}
+
+	// Серверный span назван по шаблону маршрута и продолжает входящую трассировку
+	server, ok := spans["GET /{id}"]
+	require.True(t, ok, "нет серверного span'а: %v", spans)
+	assert.Equal(t, parentSpanID, server.ParentSpanID)
+	assert.Equal(t, "/{id}", server.Attributes["http.route"])
+	assert.Equal(t, float64(http.StatusNotFound), server.Attributes["http.status_code"])
+	assert.Equal(t, server.SpanID, entries[0].ContextMap()["span_id"])
+
+	// Span сервиса вложен в серверный span
+	svcSpan, ok := spans["ShortenerService.GetOriginalURL"]
+	require.True(t, ok, "нет span'а сервиса: %v", spans)
+	assert.Equal(t, server.SpanID, svcSpan.ParentSpanID)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/service"
+	"github.com/Adigezalov/shortener/internal/shortener"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRouter_URLPolicy(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	store := storage.NewMemoryStorage("")
+	defer store.Close()
+	policy, err := urlpolicy.New(urlpolicy.Options{
+		SelfHosts: []string{"short.test"},
+		DenyHosts: []string{"*.evil.example"},
+	})
+	require.NoError(t, err)
+	svc := service.NewShortenerService(store, shortener.New("http://short.test"), nil).WithURLPolicy(policy)
+	c := authClient{t: t, router: NewRouter(New(svc), "")}
+
+	decodeError := func(rec *http.Response) models.ErrorResponse {
+		var body models.ErrorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+		return body
+	}
+
+	// JSON API
+	rec := c.do(http.MethodPost, "/api/shorten", `{"url":"javascript:alert(1)"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "scheme_not_allowed", decodeError(rec.Result()).Code)
+
+	rec = c.do(http.MethodPost, "/api/shorten", `{"url":"https://short.test/abc"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "self_reference", decodeError(rec.Result()).Code)
+
+	// Текстовый API
+	rec = c.do(http.MethodPost, "/", "http://169.254.169.254/latest/meta-data", "Content-Type", "text/plain")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "private_address", decodeError(rec.Result()).Code)
+
+	// Сохраняется нормализованный URL
+	rec = c.do(http.MethodPost, "/api/shorten", `{"url":"HTTPS://Пример.рф/a"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created models.ShortenResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	original, err := store.Get(t.Context(), created.Result[len("http://short.test/"):])
+	require.NoError(t, err)
+	assert.Equal(t, "https://xn--e1afmkfd.xn--p1ai/a", original)
+
+	// В пакете отклоняется только элемент, нарушающий политику
+	rec = c.do(http.MethodPost, "/api/shorten/batch", `[
+		{"correlation_id":"1","original_url":"https://example.com/ok"},
+		{"correlation_id":"2","original_url":"https://cdn.evil.example/x"}
+	]`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var batch []models.BatchShortenResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&batch))
+	require.Len(t, batch, 2)
+	assert.Equal(t, string(storage.RecordCreated), batch[0].Status)
+	assert.Empty(t, batch[0].ErrorCode)
+	assert.Equal(t, string(storage.RecordFailed), batch[1].Status)
+	assert.Equal(t, "host_denied", batch[1].ErrorCode)
+	assert.NotEmpty(t, batch[1].Error)
+}
@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"context"
+
+	"github.com/Adigezalov/shortener/internal/tracing"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -37,3 +40,16 @@ func Sync() {
 		_ = Logger.Sync()
 	}
 }
+
+// Ctx возвращает логгер с идентификаторами трассировки и span'а
+// из контекста (поля trace_id и span_id). Без трассировки возвращает Logger.
+func Ctx(ctx context.Context) *zap.Logger {
+	sc := tracing.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return Logger
+	}
+	return Logger.With(
+		zap.String("trace_id", sc.TraceID.String()),
+		zap.String("span_id", sc.SpanID.String()),
+	)
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import "database/sql"
+
+// RegisterDBStats регистрирует в реестре Default метрики пула соединений
+// с базой данных. Функция stats вызывается при каждой выдаче метрик.
+func RegisterDBStats(stats func() sql.DBStats) {
+	gauge := func(name, help string, value func(s sql.DBStats) float64) {
+		Default.Register(NewGaugeFunc(name, help, func() float64 { return value(stats()) }))
+	}
+	counter := func(name, help string, value func(s sql.DBStats) float64) {
+		Default.Register(NewCounterFunc(name, help, func() float64 { return value(stats()) }))
+	}
+
+	gauge("shortener_db_max_open_connections", "Максимальное количество открытых соединений с базой данных",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("shortener_db_open_connections", "Количество открытых соединений с базой данных",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("shortener_db_in_use_connections", "Количество используемых соединений с базой данных",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("shortener_db_idle_connections", "Количество простаивающих соединений с базой данных",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("shortener_db_wait_count_total", "Количество ожиданий свободного соединения",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("shortener_db_wait_duration_seconds_total", "Суммарное время ожидания свободного соединения в секундах",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("shortener_db_max_idle_closed_total", "Количество соединений, закрытых из-за лимита простаивающих",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("shortener_db_max_lifetime_closed_total", "Количество соединений, закрытых по истечении времени жизни",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
@@ -0,0 +1,458 @@
+// Package metrics содержит метрики сервиса и их выдачу в текстовом формате Prometheus.
+//
+// Метрики объявляются переменными пакетов, которые их обновляют, и
+// регистрируются в реестре Default через Register:
+//
+//	var requests = metrics.Register(metrics.NewCounterVec(
+//		"shortener_requests_total", "Количество запросов", "method"))
+//
+//	requests.WithLabelValues("GET").Inc()
+//
+// Handler отдает все зарегистрированные метрики в формате text/plain 0.0.4.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultBuckets - границы корзин гистограммы длительности в секундах
+var DefaultBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector - метрика или группа метрик с общим именем
+type Collector interface {
+	// Name возвращает имя метрики, уникальное в реестре
+	Name() string
+
+	// Write записывает метрику в текстовом формате Prometheus
+	Write(w *bufio.Writer)
+}
+
+// Registry - набор метрик, выдаваемых обработчиком /metrics
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry создает пустой реестр метрик
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// Default - реестр метрик сервиса
+var Default = NewRegistry()
+
+// Register регистрирует метрику в реестре Default и возвращает ее
+func Register[C Collector](c C) C {
+	Default.Register(c)
+	return c
+}
+
+// Register добавляет метрику в реестр. Метрика с тем же именем заменяется:
+// так повторно созданное хранилище подменяет функции метрик предыдущего.
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors[c.Name()] = c
+}
+
+// WriteTo записывает все метрики реестра в порядке имен
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].Name() < collectors[j].Name()
+	})
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, c := range collectors {
+		c.Write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// Handler возвращает HTTP обработчик, отдающий метрики реестра
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if _, err := r.WriteTo(w); err != nil {
+			logger.Logger.Error("Ошибка записи метрик", zap.Error(err))
+		}
+	})
+}
+
+// Handler возвращает HTTP обработчик, отдающий метрики реестра Default
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// countingWriter считает количество записанных байт
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// desc - общие поля описания метрики
+type desc struct {
+	name   string
+	help   string
+	kind   string   // тип метрики: counter, gauge, histogram
+	labels []string // имена меток
+}
+
+func (d desc) Name() string {
+	return d.name
+}
+
+// writeHeader записывает строки HELP и TYPE
+func (d desc) writeHeader(w *bufio.Writer) {
+	w.WriteString("# HELP ")
+	w.WriteString(d.name)
+	w.WriteByte(' ')
+	w.WriteString(escapeHelp(d.help))
+	w.WriteString("\n# TYPE ")
+	w.WriteString(d.name)
+	w.WriteByte(' ')
+	w.WriteString(d.kind)
+	w.WriteByte('\n')
+}
+
+// writeSample записывает одно значение метрики с метками
+func writeSample(w *bufio.Writer, name string, labels, values []string, extra string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extra != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabel(values[i]))
+			w.WriteByte('"')
+		}
+		if extra != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extra)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// formatFloat форматирует значение метрики
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// atomicFloat - число с плавающей точкой с атомарным обновлением
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if f.bits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) Set(v float64) {
+	f.bits.Store(math.Float64bits(v))
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+// labelKey объединяет значения меток в ключ карты серий
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// series - набор серий метрики, различающихся значениями меток
+type series[T any] struct {
+	mu     sync.RWMutex
+	items  map[string]*T
+	values map[string][]string // ключ -> значения меток
+	create func() *T
+}
+
+func newSeries[T any](create func() *T) series[T] {
+	return series[T]{
+		items:  make(map[string]*T),
+		values: make(map[string][]string),
+		create: create,
+	}
+}
+
+// get возвращает серию для значений меток, создавая ее при первом обращении
+func (s *series[T]) get(d desc, values []string) *T {
+	if len(values) != len(d.labels) {
+		panic("metrics: " + d.name + ": ожидается " + strconv.Itoa(len(d.labels)) +
+			" значений меток, передано " + strconv.Itoa(len(values)))
+	}
+
+	key := labelKey(values)
+	s.mu.RLock()
+	item, ok := s.items[key]
+	s.mu.RUnlock()
+	if ok {
+		return item
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item, ok := s.items[key]; ok {
+		return item
+	}
+	item = s.create()
+	s.items[key] = item
+	s.values[key] = append([]string(nil), values...)
+	return item
+}
+
+// each вызывает fn для каждой серии в порядке значений меток
+func (s *series[T]) each(fn func(values []string, item *T)) {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.items))
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s.mu.RLock()
+		item, values := s.items[key], s.values[key]
+		s.mu.RUnlock()
+		fn(values, item)
+	}
+}
+
+// Counter - монотонно возрастающий счетчик
+type Counter struct {
+	value atomicFloat
+}
+
+// Inc увеличивает счетчик на 1
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add увеличивает счетчик на delta (delta не должна быть отрицательной)
+func (c *Counter) Add(delta float64) {
+	c.value.Add(delta)
+}
+
+// CounterVec - счетчик с метками
+type CounterVec struct {
+	desc
+	series series[Counter]
+}
+
+// NewCounterVec создает счетчик с метками labels
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		desc:   desc{name: name, help: help, kind: "counter", labels: labels},
+		series: newSeries(func() *Counter { return &Counter{} }),
+	}
+}
+
+// WithLabelValues возвращает счетчик для значений меток
+func (v *CounterVec) WithLabelValues(values ...string) *Counter {
+	return v.series.get(v.desc, values)
+}
+
+// Write записывает счетчик в текстовом формате Prometheus
+func (v *CounterVec) Write(w *bufio.Writer) {
+	v.writeHeader(w)
+	v.series.each(func(values []string, c *Counter) {
+		writeSample(w, v.name, v.labels, values, "", c.value.Load())
+	})
+}
+
+// Gauge - значение, которое может увеличиваться и уменьшаться
+type Gauge struct {
+	value atomicFloat
+}
+
+// Set устанавливает значение
+func (g *Gauge) Set(v float64) {
+	g.value.Set(v)
+}
+
+// Inc увеличивает значение на 1
+func (g *Gauge) Inc() {
+	g.value.Add(1)
+}
+
+// Dec уменьшает значение на 1
+func (g *Gauge) Dec() {
+	g.value.Add(-1)
+}
+
+// GaugeVec - значение с метками
+type GaugeVec struct {
+	desc
+	series series[Gauge]
+}
+
+// NewGaugeVec создает значение с метками labels
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{
+		desc:   desc{name: name, help: help, kind: "gauge", labels: labels},
+		series: newSeries(func() *Gauge { return &Gauge{} }),
+	}
+}
+
+// WithLabelValues возвращает значение для значений меток
+func (v *GaugeVec) WithLabelValues(values ...string) *Gauge {
+	return v.series.get(v.desc, values)
+}
+
+// Write записывает значение в текстовом формате Prometheus
+func (v *GaugeVec) Write(w *bufio.Writer) {
+	v.writeHeader(w)
+	v.series.each(func(values []string, g *Gauge) {
+		writeSample(w, v.name, v.labels, values, "", g.value.Load())
+	})
+}
+
+// Histogram - распределение наблюдаемых значений по корзинам
+type Histogram struct {
+	buckets []float64
+	counts  []atomic.Uint64 // количество наблюдений в каждой корзине (не накопительно)
+	sum     atomicFloat
+	count   atomic.Uint64
+}
+
+// Observe добавляет наблюдение
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+	if i < len(h.counts) {
+		h.counts[i].Add(1)
+	}
+	h.sum.Add(v)
+	h.count.Add(1)
+}
+
+// HistogramVec - гистограмма с метками
+type HistogramVec struct {
+	desc
+	buckets []float64
+	series  series[Histogram]
+}
+
+// NewHistogramVec создает гистограмму с границами корзин buckets
+// (nil - DefaultBuckets) и метками labels
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &HistogramVec{
+		desc:    desc{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: buckets,
+		series: newSeries(func() *Histogram {
+			return &Histogram{buckets: buckets, counts: make([]atomic.Uint64, len(buckets))}
+		}),
+	}
+}
+
+// WithLabelValues возвращает гистограмму для значений меток
+func (v *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return v.series.get(v.desc, values)
+}
+
+// Write записывает гистограмму в текстовом формате Prometheus
+func (v *HistogramVec) Write(w *bufio.Writer) {
+	v.writeHeader(w)
+	v.series.each(func(values []string, h *Histogram) {
+		count := h.count.Load()
+		var cumulative uint64
+		for i, bound := range v.buckets {
+			cumulative += h.counts[i].Load()
+			writeSample(w, v.name+"_bucket", v.labels, values, `le="`+formatFloat(bound)+`"`, float64(cumulative))
+		}
+		writeSample(w, v.name+"_bucket", v.labels, values, `le="+Inf"`, float64(count))
+		writeSample(w, v.name+"_sum", v.labels, values, "", h.sum.Load())
+		writeSample(w, v.name+"_count", v.labels, values, "", float64(count))
+	})
+}
+
+// Func - метрика без меток, значение которой вычисляется при выдаче
+type Func struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc создает значение, вычисляемое функцией fn
+func NewGaugeFunc(name, help string, fn func() float64) *Func {
+	return &Func{desc: desc{name: name, help: help, kind: "gauge"}, fn: fn}
+}
+
+// NewCounterFunc создает счетчик, значение которого возвращает функция fn
+func NewCounterFunc(name, help string, fn func() float64) *Func {
+	return &Func{desc: desc{name: name, help: help, kind: "counter"}, fn: fn}
+}
+
+// Write записывает метрику в текстовом формате Prometheus
+func (f *Func) Write(w *bufio.Writer) {
+	f.writeHeader(w)
+	writeSample(w, f.name, nil, nil, "", f.fn())
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	r := NewRegistry()
+
+	requests := NewCounterVec("test_requests_total", "Количество запросов", "method", "path")
+	requests.WithLabelValues("GET", `/a"b`).Inc()
+	requests.WithLabelValues("GET", `/a"b`).Add(2)
+	requests.WithLabelValues("POST", "/").Inc()
+	r.Register(requests)
+
+	duration := NewHistogramVec("test_duration_seconds", "Длительность", []float64{0.5, 0.1}, "method")
+	duration.WithLabelValues("GET").Observe(0.05)
+	duration.WithLabelValues("GET").Observe(0.1)
+	duration.WithLabelValues("GET").Observe(2)
+	r.Register(duration)
+
+	depth := 7
+	r.Register(NewGaugeFunc("test_queue_depth", "Глубина\nочереди", func() float64 { return float64(depth) }))
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# HELP test_duration_seconds Длительность
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{method="GET",le="0.1"} 2
+test_duration_seconds_bucket{method="GET",le="0.5"} 2
+test_duration_seconds_bucket{method="GET",le="+Inf"} 3
+test_duration_seconds_sum{method="GET"} 2.15
+test_duration_seconds_count{method="GET"} 3
+# HELP test_queue_depth Глубина\nочереди
+# TYPE test_queue_depth gauge
+test_queue_depth 7
+# HELP test_requests_total Количество запросов
+# TYPE test_requests_total counter
+test_requests_total{method="GET",path="/a\"b"} 3
+test_requests_total{method="POST",path="/"} 1
+`, buf.String())
+}
+
+func TestRegistry_RegisterReplaces(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewGaugeFunc("test_value", "Значение", func() float64 { return 1 }))
+	r.Register(NewGaugeFunc("test_value", "Значение", func() float64 { return 2 }))
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "test_value 2\n")
+	assert.NotContains(t, buf.String(), "test_value 1\n")
+}
+
+func TestGaugeVec_IncDec(t *testing.T) {
+	gauge := NewGaugeVec("test_in_flight", "Выполняется", "transport")
+	gauge.WithLabelValues("http").Inc()
+	gauge.WithLabelValues("http").Inc()
+	gauge.WithLabelValues("http").Dec()
+
+	r := NewRegistry()
+	r.Register(gauge)
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `test_in_flight{transport="http"} 1`)
+}
+
+func TestCounterVec_WrongLabelCount(t *testing.T) {
+	counter := NewCounterVec("test_total", "Счетчик", "method")
+	assert.Panics(t, func() { counter.WithLabelValues("GET", "extra") })
+}
@@ -0,0 +1,8 @@
+package metrics
+
+// AsyncDeletes - количество выполняющихся горутин асинхронного удаления URL.
+// Метка transport - http или grpc.
+var AsyncDeletes = Register(NewGaugeVec(
+	"shortener_async_deletes_in_flight",
+	"Количество выполняющихся асинхронных удалений URL",
+	"transport"))
@@ -6,33 +6,128 @@ import (
 	"strings"
 
 	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"go.uber.org/zap"
 )
 
 type authContextKey string
 
-const UserIDKey authContextKey = "userID"
+const (
+	UserIDKey authContextKey = "userID"
+)
 
-// AuthMiddleware проверяет аутентификацию пользователя и устанавливает куку если нужно
+// AuthMiddleware проверяет аутентификацию пользователя и устанавливает куку если нужно.
+// Учитывает только анонимных пользователей; см. Authentication.
 func AuthMiddleware(next http.Handler) http.Handler {
+	return Authentication(nil)(next)
+}
+
+// Authentication проверяет аутентификацию пользователя.
+//
+// Порядок проверки:
+//  1. токен учетной записи (API-ключ или сессия) в заголовке Authorization;
+//  2. токен сессии в куке session;
+//  3. подписанный ID анонимного пользователя в заголовке или куке user_id.
+//
+// Если ничего не подошло, выдается новый анонимный ID. Недействительный
+// токен учетной записи в заголовке отклоняется с кодом 401, недействительная
+// кука сессии удаляется. При tokens == nil проверяются только анонимные ID.
+func Authentication(tokens auth.TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			if tokens != nil {
+				if token := auth.BearerToken(r.Header.Get("Authorization")); auth.IsAccountToken(token) {
+					identity, err := tokens.VerifyToken(ctx, token)
+					if err != nil {
+						logger.Ctx(ctx).Debug("Недействительный токен учетной записи", zap.Error(err))
+						unauthorized(w, true, "Недействительный токен")
+						return
+					}
+					next.ServeHTTP(w, r.WithContext(withIdentity(ctx, identity)))
+					return
+				}
+
+				if cookie, err := r.Cookie(auth.SessionCookieName); err == nil && cookie.Value != "" {
+					identity, err := tokens.VerifyToken(ctx, cookie.Value)
+					if err == nil {
+						next.ServeHTTP(w, r.WithContext(withIdentity(ctx, identity)))
+						return
+					}
+					auth.ClearSessionCookie(w)
+				}
+			}
+
+			userID, err := auth.GetUserIDFromRequest(r)
+			if err != nil {
+				// Генерируем новый ID пользователя и устанавливаем куку и заголовок
+				userID = auth.GenerateUserID()
+				auth.SetUserIDCookie(w, userID)
+				auth.SetAuthorizationHeader(w, userID)
+			}
+
+			// Добавляем userID в контекст запроса
+			ctx = context.WithValue(ctx, UserIDKey, userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// withIdentity добавляет в контекст учетную запись (auth.WithIdentity)
+// и ее ID пользователя
+func withIdentity(ctx context.Context, identity auth.Identity) context.Context {
+	ctx = auth.WithIdentity(ctx, identity)
+	return context.WithValue(ctx, UserIDKey, identity.UserID)
+}
+
+// AuthChallenge - значение заголовка WWW-Authenticate в ответах 401
+const AuthChallenge = `Bearer realm="shortener"`
+
+// RequireAuth middleware требует аутентификации пользователя (режим auth.ModeStrict).
+//
+// Запрос, аутентифицированный учетной записью, пропускается. Иначе подписанный
+// ID пользователя из заголовка Authorization или куки user_id проверяется
+// (подпись, ключ и срок действия); при ошибке возвращается 401 с заголовком
+// WWW-Authenticate, новый пользователь не создается.
+func RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		userID, err := auth.GetUserIDFromRequest(r)
+		// Запрос уже аутентифицирован токеном учетной записи
+		if _, ok := auth.IdentityFromContext(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
 
+		userID, err := auth.GetUserIDFromRequest(r)
 		if err != nil {
-			// Генерируем новый ID пользователя и устанавливаем куку и заголовок
-			userID = auth.GenerateUserID()
-			auth.SetUserIDCookie(w, userID)
-			auth.SetAuthorizationHeader(w, userID)
+			presented := r.Header.Get("Authorization") != ""
+			if _, cookieErr := r.Cookie(auth.CookieName); cookieErr == nil {
+				presented = true
+			}
+			logger.Ctx(r.Context()).Debug("Отказ в аутентификации", zap.Error(err))
+			unauthorized(w, presented, "Требуется аутентификация")
+			return
 		}
 
-		// Добавляем userID в контекст запроса
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// RequireAuth middleware требует наличия валидной аутентификации
-func RequireAuth(next http.Handler) http.Handler {
+// LegacyRequireAuth middleware для режима auth.ModeLegacy.
+//
+// Принимает любую куку вида userID.signature без проверки подписи и создает
+// нового пользователя вместо ответа 401. Позволяет любому клиенту действовать
+// от имени чужого ID, поэтому используется только для совместимости со
+// старыми автотестами.
+func LegacyRequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Запрос уже аутентифицирован токеном учетной записи
+		if _, ok := auth.IdentityFromContext(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// Сначала пробуем получить userID через стандартную проверку
 		userID, err := auth.GetUserIDFromRequest(r)
 
@@ -63,6 +158,68 @@ func RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// RequireAuthMode возвращает middleware проверки аутентификации для режима mode
+func RequireAuthMode(mode auth.Mode) func(http.Handler) http.Handler {
+	if mode == auth.ModeLegacy {
+		return LegacyRequireAuth
+	}
+	return RequireAuth
+}
+
+// unauthorized отправляет ответ 401 с заголовком WWW-Authenticate.
+// invalidToken сообщает, что клиент передал недействительные учетные данные.
+func unauthorized(w http.ResponseWriter, invalidToken bool, message string) {
+	challenge := AuthChallenge
+	if invalidToken {
+		challenge += `, error="invalid_token"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
+// RequireAccount middleware требует аутентификации учетной записью
+// (сессией или API-ключом). Анонимным пользователям возвращается 401.
+func RequireAccount(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.IdentityFromContext(r.Context()); !ok {
+			unauthorized(w, false, "Требуется вход в учетную запись")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole возвращает middleware, требующее учетную запись с ролью role.
+// Анонимному пользователю возвращается 401, учетной записи без роли - 403.
+// Роль проверяется на каждом запросе, поэтому ее снятие действует сразу.
+func RequireRole(roles auth.RoleChecker, role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, ok := auth.IdentityFromContext(r.Context())
+			if !ok {
+				unauthorized(w, false, "Требуется вход в учетную запись")
+				return
+			}
+
+			allowed, err := roles.HasRole(r.Context(), identity.UserID, role)
+			if err != nil {
+				logger.Ctx(r.Context()).Error("Ошибка проверки роли", zap.Error(err))
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				logger.Ctx(r.Context()).Warn("Недостаточно прав",
+					zap.String("user_id", identity.UserID),
+					zap.String("role", role))
+				http.Error(w, "Недостаточно прав", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // GetUserIDFromContext извлекает ID пользователя из контекста
 func GetUserIDFromContext(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value(UserIDKey).(string)
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// clientIPKey - ключ контекста для IP-адреса клиента
+const clientIPKey contextKey = "clientIP"
+
+// ParseTrustedProxies разбирает список доверенных прокси через запятую.
+// Элемент списка - подсеть CIDR или отдельный IP-адрес.
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный доверенный прокси %q", item)
+		}
+		proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return proxies, nil
+}
+
+// ClientIP middleware определяет IP-адрес клиента (см. getRealIP) для
+// ограничения частоты запросов и проверки доверенной подсети.
+func ClientIP(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := getRealIP(r, trustedProxies)
+			ctx := context.WithValue(r.Context(), clientIPKey, ip)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// getClientIP возвращает IP-адрес клиента, определенный ClientIP.
+// Без ClientIP используется адрес соединения.
+func getClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// getRealIP извлекает реальный IP-адрес клиента из запроса.
+//
+// По умолчанию используется адрес соединения (RemoteAddr), а заголовки
+// X-Real-IP и X-Forwarded-For игнорируются, поскольку их задает клиент.
+// Если соединение пришло от доверенного прокси из trustedProxies,
+// используется X-Real-IP, выставленный прокси, а без него - самый правый
+// адрес X-Forwarded-For, не входящий в доверенные: левее него адреса
+// мог дописать сам клиент.
+func getRealIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	ip := remoteIP(r)
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+
+	// Заголовков может быть несколько: каждый прокси дописывает адрес справа
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !isTrustedProxy(hop, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+// isTrustedProxy проверяет, входит ли адрес в список доверенных прокси
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	if len(trustedProxies) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP возвращает IP-адрес соединения без порта
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
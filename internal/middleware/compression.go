@@ -77,6 +77,11 @@ func (w *gzipWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap возвращает исходный http.ResponseWriter для http.ResponseController
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Close закрывает gzip writer, если он используется
 func (w *gzipWriter) Close() error {
 	if w.compress {
@@ -3,13 +3,13 @@ package middleware
 import (
 	"net"
 	"net/http"
-	"strings"
 
 	"github.com/Adigezalov/shortener/internal/logger"
 	"go.uber.org/zap"
 )
 
-// IPAuthMiddleware проверяет, что IP-адрес клиента входит в доверенную подсеть
+// IPAuthMiddleware проверяет, что IP-адрес клиента входит в доверенную подсеть.
+// IP-адрес определяется так же, как для ограничения частоты запросов (см. ClientIP).
 func IPAuthMiddleware(trustedSubnet string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -20,13 +20,9 @@ func IPAuthMiddleware(trustedSubnet string) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Получаем IP адрес из заголовка X-Real-IP
-			ipStr := r.Header.Get("X-Real-IP")
-			if ipStr == "" {
-				logger.Logger.Warn("Доступ к защищенному эндпоинту запрещен: заголовок X-Real-IP отсутствует")
-				w.WriteHeader(http.StatusForbidden)
-				return
-			}
+			// Получаем IP клиента, определенный ClientIP: заголовки X-Real-IP
+			// и X-Forwarded-For учитываются только от доверенных прокси
+			ipStr := getClientIP(r)
 
 			// Парсим IP адрес
 			ip := net.ParseIP(ipStr)
@@ -64,28 +60,3 @@ func IPAuthMiddleware(trustedSubnet string) func(http.Handler) http.Handler {
 		})
 	}
 }
-
-// getRealIP извлекает реальный IP адрес из запроса
-func getRealIP(r *http.Request) string {
-	// Сначала проверяем X-Real-IP
-	if ip := r.Header.Get("X-Real-IP"); ip != "" {
-		return strings.TrimSpace(ip)
-	}
-
-	// Затем проверяем X-Forwarded-For
-	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
-		// X-Forwarded-For может содержать несколько IP через запятую
-		ips := strings.Split(ip, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	// В конце используем RemoteAddr
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-
-	return ip
-}
@@ -6,12 +6,32 @@ import (
 	"fmt"
 	"net/http"
 	"runtime/debug"
+	"strconv"
 	"time"
 
 	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/metrics"
+	"github.com/Adigezalov/shortener/internal/tracing"
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 )
 
+// Метрики HTTP запросов. Метка route - шаблон маршрута chi ("/{id}"),
+// а не путь запроса, чтобы количество серий не зависело от коротких ID.
+var (
+	httpRequests = metrics.Register(metrics.NewCounterVec(
+		"shortener_http_requests_total",
+		"Количество обработанных HTTP запросов",
+		"method", "route", "status"))
+	httpDuration = metrics.Register(metrics.NewHistogramVec(
+		"shortener_http_request_duration_seconds",
+		"Длительность обработки HTTP запросов в секундах",
+		nil, "method", "route"))
+)
+
+// unmatchedRoute - значение метки route для запроса, не совпавшего ни с одним маршрутом
+const unmatchedRoute = "unmatched"
+
 // contextKey пользовательский тип для ключей контекста
 type contextKey string
 
@@ -38,7 +58,14 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
-// RequestLogger middleware для логирования запросов и ответов
+// Unwrap возвращает исходный http.ResponseWriter для http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// RequestLogger middleware для логирования запросов и ответов.
+// Также учитывает запрос в метриках shortener_http_requests_total
+// и shortener_http_request_duration_seconds.
 func RequestLogger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Время начала обработки запроса
@@ -56,8 +83,13 @@ func RequestLogger(next http.Handler) http.Handler {
 		// Вычисляем длительность обработки запроса
 		duration := time.Since(start)
 
+		// Учитываем запрос в метриках по шаблону маршрута
+		route := routePattern(r)
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(rw.statusCode)).Inc()
+		httpDuration.WithLabelValues(r.Method, route).Observe(duration.Seconds())
+
 		// Логируем информацию о запросе и ответе
-		logger.Logger.Info("HTTP request",
+		logger.Ctx(r.Context()).Info("HTTP request",
 			zap.String("method", r.Method),
 			zap.String("uri", r.RequestURI),
 			zap.Int("status", rw.statusCode),
@@ -67,16 +99,31 @@ func RequestLogger(next http.Handler) http.Handler {
 	})
 }
 
+// routePattern возвращает шаблон маршрута chi, совпавшего с запросом.
+// Вызывается после обработки запроса, когда маршрут уже определен.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+	return unmatchedRoute
+}
+
 // LoggingRecoverer middleware для восстановления после паники с логированием
 func LoggingRecoverer(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
+				// Прерывание ответа после начала отправки передается net/http,
+				// чтобы клиент получил оборванное соединение, а не 500 в теле
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
 				// Получаем стек вызовов
 				stack := debug.Stack()
 
 				// Логируем панику
-				logger.Logger.Error("Паника в обработчике HTTP",
+				logger.Ctx(r.Context()).Error("Паника в обработчике HTTP",
 					zap.Any("error", err),
 					zap.ByteString("stack", stack),
 					zap.String("method", r.Method),
@@ -93,11 +140,16 @@ func LoggingRecoverer(next http.Handler) http.Handler {
 	})
 }
 
-// WithRequestID добавляет уникальный идентификатор запроса в контекст и логи
+// WithRequestID добавляет уникальный идентификатор запроса в контекст и логи.
+// Если запрос трассируется, в качестве ID используется идентификатор
+// трассировки, чтобы X-Request-ID совпадал с trace_id в логах и span'ах.
 func WithRequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Генерируем уникальный ID запроса
+		// Берем ID трассировки или генерируем уникальный ID запроса
 		requestID := generateRequestID()
+		if sc := tracing.SpanContextFromContext(r.Context()); sc.IsValid() {
+			requestID = sc.TraceID.String()
+		}
 
 		// Добавляем ID запроса в заголовок ответа
 		w.Header().Set("X-Request-ID", requestID)
@@ -106,7 +158,7 @@ func WithRequestID(next http.Handler) http.Handler {
 		ctx := context.WithValue(r.Context(), requestIDKey, requestID)
 
 		// Логируем с ID запроса
-		logger.Logger.Info("Request started",
+		logger.Ctx(r.Context()).Info("Request started",
 			zap.String("request_id", requestID),
 			zap.String("method", r.Method),
 			zap.String("uri", r.RequestURI),
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/ratelimit"
+	"github.com/go-chi/chi/v5"
+)
+
+// RateLimit middleware ограничивает частоту запросов правилами limiter.
+//
+// Маршрут запроса определяется шаблоном chi ("POST /api/shorten/batch"),
+// поэтому middleware подключается к корневому роутеру после Authentication:
+// клиент определяется ID пользователя учетной записи, а для анонимного
+// запроса (в том числе с подписанным ID) - IP-адресом, определенным
+// middleware ClientIP (без него - адресом соединения). Ответы содержат
+// заголовки RateLimit-*, при превышении возвращается 429 с Retry-After.
+// При limiter == nil запросы не ограничиваются.
+func RateLimit(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := findRoute(r)
+			if route == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, ok := limiter.Allow(r.Context(), rateLimitRequest(r, r.Method+" "+route))
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for name, value := range res.Headers() {
+				w.Header().Set(name, value)
+			}
+			if !res.Allowed {
+				http.Error(w, "Слишком много запросов", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ChargeRateLimit забирает токен маршрута route для клиента запроса r,
+// определяемого так же, как в RateLimit. Используется обработчиками,
+// выполняющими в одном запросе работу нескольких (импорт URL учитывает
+// каждый пакет как запрос POST /api/shorten/batch). Второе значение false
+// означает, что ограничение не применялось (см. ratelimit.Limiter.Allow).
+func ChargeRateLimit(limiter *ratelimit.Limiter, r *http.Request, route string) (ratelimit.Result, bool) {
+	if limiter == nil {
+		return ratelimit.Result{}, false
+	}
+	return limiter.Allow(r.Context(), rateLimitRequest(r, route))
+}
+
+// rateLimitRequest описывает запрос r к маршруту route для ограничителя
+func rateLimitRequest(r *http.Request, route string) ratelimit.Request {
+	// Подписанный анонимный ID можно получить на каждый запрос, поэтому
+	// по пользователю различаются только запросы с токеном учетной записи
+	req := ratelimit.Request{Route: route, IP: getClientIP(r)}
+	if identity, ok := auth.IdentityFromContext(r.Context()); ok {
+		req.UserID = identity.UserID
+	}
+	return req
+}
+
+// findRoute возвращает шаблон маршрута chi, которому соответствует запрос,
+// до выполнения маршрутизации. Для неизвестного маршрута возвращает "".
+func findRoute(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+
+	path := rctx.RoutePath
+	if path == "" {
+		path = r.URL.RawPath
+		if path == "" {
+			path = r.URL.Path
+		}
+	}
+	return rctx.Routes.Find(chi.NewRouteContext(), r.Method, path)
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Adigezalov/shortener/internal/tracing"
+)
+
+// Tracing middleware продолжает трассировку из заголовка traceparent
+// (или начинает новую) и создает серверный span запроса. Имя span'а
+// содержит шаблон маршрута chi, а не путь запроса. Ответы 5xx
+// отмечаются как ошибка.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Extract(r.Context(), r.Header.Get(tracing.TraceparentHeader))
+		ctx, span := tracing.Start(ctx, "HTTP "+r.Method,
+			tracing.WithKind(tracing.SpanKindServer),
+			tracing.WithAttributes(
+				tracing.String("http.method", r.Method),
+				tracing.String("http.target", r.URL.Path),
+			))
+		defer span.End()
+
+		rw := &responseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		route := routePattern(r)
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(
+			tracing.String("http.route", route),
+			tracing.Int("http.status_code", rw.statusCode),
+		)
+		if rw.statusCode >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("HTTP %d", rw.statusCode))
+		}
+	})
+}
@@ -4,18 +4,28 @@
 // а также внутренние модели для работы с хранилищем данных.
 package models
 
+import "time"
+
 // ShortenRequest представляет запрос на сокращение URL через JSON API.
 //
 // Используется в эндпоинте POST /api/shorten для получения URL,
-// который необходимо сократить.
+// который необходимо сократить. Необязательное поле alias задает
+// собственный короткий идентификатор вместо случайного. Срок действия
+// ссылки задается либо абсолютным моментом expires_at (RFC 3339),
+// либо относительным ttl_seconds, но не обоими сразу.
 //
 // Пример JSON:
 //
 //	{
-//	  "url": "https://example.com/very/long/url"
+//	  "url": "https://example.com/very/long/url",
+//	  "alias": "q3-report",
+//	  "expires_at": "2025-12-31T23:59:59Z"
 //	}
 type ShortenRequest struct {
-	URL string `json:"url"` // URL для сокращения
+	URL        string     `json:"url"`                   // URL для сокращения
+	Alias      string     `json:"alias,omitempty"`       // Желаемый короткий идентификатор (опционально)
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`  // Момент истечения срока действия (опционально)
+	TTLSeconds int64      `json:"ttl_seconds,omitempty"` // Срок действия в секундах (опционально)
 }
 
 // ShortenResponse представляет ответ с сокращенным URL через JSON API.
@@ -42,53 +52,136 @@ type ShortenResponse struct {
 //
 //	{
 //	  "correlation_id": "user_request_1",
-//	  "original_url": "https://example.com/page1"
+//	  "original_url": "https://example.com/page1",
+//	  "alias": "page-one"
 //	}
 type BatchShortenRequest struct {
-	CorrelationID string `json:"correlation_id"` // Идентификатор для связи запроса с ответом
-	OriginalURL   string `json:"original_url"`   // Оригинальный URL для сокращения
+	CorrelationID string     `json:"correlation_id"`        // Идентификатор для связи запроса с ответом
+	OriginalURL   string     `json:"original_url"`          // Оригинальный URL для сокращения
+	Alias         string     `json:"alias,omitempty"`       // Желаемый короткий идентификатор (опционально)
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`  // Момент истечения срока действия (опционально)
+	TTLSeconds    int64      `json:"ttl_seconds,omitempty"` // Срок действия в секундах (опционально)
 }
 
 // BatchShortenResponse представляет элемент ответа на пакетное сокращение URL.
 //
 // Возвращается эндпоинтом POST /api/shorten/batch для каждого
-// успешно обработанного URL из запроса.
+// элемента запроса. Поле status принимает значения "created" (URL сокращен),
+// "existing" (URL был сокращен ранее) и "error" (причина в поле error).
 //
 // Пример JSON элемента:
 //
 //	{
 //	  "correlation_id": "user_request_1",
-//	  "short_url": "http://localhost:8080/abc123"
+//	  "short_url": "http://localhost:8080/abc123",
+//	  "status": "created"
 //	}
 type BatchShortenResponse struct {
-	CorrelationID string `json:"correlation_id"` // Идентификатор из соответствующего запроса
-	ShortURL      string `json:"short_url"`      // Созданный короткий URL
+	CorrelationID string `json:"correlation_id"`       // Идентификатор из соответствующего запроса
+	ShortURL      string `json:"short_url,omitempty"`  // Созданный или существующий короткий URL
+	Status        string `json:"status"`               // Результат обработки элемента
+	Error         string `json:"error,omitempty"`      // Описание ошибки для статуса "error"
+	ErrorCode     string `json:"error_code,omitempty"` // Код нарушения политики URL (например, "private_address")
+}
+
+// ImportResult представляет результат импорта строки CSV или JSONL.
+//
+// Возвращается эндпоинтом POST /api/user/urls/import построчно (JSONL) в порядке
+// входных строк. Поле status принимает значения "created", "existing" и "error",
+// как в BatchShortenResponse, а строка со статусом "aborted" (без поля line)
+// завершает ответ, если импорт прерван сбоем хранилища: строки после нее
+// не импортированы.
+//
+// Пример JSON элемента:
+//
+//	{
+//	  "line": 2,
+//	  "original_url": "https://example.com/page",
+//	  "short_url": "http://localhost:8080/abc123",
+//	  "status": "created"
+//	}
+type ImportResult struct {
+	Line        int    `json:"line,omitempty"`         // Номер строки во входных данных (с 1)
+	OriginalURL string `json:"original_url,omitempty"` // Оригинальный URL из строки
+	ShortURL    string `json:"short_url,omitempty"`    // Созданный или существующий короткий URL
+	Status      string `json:"status"`                 // Результат обработки строки
+	Error       string `json:"error,omitempty"`        // Описание ошибки для статусов "error" и "aborted"
+	ErrorCode   string `json:"error_code,omitempty"`   // Код нарушения политики URL (например, "private_address")
+}
+
+// ErrorResponse представляет ошибку с машиночитаемым кодом.
+//
+// Возвращается с кодом 400 Bad Request эндпоинтами создания коротких URL,
+// когда оригинальный URL нарушает политику (см. пакет urlpolicy).
+//
+// Пример JSON:
+//
+//	{
+//	  "code": "scheme_not_allowed",
+//	  "message": "схема \"javascript\" не разрешена"
+//	}
+type ErrorResponse struct {
+	Code    string `json:"code"`    // Код ошибки
+	Message string `json:"message"` // Описание ошибки
 }
 
+// Типы событий в журнале файлового хранилища.
+const (
+	URLRecordEventAdd      = "add"      // Добавление URL (также для записей без поля event)
+	URLRecordEventDelete   = "delete"   // Удаление URL владельцем (tombstone)
+	URLRecordEventReassign = "reassign" // Передача всех URL пользователя previous_user_id пользователю user_id
+	URLRecordEventMove     = "move"     // Передача ссылки short_url пользователю user_id модератором
+	URLRecordEventDisable  = "disable"  // Отключение ссылки модератором
+	URLRecordEventEnable   = "enable"   // Включение ранее отключенной ссылки
+	URLRecordEventPurge    = "purge"    // Безвозвратное удаление ссылки модератором
+	URLRecordEventCounter  = "counter"  // Граница номеров, выданных генератору ID
+)
+
 // URLRecord представляет запись URL для сохранения в файловом хранилище.
 //
 // Используется для сериализации данных в JSON формат при сохранении
-// в файл. Содержит всю необходимую информацию для восстановления URL.
+// в файл. Файл является журналом событий: записи добавления URL
+// и записи удаления (tombstone) воспроизводятся по порядку при восстановлении.
+// Записи старого формата без полей event и user_id читаются как добавление
+// URL без владельца. Те же записи передаются при переносе ссылок между
+// хранилищами (storage.RecordStorage).
+//
+// Пример строк файла:
+//
+//	{"uuid":"1","short_url":"abc123","original_url":"https://example.com","user_id":"u1"}
+//	{"uuid":"2","short_url":"abc123","original_url":"","event":"delete","user_id":"u1"}
+//	{"uuid":"3","short_url":"","original_url":"","event":"reassign","user_id":"u2","previous_user_id":"u1"}
+//	{"uuid":"4","short_url":"abc123","original_url":"","event":"disable"}
+//	{"uuid":"5","short_url":"","original_url":"","event":"counter","counter":200}
 type URLRecord struct {
-	UUID        string `json:"uuid"`         // Уникальный идентификатор пользователя
-	ShortURL    string `json:"short_url"`    // Короткий идентификатор URL
-	OriginalURL string `json:"original_url"` // Оригинальный URL
+	UUID        string     `json:"uuid"`                 // Порядковый номер записи
+	ShortURL    string     `json:"short_url"`            // Короткий идентификатор URL
+	OriginalURL string     `json:"original_url"`         // Оригинальный URL
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Момент истечения срока действия
+	CreatedAt   *time.Time `json:"created_at,omitempty"` // Момент создания (нет в записях старого формата)
+	Event       string     `json:"event,omitempty"`      // Тип события (пустой - добавление)
+	UserID      string     `json:"user_id,omitempty"`    // Владелец URL
+
+	PreviousUserID string `json:"previous_user_id,omitempty"` // Прежний владелец (для event "reassign" и "move")
+	Counter        uint64 `json:"counter,omitempty"`          // Граница номеров генератора ID (для event "counter")
 }
 
 // UserURL представляет URL пользователя для API ответов.
 //
 // Используется в эндпоинте GET /api/user/urls для возврата
-// списка всех URL, созданных пользователем.
+// списка URL, созданных пользователем.
 //
 // Пример JSON элемента:
 //
 //	{
 //	  "short_url": "http://localhost:8080/abc123",
-//	  "original_url": "https://example.com/page1"
+//	  "original_url": "https://example.com/page1",
+//	  "created_at": "2025-01-02T10:00:00Z"
 //	}
 type UserURL struct {
-	ShortURL    string `json:"short_url"`    // Короткий URL
-	OriginalURL string `json:"original_url"` // Оригинальный URL
+	ShortURL    string     `json:"short_url"`            // Короткий URL
+	OriginalURL string     `json:"original_url"`         // Оригинальный URL
+	CreatedAt   *time.Time `json:"created_at,omitempty"` // Момент создания (если известен)
 }
 
 // URLStorage представляет запись URL в базе данных.
@@ -101,3 +194,215 @@ type URLStorage struct {
 	OriginalURL string `db:"original_url"` // Оригинальный URL
 	DeletedFlag bool   `db:"is_deleted"`   // Флаг удаления (мягкое удаление)
 }
+
+// Click представляет одно событие перехода по короткой ссылке.
+//
+// Используется асинхронным регистратором переходов для пакетной
+// записи в хранилище аналитики.
+type Click struct {
+	ShortID         string    // Короткий идентификатор URL
+	ClickedAt       time.Time // Момент перехода
+	Referrer        string    // Хост источника перехода (пустой для прямых переходов)
+	UserAgentFamily string    // Семейство клиента: Chrome, Firefox, Bot и т.д.
+}
+
+// StatsCounter представляет значение с количеством переходов.
+//
+// Пример JSON элемента:
+//
+//	{
+//	  "value": "Chrome",
+//	  "clicks": 42
+//	}
+type StatsCounter struct {
+	Value  string `json:"value"`  // Значение (хост источника или семейство клиента)
+	Clicks int64  `json:"clicks"` // Количество переходов
+}
+
+// HourlyClicks представляет количество переходов за один час.
+type HourlyClicks struct {
+	Hour   time.Time `json:"hour"`   // Начало часа (UTC)
+	Clicks int64     `json:"clicks"` // Количество переходов за час
+}
+
+// URLStats представляет статистику переходов по короткой ссылке.
+//
+// Возвращается эндпоинтом GET /api/user/urls/{id}/stats.
+//
+// Пример JSON:
+//
+//	{
+//	  "short_url": "http://localhost:8080/abc123",
+//	  "clicks": 3,
+//	  "first_click_at": "2025-01-01T10:15:00Z",
+//	  "last_click_at": "2025-01-01T11:05:00Z",
+//	  "referrers": [{"value": "t.me", "clicks": 2}],
+//	  "user_agents": [{"value": "Chrome", "clicks": 3}],
+//	  "hourly": [{"hour": "2025-01-01T10:00:00Z", "clicks": 1}]
+//	}
+type URLStats struct {
+	ShortURL     string         `json:"short_url"`                // Короткий URL
+	Clicks       int64          `json:"clicks"`                   // Общее количество переходов
+	FirstClickAt *time.Time     `json:"first_click_at,omitempty"` // Первый переход
+	LastClickAt  *time.Time     `json:"last_click_at,omitempty"`  // Последний переход
+	Referrers    []StatsCounter `json:"referrers"`                // Источники переходов
+	UserAgents   []StatsCounter `json:"user_agents"`              // Семейства клиентов
+	Hourly       []HourlyClicks `json:"hourly"`                   // Почасовая история
+}
+
+// AuthRequest представляет запрос регистрации или входа.
+//
+// Используется в эндпоинтах POST /api/auth/register и POST /api/auth/login.
+//
+// Пример JSON:
+//
+//	{
+//	  "login": "alice",
+//	  "password": "correct horse battery"
+//	}
+type AuthRequest struct {
+	Login    string `json:"login"`    // Логин учетной записи
+	Password string `json:"password"` // Пароль
+}
+
+// AuthResponse представляет ответ на регистрацию или вход.
+//
+// Токен сессии также устанавливается в куку session и может
+// передаваться в заголовке Authorization: Bearer <token>.
+//
+// Пример JSON:
+//
+//	{
+//	  "user_id": "0b9c3c1e-6f0a-4d8e-9a57-0c7f1d0e2a11",
+//	  "login": "alice",
+//	  "token": "ss_Qm9vZ...",
+//	  "expires_at": "2025-02-01T10:00:00Z",
+//	  "claimed": 3
+//	}
+type AuthResponse struct {
+	UserID    string    `json:"user_id"`    // ID пользователя учетной записи
+	Login     string    `json:"login"`      // Логин учетной записи
+	Token     string    `json:"token"`      // Токен сессии
+	ExpiresAt time.Time `json:"expires_at"` // Момент истечения сессии
+	Claimed   int       `json:"claimed"`    // Количество URL, перенесенных из анонимного профиля
+}
+
+// ClaimRequest представляет запрос переноса URL анонимного пользователя
+// в учетную запись.
+//
+// Используется в эндпоинте POST /api/auth/claim. Поле token содержит
+// подписанный ID анонимного пользователя (значение куки user_id).
+type ClaimRequest struct {
+	Token string `json:"token"` // Подписанный ID анонимного пользователя
+}
+
+// ClaimResponse представляет результат переноса URL в учетную запись.
+type ClaimResponse struct {
+	Claimed int `json:"claimed"` // Количество перенесенных URL
+}
+
+// APIKeyRequest представляет запрос создания API-ключа.
+//
+// Используется в эндпоинте POST /api/auth/keys.
+type APIKeyRequest struct {
+	Name string `json:"name"` // Имя ключа
+}
+
+// APIKey представляет API-ключ учетной записи.
+//
+// Возвращается эндпоинтами /api/auth/keys. Поле key содержит полный
+// ключ и заполняется только в ответе на создание.
+//
+// Пример JSON:
+//
+//	{
+//	  "id": "5f1d7c2a-3b4e-4c6d-8e9f-0a1b2c3d4e5f",
+//	  "name": "ci",
+//	  "prefix": "sk_Zm9vYm",
+//	  "created_at": "2025-01-01T10:00:00Z",
+//	  "key": "sk_Zm9vYmFy..."
+//	}
+type APIKey struct {
+	ID        string     `json:"id"`                   // ID ключа
+	Name      string     `json:"name"`                 // Имя ключа
+	Prefix    string     `json:"prefix"`               // Начало ключа для опознания
+	CreatedAt time.Time  `json:"created_at"`           // Момент создания
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // Момент отзыва
+	Key       string     `json:"key,omitempty"`        // Полный ключ (только при создании)
+}
+
+// AdminLink представляет ссылку в ответах API модерации.
+//
+// Возвращается эндпоинтами GET /api/admin/links и GET /api/admin/links/{id}.
+// Поле user_id пустое для ссылки без владельца, created_at отсутствует
+// у ссылок, созданных до появления модерации в файловом хранилище.
+//
+// Пример JSON:
+//
+//	{
+//	  "short_id": "abc123",
+//	  "short_url": "http://localhost:8080/abc123",
+//	  "original_url": "https://example.com/page1",
+//	  "user_id": "0b9c3c1e-6f0a-4d8e-9a57-0c7f1d0e2a11",
+//	  "created_at": "2025-01-01T10:00:00Z",
+//	  "deleted": false,
+//	  "disabled": true
+//	}
+type AdminLink struct {
+	ShortID     string     `json:"short_id"`             // Короткий идентификатор
+	ShortURL    string     `json:"short_url"`            // Короткий URL
+	OriginalURL string     `json:"original_url"`         // Оригинальный URL
+	UserID      string     `json:"user_id,omitempty"`    // Владелец ссылки
+	CreatedAt   *time.Time `json:"created_at,omitempty"` // Момент создания
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Момент истечения срока действия
+	Deleted     bool       `json:"deleted"`              // Ссылка удалена владельцем или очисткой
+	Disabled    bool       `json:"disabled"`             // Ссылка отключена модератором
+}
+
+// AdminLinksResponse представляет страницу результатов поиска ссылок.
+//
+// Возвращается эндпоинтом GET /api/admin/links. Поле total содержит
+// количество найденных ссылок на всех страницах.
+type AdminLinksResponse struct {
+	Links  []AdminLink `json:"links"`  // Ссылки текущей страницы
+	Total  int         `json:"total"`  // Всего найдено ссылок
+	Offset int         `json:"offset"` // Смещение страницы
+	Limit  int         `json:"limit"`  // Размер страницы
+}
+
+// AdminLinksRequest представляет групповую операцию над ссылками.
+//
+// Используется в эндпоинтах POST /api/admin/links/{disable,enable,delete,reassign}.
+// Для reassign новый владелец задается полем user_id или login.
+//
+// Пример JSON:
+//
+//	{
+//	  "ids": ["abc123", "def456"],
+//	  "login": "alice"
+//	}
+type AdminLinksRequest struct {
+	IDs    []string `json:"ids"`               // Короткие идентификаторы ссылок
+	UserID string   `json:"user_id,omitempty"` // ID нового владельца (только reassign)
+	Login  string   `json:"login,omitempty"`   // Логин нового владельца (только reassign)
+}
+
+// AdminLinksResult представляет результат групповой операции над ссылками.
+type AdminLinksResult struct {
+	Affected int `json:"affected"` // Количество ссылок, состояние которых изменилось
+}
+
+// AccountRoleRequest представляет запрос изменения роли учетной записи.
+//
+// Используется в эндпоинте PUT /api/admin/accounts/{login}/role.
+// Допустимые роли: "user" и "admin".
+type AccountRoleRequest struct {
+	Role string `json:"role"` // Новая роль
+}
+
+// AccountRoleResponse представляет учетную запись с ее ролью.
+type AccountRoleResponse struct {
+	UserID string `json:"user_id"` // ID пользователя учетной записи
+	Login  string `json:"login"`   // Логин учетной записи
+	Role   string `json:"role"`    // Роль учетной записи
+}
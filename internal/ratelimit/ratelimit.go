@@ -0,0 +1,362 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму
+// корзины токенов (token bucket).
+//
+// Для каждого маршрута и клиента ведется отдельная корзина: запрос забирает
+// из нее один токен, а токены восстанавливаются с постоянной скоростью до
+// емкости корзины. Клиент определяется ID аутентифицированного пользователя
+// или IP-адресом. Состояние корзин хранится в Store: в памяти процесса
+// (MemoryStore) или в PostgreSQL (DatabaseStore), общем для всех экземпляров.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// DefaultRoute - маршрут правила, применяемого к маршрутам без собственного правила
+const DefaultRoute = "*"
+
+// Заголовки ответа с состоянием ограничения (draft-ietf-httpapi-ratelimit-headers)
+const (
+	HeaderLimit      = "RateLimit-Limit"     // Емкость корзины
+	HeaderRemaining  = "RateLimit-Remaining" // Оставшиеся токены
+	HeaderReset      = "RateLimit-Reset"     // Секунды до полного восстановления корзины
+	HeaderPolicy     = "RateLimit-Policy"    // Квота: "<count>;w=<период в секундах>;burst=<емкость>"
+	HeaderRetryAfter = "Retry-After"         // Секунды до появления токена (только при отказе)
+)
+
+// Метрики ограничения запросов
+var (
+	rejectedRequests = metrics.Register(metrics.NewCounterVec(
+		"shortener_rate_limit_rejected_total",
+		"Количество запросов, отклоненных ограничением частоты",
+		"route"))
+	storeErrors = metrics.Register(metrics.NewCounterVec(
+		"shortener_rate_limit_store_errors_total",
+		"Количество ошибок хранилища ограничения частоты (запрос пропускается)",
+		"route"))
+)
+
+// Scope определяет, по какому признаку различаются клиенты правила
+type Scope string
+
+const (
+	// ScopeUser - по ID пользователя учетной записи, а для анонимных
+	// запросов, в том числе с подписанным ID, - по IP-адресу
+	ScopeUser Scope = "user"
+
+	// ScopeIP - всегда по IP-адресу клиента
+	ScopeIP Scope = "ip"
+)
+
+// Limit - параметры корзины токенов: Count запросов за Period
+// с накоплением не более Burst токенов
+type Limit struct {
+	Count  int
+	Period time.Duration
+	Burst  int // 0 соответствует Count
+}
+
+// burst возвращает емкость корзины
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Count
+}
+
+// rate возвращает скорость восстановления токенов в секунду
+func (l Limit) rate() float64 {
+	return float64(l.Count) / l.Period.Seconds()
+}
+
+// Policy возвращает значение заголовка RateLimit-Policy
+func (l Limit) Policy() string {
+	return fmt.Sprintf("%d;w=%d;burst=%d", l.Count, int64(math.Ceil(l.Period.Seconds())), l.burst())
+}
+
+// bucket - состояние корзины токенов
+type bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// take восстанавливает токены корзины b на момент now и забирает один токен.
+// Отсутствующая корзина (exists == false) считается полной.
+func (l Limit) take(b bucket, exists bool, now time.Time) (bucket, Result) {
+	burst := float64(l.burst())
+	rate := l.rate()
+
+	tokens := burst
+	if exists {
+		elapsed := now.Sub(b.Updated).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		tokens = math.Min(burst, b.Tokens+elapsed*rate)
+	}
+
+	res := Result{Limit: l.burst(), Policy: l.Policy()}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	res.Remaining = int(tokens)
+	res.Reset = seconds((burst - tokens) / rate)
+	return bucket{Tokens: tokens, Updated: now}, res
+}
+
+// seconds преобразует количество секунд в time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Result - результат проверки запроса
+type Result struct {
+	Allowed    bool          // Запрос разрешен
+	Limit      int           // Емкость корзины
+	Remaining  int           // Оставшиеся токены
+	Reset      time.Duration // Время до полного восстановления корзины
+	RetryAfter time.Duration // Время до появления токена (только при отказе)
+	Policy     string        // Значение заголовка RateLimit-Policy
+}
+
+// Headers возвращает заголовки ответа с состоянием ограничения
+func (r Result) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderLimit:     strconv.Itoa(r.Limit),
+		HeaderRemaining: strconv.Itoa(r.Remaining),
+		HeaderReset:     ceilSeconds(r.Reset),
+		HeaderPolicy:    r.Policy,
+	}
+	if !r.Allowed {
+		headers[HeaderRetryAfter] = ceilSeconds(r.RetryAfter)
+	}
+	return headers
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// Rule - ограничение для маршрута
+type Rule struct {
+	// Route - маршрут: "<МЕТОД> <шаблон chi>" (POST /api/shorten/batch),
+	// шаблон без метода (любой метод), полное имя gRPC метода
+	// (/shortener.ShortenerService/ShortenBatch) или DefaultRoute.
+	Route string
+	Limit Limit
+	Scope Scope
+}
+
+// ParseRules разбирает список правил.
+//
+// Правила разделяются точкой с запятой или переводом строки и имеют формат
+//
+//	<маршрут>=<count>/<период>[ burst=<n>][ by=user|ip]
+//
+// где период - s, m, h или длительность в формате time.ParseDuration (30s).
+// Например: "POST /api/shorten/batch=10/m burst=20 by=ip; *=100/s".
+func ParseRules(value string) ([]Rule, error) {
+	var rules []Rule
+	seen := make(map[string]bool)
+	for _, line := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '\n' }) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		rule, err := parseRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("правило ограничения %q: %w", line, err)
+		}
+		if seen[rule.Route] {
+			return nil, fmt.Errorf("правило ограничения для маршрута %q задано несколько раз", rule.Route)
+		}
+		seen[rule.Route] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseRule разбирает одно правило
+func parseRule(line string) (Rule, error) {
+	route, spec, ok := strings.Cut(line, "=")
+	if !ok {
+		return Rule{}, errors.New("ожидается <маршрут>=<count>/<период>")
+	}
+	route, err := normalizeRoute(route)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return Rule{}, errors.New("не задана квота")
+	}
+	limit, err := parseLimit(fields[0])
+	if err != nil {
+		return Rule{}, err
+	}
+
+	rule := Rule{Route: route, Limit: limit, Scope: ScopeUser}
+	for _, option := range fields[1:] {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "burst":
+			burst, err := strconv.Atoi(value)
+			if err != nil || burst < 1 {
+				return Rule{}, fmt.Errorf("некорректная емкость корзины %q", value)
+			}
+			rule.Limit.Burst = burst
+		case "by":
+			switch scope := Scope(value); scope {
+			case ScopeUser, ScopeIP:
+				rule.Scope = scope
+			default:
+				return Rule{}, fmt.Errorf("неизвестный признак клиента %q (допустимо: user, ip)", value)
+			}
+		default:
+			return Rule{}, fmt.Errorf("неизвестный параметр %q (допустимо: burst, by)", option)
+		}
+	}
+	return rule, nil
+}
+
+// normalizeRoute проверяет маршрут правила и приводит метод к верхнему регистру
+func normalizeRoute(route string) (string, error) {
+	route = strings.TrimSpace(route)
+	if route == DefaultRoute {
+		return route, nil
+	}
+
+	method, pattern, hasMethod := strings.Cut(route, " ")
+	if !hasMethod {
+		method, pattern = "", route
+	}
+	pattern = strings.TrimSpace(pattern)
+	if !strings.HasPrefix(pattern, "/") {
+		return "", errors.New("маршрут должен начинаться с /")
+	}
+	if method == "" {
+		return pattern, nil
+	}
+	return strings.ToUpper(method) + " " + pattern, nil
+}
+
+// parseLimit разбирает квоту "<count>/<период>"
+func parseLimit(value string) (Limit, error) {
+	countValue, periodValue, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("некорректная квота %q: ожидается <count>/<период>", value)
+	}
+	count, err := strconv.Atoi(countValue)
+	if err != nil || count < 1 {
+		return Limit{}, fmt.Errorf("некорректное количество запросов %q", countValue)
+	}
+
+	var period time.Duration
+	switch periodValue {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		period, err = time.ParseDuration(periodValue)
+		if err != nil || period <= 0 {
+			return Limit{}, fmt.Errorf("некорректный период %q (допустимо: s, m, h или длительность)", periodValue)
+		}
+	}
+	return Limit{Count: count, Period: period}, nil
+}
+
+// Store хранит состояние корзин токенов
+type Store interface {
+	// Take забирает токен из корзины key с параметрами limit на момент now
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// Request - проверяемый запрос
+type Request struct {
+	Route  string // Маршрут: "<МЕТОД> <шаблон chi>" или полное имя gRPC метода
+	UserID string // ID пользователя учетной записи; пустой для анонимного запроса
+	IP     string // IP-адрес клиента
+}
+
+// Limiter применяет правила ограничения к запросам
+type Limiter struct {
+	rules map[string]Rule
+	store Store
+	now   func() time.Time
+}
+
+// New создает ограничитель с правилами rules и хранилищем корзин store
+func New(rules []Rule, store Store) *Limiter {
+	l := &Limiter{rules: make(map[string]Rule, len(rules)), store: store, now: time.Now}
+	for _, rule := range rules {
+		l.rules[rule.Route] = rule
+	}
+	return l
+}
+
+// match выбирает правило маршрута: правило с методом, затем правило
+// шаблона без метода, затем правило DefaultRoute
+func (l *Limiter) match(route string) (Rule, bool) {
+	if rule, ok := l.rules[route]; ok {
+		return rule, true
+	}
+	if _, pattern, ok := strings.Cut(route, " "); ok {
+		if rule, ok := l.rules[pattern]; ok {
+			return rule, true
+		}
+	}
+	rule, ok := l.rules[DefaultRoute]
+	return rule, ok
+}
+
+// Allow забирает токен для запроса. Второе значение false означает,
+// что ограничение к запросу не применялось: для маршрута нет правила или
+// хранилище недоступно. При ошибке хранилища запрос пропускается, чтобы
+// сбой хранилища не останавливал сервис.
+func (l *Limiter) Allow(ctx context.Context, req Request) (Result, bool) {
+	rule, ok := l.match(req.Route)
+	if !ok {
+		return Result{}, false
+	}
+
+	client := "ip:" + req.IP
+	if rule.Scope == ScopeUser && req.UserID != "" {
+		client = "user:" + req.UserID
+	}
+
+	res, err := l.store.Take(ctx, req.Route+"|"+client, rule.Limit, l.now())
+	if err != nil {
+		storeErrors.WithLabelValues(req.Route).Inc()
+		logger.Ctx(ctx).Error("Ошибка хранилища ограничения частоты запросов",
+			zap.String("route", req.Route),
+			zap.Error(err))
+		return Result{}, false
+	}
+
+	if !res.Allowed {
+		rejectedRequests.WithLabelValues(req.Route).Inc()
+		logger.Ctx(ctx).Debug("Превышено ограничение частоты запросов",
+			zap.String("route", req.Route),
+			zap.String("client", client))
+	}
+	return res, true
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("post /api/shorten/batch=10/m burst=20 by=ip;\n/{id}=100/s\n *=5/30s ;")
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{Route: "POST /api/shorten/batch", Limit: Limit{Count: 10, Period: time.Minute, Burst: 20}, Scope: ScopeIP},
+		{Route: "/{id}", Limit: Limit{Count: 100, Period: time.Second}, Scope: ScopeUser},
+		{Route: "*", Limit: Limit{Count: 5, Period: 30 * time.Second}, Scope: ScopeUser},
+	}, rules)
+
+	rules, err = ParseRules("")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	invalid := []string{
+		"/api/shorten",
+		"/api/shorten=10",
+		"/api/shorten=0/s",
+		"/api/shorten=10/d",
+		"/api/shorten=10/-1s",
+		"api/shorten=10/s",
+		"/api/shorten=10/s burst=0",
+		"/api/shorten=10/s by=session",
+		"/api/shorten=10/s window=1m",
+		"/api/shorten=10/s; /api/shorten=20/s",
+	}
+	for _, value := range invalid {
+		t.Run(value, func(t *testing.T) {
+			_, err := ParseRules(value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	store := NewMemoryStore()
+	l := New([]Rule{
+		{Route: "POST /api/shorten", Limit: Limit{Count: 2, Period: time.Minute}, Scope: ScopeUser},
+		{Route: "/{id}", Limit: Limit{Count: 1, Period: time.Second, Burst: 3}, Scope: ScopeIP},
+	}, store)
+	now := time.Unix(1_700_000_000, 0)
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Корзина емкостью 2 восстанавливает токен за 30 секунд
+	req := Request{Route: "POST /api/shorten", UserID: "u1", IP: "10.0.0.1"}
+	for i := 1; i >= 0; i-- {
+		res, ok := l.Allow(ctx, req)
+		require.True(t, ok)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+	res, ok := l.Allow(ctx, req)
+	require.True(t, ok)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 30*time.Second, res.RetryAfter)
+	assert.Equal(t, time.Minute, res.Reset)
+	assert.Equal(t, map[string]string{
+		HeaderLimit:      "2",
+		HeaderRemaining:  "0",
+		HeaderReset:      "60",
+		HeaderPolicy:     "2;w=60;burst=2",
+		HeaderRetryAfter: "30",
+	}, res.Headers())
+
+	// Другой пользователь с того же IP ограничивается отдельно,
+	// анонимный запрос - по IP
+	res, _ = l.Allow(ctx, Request{Route: "POST /api/shorten", UserID: "u2", IP: "10.0.0.1"})
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, Request{Route: "POST /api/shorten", IP: "10.0.0.1"})
+	assert.True(t, res.Allowed)
+
+	now = now.Add(30 * time.Second)
+	res, _ = l.Allow(ctx, req)
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, req)
+	assert.False(t, res.Allowed)
+
+	// Правило шаблона без метода и ограничение по IP
+	redirect := Request{Route: "GET /{id}", UserID: "u1", IP: "10.0.0.2"}
+	for i := 0; i < 3; i++ {
+		res, _ = l.Allow(ctx, redirect)
+		assert.True(t, res.Allowed)
+	}
+	redirect.UserID = "u2"
+	res, _ = l.Allow(ctx, redirect)
+	assert.False(t, res.Allowed)
+
+	// Маршрут без правила не ограничивается
+	_, ok = l.Allow(ctx, Request{Route: "GET /ping", IP: "10.0.0.1"})
+	assert.False(t, ok)
+
+	// Восстановленные корзины удаляются из памяти
+	now = now.Add(time.Hour)
+	res, _ = l.Allow(ctx, redirect)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestLimiter_DefaultRoute(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	l := New([]Rule{
+		{Route: DefaultRoute, Limit: Limit{Count: 1, Period: time.Hour}, Scope: ScopeIP},
+	}, NewMemoryStore())
+	ctx := context.Background()
+
+	// Правило по умолчанию ведет отдельную корзину для каждого маршрута
+	for _, route := range []string{"GET /ping", "/shortener.ShortenerService/ShortenURL"} {
+		res, ok := l.Allow(ctx, Request{Route: route, IP: "10.0.0.1"})
+		require.True(t, ok)
+		assert.True(t, res.Allowed, route)
+		res, _ = l.Allow(ctx, Request{Route: route, IP: "10.0.0.1"})
+		assert.False(t, res.Allowed, route)
+	}
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Backend определяет, где хранятся корзины токенов
+type Backend string
+
+const (
+	// BackendMemory - корзины в памяти процесса. Каждый экземпляр сервиса
+	// ограничивает запросы независимо.
+	BackendMemory Backend = "memory"
+
+	// BackendPostgres - корзины в таблице rate_limits PostgreSQL,
+	// общие для всех экземпляров сервиса.
+	BackendPostgres Backend = "postgres"
+)
+
+// ParseBackend проверяет и преобразует строковое значение хранилища корзин
+func ParseBackend(value string) (Backend, error) {
+	switch b := Backend(value); b {
+	case "":
+		return BackendMemory, nil
+	case BackendMemory, BackendPostgres:
+		return b, nil
+	default:
+		return "", fmt.Errorf("неизвестное хранилище ограничения запросов %q (допустимо: memory, postgres)", value)
+	}
+}
+
+// sweepInterval - период удаления полностью восстановленных корзин
+const sweepInterval = time.Minute
+
+// memoryBucket - корзина MemoryStore с моментом полного восстановления
+type memoryBucket struct {
+	bucket
+	fullAt time.Time
+}
+
+// MemoryStore хранит корзины в памяти процесса.
+//
+// Полностью восстановленные корзины не отличаются от отсутствующих,
+// поэтому периодически удаляются, и память не растет с числом клиентов.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore создает хранилище корзин в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]memoryBucket)}
+}
+
+// Take забирает токен из корзины key
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.fullAt) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, exists := s.buckets[key]
+	updated, res := limit.take(b.bucket, exists, now)
+	s.buckets[key] = memoryBucket{bucket: updated, fullAt: now.Add(res.Reset)}
+	return res, nil
+}
+
+// Len возвращает количество корзин в хранилище
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// DatabaseStore хранит корзины в таблице rate_limits PostgreSQL
+// (миграция 0006_create_rate_limits).
+//
+// Корзина блокируется на время обновления (SELECT ... FOR UPDATE), поэтому
+// одновременные запросы разных экземпляров сервиса не теряют токены.
+// Полностью восстановленные корзины периодически удаляются.
+type DatabaseStore struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewDatabaseStore создает хранилище корзин в PostgreSQL
+func NewDatabaseStore(db *sql.DB) *DatabaseStore {
+	return &DatabaseStore{db: db}
+}
+
+// Take забирает токен из корзины key
+func (s *DatabaseStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (_ Result, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Новая корзина создается полной; ON CONFLICT DO NOTHING ожидает
+	// фиксации одновременной вставки, поэтому SELECT ... FOR UPDATE
+	// всегда находит строку и блокирует ее
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limits (key, tokens, updated_at, full_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (key) DO NOTHING
+	`, key, float64(limit.burst()), now)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var b bucket
+	err = tx.QueryRowContext(ctx, `
+		SELECT tokens, updated_at FROM rate_limits WHERE key = $1 FOR UPDATE
+	`, key).Scan(&b.Tokens, &b.Updated)
+	if err != nil {
+		return Result{}, err
+	}
+
+	b, res := limit.take(b, true, now)
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rate_limits SET tokens = $2, updated_at = $3, full_at = $4 WHERE key = $1
+	`, key, b.Tokens, b.Updated, now.Add(res.Reset))
+	if err != nil {
+		return Result{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return Result{}, err
+	}
+
+	s.sweep(ctx, now)
+	return res, nil
+}
+
+// sweep удаляет полностью восстановленные корзины не чаще sweepInterval
+func (s *DatabaseStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE full_at <= $1`, now); err != nil {
+		logger.Ctx(ctx).Warn("Ошибка удаления восстановленных корзин ограничения запросов", zap.Error(err))
+	}
+}
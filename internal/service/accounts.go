@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/tracing"
+)
+
+// SessionTTL - срок действия сессии, созданной входом по паролю.
+const SessionTTL = 30 * 24 * time.Hour
+
+// Ограничения учетных данных.
+const (
+	MinLoginLength    = 3
+	MaxLoginLength    = 64
+	MinPasswordLength = 8
+	MaxPasswordLength = 72 // Предел bcrypt
+	MaxAPIKeyName     = 64
+)
+
+// WithAccounts подключает к сервису хранилище учетных записей.
+func (s *ShortenerService) WithAccounts(accounts storage.AccountStorage) *ShortenerService {
+	s.accounts = accounts
+	return s
+}
+
+// AuthResult содержит результат регистрации или входа.
+type AuthResult struct {
+	UserID    string    // ID пользователя учетной записи
+	Login     string    // Логин учетной записи
+	Token     string    // Токен сессии
+	ExpiresAt time.Time // Момент истечения сессии
+	Claimed   int       // Количество URL, перенесенных из анонимного профиля
+}
+
+// APIKeyResult содержит созданный API-ключ. Token возвращается только
+// при создании: хранилище содержит лишь его хеш.
+type APIKeyResult struct {
+	Key   storage.Credential
+	Token string
+}
+
+// Register создает учетную запись и сессию.
+//
+// Если запрос выполнен от имени анонимного пользователя anonymousUserID,
+// его URL переносятся в новую учетную запись.
+func (s *ShortenerService) Register(ctx context.Context, login, password, anonymousUserID string) (AuthResult, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.Register")
+	defer span.End()
+
+	if s.accounts == nil {
+		return AuthResult{}, ErrAccountsNotConfigured
+	}
+
+	login, err := normalizeLogin(login)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	if err := validatePassword(password); err != nil {
+		return AuthResult{}, err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	account := storage.Account{
+		ID:           auth.GenerateUserID(),
+		Login:        login,
+		PasswordHash: hash,
+		Role:         auth.RoleUser,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.accounts.CreateAccount(ctx, account); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			return AuthResult{}, ErrLoginTaken
+		}
+		return AuthResult{}, err
+	}
+
+	return s.startSession(ctx, account, anonymousUserID)
+}
+
+// Login проверяет пароль и создает сессию.
+//
+// Если запрос выполнен от имени анонимного пользователя anonymousUserID,
+// его URL переносятся в учетную запись.
+func (s *ShortenerService) Login(ctx context.Context, login, password, anonymousUserID string) (AuthResult, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.Login")
+	defer span.End()
+
+	if s.accounts == nil {
+		return AuthResult{}, ErrAccountsNotConfigured
+	}
+
+	account, err := s.accounts.GetAccountByLogin(ctx, strings.ToLower(strings.TrimSpace(login)))
+	if errors.Is(err, storage.ErrNotFound) {
+		// Сравниваем с фиктивным хешем, чтобы время ответа не выдавало
+		// существование логина
+		auth.CheckPassword(dummyPasswordHash, password)
+		return AuthResult{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return AuthResult{}, err
+	}
+	if !auth.CheckPassword(account.PasswordHash, password) {
+		return AuthResult{}, ErrInvalidCredentials
+	}
+
+	return s.startSession(ctx, account, anonymousUserID)
+}
+
+// dummyPasswordHash - bcrypt хеш для выравнивания времени входа с неизвестным логином
+const dummyPasswordHash = "$2a$10$7EqJtq98hPqEX7fNZaFWoOhi5BkdO.c.vgrz/hxRyCTSh6tDSE8Iq"
+
+// startSession создает сессию учетной записи и переносит в нее URL анонимного пользователя
+func (s *ShortenerService) startSession(ctx context.Context, account storage.Account, anonymousUserID string) (AuthResult, error) {
+	token, hash, display, err := auth.NewToken(auth.SessionTokenPrefix)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	now := time.Now().UTC()
+	session := storage.Credential{
+		ID:        auth.GenerateUserID(),
+		UserID:    account.ID,
+		Kind:      storage.CredentialSession,
+		TokenHash: hash,
+		Prefix:    display,
+		CreatedAt: now,
+		ExpiresAt: now.Add(SessionTTL),
+	}
+	if err := s.accounts.CreateCredential(ctx, session); err != nil {
+		return AuthResult{}, err
+	}
+
+	claimed, err := s.claim(ctx, account.ID, anonymousUserID)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	return AuthResult{
+		UserID:    account.ID,
+		Login:     account.Login,
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		Claimed:   claimed,
+	}, nil
+}
+
+// Logout завершает сессию, которой аутентифицирован запрос.
+// Для запроса с API-ключом ключ не отзывается.
+func (s *ShortenerService) Logout(ctx context.Context, identity auth.Identity) error {
+	if s.accounts == nil {
+		return ErrAccountsNotConfigured
+	}
+	if identity.Kind != string(storage.CredentialSession) {
+		return nil
+	}
+	return s.accounts.RevokeCredential(ctx, identity.UserID, identity.CredentialID, time.Now().UTC())
+}
+
+// ClaimAnonymous переносит URL анонимного пользователя в учетную запись userID.
+//
+// Владение анонимным ID подтверждается подписанным токеном signedUserID
+// (значение куки user_id или заголовка Authorization, выданное анонимному
+// пользователю). Возвращает количество перенесенных URL.
+func (s *ShortenerService) ClaimAnonymous(ctx context.Context, userID, signedUserID string) (int, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.ClaimAnonymous")
+	defer span.End()
+
+	if s.accounts == nil {
+		return 0, ErrAccountsNotConfigured
+	}
+
+	anonymousUserID, err := auth.VerifyUserID(auth.BearerToken(signedUserID))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidClaim, err)
+	}
+	return s.claim(ctx, userID, anonymousUserID)
+}
+
+// claim переносит URL анонимного пользователя в учетную запись.
+// ID, принадлежащий другой учетной записи, не переносится.
+func (s *ShortenerService) claim(ctx context.Context, userID, anonymousUserID string) (int, error) {
+	if anonymousUserID == "" || anonymousUserID == userID {
+		return 0, nil
+	}
+
+	_, err := s.accounts.GetAccount(ctx, anonymousUserID)
+	if err == nil {
+		return 0, ErrInvalidClaim
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return 0, err
+	}
+
+	return s.accounts.ReassignUserURLs(ctx, anonymousUserID, userID)
+}
+
+// CreateAPIKey создает именованный API-ключ учетной записи.
+func (s *ShortenerService) CreateAPIKey(ctx context.Context, userID, name string) (APIKeyResult, error) {
+	if s.accounts == nil {
+		return APIKeyResult{}, ErrAccountsNotConfigured
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxAPIKeyName {
+		return APIKeyResult{}, fmt.Errorf("%w: имя ключа должно содержать от 1 до %d символов",
+			ErrInvalidAPIKeyName, MaxAPIKeyName)
+	}
+
+	token, hash, display, err := auth.NewToken(auth.APIKeyPrefix)
+	if err != nil {
+		return APIKeyResult{}, err
+	}
+
+	key := storage.Credential{
+		ID:        auth.GenerateUserID(),
+		UserID:    userID,
+		Kind:      storage.CredentialAPIKey,
+		Name:      name,
+		TokenHash: hash,
+		Prefix:    display,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.accounts.CreateCredential(ctx, key); err != nil {
+		return APIKeyResult{}, err
+	}
+
+	return APIKeyResult{Key: key, Token: token}, nil
+}
+
+// ListAPIKeys возвращает API-ключи учетной записи, включая отозванные.
+func (s *ShortenerService) ListAPIKeys(ctx context.Context, userID string) ([]storage.Credential, error) {
+	if s.accounts == nil {
+		return nil, ErrAccountsNotConfigured
+	}
+	return s.accounts.ListCredentials(ctx, userID, storage.CredentialAPIKey)
+}
+
+// RevokeAPIKey отзывает API-ключ учетной записи.
+// Возвращает storage.ErrNotFound, если ключ не найден или принадлежит другой учетной записи.
+func (s *ShortenerService) RevokeAPIKey(ctx context.Context, userID, id string) error {
+	if s.accounts == nil {
+		return ErrAccountsNotConfigured
+	}
+	return s.accounts.RevokeCredential(ctx, userID, id, time.Now().UTC())
+}
+
+// VerifyToken проверяет токен сессии или API-ключ и возвращает учетную запись.
+// Реализует auth.TokenVerifier.
+func (s *ShortenerService) VerifyToken(ctx context.Context, token string) (auth.Identity, error) {
+	if s.accounts == nil || !auth.IsAccountToken(token) {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+
+	credential, err := s.accounts.GetCredential(ctx, auth.HashToken(token))
+	if errors.Is(err, storage.ErrNotFound) {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return auth.Identity{}, err
+	}
+	if !credential.Active(time.Now()) {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+
+	return auth.Identity{
+		UserID:       credential.UserID,
+		CredentialID: credential.ID,
+		Kind:         string(credential.Kind),
+	}, nil
+}
+
+// normalizeLogin приводит логин к нижнему регистру и проверяет его.
+// Допустимы латинские буквы, цифры и символы . _ - @.
+func normalizeLogin(login string) (string, error) {
+	login = strings.ToLower(strings.TrimSpace(login))
+	if len(login) < MinLoginLength || len(login) > MaxLoginLength {
+		return "", fmt.Errorf("%w: длина логина должна быть от %d до %d символов",
+			ErrInvalidLogin, MinLoginLength, MaxLoginLength)
+	}
+	for _, r := range login {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && !strings.ContainsRune("._-@", r) {
+			return "", fmt.Errorf("%w: недопустимый символ %q", ErrInvalidLogin, r)
+		}
+	}
+	return login, nil
+}
+
+// validatePassword проверяет длину пароля
+func validatePassword(password string) error {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return fmt.Errorf("%w: длина пароля должна быть от %d до %d байт",
+			ErrInvalidPassword, MinPasswordLength, MaxPasswordLength)
+	}
+	return nil
+}
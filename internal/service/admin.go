@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/auth"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/tracing"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
+)
+
+// Ограничения API модерации.
+const (
+	DefaultAdminPageSize = 50   // Размер страницы поиска по умолчанию
+	MaxAdminPageSize     = 500  // Максимальный размер страницы поиска
+	MaxAdminBatch        = 1000 // Максимальное количество ссылок в групповой операции
+)
+
+// WithAdmin подключает к сервису хранилище модерации ссылок.
+func (s *ShortenerService) WithAdmin(admin storage.AdminStorage) *ShortenerService {
+	s.admin = admin
+	return s
+}
+
+// HasRole сообщает, есть ли у учетной записи пользователя userID роль role.
+// Реализует auth.RoleChecker. Анонимный пользователь не имеет ролей,
+// учетная запись без сохраненной роли имеет роль auth.RoleUser.
+func (s *ShortenerService) HasRole(ctx context.Context, userID string, role string) (bool, error) {
+	if s.accounts == nil {
+		return false, nil
+	}
+
+	account, err := s.accounts.GetAccount(ctx, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	accountRole := account.Role
+	if accountRole == "" {
+		accountRole = auth.RoleUser
+	}
+	return accountRole == role, nil
+}
+
+// SetAccountRole назначает роль учетной записи с логином login
+// и возвращает измененную учетную запись.
+func (s *ShortenerService) SetAccountRole(ctx context.Context, login string, role string) (storage.Account, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.SetAccountRole")
+	defer span.End()
+
+	if s.accounts == nil {
+		return storage.Account{}, ErrAccountsNotConfigured
+	}
+	if role != auth.RoleUser && role != auth.RoleAdmin {
+		return storage.Account{}, fmt.Errorf("%w %q (допустимо: %s, %s)", ErrInvalidRole, role, auth.RoleUser, auth.RoleAdmin)
+	}
+
+	account, err := s.findAccount(ctx, login)
+	if err != nil {
+		return storage.Account{}, err
+	}
+	if err := s.accounts.SetAccountRole(ctx, account.ID, role); err != nil {
+		return storage.Account{}, err
+	}
+
+	account.Role = role
+	return account, nil
+}
+
+// LinkSearch содержит параметры поиска ссылок.
+type LinkSearch struct {
+	storage.LinkFilter
+
+	// OwnerLogin - логин владельца; заменяет UserID фильтра
+	OwnerLogin string
+
+	Offset int // Количество пропускаемых ссылок
+	Limit  int // Размер страницы (0 - DefaultAdminPageSize)
+}
+
+// LinkSearchResult содержит страницу результатов поиска ссылок.
+type LinkSearchResult struct {
+	Links  []models.AdminLink
+	Total  int // Количество найденных ссылок на всех страницах
+	Offset int
+	Limit  int
+}
+
+// SearchLinks ищет ссылки для модерации.
+//
+// Хост фильтра приводится к нижнему регистру и punycode, как при проверке
+// URL политикой, поэтому поиск по "пример.рф" находит сохраненные ссылки.
+func (s *ShortenerService) SearchLinks(ctx context.Context, search LinkSearch) (LinkSearchResult, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.SearchLinks")
+	defer span.End()
+
+	if s.admin == nil {
+		return LinkSearchResult{}, ErrAdminNotConfigured
+	}
+
+	if search.Limit == 0 {
+		search.Limit = DefaultAdminPageSize
+	}
+	if search.Limit < 0 || search.Limit > MaxAdminPageSize {
+		return LinkSearchResult{}, fmt.Errorf("%w: размер страницы должен быть от 1 до %d", ErrInvalidFilter, MaxAdminPageSize)
+	}
+	if search.Offset < 0 {
+		return LinkSearchResult{}, fmt.Errorf("%w: отрицательное смещение", ErrInvalidFilter)
+	}
+
+	filter := search.LinkFilter
+	if err := checkPeriod(filter.CreatedFrom, filter.CreatedTo); err != nil {
+		return LinkSearchResult{}, err
+	}
+	host, err := normalizeHostFilter(filter.Host)
+	if err != nil {
+		return LinkSearchResult{}, err
+	}
+	filter.Host = host
+	if search.OwnerLogin != "" {
+		if filter.UserID != "" {
+			return LinkSearchResult{}, fmt.Errorf("%w: укажите ID владельца или логин", ErrInvalidFilter)
+		}
+		account, err := s.findAccount(ctx, search.OwnerLogin)
+		if err != nil {
+			return LinkSearchResult{}, err
+		}
+		filter.UserID = account.ID
+	}
+
+	page, err := s.admin.SearchLinks(ctx, filter, search.Offset, search.Limit)
+	if err != nil {
+		return LinkSearchResult{}, err
+	}
+
+	links := make([]models.AdminLink, len(page.Links))
+	for i, link := range page.Links {
+		links[i] = s.adminLink(link)
+	}
+
+	return LinkSearchResult{
+		Links:  links,
+		Total:  page.Total,
+		Offset: search.Offset,
+		Limit:  search.Limit,
+	}, nil
+}
+
+// checkPeriod проверяет период создания ссылок фильтра
+func checkPeriod(from, to time.Time) error {
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return fmt.Errorf("%w: начало периода должно быть раньше конца", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// normalizeHostFilter приводит хост фильтра к виду, в котором его
+// сохраняет политика URL
+func normalizeHostFilter(host string) (string, error) {
+	if host == "" {
+		return "", nil
+	}
+	normalized, err := urlpolicy.NormalizeHost(host)
+	if err != nil {
+		return "", fmt.Errorf("%w: некорректный хост %q", ErrInvalidFilter, host)
+	}
+	return normalized, nil
+}
+
+// GetLink возвращает ссылку для модерации.
+// Возвращает storage.ErrNotFound, если ссылки нет.
+func (s *ShortenerService) GetLink(ctx context.Context, id string) (models.AdminLink, error) {
+	result, err := s.SearchLinks(ctx, LinkSearch{
+		LinkFilter: storage.LinkFilter{ShortID: id},
+		Limit:      1,
+	})
+	if err != nil {
+		return models.AdminLink{}, err
+	}
+	if len(result.Links) == 0 {
+		return models.AdminLink{}, storage.ErrNotFound
+	}
+	return result.Links[0], nil
+}
+
+// adminLink преобразует ссылку хранилища в модель API модерации
+func (s *ShortenerService) adminLink(link storage.LinkInfo) models.AdminLink {
+	result := models.AdminLink{
+		ShortID:     link.ShortID,
+		ShortURL:    s.shortener.BuildShortURL(link.ShortID),
+		OriginalURL: link.OriginalURL,
+		UserID:      link.UserID,
+		Deleted:     link.Deleted,
+		Disabled:    link.Disabled,
+	}
+	if !link.CreatedAt.IsZero() {
+		createdAt := link.CreatedAt.UTC()
+		result.CreatedAt = &createdAt
+	}
+	if !link.ExpiresAt.IsZero() {
+		expiresAt := link.ExpiresAt.UTC()
+		result.ExpiresAt = &expiresAt
+	}
+	return result
+}
+
+// DisableLinks отключает ссылки: переход по ним возвращает 410 Gone,
+// но ссылки остаются в хранилище. Возвращает количество отключенных ссылок.
+func (s *ShortenerService) DisableLinks(ctx context.Context, ids []string) (int, error) {
+	return s.setLinksDisabled(ctx, ids, true)
+}
+
+// EnableLinks включает ранее отключенные ссылки.
+// Возвращает количество включенных ссылок.
+func (s *ShortenerService) EnableLinks(ctx context.Context, ids []string) (int, error) {
+	return s.setLinksDisabled(ctx, ids, false)
+}
+
+// setLinksDisabled отключает или включает ссылки
+func (s *ShortenerService) setLinksDisabled(ctx context.Context, ids []string, disabled bool) (int, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.SetLinksDisabled",
+		tracing.WithAttributes(tracing.Int("urls.count", len(ids))))
+	defer span.End()
+
+	if err := s.checkAdminBatch(ids); err != nil {
+		return 0, err
+	}
+	return s.admin.SetLinksDisabled(ctx, ids, disabled)
+}
+
+// PurgeLinks безвозвратно удаляет ссылки вместе со статистикой переходов.
+// Возвращает количество удаленных ссылок.
+func (s *ShortenerService) PurgeLinks(ctx context.Context, ids []string) (int, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.PurgeLinks",
+		tracing.WithAttributes(tracing.Int("urls.count", len(ids))))
+	defer span.End()
+
+	if err := s.checkAdminBatch(ids); err != nil {
+		return 0, err
+	}
+	return s.admin.PurgeLinks(ctx, ids)
+}
+
+// LinkOwner задает нового владельца ссылок: ID пользователя
+// (в том числе анонимного) или логин учетной записи.
+type LinkOwner struct {
+	UserID string
+	Login  string
+}
+
+// ReassignLinks передает ссылки новому владельцу.
+// Возвращает количество переданных ссылок.
+func (s *ShortenerService) ReassignLinks(ctx context.Context, ids []string, owner LinkOwner) (int, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.ReassignLinks",
+		tracing.WithAttributes(tracing.Int("urls.count", len(ids))))
+	defer span.End()
+
+	if err := s.checkAdminBatch(ids); err != nil {
+		return 0, err
+	}
+
+	userID := strings.TrimSpace(owner.UserID)
+	switch {
+	case userID != "" && owner.Login != "":
+		return 0, fmt.Errorf("%w: укажите ID пользователя или логин", ErrInvalidOwner)
+	case owner.Login != "":
+		account, err := s.findAccount(ctx, owner.Login)
+		if err != nil {
+			return 0, err
+		}
+		userID = account.ID
+	case userID == "":
+		return 0, fmt.Errorf("%w: не указан новый владелец", ErrInvalidOwner)
+	}
+
+	return s.admin.ReassignLinks(ctx, ids, userID)
+}
+
+// checkAdminBatch проверяет список ссылок групповой операции
+func (s *ShortenerService) checkAdminBatch(ids []string) error {
+	if s.admin == nil {
+		return ErrAdminNotConfigured
+	}
+	if len(ids) == 0 {
+		return ErrEmptyList
+	}
+	if len(ids) > MaxAdminBatch {
+		return fmt.Errorf("%w: не более %d", ErrTooManyLinks, MaxAdminBatch)
+	}
+	return nil
+}
+
+// findAccount возвращает учетную запись по логину или ErrAccountNotFound
+func (s *ShortenerService) findAccount(ctx context.Context, login string) (storage.Account, error) {
+	if s.accounts == nil {
+		return storage.Account{}, ErrAccountsNotConfigured
+	}
+
+	account, err := s.accounts.GetAccountByLogin(ctx, strings.ToLower(strings.TrimSpace(login)))
+	if errors.Is(err, storage.ErrNotFound) {
+		return storage.Account{}, fmt.Errorf("%w: %s", ErrAccountNotFound, login)
+	}
+	return account, err
+}
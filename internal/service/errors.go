@@ -11,4 +11,66 @@ var (
 
 	// ErrDBNotConfigured возвращается, когда база данных не настроена.
 	ErrDBNotConfigured = errors.New("база данных не настроена")
+
+	// ErrAliasTaken возвращается, когда запрошенный псевдоним уже занят.
+	ErrAliasTaken = errors.New("псевдоним уже занят")
+
+	// ErrAliasURLExists возвращается, когда для URL с псевдонимом уже есть
+	// ссылка под другим коротким ID в области дедупликации: псевдоним не
+	// создается, чтобы не вернуть молча ссылку без запрошенного псевдонима.
+	ErrAliasURLExists = errors.New("URL уже сокращен под другим коротким ID")
+
+	// ErrDuplicateAlias возвращается, когда псевдоним повторяется внутри пакета.
+	ErrDuplicateAlias = errors.New("псевдоним повторяется в пакете")
+
+	// ErrIDCollision возвращается, когда за MaxIDAttempts попыток не удалось
+	// сгенерировать свободный короткий ID.
+	ErrIDCollision = errors.New("не удалось сгенерировать свободный короткий ID")
+
+	// ErrStatsNotConfigured возвращается, когда сбор статистики переходов не подключен.
+	ErrStatsNotConfigured = errors.New("статистика переходов не настроена")
+)
+
+var (
+	// ErrAccountsNotConfigured возвращается, когда хранилище не поддерживает учетные записи.
+	ErrAccountsNotConfigured = errors.New("учетные записи не настроены")
+
+	// ErrInvalidLogin возвращается для логина, не удовлетворяющего ограничениям.
+	ErrInvalidLogin = errors.New("недопустимый логин")
+
+	// ErrInvalidPassword возвращается для пароля, не удовлетворяющего ограничениям.
+	ErrInvalidPassword = errors.New("недопустимый пароль")
+
+	// ErrLoginTaken возвращается при регистрации с занятым логином.
+	ErrLoginTaken = errors.New("логин уже занят")
+
+	// ErrInvalidCredentials возвращается при неверном логине или пароле.
+	ErrInvalidCredentials = errors.New("неверный логин или пароль")
+
+	// ErrInvalidClaim возвращается, когда анонимный ID нельзя перенести в учетную запись.
+	ErrInvalidClaim = errors.New("анонимный ID нельзя перенести в учетную запись")
+
+	// ErrInvalidAPIKeyName возвращается для недопустимого имени API-ключа.
+	ErrInvalidAPIKeyName = errors.New("недопустимое имя API-ключа")
+)
+
+var (
+	// ErrAdminNotConfigured возвращается, когда хранилище не поддерживает модерацию ссылок.
+	ErrAdminNotConfigured = errors.New("модерация ссылок не настроена")
+
+	// ErrInvalidFilter возвращается для недопустимых параметров поиска ссылок
+	// или списка URL пользователя.
+	ErrInvalidFilter = errors.New("недопустимые параметры поиска")
+
+	// ErrTooManyLinks возвращается, когда групповая операция содержит больше MaxAdminBatch ссылок.
+	ErrTooManyLinks = errors.New("слишком много ссылок в запросе")
+
+	// ErrInvalidOwner возвращается, когда новый владелец ссылок не указан или указан неоднозначно.
+	ErrInvalidOwner = errors.New("недопустимый владелец ссылок")
+
+	// ErrAccountNotFound возвращается, когда учетная запись с указанным логином не найдена.
+	ErrAccountNotFound = errors.New("учетная запись не найдена")
+
+	// ErrInvalidRole возвращается для неизвестной роли учетной записи.
+	ErrInvalidRole = errors.New("недопустимая роль")
 )
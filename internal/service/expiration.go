@@ -0,0 +1,34 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidExpiration возвращается, когда срок действия ссылки задан некорректно.
+var ErrInvalidExpiration = errors.New("некорректный срок действия ссылки")
+
+// ResolveExpiration вычисляет момент истечения срока действия ссылки.
+//
+// Срок задается либо абсолютным моментом expiresAt, либо относительным
+// ttlSeconds от момента now. Одновременное указание обоих значений,
+// неположительный TTL и момент в прошлом считаются ошибкой.
+// Если срок не задан, возвращается нулевое время (бессрочная ссылка).
+func ResolveExpiration(expiresAt *time.Time, ttlSeconds int64, now time.Time) (time.Time, error) {
+	switch {
+	case expiresAt != nil && ttlSeconds != 0:
+		return time.Time{}, fmt.Errorf("%w: expires_at и ttl_seconds взаимоисключающие", ErrInvalidExpiration)
+	case expiresAt != nil:
+		if !expiresAt.After(now) {
+			return time.Time{}, fmt.Errorf("%w: expires_at должен быть в будущем", ErrInvalidExpiration)
+		}
+		return expiresAt.UTC(), nil
+	case ttlSeconds < 0:
+		return time.Time{}, fmt.Errorf("%w: ttl_seconds должен быть положительным", ErrInvalidExpiration)
+	case ttlSeconds > 0:
+		return now.Add(time.Duration(ttlSeconds) * time.Second).UTC(), nil
+	default:
+		return time.Time{}, nil
+	}
+}
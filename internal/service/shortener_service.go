@@ -3,51 +3,92 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/Adigezalov/shortener/internal/database"
 	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/shortener"
 	"github.com/Adigezalov/shortener/internal/storage"
+	"github.com/Adigezalov/shortener/internal/tracing"
+	"github.com/Adigezalov/shortener/internal/urlpolicy"
 )
 
-// URLStorage определяет интерфейс для хранения и управления URL.
-type URLStorage interface {
-	Add(id string, url string) (string, bool, error)
-	AddWithUser(id string, url string, userID string) (string, bool, error)
-	Get(id string) (string, bool)
-	FindByOriginalURL(url string) (string, bool)
-	GetUserURLs(userID string) ([]models.UserURL, error)
-	DeleteUserURLs(userID string, shortURLs []string) error
-	IsDeleted(shortURL string) (bool, error)
-	Stats() (storage.Stats, error)
-	Close() error
-}
-
 // URLShortener определяет интерфейс для сокращения URL.
 type URLShortener interface {
 	Shorten(url string) string
 	BuildShortURL(id string) string
 }
 
+// RetryShortener реализуется генераторами, которые выдают другой ID
+// для повторной попытки после коллизии (например, детерминированный хеш).
+// Для остальных URLShortener повторная попытка вызывает Shorten снова.
+type RetryShortener interface {
+	ShortenAttempt(url string, attempt int) string
+}
+
+// MaxIDAttempts - количество попыток сгенерировать свободный короткий ID.
+const MaxIDAttempts = 5
+
 // Pinger определяет интерфейс для проверки подключения к базе данных.
 type Pinger interface {
 	Ping() error
 }
 
+// ClickStatsProvider определяет интерфейс получения статистики переходов.
+type ClickStatsProvider interface {
+	GetClickStats(userID string, shortURL string, since time.Time) (models.URLStats, bool, error)
+}
+
 // ShortenerService содержит бизнес-логику для работы с URL.
 type ShortenerService struct {
-	storage   URLStorage
-	shortener URLShortener
-	db        Pinger
+	storage    storage.URLStorageV2
+	shortener  URLShortener
+	db         Pinger
+	clickStats ClickStatsProvider
+	accounts   storage.AccountStorage
+	admin      storage.AdminStorage
+	policy     *urlpolicy.Policy
 }
 
 // NewShortenerService создает новый экземпляр сервиса.
-func NewShortenerService(storage URLStorage, shortener URLShortener, db Pinger) *ShortenerService {
+//
+// Сервис является единственной точкой доступа к хранилищу для HTTP и gRPC
+// транспортов, поэтому правила обработки ошибок хранилища у них совпадают.
+// Оригинальные URL проверяются политикой urlpolicy.Default.
+func NewShortenerService(store storage.URLStorageV2, shortener URLShortener, db Pinger) *ShortenerService {
 	return &ShortenerService{
-		storage:   storage,
+		storage:   store,
 		shortener: shortener,
 		db:        db,
+		policy:    urlpolicy.Default(),
 	}
 }
 
+// WithURLPolicy заменяет политику проверки оригинальных URL.
+// При policy == nil URL проверяются только на пустоту.
+func (s *ShortenerService) WithURLPolicy(policy *urlpolicy.Policy) *ShortenerService {
+	s.policy = policy
+	return s
+}
+
+// checkURL проверяет оригинальный URL политикой и возвращает
+// его нормализованную форму или *urlpolicy.Error.
+func (s *ShortenerService) checkURL(ctx context.Context, url string) (string, error) {
+	if s.policy == nil {
+		return url, nil
+	}
+	return s.policy.Check(ctx, url)
+}
+
+// WithClickStats подключает к сервису источник статистики переходов.
+func (s *ShortenerService) WithClickStats(stats ClickStatsProvider) *ShortenerService {
+	s.clickStats = stats
+	return s
+}
+
 // CreateShortURLResult содержит результат создания короткого URL.
 type CreateShortURLResult struct {
 	ShortURL string
@@ -55,18 +96,73 @@ type CreateShortURLResult struct {
 	Error    error
 }
 
+// ShortenOptions содержит необязательные параметры создания короткого URL.
+type ShortenOptions struct {
+	Alias      string     // Желаемый короткий ID (пустой - сгенерировать)
+	ExpiresAt  *time.Time // Абсолютный момент истечения срока действия
+	TTLSeconds int64      // Срок действия в секундах от текущего момента
+}
+
 // CreateShortURL создает короткий URL для указанного оригинального URL.
-func (s *ShortenerService) CreateShortURL(url string, userID string) CreateShortURLResult {
+func (s *ShortenerService) CreateShortURL(ctx context.Context, url string, userID string) CreateShortURLResult {
+	return s.CreateShortURLWithOptions(ctx, url, userID, ShortenOptions{})
+}
+
+// CreateShortURLWithOptions создает короткий URL с заданным псевдонимом и сроком действия.
+// URL, нарушающий политику, отклоняется с ошибкой *urlpolicy.Error,
+// а сохраняется нормализованная форма URL.
+// Если псевдоним не задан, идентификатор генерируется автоматически;
+// при коллизии с занятым ID генерация повторяется до MaxIDAttempts раз.
+// Если URL с псевдонимом уже сокращен под другим ID, возвращается
+// ErrAliasURLExists, а ShortURL содержит существующую ссылку.
+func (s *ShortenerService) CreateShortURLWithOptions(ctx context.Context, url string, userID string, opts ShortenOptions) CreateShortURLResult {
+	ctx, span := tracing.Start(ctx, "ShortenerService.CreateShortURLWithOptions")
+	defer span.End()
+
 	if url == "" {
 		return CreateShortURLResult{Error: ErrEmptyURL}
 	}
 
-	// Генерируем ID
-	id := s.shortener.Shorten(url)
+	url, err := s.checkURL(ctx, url)
+	if err != nil {
+		return CreateShortURLResult{Error: err}
+	}
 
-	// Добавляем URL с привязкой к пользователю
-	id, exists, err := s.storage.AddWithUser(id, url, userID)
-	if err != nil && err != database.ErrURLConflict {
+	if opts.Alias != "" {
+		if err := shortener.ValidateAlias(opts.Alias); err != nil {
+			return CreateShortURLResult{Error: err}
+		}
+	}
+
+	expiresAt, err := ResolveExpiration(opts.ExpiresAt, opts.TTLSeconds, time.Now())
+	if err != nil {
+		return CreateShortURLResult{Error: err}
+	}
+
+	var id string
+	if opts.Alias != "" {
+		// Добавляем URL под псевдонимом пользователя
+		id, err = s.storage.Add(ctx, opts.Alias, url, userID, expiresAt)
+		if errors.Is(err, database.ErrShortIDConflict) {
+			return CreateShortURLResult{Error: ErrAliasTaken}
+		}
+		if errors.Is(err, database.ErrURLConflict) && id != opts.Alias {
+			return CreateShortURLResult{ShortURL: s.shortener.BuildShortURL(id), Error: ErrAliasURLExists}
+		}
+	} else {
+		// Генерируем ID, повторяя попытку при коллизии
+		for attempt := 0; attempt < MaxIDAttempts; attempt++ {
+			id, err = s.storage.Add(ctx, s.generateID(url, attempt), url, userID, expiresAt)
+			if !errors.Is(err, database.ErrShortIDConflict) {
+				break
+			}
+		}
+		if errors.Is(err, database.ErrShortIDConflict) {
+			return CreateShortURLResult{Error: ErrIDCollision}
+		}
+	}
+	exists := errors.Is(err, database.ErrURLConflict)
+	if err != nil && !exists {
 		return CreateShortURLResult{Error: err}
 	}
 
@@ -75,7 +171,7 @@ func (s *ShortenerService) CreateShortURL(url string, userID string) CreateShort
 
 	return CreateShortURLResult{
 		ShortURL: shortURL,
-		Exists:   exists || err == database.ErrURLConflict,
+		Exists:   exists,
 		Error:    nil,
 	}
 }
@@ -84,109 +180,392 @@ func (s *ShortenerService) CreateShortURL(url string, userID string) CreateShort
 type BatchItem struct {
 	CorrelationID string
 	OriginalURL   string
+	Alias         string
+	ExpiresAt     *time.Time
+	TTLSeconds    int64
 }
 
 // BatchResult представляет результат пакетного создания URL.
+//
+// Status принимает значения storage.RecordCreated, storage.RecordExisting
+// и storage.RecordFailed. Для элемента с ошибкой ShortURL пустой,
+// а причина указана в Error.
 type BatchResult struct {
 	CorrelationID string
 	ShortURL      string
+	Status        storage.RecordStatus
+	Error         error
 }
 
 // CreateShortURLBatch создает короткие URL для списка оригинальных URL.
-func (s *ShortenerService) CreateShortURLBatch(items []BatchItem, userID string) []BatchResult {
-	results := make([]BatchResult, 0, len(items))
+//
+// Псевдонимы и сроки действия всех элементов проверяются до записи,
+// некорректный элемент отклоняет весь пакет. Все элементы записываются
+// одной операцией хранилища (storage.AddBatch), а результат каждого
+// элемента возвращается отдельно: пустой URL, URL, нарушающий политику
+// (*urlpolicy.Error), занятый псевдоним (ErrAliasTaken) или псевдоним для URL,
+// уже сокращенного под другим ID (ErrAliasURLExists), не пропускаются
+// молча, а получают статус ошибки.
+// Сгенерированные ID, оказавшиеся занятыми, генерируются заново до
+// MaxIDAttempts раз, после чего элемент получает ошибку ErrIDCollision.
+// Сбой хранилища прерывает обработку и возвращается вызывающему.
+func (s *ShortenerService) CreateShortURLBatch(ctx context.Context, items []BatchItem, userID string) ([]BatchResult, error) {
+	ctx, span := tracing.Start(ctx, "ShortenerService.CreateShortURLBatch",
+		tracing.WithAttributes(tracing.Int("batch.size", len(items))))
+	defer span.End()
+
+	now := time.Now()
+	aliases := make(map[string]struct{}, len(items))
+	expirations := make([]time.Time, len(items))
+	for i, item := range items {
+		expiresAt, err := ResolveExpiration(item.ExpiresAt, item.TTLSeconds, now)
+		if err != nil {
+			return nil, fmt.Errorf("correlation_id %s: %w", item.CorrelationID, err)
+		}
+		expirations[i] = expiresAt
 
-	for _, item := range items {
-		if item.OriginalURL == "" {
+		if item.Alias == "" {
 			continue
 		}
+		if err := shortener.ValidateAlias(item.Alias); err != nil {
+			return nil, fmt.Errorf("correlation_id %s: %w", item.CorrelationID, err)
+		}
+		if _, duplicate := aliases[item.Alias]; duplicate {
+			return nil, fmt.Errorf("correlation_id %s: %w", item.CorrelationID, ErrDuplicateAlias)
+		}
+		aliases[item.Alias] = struct{}{}
+	}
 
-		// Генерируем ID
-		id := s.shortener.Shorten(item.OriginalURL)
+	results := make([]BatchResult, len(items))
 
-		// Добавляем URL с привязкой к пользователю
-		id, _, err := s.storage.AddWithUser(id, item.OriginalURL, userID)
-		if err != nil && err != database.ErrURLConflict {
+	// Пустые и отклоненные политикой URL не записываются, но получают
+	// статус ошибки
+	urls := make([]string, len(items))
+	var pending []int
+	for i, item := range items {
+		results[i].CorrelationID = item.CorrelationID
+		if item.OriginalURL == "" {
+			results[i].Status = storage.RecordFailed
+			results[i].Error = ErrEmptyURL
+			continue
+		}
+		normalized, err := s.checkURL(ctx, item.OriginalURL)
+		if err != nil {
+			results[i].Status = storage.RecordFailed
+			results[i].Error = err
 			continue
 		}
+		urls[i] = normalized
+		pending = append(pending, i)
+	}
 
-		// Строим полный короткий URL
-		shortURL := s.shortener.BuildShortURL(id)
+	// Записываем элементы одной операцией хранилища. Элементы со
+	// сгенерированным ID, столкнувшимся с занятым, повторяются с новым ID
+	for attempt := 0; attempt < MaxIDAttempts && len(pending) > 0; attempt++ {
+		records := make([]storage.Record, 0, len(pending))
+		for _, i := range pending {
+			// Используем псевдоним или генерируем ID
+			id := items[i].Alias
+			if id == "" {
+				id = s.generateID(urls[i], attempt)
+			}
+			records = append(records, storage.Record{
+				ID:          id,
+				OriginalURL: urls[i],
+				ExpiresAt:   expirations[i],
+			})
+		}
 
-		results = append(results, BatchResult{
-			CorrelationID: item.CorrelationID,
-			ShortURL:      shortURL,
-		})
+		stored, err := storage.AddBatch(ctx, s.storage, records, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		var retry []int
+		for j, record := range stored {
+			i := pending[j]
+			results[i].Status = record.Status
+			results[i].Error = nil
+			if record.Status == storage.RecordExisting && items[i].Alias != "" && record.ID != items[i].Alias {
+				results[i].Status = storage.RecordFailed
+				results[i].Error = ErrAliasURLExists
+				continue
+			}
+			if record.Status != storage.RecordFailed {
+				// Строим полный короткий URL
+				results[i].ShortURL = s.shortener.BuildShortURL(record.ID)
+				continue
+			}
+
+			results[i].Error = record.Err
+			if errors.Is(record.Err, database.ErrShortIDConflict) {
+				if items[i].Alias != "" {
+					results[i].Error = ErrAliasTaken
+					continue
+				}
+				results[i].Error = ErrIDCollision
+				retry = append(retry, i)
+			}
+		}
+		pending = retry
+	}
+
+	return results, nil
+}
+
+// CreateShortURLEach создает короткие URL для независимых элементов, как
+// CreateShortURLBatch, но некорректный псевдоним или срок действия отклоняет
+// только свой элемент: он получает статус storage.RecordFailed, а пакет без
+// него записывается заново (повторяющийся псевдоним получает ErrAliasTaken).
+// Используется потоковым сокращением и импортом, где элементы пакета
+// не связаны друг с другом. Сбой хранилища возвращается вызывающему.
+func (s *ShortenerService) CreateShortURLEach(ctx context.Context, items []BatchItem, userID string) ([]BatchResult, error) {
+	results, err := s.CreateShortURLBatch(ctx, items, userID)
+	if !isItemError(err) {
+		return results, err
+	}
+	if len(items) == 1 {
+		return []BatchResult{{
+			CorrelationID: items[0].CorrelationID,
+			Status:        storage.RecordFailed,
+			Error:         err,
+		}}, nil
 	}
 
-	return results
+	// Некорректный элемент неизвестен заранее, поэтому элементы
+	// записываются по одному
+	results = make([]BatchResult, 0, len(items))
+	for _, item := range items {
+		itemResults, err := s.CreateShortURLEach(ctx, []BatchItem{item}, userID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, itemResults...)
+	}
+	return results, nil
+}
+
+// isItemError сообщает, что пакет отклонен из-за некорректного элемента
+// (псевдонима или срока действия), а не из-за сбоя хранилища
+func isItemError(err error) bool {
+	return errors.Is(err, shortener.ErrInvalidAlias) || errors.Is(err, ErrDuplicateAlias) ||
+		errors.Is(err, ErrInvalidExpiration)
+}
+
+// generateID генерирует короткий ID для попытки attempt (начиная с 0)
+func (s *ShortenerService) generateID(url string, attempt int) string {
+	if retry, ok := s.shortener.(RetryShortener); ok && attempt > 0 {
+		return retry.ShortenAttempt(url, attempt)
+	}
+	return s.shortener.Shorten(url)
 }
 
 // GetOriginalURLResult содержит результат получения оригинального URL.
 type GetOriginalURLResult struct {
 	OriginalURL string
 	Deleted     bool
+	Expired     bool
+	Disabled    bool
 	Found       bool
 	Error       error
 }
 
 // GetOriginalURL возвращает оригинальный URL по короткому ID.
-func (s *ShortenerService) GetOriginalURL(id string) GetOriginalURLResult {
-	// Получаем оригинальный URL
-	originalURL, found := s.storage.Get(id)
-	if !found {
+//
+// Отсутствие, удаление, истечение срока действия и отключение ссылки
+// модератором отражаются флагами результата. Поле Error заполняется только
+// при сбое хранилища или отмене контекста, чтобы вызывающий не путал
+// недоступность базы с отсутствием URL.
+func (s *ShortenerService) GetOriginalURL(ctx context.Context, id string) GetOriginalURLResult {
+	ctx, span := tracing.Start(ctx, "ShortenerService.GetOriginalURL",
+		tracing.WithAttributes(tracing.String("url.id", id)))
+	defer span.End()
+
+	originalURL, err := s.storage.Get(ctx, id)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
 		return GetOriginalURLResult{Found: false}
-	}
-
-	// Проверяем, не удален ли URL
-	deleted, err := s.storage.IsDeleted(id)
-	if err != nil {
+	case errors.Is(err, storage.ErrExpired):
+		return GetOriginalURLResult{Expired: true, Found: true}
+	case errors.Is(err, storage.ErrDisabled):
+		return GetOriginalURLResult{Disabled: true, Found: true}
+	case errors.Is(err, storage.ErrGone):
+		return GetOriginalURLResult{Deleted: true, Found: true}
+	case err != nil:
 		return GetOriginalURLResult{Error: err}
 	}
 
 	return GetOriginalURLResult{
 		OriginalURL: originalURL,
-		Deleted:     deleted,
 		Found:       true,
 		Error:       nil,
 	}
 }
 
+// Ограничения списка URL пользователя.
+const (
+	MaxUserURLsPageSize = 1000 // Максимальный размер страницы списка URL пользователя
+)
+
+// UserURLsQuery содержит параметры списка URL пользователя.
+// Нулевое значение возвращает все URL, начиная с новых.
+type UserURLsQuery struct {
+	URLContains string    // Подстрока оригинального URL без учета регистра
+	Host        string    // Хост оригинального URL, включая поддомены
+	CreatedFrom time.Time // URL создан не раньше этого момента
+	CreatedTo   time.Time // URL создан раньше этого момента
+
+	Order  storage.SortOrder // Порядок по моменту создания (пустой - сначала новые)
+	Cursor string            // Курсор из NextCursor предыдущей страницы
+	Limit  int               // Размер страницы (0 - все URL)
+}
+
 // GetUserURLsResult содержит результат получения URL пользователя.
 type GetUserURLsResult struct {
 	URLs  []models.UserURL
+	Total int // Количество URL, удовлетворяющих фильтру, на всех страницах
+
+	// NextCursor - курсор следующей страницы (пустой - страница последняя)
+	NextCursor string
+
+	// Error - ошибка ErrInvalidFilter для недопустимых параметров
+	// или ошибка хранилища
 	Error error
 }
 
-// GetUserURLs возвращает все URL пользователя.
-func (s *ShortenerService) GetUserURLs(userID string) GetUserURLsResult {
-	userURLs, err := s.storage.GetUserURLs(userID)
+// GetUserURLs возвращает страницу URL пользователя.
+//
+// Хост фильтра приводится к нижнему регистру и punycode, как при проверке
+// URL политикой. Курсор непрозрачен для клиента и действителен только
+// с тем же порядком сортировки.
+func (s *ShortenerService) GetUserURLs(ctx context.Context, userID string, query UserURLsQuery) GetUserURLsResult {
+	ctx, span := tracing.Start(ctx, "ShortenerService.GetUserURLs")
+	defer span.End()
+
+	storageQuery, err := userURLQuery(query)
 	if err != nil {
 		return GetUserURLsResult{Error: err}
 	}
 
-	// Преобразуем URL в полные ссылки
-	result := make([]models.UserURL, len(userURLs))
-	for i, userURL := range userURLs {
-		result[i] = models.UserURL{
-			ShortURL:    s.shortener.BuildShortURL(userURL.ShortURL),
-			OriginalURL: userURL.OriginalURL,
-		}
+	page, err := s.storage.ListUserURLs(ctx, userID, storageQuery)
+	if err != nil {
+		return GetUserURLsResult{Error: err}
+	}
+
+	var nextCursor string
+	if page.Next != nil {
+		nextCursor = page.Next.String()
 	}
 
 	return GetUserURLsResult{
-		URLs:  result,
-		Error: nil,
+		URLs:       s.fullUserURLs(page.URLs),
+		Total:      page.Total,
+		NextCursor: nextCursor,
+		Error:      nil,
+	}
+}
+
+// ExportPageSize - размер страницы, которой ExportUserURLs читает URL из хранилища
+const ExportPageSize = 500
+
+// ExportUserURLs передает в fn по одному все URL пользователя, удовлетворяющие
+// фильтру query, в порядке query.Order. Поля Cursor и Limit не используются:
+// URL читаются из хранилища страницами по ExportPageSize, поэтому выгрузка
+// не держит в памяти весь список. Ошибка fn прерывает выгрузку и возвращается
+// вызывающему. Возвращает ErrInvalidFilter для недопустимых параметров.
+func (s *ShortenerService) ExportUserURLs(ctx context.Context, userID string, query UserURLsQuery, fn func(models.UserURL) error) error {
+	ctx, span := tracing.Start(ctx, "ShortenerService.ExportUserURLs")
+	defer span.End()
+
+	query.Cursor = ""
+	query.Limit = ExportPageSize
+	storageQuery, err := userURLQuery(query)
+	if err != nil {
+		return err
+	}
+
+	exported := 0
+	for {
+		page, err := s.storage.ListUserURLs(ctx, userID, storageQuery)
+		if err != nil {
+			return err
+		}
+		for _, userURL := range s.fullUserURLs(page.URLs) {
+			if err := fn(userURL); err != nil {
+				return err
+			}
+		}
+		exported += len(page.URLs)
+		if page.Next == nil {
+			span.SetAttributes(tracing.Int("urls.count", exported))
+			return nil
+		}
+		storageQuery.After = page.Next
+	}
+}
+
+// fullUserURLs преобразует короткие ID URL пользователя в полные ссылки,
+// а моменты создания - в UTC
+func (s *ShortenerService) fullUserURLs(urls []models.UserURL) []models.UserURL {
+	result := make([]models.UserURL, len(urls))
+	for i, userURL := range urls {
+		userURL.ShortURL = s.shortener.BuildShortURL(userURL.ShortURL)
+		if userURL.CreatedAt != nil {
+			createdAt := userURL.CreatedAt.UTC()
+			userURL.CreatedAt = &createdAt
+		}
+		result[i] = userURL
+	}
+	return result
+}
+
+// userURLQuery проверяет параметры списка URL пользователя
+// и преобразует их в запрос к хранилищу
+func userURLQuery(query UserURLsQuery) (storage.UserURLQuery, error) {
+	if query.Limit < 0 || query.Limit > MaxUserURLsPageSize {
+		return storage.UserURLQuery{}, fmt.Errorf("%w: размер страницы должен быть не больше %d", ErrInvalidFilter, MaxUserURLsPageSize)
+	}
+	if err := checkPeriod(query.CreatedFrom, query.CreatedTo); err != nil {
+		return storage.UserURLQuery{}, err
+	}
+	host, err := normalizeHostFilter(query.Host)
+	if err != nil {
+		return storage.UserURLQuery{}, err
+	}
+	order, err := storage.ParseSortOrder(string(query.Order))
+	if err != nil {
+		return storage.UserURLQuery{}, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+
+	result := storage.UserURLQuery{
+		URLContains: query.URLContains,
+		Host:        host,
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		Order:       order,
+		Limit:       query.Limit,
+	}
+	if query.Cursor != "" {
+		cursor, err := storage.ParseCursor(query.Cursor)
+		if err != nil {
+			return storage.UserURLQuery{}, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+		}
+		result.After = &cursor
 	}
+	return result, nil
 }
 
 // DeleteUserURLs помечает URL пользователя как удаленные.
-func (s *ShortenerService) DeleteUserURLs(userID string, shortURLs []string) error {
+func (s *ShortenerService) DeleteUserURLs(ctx context.Context, userID string, shortURLs []string) error {
+	ctx, span := tracing.Start(ctx, "ShortenerService.DeleteUserURLs",
+		tracing.WithAttributes(tracing.Int("urls.count", len(shortURLs))))
+	defer span.End()
+
 	if len(shortURLs) == 0 {
 		return ErrEmptyList
 	}
 
-	return s.storage.DeleteUserURLs(userID, shortURLs)
+	return s.storage.DeleteUserURLs(ctx, userID, shortURLs)
 }
 
 // PingDB проверяет доступность базы данных.
@@ -201,12 +580,16 @@ func (s *ShortenerService) PingDB() error {
 type StatsResult struct {
 	URLs  int
 	Users int
+	Cache *storage.CacheStats // Счетчики кэша ссылок (nil, если кэш отключен)
 	Error error
 }
 
 // GetStats возвращает статистику сервиса.
-func (s *ShortenerService) GetStats() StatsResult {
-	stats, err := s.storage.Stats()
+func (s *ShortenerService) GetStats(ctx context.Context) StatsResult {
+	ctx, span := tracing.Start(ctx, "ShortenerService.GetStats")
+	defer span.End()
+
+	stats, err := s.storage.Stats(ctx)
 	if err != nil {
 		return StatsResult{Error: err}
 	}
@@ -214,6 +597,48 @@ func (s *ShortenerService) GetStats() StatsResult {
 	return StatsResult{
 		URLs:  stats.URLs,
 		Users: stats.Users,
+		Cache: stats.Cache,
 		Error: nil,
 	}
 }
+
+// URLStatsResult содержит статистику переходов по URL пользователя.
+type URLStatsResult struct {
+	Stats models.URLStats
+	Found bool
+	Error error
+}
+
+// GetURLStats возвращает статистику переходов по URL пользователя
+// с почасовой историей начиная с since.
+func (s *ShortenerService) GetURLStats(userID string, id string, since time.Time) URLStatsResult {
+	if s.clickStats == nil {
+		return URLStatsResult{Error: ErrStatsNotConfigured}
+	}
+
+	stats, found, err := s.clickStats.GetClickStats(userID, id, since)
+	if err != nil {
+		return URLStatsResult{Error: err}
+	}
+	if !found {
+		return URLStatsResult{Found: false}
+	}
+
+	stats.ShortURL = s.shortener.BuildShortURL(id)
+
+	return URLStatsResult{
+		Stats: stats,
+		Found: true,
+		Error: nil,
+	}
+}
+
+// CompactStorage запускает компактирование журнала файлового хранилища.
+// Возвращает storage.ErrCompactionUnsupported, если хранилище не поддерживает компактирование.
+func (s *ShortenerService) CompactStorage() (storage.CompactionResult, error) {
+	compactor, ok := s.storage.(storage.Compactor)
+	if !ok {
+		return storage.CompactionResult{}, storage.ErrCompactionUnsupported
+	}
+	return compactor.Compact()
+}
@@ -0,0 +1,76 @@
+package shortener
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ограничения на пользовательские псевдонимы (vanity short ID).
+const (
+	MinAliasLength = 3  // Минимальная длина псевдонима
+	MaxAliasLength = 32 // Максимальная длина псевдонима
+)
+
+// ErrInvalidAlias возвращается, когда псевдоним не проходит валидацию.
+// Конкретная причина добавляется к ошибке через обертку.
+var ErrInvalidAlias = errors.New("недопустимый псевдоним")
+
+// reservedAliases содержит идентификаторы, совпадающие с маршрутами сервиса.
+// Такие псевдонимы перекрыли бы служебные эндпоинты при редиректе GET /{id}.
+var reservedAliases = map[string]struct{}{
+	"api":     {},
+	"ping":    {},
+	"admin":   {},
+	"debug":   {},
+	"health":  {},
+	"metrics": {},
+	"static":  {},
+}
+
+// ValidateAlias проверяет пользовательский псевдоним короткой ссылки.
+//
+// Псевдоним должен:
+//  1. иметь длину от MinAliasLength до MaxAliasLength символов
+//  2. состоять только из латинских букв, цифр, '-' и '_'
+//  3. не совпадать (без учета регистра) с зарезервированными словами
+//
+// Возвращает ошибку, оборачивающую ErrInvalidAlias, или nil.
+//
+// Пример:
+//
+//	if err := shortener.ValidateAlias("q3-report"); err != nil {
+//		// errors.Is(err, shortener.ErrInvalidAlias) == true
+//	}
+func ValidateAlias(alias string) error {
+	if len(alias) < MinAliasLength || len(alias) > MaxAliasLength {
+		return fmt.Errorf("%w: длина должна быть от %d до %d символов",
+			ErrInvalidAlias, MinAliasLength, MaxAliasLength)
+	}
+
+	for _, c := range alias {
+		if !isAliasChar(c) {
+			return fmt.Errorf("%w: допустимы только латинские буквы, цифры, '-' и '_'", ErrInvalidAlias)
+		}
+	}
+
+	if isReserved(alias) {
+		return fmt.Errorf("%w: %q зарезервирован", ErrInvalidAlias, alias)
+	}
+
+	return nil
+}
+
+// isReserved проверяет, совпадает ли идентификатор с зарезервированным словом
+func isReserved(id string) bool {
+	_, reserved := reservedAliases[strings.ToLower(id)]
+	return reserved
+}
+
+// isAliasChar проверяет, допустим ли символ в псевдониме
+func isAliasChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') ||
+		c == '-' || c == '_'
+}
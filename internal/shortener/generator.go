@@ -0,0 +1,204 @@
+package shortener
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Поддерживаемые стратегии генерации коротких идентификаторов.
+const (
+	GeneratorRandom  = "random"  // Случайный ID заданной длины
+	GeneratorCounter = "counter" // Последовательный счетчик в base62
+	GeneratorHash    = "hash"    // Детерминированный ID из хеша URL
+	GeneratorSqids   = "sqids"   // Счетчик, обфусцированный в стиле Sqids
+)
+
+// Ограничения длины генерируемого идентификатора.
+const (
+	DefaultIDLength = 8  // Длина идентификатора по умолчанию
+	MinIDLength     = 4  // Минимальная длина идентификатора
+	MaxIDLength     = 32 // Максимальная длина идентификатора (совпадает с MaxAliasLength)
+)
+
+// CounterBlockSize - количество номеров счетчика, резервируемых
+// CounterReserver за одно обращение
+const CounterBlockSize = 100
+
+// base62Alphabet - алфавит base62 для кодирования идентификаторов
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ErrUnknownGenerator возвращается для неизвестного имени стратегии генерации.
+var ErrUnknownGenerator = errors.New("неизвестная стратегия генерации ID")
+
+// Generator генерирует короткий идентификатор для URL.
+//
+// Параметр attempt - номер попытки, начиная с 0. При коллизии сервис
+// повторяет генерацию с увеличенным attempt, поэтому детерминированные
+// стратегии обязаны выдавать для разных попыток разные идентификаторы.
+type Generator interface {
+	Generate(url string, attempt int) string
+}
+
+// CounterReserver резервирует count номеров счетчика и возвращает первый
+// из них. Граница выданных номеров хранится постоянно (см.
+// storage.CounterStorage), поэтому номера не повторяются после перезапуска
+// и удаления ссылок.
+type CounterReserver func(count uint64) (uint64, error)
+
+// NewGenerator создает генератор по имени стратегии.
+//
+// Параметр length задает длину идентификатора для стратегий random и hash
+// и минимальную длину для sqids; стратегия counter его не использует.
+// Параметр reserve задает источник номеров для counter и sqids (nil -
+// счетчик в памяти процесса, начинающийся с 0).
+func NewGenerator(name string, length int, reserve CounterReserver) (Generator, error) {
+	if length < MinIDLength || length > MaxIDLength {
+		return nil, fmt.Errorf("длина ID должна быть от %d до %d символов", MinIDLength, MaxIDLength)
+	}
+
+	switch name {
+	case GeneratorRandom, "":
+		return NewRandomGenerator(length), nil
+	case GeneratorCounter:
+		return NewCounterGenerator(0).WithReserver(reserve), nil
+	case GeneratorHash:
+		return NewHashGenerator(length), nil
+	case GeneratorSqids:
+		return NewSqidsGenerator(0, length).WithReserver(reserve), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGenerator, name)
+	}
+}
+
+// RandomGenerator генерирует случайные идентификаторы заданной длины
+// в алфавите URL-safe base64 с помощью crypto/rand.
+type RandomGenerator struct {
+	length int
+}
+
+// NewRandomGenerator создает генератор случайных идентификаторов
+func NewRandomGenerator(length int) *RandomGenerator {
+	return &RandomGenerator{length: length}
+}
+
+// Generate возвращает случайный идентификатор; url и attempt не используются
+func (g *RandomGenerator) Generate(url string, attempt int) string {
+	// Каждый символ base64 кодирует 6 бит
+	b := make([]byte, (g.length*6+7)/8)
+	rand.Read(b)
+
+	// Используем URL-safe версию base64 (без '/' и '+')
+	return base64.RawURLEncoding.EncodeToString(b)[:g.length]
+}
+
+// CounterGenerator выдает последовательные номера, закодированные в base62.
+//
+// Без CounterReserver счетчик хранится в памяти процесса и после
+// перезапуска начинается заново. С ним номера резервируются в хранилище
+// блоками по CounterBlockSize (см. WithReserver).
+type CounterGenerator struct {
+	counter counter
+}
+
+// NewCounterGenerator создает счетчик, начинающийся со значения start
+func NewCounterGenerator(start uint64) *CounterGenerator {
+	return &CounterGenerator{counter: counter{next: start}}
+}
+
+// WithReserver подключает резервирование номеров: номера берутся
+// из блоков, выданных reserve, а не начиная со start
+func (g *CounterGenerator) WithReserver(reserve CounterReserver) *CounterGenerator {
+	g.counter.reserve = reserve
+	return g
+}
+
+// Generate возвращает следующий номер счетчика в base62
+func (g *CounterGenerator) Generate(url string, attempt int) string {
+	return encodeBase62(g.counter.take())
+}
+
+// counter - счетчик генераторов counter и sqids
+type counter struct {
+	mu      sync.Mutex
+	next    uint64          // следующий номер
+	end     uint64          // граница зарезервированного блока
+	reserve CounterReserver // источник блоков (может быть nil)
+}
+
+// take возвращает следующий номер, резервируя новый блок по исчерпании
+// текущего. Если резервирование не удалось, счетчик продолжает выдавать
+// номера без резерва: после перезапуска они могут совпасть с занятыми,
+// и такие коллизии разрешаются повторами сервиса.
+func (c *counter) take() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reserve != nil && c.next >= c.end {
+		start, err := c.reserve(CounterBlockSize)
+		if err != nil {
+			logger.Logger.Warn("Не удалось зарезервировать номера счетчика ID", zap.Error(err))
+		} else {
+			c.next, c.end = start, start+CounterBlockSize
+		}
+	}
+
+	n := c.next
+	c.next++
+	return n
+}
+
+// HashGenerator строит идентификатор из SHA-256 хеша URL.
+//
+// Один и тот же URL всегда получает один и тот же идентификатор
+// (для attempt = 0). Для повторных попыток номер попытки добавляется
+// к хешируемым данным.
+type HashGenerator struct {
+	length int
+}
+
+// NewHashGenerator создает генератор детерминированных идентификаторов
+func NewHashGenerator(length int) *HashGenerator {
+	return &HashGenerator{length: length}
+}
+
+// Generate возвращает идентификатор из хеша URL и номера попытки
+func (g *HashGenerator) Generate(url string, attempt int) string {
+	data := url
+	if attempt > 0 {
+		data += "#" + strconv.Itoa(attempt)
+	}
+	sum := sha256.Sum256([]byte(data))
+
+	// Кодируем хеш в base62 и берем первые length символов:
+	// 256 бит дают 43 символа, что больше MaxIDLength
+	encoded := new(big.Int).SetBytes(sum[:]).Text(62)
+	for len(encoded) < g.length {
+		encoded = "0" + encoded
+	}
+	return encoded[:g.length]
+}
+
+// encodeBase62 кодирует число в base62
+func encodeBase62(n uint64) string {
+	if n == 0 {
+		return base62Alphabet[:1]
+	}
+
+	var buf [11]byte // 62^11 > 2^64
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+	return string(buf[i:])
+}
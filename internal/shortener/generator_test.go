@@ -0,0 +1,119 @@
+package shortener
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewGenerator(t *testing.T) {
+	reserve := func(count uint64) (uint64, error) { return 1_000_000, nil }
+	for _, name := range []string{GeneratorRandom, GeneratorCounter, GeneratorHash, GeneratorSqids} {
+		gen, err := NewGenerator(name, DefaultIDLength, reserve)
+		require.NoError(t, err, name)
+		assert.NoError(t, ValidateAlias(gen.Generate("https://example.com", 0)), name)
+	}
+
+	_, err := NewGenerator("uuid", DefaultIDLength, nil)
+	assert.ErrorIs(t, err, ErrUnknownGenerator)
+
+	_, err = NewGenerator(GeneratorRandom, MaxIDLength+1, nil)
+	assert.Error(t, err)
+}
+
+func TestRandomGenerator(t *testing.T) {
+	gen := NewRandomGenerator(12)
+
+	seen := make(map[string]struct{})
+	for i := 0; i < 1000; i++ {
+		id := gen.Generate("https://example.com", 0)
+		require.Len(t, id, 12)
+		seen[id] = struct{}{}
+	}
+	assert.Len(t, seen, 1000)
+}
+
+func TestCounterGenerator(t *testing.T) {
+	gen := NewCounterGenerator(61)
+
+	assert.Equal(t, "z", gen.Generate("", 0))
+	assert.Equal(t, "10", gen.Generate("", 0))
+	assert.Equal(t, "11", gen.Generate("", 0))
+}
+
+func TestCounterGenerator_Reserver(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	var next uint64 = 500
+	var calls []uint64
+	failing := false
+	gen := NewCounterGenerator(0).WithReserver(func(count uint64) (uint64, error) {
+		if failing {
+			return 0, errors.New("хранилище недоступно")
+		}
+		calls = append(calls, count)
+		start := next
+		next += count
+		return start, nil
+	})
+
+	// Номера выдаются из зарезервированного блока, новый блок - по исчерпании
+	assert.Equal(t, encodeBase62(500), gen.Generate("", 0))
+	for i := 1; i < CounterBlockSize; i++ {
+		gen.Generate("", 0)
+	}
+	assert.Equal(t, []uint64{CounterBlockSize}, calls)
+	assert.Equal(t, encodeBase62(500+CounterBlockSize), gen.Generate("", 0))
+	assert.Len(t, calls, 2)
+
+	// Без резерва счетчик продолжает выдавать номера
+	for i := 1; i < CounterBlockSize; i++ {
+		gen.Generate("", 0)
+	}
+	failing = true
+	assert.Equal(t, encodeBase62(500+2*CounterBlockSize), gen.Generate("", 0))
+}
+
+func TestHashGenerator(t *testing.T) {
+	gen := NewHashGenerator(10)
+
+	first := gen.Generate("https://example.com", 0)
+	assert.Len(t, first, 10)
+	assert.Equal(t, first, gen.Generate("https://example.com", 0), "ID должен быть детерминированным")
+	assert.NotEqual(t, first, gen.Generate("https://example.org", 0))
+	assert.NotEqual(t, first, gen.Generate("https://example.com", 1), "повторная попытка должна давать другой ID")
+}
+
+func TestSqidsGenerator(t *testing.T) {
+	// Значения совпадают с эталонной реализацией Sqids для алфавита по умолчанию
+	assert.Equal(t, "86Rf07", NewSqidsGenerator(0, 0).Encode(1, 2, 3))
+	assert.Equal(t, "86Rf07xd4z", NewSqidsGenerator(0, 10).Encode(1, 2, 3))
+	assert.Equal(t, "bM", NewSqidsGenerator(0, 0).Encode(0))
+
+	gen := NewSqidsGenerator(0, 8)
+	seen := make(map[string]struct{})
+	for i := 0; i < 1000; i++ {
+		id := gen.Generate("", 0)
+		require.GreaterOrEqual(t, len(id), 8)
+		seen[id] = struct{}{}
+	}
+	assert.Len(t, seen, 1000)
+}
+
+func TestService_SkipsReservedIDs(t *testing.T) {
+	// "api" в base62 соответствует числу 36*62*62 + 51*62 + 44
+	svc := NewWithGenerator("http://localhost:8080", NewCounterGenerator(36*62*62+51*62+44))
+
+	assert.Equal(t, "apj", svc.Shorten("https://example.com"))
+}
+
+func TestService_ShortenAttempt(t *testing.T) {
+	svc := NewWithGenerator("http://localhost:8080", NewHashGenerator(8))
+
+	assert.Equal(t, svc.Shorten("https://example.com"), svc.ShortenAttempt("https://example.com", 0))
+	assert.NotEqual(t, svc.Shorten("https://example.com"), svc.ShortenAttempt("https://example.com", 1))
+}
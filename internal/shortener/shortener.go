@@ -1,21 +1,19 @@
 // Package shortener предоставляет сервис для сокращения URL.
 //
-// Пакет реализует алгоритм генерации коротких идентификаторов
-// на основе криптографически стойкого генератора случайных чисел
-// и base64 кодирования.
+// Пакет реализует подключаемые стратегии генерации коротких
+// идентификаторов (Generator): случайные ID заданной длины,
+// последовательный счетчик в base62, детерминированный ID из хеша URL
+// и обфусцированный счетчик в стиле Sqids.
 package shortener
 
 import (
-	"crypto/rand"
-	"encoding/base64"
 	"strings"
 )
 
 // Service предоставляет функциональность сокращения URL.
 //
-// Сервис генерирует короткие идентификаторы длиной 8 символов
-// используя URL-safe base64 кодирование. Каждый идентификатор
-// статистически уникален благодаря использованию crypto/rand.
+// Идентификаторы создаются генератором (Generator). По умолчанию
+// используется RandomGenerator: 8 символов URL-safe base64 из crypto/rand.
 //
 // Пример использования:
 //
@@ -24,7 +22,8 @@ import (
 //	fullURL := service.BuildShortURL(shortID)
 //	// fullURL = "https://example.com/abc12345"
 type Service struct {
-	baseURL string // Базовый URL для формирования коротких ссылок
+	baseURL   string    // Базовый URL для формирования коротких ссылок
+	generator Generator // Стратегия генерации идентификаторов
 }
 
 // New создает новый экземпляр сервиса сокращения URL.
@@ -39,36 +38,56 @@ type Service struct {
 //
 // Возвращает готовый к использованию сервис.
 func New(baseURL string) *Service {
-	return &Service{
-		baseURL: baseURL,
-	}
+	return NewWithGenerator(baseURL, NewRandomGenerator(DefaultIDLength))
 }
 
-// Shorten генерирует уникальный короткий идентификатор для URL.
+// NewWithGenerator создает сервис сокращения URL с заданной стратегией
+// генерации идентификаторов.
 //
-// Метод создает 8-символьный идентификатор используя:
-//  1. 6 случайных байт из crypto/rand
-//  2. URL-safe base64 кодирование (без символов '/' и '+')
-//  3. Обрезание до 8 символов
+// Пример:
 //
-// Параметр url в данной реализации не используется для генерации ID,
-// что обеспечивает полную случайность идентификаторов.
+//	gen, err := shortener.NewGenerator(shortener.GeneratorSqids, 8, nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	service := shortener.NewWithGenerator("https://short.ly", gen)
+func NewWithGenerator(baseURL string, generator Generator) *Service {
+	return &Service{
+		baseURL:   baseURL,
+		generator: generator,
+	}
+}
+
+// Shorten генерирует короткий идентификатор для URL.
 //
-// Возвращает короткий идентификатор, например: "abc12345"
+// Результат зависит от стратегии генерации: случайный ID, следующий
+// номер счетчика или хеш URL. Уникальность не гарантируется - при
+// коллизии вызывающий повторяет генерацию через ShortenAttempt.
 //
 // Пример:
 //
 //	id := service.Shorten("https://example.com/very/long/path")
 //	// id = "kJ8xN2mP" (случайный 8-символьный ID)
 func (s *Service) Shorten(url string) string {
-	// Генерируем случайную последовательность байт
-	b := make([]byte, 6)
-	rand.Read(b)
+	return s.generate(url, 0)
+}
 
-	// Кодируем в base64 и берем первые 8 символов
-	// Используем URL-safe версию base64 (без '/' и '+')
-	encoded := base64.RawURLEncoding.EncodeToString(b)
-	return encoded[:8]
+// ShortenAttempt генерирует идентификатор для повторной попытки после
+// коллизии. Номер попытки attempt начинается с 1; детерминированные
+// стратегии учитывают его, чтобы не повторять занятый ID.
+func (s *Service) ShortenAttempt(url string, attempt int) string {
+	return s.generate(url, attempt)
+}
+
+// generate получает ID от генератора, пропуская зарезервированные слова:
+// такой ID перекрывался бы служебным маршрутом при редиректе GET /{id}
+func (s *Service) generate(url string, attempt int) string {
+	id := s.generator.Generate(url, attempt)
+	for isReserved(id) {
+		attempt++
+		id = s.generator.Generate(url, attempt)
+	}
+	return id
 }
 
 // BuildShortURL строит полный короткий URL из идентификатора.
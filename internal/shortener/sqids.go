@@ -0,0 +1,109 @@
+package shortener
+
+// sqidsDefaultAlphabet - алфавит Sqids по умолчанию
+const sqidsDefaultAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// SqidsGenerator кодирует последовательный счетчик алгоритмом Sqids.
+//
+// Идентификаторы уникальны, как у CounterGenerator, но не выглядят
+// последовательными: соседние номера дают непохожие строки. Список
+// запрещенных слов Sqids не поддерживается.
+type SqidsGenerator struct {
+	counter   counter
+	alphabet  []byte
+	minLength int
+}
+
+// NewSqidsGenerator создает генератор со счетчиком, начинающимся со start,
+// и минимальной длиной идентификатора minLength
+func NewSqidsGenerator(start uint64, minLength int) *SqidsGenerator {
+	return &SqidsGenerator{
+		counter:   counter{next: start},
+		alphabet:  sqidsShuffle([]byte(sqidsDefaultAlphabet)),
+		minLength: minLength,
+	}
+}
+
+// WithReserver подключает резервирование номеров счетчика
+// (см. CounterGenerator.WithReserver)
+func (g *SqidsGenerator) WithReserver(reserve CounterReserver) *SqidsGenerator {
+	g.counter.reserve = reserve
+	return g
+}
+
+// Generate возвращает следующий номер счетчика, закодированный Sqids
+func (g *SqidsGenerator) Generate(url string, attempt int) string {
+	return g.Encode(g.counter.take())
+}
+
+// Encode кодирует последовательность чисел в идентификатор Sqids
+func (g *SqidsGenerator) Encode(numbers ...uint64) string {
+	if len(numbers) == 0 {
+		return ""
+	}
+
+	alphabet := g.alphabet
+	size := uint64(len(alphabet))
+
+	// Смещение алфавита зависит от кодируемых чисел
+	offset := uint64(len(numbers))
+	for i, n := range numbers {
+		offset += uint64(alphabet[n%size]) + uint64(i)
+	}
+	offset %= size
+	rotated := make([]byte, 0, len(alphabet))
+	rotated = append(rotated, alphabet[offset:]...)
+	alphabet = append(rotated, alphabet[:offset]...)
+
+	prefix := alphabet[0]
+	sqidsReverse(alphabet)
+
+	id := []byte{prefix}
+	for i, n := range numbers {
+		id = append(id, sqidsToID(n, alphabet[1:])...)
+		if i < len(numbers)-1 {
+			id = append(id, alphabet[0])
+			alphabet = sqidsShuffle(alphabet)
+		}
+	}
+
+	// Дополняем идентификатор до минимальной длины
+	if len(id) < g.minLength {
+		id = append(id, alphabet[0])
+		for len(id) < g.minLength {
+			alphabet = sqidsShuffle(alphabet)
+			id = append(id, alphabet[:min(g.minLength-len(id), len(alphabet))]...)
+		}
+	}
+
+	return string(id)
+}
+
+// sqidsShuffle детерминированно перемешивает алфавит (на месте)
+func sqidsShuffle(alphabet []byte) []byte {
+	for i, j := 0, len(alphabet)-1; j > 0; i, j = i+1, j-1 {
+		r := (i*j + int(alphabet[i]) + int(alphabet[j])) % len(alphabet)
+		alphabet[i], alphabet[r] = alphabet[r], alphabet[i]
+	}
+	return alphabet
+}
+
+// sqidsReverse разворачивает алфавит (на месте)
+func sqidsReverse(alphabet []byte) {
+	for i, j := 0, len(alphabet)-1; i < j; i, j = i+1, j-1 {
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+}
+
+// sqidsToID кодирует число в алфавите
+func sqidsToID(n uint64, alphabet []byte) []byte {
+	size := uint64(len(alphabet))
+	var id []byte
+	for {
+		id = append([]byte{alphabet[n%size]}, id...)
+		n /= size
+		if n == 0 {
+			return id
+		}
+	}
+}
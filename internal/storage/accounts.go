@@ -0,0 +1,432 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// Account представляет учетную запись пользователя.
+// ID совпадает с идентификатором пользователя, которому принадлежат URL.
+// Role - роль учетной записи (auth.RoleUser или auth.RoleAdmin); пустое
+// значение в учетных записях, созданных до появления ролей, означает auth.RoleUser.
+type Account struct {
+	ID           string    `json:"id"`
+	Login        string    `json:"login"`
+	PasswordHash string    `json:"password_hash"`
+	Role         string    `json:"role,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CredentialKind - тип учетных данных
+type CredentialKind string
+
+// Типы учетных данных учетной записи.
+const (
+	CredentialSession CredentialKind = "session" // Сессия, созданная входом по паролю
+	CredentialAPIKey  CredentialKind = "api_key" // Именованный API-ключ
+)
+
+// Credential представляет выданный учетной записи токен (сессию или API-ключ).
+//
+// Сам токен не хранится: хранится только его хеш TokenHash, а Prefix -
+// начало токена для отображения пользователю.
+type Credential struct {
+	ID        string         `json:"id"`
+	UserID    string         `json:"user_id"`
+	Kind      CredentialKind `json:"kind"`
+	Name      string         `json:"name,omitempty"`
+	TokenHash string         `json:"token_hash"`
+	Prefix    string         `json:"prefix"`
+	CreatedAt time.Time      `json:"created_at"`
+	ExpiresAt time.Time      `json:"expires_at,omitzero"` // нулевое - бессрочно
+	RevokedAt time.Time      `json:"revoked_at,omitzero"` // нулевое - действует
+}
+
+// Active сообщает, действуют ли учетные данные в момент now
+func (c Credential) Active(now time.Time) bool {
+	if !c.RevokedAt.IsZero() {
+		return false
+	}
+	return c.ExpiresAt.IsZero() || now.Before(c.ExpiresAt)
+}
+
+// AccountStorage интерфейс хранилища учетных записей.
+// Реализуется MemoryStorage и DatabaseStorage.
+type AccountStorage interface {
+	// CreateAccount создает учетную запись.
+	// Возвращает ErrConflict, если логин занят.
+	CreateAccount(ctx context.Context, account Account) error
+
+	// GetAccount возвращает учетную запись по ID или ErrNotFound
+	GetAccount(ctx context.Context, id string) (Account, error)
+
+	// GetAccountByLogin возвращает учетную запись по логину или ErrNotFound
+	GetAccountByLogin(ctx context.Context, login string) (Account, error)
+
+	// SetAccountRole меняет роль учетной записи или возвращает ErrNotFound
+	SetAccountRole(ctx context.Context, id string, role string) error
+
+	// CreateCredential сохраняет учетные данные
+	CreateCredential(ctx context.Context, credential Credential) error
+
+	// GetCredential возвращает учетные данные по хешу токена или ErrNotFound
+	GetCredential(ctx context.Context, tokenHash string) (Credential, error)
+
+	// ListCredentials возвращает учетные данные пользователя заданного типа
+	// (включая отозванные) в порядке создания
+	ListCredentials(ctx context.Context, userID string, kind CredentialKind) ([]Credential, error)
+
+	// RevokeCredential отзывает учетные данные пользователя в момент at.
+	// Возвращает ErrNotFound, если их нет или они принадлежат другому пользователю.
+	RevokeCredential(ctx context.Context, userID string, id string, at time.Time) error
+
+	// ReassignUserURLs передает все URL пользователя fromUserID пользователю
+	// toUserID и возвращает количество переданных URL. Если у toUserID уже есть
+	// ссылка на тот же URL, переданная ссылка исключается из дедупликации.
+	ReassignUserURLs(ctx context.Context, fromUserID string, toUserID string) (int, error)
+}
+
+// accountStore хранит учетные записи MemoryStorage.
+// Защищена отдельным мьютексом: проверка токенов выполняется на каждом
+// запросе и не должна конкурировать с основными операциями хранилища.
+type accountStore struct {
+	mu          sync.RWMutex
+	accounts    map[string]Account    // ID -> учетная запись
+	logins      map[string]string     // логин -> ID
+	credentials map[string]Credential // ID -> учетные данные
+	tokens      map[string]string     // хеш токена -> ID учетных данных
+}
+
+// newAccountStore создает пустое хранилище учетных записей
+func newAccountStore() *accountStore {
+	return &accountStore{
+		accounts:    make(map[string]Account),
+		logins:      make(map[string]string),
+		credentials: make(map[string]Credential),
+		tokens:      make(map[string]string),
+	}
+}
+
+// accountsFile - содержимое файла учетных записей файлового хранилища
+type accountsFile struct {
+	Accounts    []Account    `json:"accounts"`
+	Credentials []Credential `json:"credentials"`
+}
+
+// accountsPath возвращает путь к файлу учетных записей
+func (s *MemoryStorage) accountsPath() string {
+	return s.storagePath + ".accounts"
+}
+
+// CreateAccount создает учетную запись
+func (s *MemoryStorage) CreateAccount(ctx context.Context, account Account) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.accounts.mu.Lock()
+	defer s.accounts.mu.Unlock()
+
+	if _, taken := s.accounts.logins[account.Login]; taken {
+		return fmt.Errorf("%w: логин %q занят", ErrConflict, account.Login)
+	}
+	if _, taken := s.accounts.accounts[account.ID]; taken {
+		return fmt.Errorf("%w: учетная запись %s уже существует", ErrConflict, account.ID)
+	}
+
+	s.accounts.accounts[account.ID] = account
+	s.accounts.logins[account.Login] = account.ID
+	if err := s.saveAccountsLocked(); err != nil {
+		delete(s.accounts.accounts, account.ID)
+		delete(s.accounts.logins, account.Login)
+		return err
+	}
+	return nil
+}
+
+// GetAccount возвращает учетную запись по ID
+func (s *MemoryStorage) GetAccount(ctx context.Context, id string) (Account, error) {
+	if err := ctx.Err(); err != nil {
+		return Account{}, err
+	}
+
+	s.accounts.mu.RLock()
+	defer s.accounts.mu.RUnlock()
+
+	account, ok := s.accounts.accounts[id]
+	if !ok {
+		return Account{}, ErrNotFound
+	}
+	return account, nil
+}
+
+// GetAccountByLogin возвращает учетную запись по логину
+func (s *MemoryStorage) GetAccountByLogin(ctx context.Context, login string) (Account, error) {
+	if err := ctx.Err(); err != nil {
+		return Account{}, err
+	}
+
+	s.accounts.mu.RLock()
+	defer s.accounts.mu.RUnlock()
+
+	id, ok := s.accounts.logins[login]
+	if !ok {
+		return Account{}, ErrNotFound
+	}
+	return s.accounts.accounts[id], nil
+}
+
+// SetAccountRole меняет роль учетной записи
+func (s *MemoryStorage) SetAccountRole(ctx context.Context, id string, role string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.accounts.mu.Lock()
+	defer s.accounts.mu.Unlock()
+
+	account, ok := s.accounts.accounts[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if account.Role == role {
+		return nil
+	}
+
+	updated := account
+	updated.Role = role
+	s.accounts.accounts[id] = updated
+	if err := s.saveAccountsLocked(); err != nil {
+		s.accounts.accounts[id] = account
+		return err
+	}
+	return nil
+}
+
+// CreateCredential сохраняет учетные данные
+func (s *MemoryStorage) CreateCredential(ctx context.Context, credential Credential) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.accounts.mu.Lock()
+	defer s.accounts.mu.Unlock()
+
+	if _, taken := s.accounts.tokens[credential.TokenHash]; taken {
+		return fmt.Errorf("%w: токен уже выдан", ErrConflict)
+	}
+
+	s.accounts.credentials[credential.ID] = credential
+	s.accounts.tokens[credential.TokenHash] = credential.ID
+	if err := s.saveAccountsLocked(); err != nil {
+		delete(s.accounts.credentials, credential.ID)
+		delete(s.accounts.tokens, credential.TokenHash)
+		return err
+	}
+	return nil
+}
+
+// GetCredential возвращает учетные данные по хешу токена
+func (s *MemoryStorage) GetCredential(ctx context.Context, tokenHash string) (Credential, error) {
+	if err := ctx.Err(); err != nil {
+		return Credential{}, err
+	}
+
+	s.accounts.mu.RLock()
+	defer s.accounts.mu.RUnlock()
+
+	id, ok := s.accounts.tokens[tokenHash]
+	if !ok {
+		return Credential{}, ErrNotFound
+	}
+	return s.accounts.credentials[id], nil
+}
+
+// ListCredentials возвращает учетные данные пользователя заданного типа
+func (s *MemoryStorage) ListCredentials(ctx context.Context, userID string, kind CredentialKind) ([]Credential, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.accounts.mu.RLock()
+	defer s.accounts.mu.RUnlock()
+
+	result := []Credential{}
+	for _, credential := range s.accounts.credentials {
+		if credential.UserID == userID && credential.Kind == kind {
+			result = append(result, credential)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// RevokeCredential отзывает учетные данные пользователя
+func (s *MemoryStorage) RevokeCredential(ctx context.Context, userID string, id string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.accounts.mu.Lock()
+	defer s.accounts.mu.Unlock()
+
+	credential, ok := s.accounts.credentials[id]
+	if !ok || credential.UserID != userID {
+		return ErrNotFound
+	}
+	if !credential.RevokedAt.IsZero() {
+		return nil
+	}
+
+	revoked := credential
+	revoked.RevokedAt = at
+	s.accounts.credentials[id] = revoked
+	if err := s.saveAccountsLocked(); err != nil {
+		s.accounts.credentials[id] = credential
+		return err
+	}
+	return nil
+}
+
+// ReassignUserURLs передает все URL пользователя fromUserID пользователю toUserID.
+//
+// В файловом режиме передача сохраняется в журнал одной записью
+// models.URLRecordEventReassign.
+func (s *MemoryStorage) ReassignUserURLs(ctx context.Context, fromUserID string, toUserID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if fromUserID == "" || toUserID == "" || fromUserID == toUserID {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrStorageClosed
+	}
+	moved := s.reassignLocked(fromUserID, toUserID)
+
+	var done <-chan error
+	if moved > 0 && s.fileMode {
+		done = s.enqueueLocked(models.URLRecord{
+			UUID:           strconv.Itoa(s.nextID),
+			Event:          models.URLRecordEventReassign,
+			UserID:         toUserID,
+			PreviousUserID: fromUserID,
+		})
+		s.nextID++
+	}
+	s.mu.Unlock()
+
+	// Дожидаемся записи на диск (только в синхронном режиме)
+	if err := waitPersisted(done); err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// reassignLocked передает URL пользователя и перестраивает индекс дедупликации,
+// вызывающий должен удерживать мьютекс. Возвращает количество переданных URL.
+func (s *MemoryStorage) reassignLocked(fromUserID string, toUserID string) int {
+	shortURLs := s.userURLs[fromUserID]
+	if len(shortURLs) == 0 {
+		return 0
+	}
+
+	for _, shortURL := range shortURLs {
+		url := s.urls[shortURL]
+
+		// Ссылка переходит в группу дедупликации нового владельца,
+		// если в ней еще нет ссылки на тот же URL
+		oldKey, dedup := s.dedup.key(fromUserID, url)
+		if !dedup || s.dedupIndex[oldKey] != shortURL {
+			continue
+		}
+		newKey, _ := s.dedup.key(toUserID, url)
+		if newKey == oldKey {
+			continue
+		}
+		delete(s.dedupIndex, oldKey)
+		if _, exists := s.dedupIndex[newKey]; !exists {
+			s.dedupIndex[newKey] = shortURL
+		}
+	}
+
+	s.userURLs[toUserID] = append(s.userURLs[toUserID], shortURLs...)
+	delete(s.userURLs, fromUserID)
+	return len(shortURLs)
+}
+
+// saveAccountsLocked атомарно перезаписывает файл учетных записей,
+// вызывающий должен удерживать мьютекс учетных записей.
+// Учетные записи меняются редко, поэтому файл пишется целиком.
+func (s *MemoryStorage) saveAccountsLocked() error {
+	if !s.fileMode {
+		return nil
+	}
+
+	data := accountsFile{
+		Accounts:    make([]Account, 0, len(s.accounts.accounts)),
+		Credentials: make([]Credential, 0, len(s.accounts.credentials)),
+	}
+	for _, account := range s.accounts.accounts {
+		data.Accounts = append(data.Accounts, account)
+	}
+	for _, credential := range s.accounts.credentials {
+		data.Credentials = append(data.Credentials, credential)
+	}
+	sort.Slice(data.Accounts, func(i, j int) bool { return data.Accounts[i].ID < data.Accounts[j].ID })
+	sort.Slice(data.Credentials, func(i, j int) bool { return data.Credentials[i].ID < data.Credentials[j].ID })
+
+	return writeFileAtomic(s.accountsPath(), func(writer *bufio.Writer) error {
+		if err := json.NewEncoder(writer).Encode(data); err != nil {
+			return fmt.Errorf("ошибка записи учетных записей: %w", err)
+		}
+		return nil
+	})
+}
+
+// loadAccounts загружает учетные записи из файла
+func (s *MemoryStorage) loadAccounts() error {
+	raw, err := os.ReadFile(s.accountsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка чтения учетных записей: %w", err)
+	}
+
+	var data accountsFile
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("ошибка декодирования учетных записей: %w", err)
+	}
+
+	s.accounts.mu.Lock()
+	defer s.accounts.mu.Unlock()
+
+	for _, account := range data.Accounts {
+		s.accounts.accounts[account.ID] = account
+		s.accounts.logins[account.Login] = account.ID
+	}
+	for _, credential := range data.Credentials {
+		s.accounts.credentials[credential.ID] = credential
+		s.accounts.tokens[credential.TokenHash] = credential.ID
+	}
+	return nil
+}
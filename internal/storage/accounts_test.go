@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage_Accounts(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage("")
+	defer store.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	account := Account{ID: "acc1", Login: "alice", PasswordHash: "hash", CreatedAt: now}
+	require.NoError(t, store.CreateAccount(ctx, account))
+
+	// Логин уникален
+	err := store.CreateAccount(ctx, Account{ID: "acc2", Login: "alice", CreatedAt: now})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	got, err := store.GetAccountByLogin(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, account, got)
+
+	_, err = store.GetAccount(ctx, "acc2")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	key := Credential{ID: "key1", UserID: "acc1", Kind: CredentialAPIKey, Name: "ci", TokenHash: "h1", Prefix: "sk_abc", CreatedAt: now}
+	session := Credential{ID: "ses1", UserID: "acc1", Kind: CredentialSession, TokenHash: "h2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, store.CreateCredential(ctx, key))
+	require.NoError(t, store.CreateCredential(ctx, session))
+
+	keys, err := store.ListCredentials(ctx, "acc1", CredentialAPIKey)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "key1", keys[0].ID)
+
+	// Чужой ключ не отзывается
+	assert.ErrorIs(t, store.RevokeCredential(ctx, "acc2", "key1", now), ErrNotFound)
+	require.NoError(t, store.RevokeCredential(ctx, "acc1", "key1", now))
+
+	got2, err := store.GetCredential(ctx, "h1")
+	require.NoError(t, err)
+	assert.False(t, got2.Active(now))
+
+	got2, err = store.GetCredential(ctx, "h2")
+	require.NoError(t, err)
+	assert.True(t, got2.Active(now))
+	assert.False(t, got2.Active(now.Add(2*time.Hour)))
+}
+
+func TestMemoryStorage_AccountsPersisted(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	store := NewMemoryStorage(path)
+	require.NoError(t, store.CreateAccount(ctx, Account{ID: "acc1", Login: "alice", PasswordHash: "hash", CreatedAt: now}))
+	require.NoError(t, store.CreateCredential(ctx, Credential{ID: "key1", UserID: "acc1", Kind: CredentialAPIKey, TokenHash: "h1", CreatedAt: now}))
+	require.NoError(t, store.RevokeCredential(ctx, "acc1", "key1", now))
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+
+	account, err := restored.GetAccountByLogin(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "acc1", account.ID)
+
+	credential, err := restored.GetCredential(ctx, "h1")
+	require.NoError(t, err)
+	assert.True(t, credential.RevokedAt.Equal(now))
+}
+
+func TestMemoryStorage_ReassignUserURLs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorageWithOptions(path, Options{Dedup: DedupPerUser, Durability: DurabilitySync})
+	_, err := store.Add(ctx, "anon1", "https://example.com/1", "anon", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Add(ctx, "anon2", "https://example.com/2", "anon", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Add(ctx, "acc2", "https://example.com/2", "acc", time.Time{})
+	require.NoError(t, err)
+
+	moved, err := store.ReassignUserURLs(ctx, "anon", "acc")
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+
+	urls, err := store.GetUserURLs(ctx, "acc")
+	require.NoError(t, err)
+	assert.Len(t, urls, 3)
+
+	urls, err = store.GetUserURLs(ctx, "anon")
+	require.NoError(t, err)
+	assert.Empty(t, urls)
+
+	// Перенесенная ссылка дедуплицируется в группе нового владельца,
+	// а для URL, который у владельца уже был, сохраняется его ссылка
+	id, err := store.FindByOriginalURL(ctx, "https://example.com/1", "acc")
+	require.NoError(t, err)
+	assert.Equal(t, "anon1", id)
+	id, err = store.FindByOriginalURL(ctx, "https://example.com/2", "acc")
+	require.NoError(t, err)
+	assert.Equal(t, "acc2", id)
+	_, err = store.FindByOriginalURL(ctx, "https://example.com/1", "anon")
+	assert.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, store.Close())
+
+	// Передача восстанавливается из журнала
+	restored := NewMemoryStorageWithOptions(path, Options{Dedup: DedupPerUser})
+	defer restored.Close()
+
+	urls, err = restored.GetUserURLs(ctx, "acc")
+	require.NoError(t, err)
+	assert.Len(t, urls, 3)
+	id, err = restored.FindByOriginalURL(ctx, "https://example.com/1", "acc")
+	require.NoError(t, err)
+	assert.Equal(t, "anon1", id)
+}
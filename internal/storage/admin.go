@@ -0,0 +1,395 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// ErrAdminUnsupported возвращается, когда хранилище не поддерживает модерацию ссылок
+var ErrAdminUnsupported = errors.New("хранилище не поддерживает модерацию ссылок")
+
+// LinkStatus - состояние ссылки в фильтре поиска
+type LinkStatus string
+
+// Состояния ссылок для поиска.
+const (
+	LinkStatusAny      LinkStatus = ""         // Любое состояние
+	LinkStatusActive   LinkStatus = "active"   // Не удалена и не отключена
+	LinkStatusDisabled LinkStatus = "disabled" // Отключена модератором
+	LinkStatusDeleted  LinkStatus = "deleted"  // Удалена владельцем или очисткой истекших
+)
+
+// ParseLinkStatus проверяет и преобразует строковое значение состояния ссылки
+func ParseLinkStatus(value string) (LinkStatus, error) {
+	switch status := LinkStatus(value); status {
+	case LinkStatusAny, LinkStatusActive, LinkStatusDisabled, LinkStatusDeleted:
+		return status, nil
+	default:
+		return "", fmt.Errorf("неизвестное состояние ссылки %q (допустимо: active, disabled, deleted)", value)
+	}
+}
+
+// LinkFilter задает условия поиска ссылок. Пустые поля поиск не ограничивают.
+type LinkFilter struct {
+	ShortID     string     // Короткий ID (точное совпадение)
+	URLContains string     // Подстрока оригинального URL без учета регистра
+	Host        string     // Хост оригинального URL или любой его поддомен (в нижнем регистре)
+	UserID      string     // Владелец ссылки
+	CreatedFrom time.Time  // Ссылка создана не раньше этого момента
+	CreatedTo   time.Time  // Ссылка создана раньше этого момента
+	Status      LinkStatus // Состояние ссылки
+}
+
+// LinkInfo описывает ссылку для модерации
+type LinkInfo struct {
+	ShortID     string
+	OriginalURL string
+	UserID      string    // Владелец (пустой - ссылка без владельца)
+	CreatedAt   time.Time // Нулевое значение - момент создания неизвестен
+	ExpiresAt   time.Time // Нулевое значение - бессрочная ссылка
+	Deleted     bool
+	Disabled    bool
+}
+
+// LinkPage - страница результатов поиска ссылок
+type LinkPage struct {
+	Links []LinkInfo
+	Total int // Количество ссылок, удовлетворяющих фильтру, на всех страницах
+}
+
+// AdminStorage интерфейс модерации ссылок.
+// Реализуется MemoryStorage и DatabaseStorage.
+//
+// Методы изменения принимают список коротких ID и пропускают отсутствующие
+// ссылки, поэтому повторный вызов безопасен.
+type AdminStorage interface {
+	// SearchLinks возвращает не более limit ссылок, удовлетворяющих фильтру,
+	// пропустив первые offset. Ссылки упорядочены от новых к старым, при
+	// равном моменте создания - по короткому ID; ссылки с неизвестным
+	// моментом создания идут последними.
+	SearchLinks(ctx context.Context, filter LinkFilter, offset int, limit int) (LinkPage, error)
+
+	// SetLinksDisabled отключает (disabled == true) или включает ссылки.
+	// Отключенная ссылка не удаляется, но Get возвращает для нее ErrDisabled.
+	// Возвращает количество ссылок, состояние которых изменилось.
+	SetLinksDisabled(ctx context.Context, ids []string, disabled bool) (int, error)
+
+	// PurgeLinks безвозвратно удаляет ссылки вместе со статистикой переходов.
+	// Короткий ID удаленной ссылки снова становится свободным.
+	// Возвращает количество удаленных ссылок.
+	PurgeLinks(ctx context.Context, ids []string) (int, error)
+
+	// ReassignLinks передает ссылки пользователю userID и возвращает
+	// количество переданных ссылок. Если у userID уже есть ссылка на тот же
+	// URL, переданная ссылка исключается из дедупликации.
+	ReassignLinks(ctx context.Context, ids []string, userID string) (int, error)
+}
+
+// match проверяет, удовлетворяет ли ссылка фильтру
+func (f LinkFilter) match(link LinkInfo) bool {
+	if f.ShortID != "" && link.ShortID != f.ShortID {
+		return false
+	}
+	if f.UserID != "" && link.UserID != f.UserID {
+		return false
+	}
+	if f.URLContains != "" && !strings.Contains(strings.ToLower(link.OriginalURL), strings.ToLower(f.URLContains)) {
+		return false
+	}
+	if f.Host != "" {
+		host := linkHost(link.OriginalURL)
+		if host != f.Host && !strings.HasSuffix(host, "."+f.Host) {
+			return false
+		}
+	}
+	if !f.CreatedFrom.IsZero() && (link.CreatedAt.IsZero() || link.CreatedAt.Before(f.CreatedFrom)) {
+		return false
+	}
+	if !f.CreatedTo.IsZero() && (link.CreatedAt.IsZero() || !link.CreatedAt.Before(f.CreatedTo)) {
+		return false
+	}
+
+	switch f.Status {
+	case LinkStatusActive:
+		return !link.Deleted && !link.Disabled
+	case LinkStatusDisabled:
+		return link.Disabled
+	case LinkStatusDeleted:
+		return link.Deleted
+	default:
+		return true
+	}
+}
+
+// linkHost возвращает хост оригинального URL в нижнем регистре
+func linkHost(originalURL string) string {
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+// SearchLinks ищет ссылки по фильтру полным просмотром данных в памяти
+func (s *MemoryStorage) SearchLinks(ctx context.Context, filter LinkFilter, offset int, limit int) (LinkPage, error) {
+	if err := ctx.Err(); err != nil {
+		return LinkPage{}, err
+	}
+
+	s.mu.RLock()
+	owners := s.ownersLocked()
+	matched := make([]LinkInfo, 0)
+	for id, originalURL := range s.urls {
+		link := LinkInfo{
+			ShortID:     id,
+			OriginalURL: originalURL,
+			UserID:      owners[id],
+			CreatedAt:   s.createdAt[id],
+			ExpiresAt:   s.expiresAt[id],
+			Deleted:     s.deletedURLs[id],
+			Disabled:    s.disabledURLs[id],
+		}
+		if filter.match(link) {
+			matched = append(matched, link)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ShortID < matched[j].ShortID
+	})
+
+	page := LinkPage{Links: []LinkInfo{}, Total: len(matched)}
+	if offset < len(matched) {
+		page.Links = matched[offset:min(offset+limit, len(matched))]
+	}
+	return page, nil
+}
+
+// SetLinksDisabled отключает или включает ссылки.
+//
+// В файловом режиме каждое изменение сохраняется в журнал записью
+// models.URLRecordEventDisable или models.URLRecordEventEnable.
+func (s *MemoryStorage) SetLinksDisabled(ctx context.Context, ids []string, disabled bool) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	event := models.URLRecordEventEnable
+	if disabled {
+		event = models.URLRecordEventDisable
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrStorageClosed
+	}
+	changed := 0
+	var pending []<-chan error
+	for _, id := range ids {
+		if _, ok := s.urls[id]; !ok || s.disabledURLs[id] == disabled {
+			continue
+		}
+		if disabled {
+			s.disabledURLs[id] = true
+		} else {
+			delete(s.disabledURLs, id)
+		}
+		changed++
+		pending = s.journalLocked(pending, models.URLRecord{ShortURL: id, Event: event})
+	}
+	s.mu.Unlock()
+
+	return changed, waitAllPersisted(pending)
+}
+
+// PurgeLinks безвозвратно удаляет ссылки.
+//
+// В файловом режиме удаление сохраняется в журнал записью
+// models.URLRecordEventPurge. Статистика переходов хранится только
+// в памяти и удаляется без записи в журнал.
+func (s *MemoryStorage) PurgeLinks(ctx context.Context, ids []string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrStorageClosed
+	}
+	owners := s.ownersLocked()
+	var purged []string
+	var pending []<-chan error
+	for _, id := range ids {
+		if !s.purgeLocked(id, owners[id]) {
+			continue
+		}
+		purged = append(purged, id)
+		pending = s.journalLocked(pending, models.URLRecord{
+			ShortURL: id,
+			Event:    models.URLRecordEventPurge,
+			UserID:   owners[id],
+		})
+	}
+	s.mu.Unlock()
+
+	s.clicks.mu.Lock()
+	for _, id := range purged {
+		delete(s.clicks.links, id)
+	}
+	s.clicks.mu.Unlock()
+
+	return len(purged), waitAllPersisted(pending)
+}
+
+// ReassignLinks передает ссылки пользователю userID.
+//
+// В файловом режиме передача каждой ссылки сохраняется в журнал записью
+// models.URLRecordEventMove.
+func (s *MemoryStorage) ReassignLinks(ctx context.Context, ids []string, userID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if userID == "" {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrStorageClosed
+	}
+	owners := s.ownersLocked()
+	moved := 0
+	var pending []<-chan error
+	for _, id := range ids {
+		previous := owners[id]
+		if !s.moveLocked(id, previous, userID) {
+			continue
+		}
+		owners[id] = userID
+		moved++
+		pending = s.journalLocked(pending, models.URLRecord{
+			ShortURL:       id,
+			Event:          models.URLRecordEventMove,
+			UserID:         userID,
+			PreviousUserID: previous,
+		})
+	}
+	s.mu.Unlock()
+
+	return moved, waitAllPersisted(pending)
+}
+
+// ownersLocked строит индекс владельцев ссылок, вызывающий должен удерживать мьютекс
+func (s *MemoryStorage) ownersLocked() map[string]string {
+	owners := make(map[string]string, len(s.urls))
+	for userID, shortURLs := range s.userURLs {
+		for _, shortURL := range shortURLs {
+			owners[shortURL] = userID
+		}
+	}
+	return owners
+}
+
+// journalLocked добавляет запись в журнал файлового хранилища, вызывающий
+// должен удерживать мьютекс. Канал ожидания записи добавляется к pending.
+func (s *MemoryStorage) journalLocked(pending []<-chan error, record models.URLRecord) []<-chan error {
+	if !s.fileMode {
+		return pending
+	}
+	record.UUID = strconv.Itoa(s.nextID)
+	s.nextID++
+	if done := s.enqueueLocked(record); done != nil {
+		pending = append(pending, done)
+	}
+	return pending
+}
+
+// waitAllPersisted дожидается записи на диск всех записей
+func waitAllPersisted(pending []<-chan error) error {
+	for _, done := range pending {
+		if err := waitPersisted(done); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeLocked удаляет ссылку владельца owner из всех индексов,
+// вызывающий должен удерживать мьютекс. Возвращает false, если ссылки нет.
+func (s *MemoryStorage) purgeLocked(id string, owner string) bool {
+	originalURL, ok := s.urls[id]
+	if !ok {
+		return false
+	}
+
+	delete(s.urls, id)
+	if key, dedup := s.dedup.key(owner, originalURL); dedup && s.dedupIndex[key] == id {
+		delete(s.dedupIndex, key)
+	}
+	if owner != "" {
+		s.removeUserURLLocked(owner, id)
+	}
+	delete(s.deletedURLs, id)
+	delete(s.disabledURLs, id)
+	delete(s.expiresAt, id)
+	delete(s.createdAt, id)
+	return true
+}
+
+// moveLocked передает ссылку от пользователя from пользователю to и переносит
+// ее в группу дедупликации нового владельца, если в ней еще нет ссылки на тот
+// же URL. Вызывающий должен удерживать мьютекс. Возвращает false, если ссылки
+// нет или она уже принадлежит to.
+func (s *MemoryStorage) moveLocked(id string, from string, to string) bool {
+	originalURL, ok := s.urls[id]
+	if !ok || from == to {
+		return false
+	}
+
+	if oldKey, dedup := s.dedup.key(from, originalURL); dedup && s.dedupIndex[oldKey] == id {
+		if newKey, _ := s.dedup.key(to, originalURL); newKey != oldKey {
+			delete(s.dedupIndex, oldKey)
+			if _, exists := s.dedupIndex[newKey]; !exists {
+				s.dedupIndex[newKey] = id
+			}
+		}
+	}
+
+	if from != "" {
+		s.removeUserURLLocked(from, id)
+	}
+	s.userURLs[to] = append(s.userURLs[to], id)
+	return true
+}
+
+// removeUserURLLocked удаляет ссылку из списка URL пользователя,
+// вызывающий должен удерживать мьютекс
+func (s *MemoryStorage) removeUserURLLocked(userID string, id string) {
+	shortURLs := s.userURLs[userID]
+	for i, shortURL := range shortURLs {
+		if shortURL == id {
+			shortURLs = append(shortURLs[:i:i], shortURLs[i+1:]...)
+			break
+		}
+	}
+	if len(shortURLs) == 0 {
+		delete(s.userURLs, userID)
+		return
+	}
+	s.userURLs[userID] = shortURLs
+}
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addLinks добавляет ссылки id -> url пользователю userID
+func addLinks(t *testing.T, store *MemoryStorage, userID string, links map[string]string) {
+	t.Helper()
+	for id, url := range links {
+		_, err := store.Add(context.Background(), id, url, userID, time.Time{})
+		require.NoError(t, err)
+	}
+}
+
+// shortIDs возвращает короткие ID страницы результатов
+func shortIDs(page LinkPage) []string {
+	ids := make([]string, len(page.Links))
+	for i, link := range page.Links {
+		ids[i] = link.ShortID
+	}
+	return ids
+}
+
+func TestMemoryStorage_SearchLinks(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage("")
+	defer store.Close()
+
+	addLinks(t, store, "alice", map[string]string{
+		"a1": "https://example.com/Page",
+		"a2": "https://news.example.com/",
+	})
+	addLinks(t, store, "bob", map[string]string{
+		"b1": "https://other.org/example",
+	})
+	require.NoError(t, store.DeleteUserURLs(ctx, "bob", []string{"b1"}))
+	_, err := store.SetLinksDisabled(ctx, []string{"a2"}, true)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		filter LinkFilter
+		want   []string
+	}{
+		{name: "все ссылки", filter: LinkFilter{}, want: []string{"a1", "a2", "b1"}},
+		{name: "по короткому ID", filter: LinkFilter{ShortID: "b1"}, want: []string{"b1"}},
+		{name: "по владельцу", filter: LinkFilter{UserID: "alice"}, want: []string{"a1", "a2"}},
+		{name: "по подстроке URL", filter: LinkFilter{URLContains: "PAGE"}, want: []string{"a1"}},
+		{name: "по хосту с поддоменами", filter: LinkFilter{Host: "example.com"}, want: []string{"a1", "a2"}},
+		{name: "активные", filter: LinkFilter{Status: LinkStatusActive}, want: []string{"a1"}},
+		{name: "отключенные", filter: LinkFilter{Status: LinkStatusDisabled}, want: []string{"a2"}},
+		{name: "удаленные", filter: LinkFilter{Status: LinkStatusDeleted}, want: []string{"b1"}},
+		{name: "по периоду создания", filter: LinkFilter{CreatedTo: time.Now().Add(-time.Hour)}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := store.SearchLinks(ctx, tt.filter, 0, 10)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, shortIDs(page))
+			assert.Equal(t, len(tt.want), page.Total)
+		})
+	}
+
+	// Страница ограничивается limit, Total учитывает все ссылки
+	page, err := store.SearchLinks(ctx, LinkFilter{}, 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, page.Links, 1)
+	assert.Equal(t, 3, page.Total)
+}
+
+func TestMemoryStorage_SetLinksDisabled(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage("")
+	defer store.Close()
+
+	addLinks(t, store, "alice", map[string]string{"a1": "https://example.com/"})
+
+	n, err := store.SetLinksDisabled(ctx, []string{"a1", "missing"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = store.Get(ctx, "a1")
+	assert.ErrorIs(t, err, ErrDisabled)
+	assert.ErrorIs(t, err, ErrGone)
+
+	// Повторное отключение ничего не меняет
+	n, err = store.SetLinksDisabled(ctx, []string{"a1"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = store.SetLinksDisabled(ctx, []string{"a1"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	url, err := store.Get(ctx, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/", url)
+}
+
+func TestMemoryStorage_PurgeLinks(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage("")
+	defer store.Close()
+
+	addLinks(t, store, "alice", map[string]string{
+		"a1": "https://example.com/",
+		"a2": "https://example.org/",
+	})
+
+	n, err := store.PurgeLinks(ctx, []string{"a1", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = store.Get(ctx, "a1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	urls, err := store.GetUserURLs(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Equal(t, "a2", urls[0].ShortURL)
+
+	// Короткий ID и URL снова свободны
+	_, err = store.FindByOriginalURL(ctx, "https://example.com/", "alice")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Add(ctx, "a1", "https://example.net/", "bob", time.Time{})
+	require.NoError(t, err)
+}
+
+func TestMemoryStorage_ReassignLinks(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage("")
+	defer store.Close()
+
+	addLinks(t, store, "alice", map[string]string{
+		"a1": "https://example.com/",
+		"a2": "https://example.org/",
+	})
+
+	n, err := store.ReassignLinks(ctx, []string{"a1", "missing"}, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// Передача тому же владельцу ничего не меняет
+	n, err = store.ReassignLinks(ctx, []string{"a1"}, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	aliceURLs, err := store.GetUserURLs(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, aliceURLs, 1)
+	assert.Equal(t, "a2", aliceURLs[0].ShortURL)
+
+	bobURLs, err := store.GetUserURLs(ctx, "bob")
+	require.NoError(t, err)
+	require.Len(t, bobURLs, 1)
+	assert.Equal(t, "a1", bobURLs[0].ShortURL)
+
+	page, err := store.SearchLinks(ctx, LinkFilter{ShortID: "a1"}, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, "bob", page.Links[0].UserID)
+}
+
+func TestMemoryStorage_AdminPersisted(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorage(path)
+	addLinks(t, store, "alice", map[string]string{
+		"a1": "https://example.com/",
+		"a2": "https://example.org/",
+		"a3": "https://example.net/",
+	})
+	_, err := store.SetLinksDisabled(ctx, []string{"a1"}, true)
+	require.NoError(t, err)
+	_, err = store.PurgeLinks(ctx, []string{"a2"})
+	require.NoError(t, err)
+	_, err = store.ReassignLinks(ctx, []string{"a3"}, "bob")
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// Состояние восстанавливается из журнала и сохраняется при уплотнении
+	for _, step := range []string{"журнал", "уплотнение"} {
+		restored := NewMemoryStorage(path)
+
+		_, err = restored.Get(ctx, "a1")
+		assert.ErrorIs(t, err, ErrDisabled, step)
+		_, err = restored.Get(ctx, "a2")
+		assert.ErrorIs(t, err, ErrNotFound, step)
+
+		page, err := restored.SearchLinks(ctx, LinkFilter{UserID: "bob"}, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a3"}, shortIDs(page), step)
+		assert.False(t, page.Links[0].CreatedAt.IsZero(), step)
+
+		_, err = restored.Compact()
+		require.NoError(t, err)
+		require.NoError(t, restored.Close())
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/database"
+)
+
+// Record описывает URL для пакетного добавления
+type Record struct {
+	ID          string    // Короткий идентификатор
+	OriginalURL string    // Оригинальный URL
+	ExpiresAt   time.Time // Момент истечения срока действия (нулевое значение - бессрочно)
+}
+
+// RecordStatus - результат пакетного добавления отдельной записи
+type RecordStatus string
+
+// Результаты пакетного добавления записи.
+const (
+	RecordCreated  RecordStatus = "created"  // Запись добавлена
+	RecordExisting RecordStatus = "existing" // URL уже был сокращен, ID - существующий
+	RecordFailed   RecordStatus = "error"    // Запись не добавлена, причина в Err
+)
+
+// RecordResult содержит результат пакетного добавления одной записи
+type RecordResult struct {
+	ID     string       // Короткий идентификатор (новый или существующий)
+	Status RecordStatus // Результат добавления
+	Err    error        // Причина ошибки для RecordFailed
+}
+
+// BatchAdder описывает хранилище, добавляющее пакет URL одной операцией.
+//
+// Результаты возвращаются в порядке записей. Ошибка самого метода означает
+// сбой хранилища: в этом случае ни одна запись не считается добавленной.
+type BatchAdder interface {
+	AddBatch(ctx context.Context, records []Record, userID string) ([]RecordResult, error)
+}
+
+// AddBatch добавляет пакет URL в хранилище.
+//
+// Если хранилище реализует BatchAdder, используется его пакетная вставка,
+// иначе записи добавляются по одной через Add. При поэлементном добавлении
+// сбой хранилища прерывает обработку, но уже добавленные записи сохраняются.
+func AddBatch(ctx context.Context, store URLStorageV2, records []Record, userID string) ([]RecordResult, error) {
+	if adder, ok := store.(BatchAdder); ok {
+		return adder.AddBatch(ctx, records, userID)
+	}
+
+	results := make([]RecordResult, len(records))
+	for i, record := range records {
+		id, err := store.Add(ctx, record.ID, record.OriginalURL, userID, record.ExpiresAt)
+		result, err := recordResult(id, err)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// recordResult преобразует результат Add в результат пакетного добавления.
+// Конфликты уникальности становятся статусом записи, остальные ошибки
+// возвращаются как сбой хранилища.
+func recordResult(id string, err error) (RecordResult, error) {
+	switch {
+	case err == nil:
+		return RecordResult{ID: id, Status: RecordCreated}, nil
+	case errors.Is(err, database.ErrURLConflict):
+		return RecordResult{ID: id, Status: RecordExisting}, nil
+	case errors.Is(err, ErrConflict), errors.Is(err, database.ErrShortIDConflict):
+		return RecordResult{Status: RecordFailed, Err: err}, nil
+	default:
+		return RecordResult{}, err
+	}
+}
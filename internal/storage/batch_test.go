@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequentialStorage скрывает AddBatch, чтобы проверить поэлементное добавление
+type sequentialStorage struct {
+	URLStorageV2
+}
+
+func TestAddBatch_Statuses(t *testing.T) {
+	stores := map[string]func() URLStorageV2{
+		"batch":      func() URLStorageV2 { return NewMemoryStorage("") },
+		"sequential": func() URLStorageV2 { return sequentialStorage{NewMemoryStorage("")} },
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore()
+			defer store.Close()
+
+			_, err := store.Add(ctx, "old", "https://example.com/old", "user1", time.Time{})
+			require.NoError(t, err)
+
+			results, err := AddBatch(ctx, store, []Record{
+				{ID: "new", OriginalURL: "https://example.com/new"},
+				{ID: "other", OriginalURL: "https://example.com/old"},
+				{ID: "old", OriginalURL: "https://example.com/taken"},
+				{ID: "new", OriginalURL: "https://example.com/new"},
+			}, "user2")
+			require.NoError(t, err)
+			require.Len(t, results, 4)
+
+			assert.Equal(t, RecordResult{ID: "new", Status: RecordCreated}, results[0])
+			assert.Equal(t, RecordResult{ID: "old", Status: RecordExisting}, results[1])
+			assert.Equal(t, RecordFailed, results[2].Status)
+			assert.ErrorIs(t, results[2].Err, database.ErrShortIDConflict)
+			assert.Equal(t, RecordResult{ID: "new", Status: RecordExisting}, results[3])
+
+			urls, err := store.GetUserURLs(ctx, "user2")
+			require.NoError(t, err)
+			assert.Len(t, urls, 1)
+		})
+	}
+}
+
+func TestMemoryStorage_AddBatchSyncPersists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorageWithOptions(path, Options{Durability: DurabilitySync})
+	results, err := store.AddBatch(ctx, []Record{
+		{ID: "a", OriginalURL: "https://example.com/a"},
+		{ID: "b", OriginalURL: "https://example.com/b", ExpiresAt: time.Now().Add(time.Hour)},
+	}, "user1")
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.Equal(t, RecordCreated, result.Status)
+	}
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+
+	urls, err := restored.GetUserURLs(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+}
+
+func TestMemoryStorage_AddBatchClosed(t *testing.T) {
+	store := NewMemoryStorage(filepath.Join(t.TempDir(), "storage.json"))
+	require.NoError(t, store.Close())
+
+	_, err := store.AddBatch(context.Background(), []Record{{ID: "a", OriginalURL: "https://example.com/a"}}, "")
+	assert.ErrorIs(t, err, ErrStorageClosed)
+}
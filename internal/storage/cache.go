@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+// CacheBackend определяет, где хранится кэш ссылок перед PostgreSQL
+type CacheBackend string
+
+// Хранилища кэша ссылок.
+const (
+	// CacheNone - кэш отключен (поведение по умолчанию для нулевого значения).
+	CacheNone CacheBackend = "none"
+
+	// CacheMemory - LRU-кэш в памяти процесса. Каждый экземпляр сервиса
+	// кэширует ссылки независимо, поэтому удаление, выполненное другим
+	// экземпляром, становится видно только по истечении TTL.
+	CacheMemory CacheBackend = "memory"
+
+	// CacheRedis - внешний кэш по протоколу RESP (Redis, Valkey, KeyDB),
+	// общий для всех экземпляров сервиса.
+	CacheRedis CacheBackend = "redis"
+)
+
+// Параметры кэша по умолчанию
+const (
+	DefaultCacheSize = 10000           // Максимальное количество ссылок в LRU-кэше
+	DefaultCacheTTL  = 5 * time.Minute // Время жизни ссылки в кэше
+)
+
+// ParseCacheBackend проверяет и преобразует строковое значение хранилища кэша
+func ParseCacheBackend(value string) (CacheBackend, error) {
+	switch b := CacheBackend(value); b {
+	case "":
+		return CacheNone, nil
+	case CacheNone, CacheMemory, CacheRedis:
+		return b, nil
+	default:
+		return "", fmt.Errorf("неизвестное хранилище кэша %q (допустимо: none, memory, redis)", value)
+	}
+}
+
+// CacheOptions содержит параметры кэша ссылок
+type CacheOptions struct {
+	// Backend - хранилище кэша. Пустое значение соответствует CacheNone.
+	Backend CacheBackend
+
+	// Size - максимальное количество ссылок в LRU-кэше (только CacheMemory).
+	// 0 соответствует DefaultCacheSize.
+	Size int
+
+	// TTL - время жизни ссылки в кэше. 0 соответствует DefaultCacheTTL.
+	TTL time.Duration
+
+	// RedisAddr - адрес сервера RESP в формате host:port (только CacheRedis).
+	RedisAddr string
+}
+
+// newCache создает кэш ссылок по параметрам.
+// Возвращает nil, если кэш отключен.
+func newCache(opts CacheOptions) (Cache, error) {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	switch opts.Backend {
+	case "", CacheNone:
+		return nil, nil
+	case CacheMemory:
+		size := opts.Size
+		if size <= 0 {
+			size = DefaultCacheSize
+		}
+		return NewLRUCache(size, ttl), nil
+	case CacheRedis:
+		if opts.RedisAddr == "" {
+			return nil, fmt.Errorf("не указан адрес сервера кэша %s", opts.Backend)
+		}
+		return NewRESPCache(opts.RedisAddr, ttl), nil
+	default:
+		return nil, fmt.Errorf("неизвестное хранилище кэша %q", opts.Backend)
+	}
+}
+
+// Link описывает сохраненную ссылку вместе с ее состоянием
+type Link struct {
+	OriginalURL string    `json:"url"`                 // Оригинальный URL
+	ExpiresAt   time.Time `json:"expires_at,omitzero"` // Момент истечения срока действия (нулевое значение - бессрочно)
+	Deleted     bool      `json:"deleted,omitempty"`   // Ссылка удалена
+	Disabled    bool      `json:"disabled,omitempty"`  // Ссылка отключена модератором
+}
+
+// Resolve возвращает оригинальный URL с учетом состояния ссылки на момент now.
+// Возвращает ErrExpired для ссылки с истекшим сроком действия, ErrGone для удаленной
+// и ErrDisabled для отключенной модератором.
+func (l Link) Resolve(now time.Time) (string, error) {
+	// Истечение срока проверяется первым: такая ссылка могла быть
+	// помечена удаленной фоновой очисткой
+	if !l.ExpiresAt.IsZero() && !now.Before(l.ExpiresAt) {
+		return "", ErrExpired
+	}
+	if l.Deleted {
+		return "", ErrGone
+	}
+	if l.Disabled {
+		return "", ErrDisabled
+	}
+	return l.OriginalURL, nil
+}
+
+// LinkGetter описывает хранилище, возвращающее ссылку вместе с ее состоянием.
+// Реализуется MemoryStorage и DatabaseStorage.
+type LinkGetter interface {
+	// GetLink возвращает ссылку по идентификатору или ErrNotFound.
+	// Удаленная ссылка и ссылка с истекшим сроком действия не считаются ошибкой.
+	GetLink(ctx context.Context, id string) (Link, error)
+}
+
+// Cache - хранилище кэша ссылок для CachedStorage.
+//
+// Ошибка методов означает недоступность кэша: CachedStorage в этом случае
+// обращается напрямую к хранилищу. Время жизни записей задается при
+// создании кэша.
+type Cache interface {
+	// Get возвращает ссылку из кэша. Второе значение false означает промах.
+	Get(ctx context.Context, id string) (Link, bool, error)
+
+	// Set сохраняет ссылку в кэш
+	Set(ctx context.Context, id string, link Link) error
+
+	// Delete удаляет ссылки из кэша
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// CacheStats содержит счетчики обращений к кэшу ссылок
+type CacheStats struct {
+	Hits   int64 // Количество ссылок, найденных в кэше
+	Misses int64 // Количество обращений к хранилищу из-за промаха или недоступности кэша
+}
+
+// CachedStorage - декоратор хранилища, кэширующий ссылки для переходов.
+//
+// Get сначала ищет ссылку в кэше и только при промахе обращается к
+// хранилищу (read-through). Кэшируются найденные ссылки, включая удаленные;
+// отсутствующие ID не кэшируются, чтобы новая ссылка сразу становилась
+// доступной на всех экземплярах сервиса. Срок действия ссылки хранится
+// в кэше, поэтому истекшая ссылка не выдается до очистки Reaper.
+//
+// DeleteUserURLs и методы модерации, меняющие состояние ссылок
+// (SetLinksDisabled, PurgeLinks), удаляют ссылки из кэша после изменения
+// хранилища. Остальные методы передаются хранилищу встраиванием Storage:
+// перенос не изменяет существующие ссылки, а отсутствующие ID не кэшируются.
+// Кэшированная ссылка не содержит владельца, поэтому передача URL
+// другому пользователю (ReassignUserURLs, ReassignLinks) кэш не затрагивает.
+//
+// Get, прочитавший ссылку до изменения, не должен вернуть ее в кэш после
+// очистки: для этого очистка увеличивает поколение ссылки, и Get не
+// сохраняет ссылку, поколение которой изменилось во время чтения.
+// Поколения общие для групп ссылок (cacheGenerations), поэтому
+// изменение одной ссылки может лишь пропустить кэширование другой.
+type CachedStorage struct {
+	Storage
+	cache       Cache
+	hits        atomic.Int64
+	misses      atomic.Int64
+	seed        maphash.Seed
+	generations [cacheGenerations]atomic.Uint64
+}
+
+// cacheGenerations - количество поколений ссылок CachedStorage
+const cacheGenerations = 256
+
+// NewCachedStorage создает кэширующий декоратор хранилища
+func NewCachedStorage(store Storage, cache Cache) *CachedStorage {
+	return &CachedStorage{
+		Storage: store,
+		cache:   cache,
+		seed:    maphash.MakeSeed(),
+	}
+}
+
+// generation возвращает счетчик поколения ссылки id
+func (s *CachedStorage) generation(id string) *atomic.Uint64 {
+	return &s.generations[maphash.String(s.seed, id)%cacheGenerations]
+}
+
+// Get возвращает оригинальный URL по идентификатору, используя кэш
+func (s *CachedStorage) Get(ctx context.Context, id string) (string, error) {
+	link, ok, err := s.cache.Get(ctx, id)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Кэш ссылок недоступен", zap.String("id", id), zap.Error(err))
+	}
+	if ok {
+		s.hits.Add(1)
+		return link.Resolve(time.Now())
+	}
+	s.misses.Add(1)
+
+	generation := s.generation(id)
+	before := generation.Load()
+	link, err = s.Storage.GetLink(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	// Ссылка изменилась во время чтения: прочитанное состояние могло устареть
+	if generation.Load() != before {
+		return link.Resolve(time.Now())
+	}
+	if err := s.cache.Set(ctx, id, link); err != nil {
+		logger.Ctx(ctx).Warn("Ошибка сохранения ссылки в кэш", zap.String("id", id), zap.Error(err))
+	}
+	// Очистка могла выполниться между проверкой и Set
+	if generation.Load() != before {
+		s.invalidate(ctx, []string{id})
+	}
+
+	return link.Resolve(time.Now())
+}
+
+// DeleteUserURLs помечает URL как удаленные и удаляет их из кэша.
+// Кэш очищается и при ошибке хранилища: часть ссылок могла быть удалена.
+func (s *CachedStorage) DeleteUserURLs(ctx context.Context, userID string, shortURLs []string) error {
+	err := s.Storage.DeleteUserURLs(ctx, userID, shortURLs)
+	s.invalidate(ctx, shortURLs)
+	return err
+}
+
+// SetLinksDisabled отключает или включает ссылки и удаляет их из кэша
+func (s *CachedStorage) SetLinksDisabled(ctx context.Context, ids []string, disabled bool) (int, error) {
+	changed, err := s.Storage.SetLinksDisabled(ctx, ids, disabled)
+	s.invalidate(ctx, ids)
+	return changed, err
+}
+
+// PurgeLinks безвозвратно удаляет ссылки и удаляет их из кэша
+func (s *CachedStorage) PurgeLinks(ctx context.Context, ids []string) (int, error) {
+	purged, err := s.Storage.PurgeLinks(ctx, ids)
+	s.invalidate(ctx, ids)
+	return purged, err
+}
+
+// invalidate удаляет ссылки из кэша
+func (s *CachedStorage) invalidate(ctx context.Context, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	// Поколение увеличивается до удаления из кэша, чтобы Get, начавший
+	// чтение раньше, не сохранил устаревшую ссылку после удаления
+	for _, id := range ids {
+		s.generation(id).Add(1)
+	}
+	// Очистка кэша не должна зависеть от отмены запроса: иначе
+	// удаленная ссылка оставалась бы доступной до истечения TTL
+	if err := s.cache.Delete(context.WithoutCancel(ctx), ids...); err != nil {
+		logger.Ctx(ctx).Error("Ошибка удаления ссылок из кэша",
+			zap.Int("count", len(ids)), zap.Error(err))
+	}
+}
+
+// Compact запускает компактирование журнала хранилища
+func (s *CachedStorage) Compact() (CompactionResult, error) {
+	return compact(s.Storage)
+}
+
+// CacheStats возвращает счетчики обращений к кэшу
+func (s *CachedStorage) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   s.hits.Load(),
+		Misses: s.misses.Load(),
+	}
+}
+
+// Stats возвращает статистику хранилища вместе со счетчиками кэша
+func (s *CachedStorage) Stats(ctx context.Context) (Stats, error) {
+	stats, err := s.Storage.Stats(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	cacheStats := s.CacheStats()
+	stats.Cache = &cacheStats
+	return stats, nil
+}
+
+// Close закрывает хранилище и соединения кэша
+func (s *CachedStorage) Close() error {
+	err := s.Storage.Close()
+	if closer, ok := s.cache.(io.Closer); ok {
+		if cerr := closer.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
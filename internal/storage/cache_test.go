@@ -0,0 +1,373 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStorage считает обращения к хранилищу за ссылками
+type countingStorage struct {
+	*MemoryStorage
+	loads atomic.Int64
+}
+
+func (s *countingStorage) GetLink(ctx context.Context, id string) (Link, error) {
+	s.loads.Add(1)
+	return s.MemoryStorage.GetLink(ctx, id)
+}
+
+// hookStorage вызывает afterGetLink после чтения ссылки из хранилища
+type hookStorage struct {
+	*MemoryStorage
+	afterGetLink func()
+}
+
+func (s *hookStorage) GetLink(ctx context.Context, id string) (Link, error) {
+	link, err := s.MemoryStorage.GetLink(ctx, id)
+	if s.afterGetLink != nil {
+		s.afterGetLink()
+	}
+	return link, err
+}
+
+// hookCache вызывает beforeSet перед сохранением ссылки в кэш
+type hookCache struct {
+	Cache
+	beforeSet func()
+}
+
+func (c *hookCache) Set(ctx context.Context, id string, link Link) error {
+	if c.beforeSet != nil {
+		c.beforeSet()
+	}
+	return c.Cache.Set(ctx, id, link)
+}
+
+// fakeRESPServer - сервер RESP в памяти с командами GET, SET PX и DEL
+type fakeRESPServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	values   map[string]string
+	expires  map[string]time.Time
+	commands []string
+}
+
+func newFakeRESPServer(t *testing.T) *fakeRESPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &fakeRESPServer{
+		listener: listener,
+		values:   make(map[string]string),
+		expires:  make(map[string]time.Time),
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+func (s *fakeRESPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeRESPServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *fakeRESPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		request, err := readRESPReply(r)
+		if err != nil {
+			return
+		}
+		items, _ := request.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			data, _ := item.([]byte)
+			args[i] = string(data)
+		}
+		if _, err := conn.Write([]byte(s.exec(args))); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRESPServer) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(args) == 0 {
+		return "-ERR empty command\r\n"
+	}
+	s.commands = append(s.commands, strings.ToUpper(args[0]))
+
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		value, ok := s.values[args[1]]
+		if expires, set := s.expires[args[1]]; set && !time.Now().Before(expires) {
+			ok = false
+		}
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		if len(args) != 5 || strings.ToUpper(args[3]) != "PX" {
+			return "-ERR syntax error\r\n"
+		}
+		ms, err := strconv.Atoi(args[4])
+		if err != nil {
+			return "-ERR value is not an integer\r\n"
+		}
+		s.values[args[1]] = args[2]
+		s.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func TestLRUCache_EvictionAndTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewLRUCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	require.NoError(t, cache.Set(ctx, "a", Link{OriginalURL: "https://example.com/a"}))
+	require.NoError(t, cache.Set(ctx, "b", Link{OriginalURL: "https://example.com/b"}))
+
+	// Обращение к "a" делает вытесняемой ссылку "b"
+	_, ok, _ := cache.Get(ctx, "a")
+	require.True(t, ok)
+	require.NoError(t, cache.Set(ctx, "c", Link{OriginalURL: "https://example.com/c"}))
+
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok)
+	link, ok, _ := cache.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com/a", link.OriginalURL)
+	assert.Equal(t, 2, cache.Len())
+
+	// Устаревшая запись считается промахом и удаляется
+	now = now.Add(time.Minute)
+	_, ok, _ = cache.Get(ctx, "c")
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.Len())
+
+	require.NoError(t, cache.Delete(ctx, "a", "missing"))
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCachedStorage_ReadThrough(t *testing.T) {
+	caches := map[string]func(t *testing.T) Cache{
+		"memory": func(*testing.T) Cache { return NewLRUCache(100, time.Minute) },
+		"resp": func(t *testing.T) Cache {
+			return NewRESPCache(newFakeRESPServer(t).Addr(), time.Minute)
+		},
+	}
+
+	for name, newCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			inner := &countingStorage{MemoryStorage: NewMemoryStorage("")}
+			store := NewCachedStorage(inner, newCache(t))
+			defer store.Close()
+
+			_, err := store.Add(ctx, "a", "https://example.com/a", "alice", time.Time{})
+			require.NoError(t, err)
+			_, err = store.Add(ctx, "soon", "https://example.com/soon", "alice", time.Now().Add(200*time.Millisecond))
+			require.NoError(t, err)
+
+			// Отсутствующий ID не кэшируется
+			_, err = store.Get(ctx, "missing")
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, err = store.Get(ctx, "missing")
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.EqualValues(t, 2, inner.loads.Load())
+
+			// Повторный переход обслуживается из кэша
+			for range 3 {
+				url, err := store.Get(ctx, "a")
+				require.NoError(t, err)
+				assert.Equal(t, "https://example.com/a", url)
+			}
+			assert.EqualValues(t, 3, inner.loads.Load())
+
+			// Удаление очищает кэш, удаленная ссылка кэшируется как удаленная
+			require.NoError(t, store.DeleteUserURLs(ctx, "alice", []string{"a"}))
+			_, err = store.Get(ctx, "a")
+			assert.ErrorIs(t, err, ErrGone)
+			_, err = store.Get(ctx, "a")
+			assert.ErrorIs(t, err, ErrGone)
+			assert.EqualValues(t, 4, inner.loads.Load())
+
+			// Срок действия хранится в кэше: ссылка истекает без обращения к хранилищу
+			_, err = store.Get(ctx, "soon")
+			require.NoError(t, err)
+			require.Eventually(t, func() bool {
+				_, err := store.Get(ctx, "soon")
+				return err == ErrExpired
+			}, 2*time.Second, 10*time.Millisecond)
+			assert.EqualValues(t, 5, inner.loads.Load())
+
+			stats, err := store.Stats(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, stats.Cache)
+			assert.EqualValues(t, 5, stats.Cache.Misses)
+			assert.Equal(t, store.CacheStats().Hits, stats.Cache.Hits)
+			assert.GreaterOrEqual(t, stats.Cache.Hits, int64(4))
+		})
+	}
+}
+
+func TestCachedStorage_AdminInvalidation(t *testing.T) {
+	ctx := context.Background()
+	store := NewCachedStorage(NewMemoryStorage(""), NewLRUCache(100, time.Minute))
+	defer store.Close()
+
+	_, err := store.Add(ctx, "a", "https://example.com/a", "alice", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+
+	// Отключение сбрасывает кэш: переход сразу возвращает ErrDisabled
+	_, err = store.SetLinksDisabled(ctx, []string{"a"}, true)
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	_, err = store.SetLinksDisabled(ctx, []string{"a"}, false)
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+
+	// Удаленная модератором ссылка не остается в кэше
+	_, err = store.PurgeLinks(ctx, []string{"a"})
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCachedStorage_InvalidationDuringFill(t *testing.T) {
+	ctx := context.Background()
+	inner := &hookStorage{MemoryStorage: NewMemoryStorage("")}
+	cache := &hookCache{Cache: NewLRUCache(100, time.Minute)}
+	store := NewCachedStorage(inner, cache)
+	defer store.Close()
+
+	for _, id := range []string{"a", "b"} {
+		_, err := store.Add(ctx, id, "https://example.com/"+id, "alice", time.Time{})
+		require.NoError(t, err)
+	}
+
+	// Удаление между чтением хранилища и сохранением в кэш
+	inner.afterGetLink = func() {
+		inner.afterGetLink = nil
+		require.NoError(t, store.DeleteUserURLs(ctx, "alice", []string{"a"}))
+	}
+	url, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", url)
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrGone)
+
+	// Удаление между проверкой поколения и сохранением в кэш
+	cache.beforeSet = func() {
+		cache.beforeSet = nil
+		require.NoError(t, store.DeleteUserURLs(ctx, "alice", []string{"b"}))
+	}
+	url, err = store.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/b", url)
+	_, err = store.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrGone)
+}
+
+func TestCachedStorage_RESPCommands(t *testing.T) {
+	ctx := context.Background()
+	srv := newFakeRESPServer(t)
+	store := NewCachedStorage(NewMemoryStorage(""), NewRESPCache(srv.Addr(), time.Minute))
+	defer store.Close()
+
+	_, err := store.Add(ctx, "a", "https://example.com/a", "alice", time.Time{})
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteUserURLs(ctx, "alice", []string{"a", "b"}))
+
+	assert.Equal(t, []string{"GET", "SET", "GET", "DEL"}, srv.Commands())
+}
+
+func TestCachedStorage_CacheUnavailable(t *testing.T) {
+	ctx := context.Background()
+
+	// Адрес закрытого сервера: все обращения к кэшу завершаются ошибкой
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	store := NewCachedStorage(NewMemoryStorage(""), NewRESPCache(addr, time.Minute))
+	defer store.Close()
+
+	_, err = store.Add(ctx, "a", "https://example.com/a", "alice", time.Time{})
+	require.NoError(t, err)
+
+	url, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", url)
+	require.NoError(t, store.DeleteUserURLs(ctx, "alice", []string{"a"}))
+
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrGone)
+	assert.Equal(t, CacheStats{Misses: 2}, store.CacheStats())
+}
+
+func TestCachedStorage_ForwardsCapabilities(t *testing.T) {
+	store := NewCachedStorage(NewMemoryStorage(""), NewLRUCache(10, time.Minute))
+	defer store.Close()
+
+	var _ BatchAdder = store
+	var _ ClickStorage = store
+	var _ AccountStorage = store
+	var _ AdminStorage = store
+
+	_, err := store.Compact()
+	assert.ErrorIs(t, err, ErrCompactionUnsupported)
+}
@@ -0,0 +1,363 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"go.uber.org/zap"
+)
+
+// snapshotVersion - версия формата файла снимка
+const snapshotVersion = 1
+
+// ErrCompactionUnsupported возвращается, когда хранилище работает без файла
+// и компактировать нечего.
+var ErrCompactionUnsupported = errors.New("компактирование доступно только для файлового хранилища")
+
+// Compactor описывает хранилище, поддерживающее компактирование журнала
+type Compactor interface {
+	Compact() (CompactionResult, error)
+}
+
+// CompactionResult содержит результат компактирования журнала
+type CompactionResult struct {
+	SnapshotRecords int           // Количество записей в снимке
+	TailRecords     int           // Количество записей, оставшихся в журнале
+	LastSeq         int           // Номер последней записи, вошедшей в снимок
+	Duration        time.Duration // Длительность компактирования
+}
+
+// snapshotHeader - первая строка файла снимка
+type snapshotHeader struct {
+	Version   int       `json:"version"`    // Версия формата снимка
+	LastSeq   int       `json:"last_seq"`   // Номер последней записи журнала, вошедшей в снимок
+	CreatedAt time.Time `json:"created_at"` // Момент создания снимка
+}
+
+// snapshotPath возвращает путь к файлу снимка
+func (s *MemoryStorage) snapshotPath() string {
+	return s.storagePath + ".snapshot"
+}
+
+// Compact записывает снимок текущего состояния и удаляет из журнала
+// записи, вошедшие в снимок.
+//
+// Снимок записывается во временный файл и атомарно переименовывается,
+// поэтому сбой на любом шаге оставляет на диске согласованную пару
+// снимок + журнал. При восстановлении из журнала воспроизводятся только
+// записи с номером больше LastSeq снимка.
+func (s *MemoryStorage) Compact() (CompactionResult, error) {
+	if !s.fileMode {
+		return CompactionResult{}, ErrCompactionUnsupported
+	}
+
+	s.compactMu.Lock()
+	defer s.compactMu.Unlock()
+
+	started := time.Now()
+
+	// Снимаем состояние под блокировкой чтения: все записи с номером
+	// не больше lastSeq уже отражены в памяти
+	s.mu.RLock()
+	records, lastSeq := s.snapshotRecordsLocked()
+	s.mu.RUnlock()
+
+	if err := s.writeSnapshot(records, lastSeq); err != nil {
+		return CompactionResult{}, err
+	}
+
+	// Переписываем журнал, блокируя запись новых пакетов
+	s.batchMu.Lock()
+	tail, err := s.truncateLog(lastSeq)
+	s.batchMu.Unlock()
+	if err != nil {
+		return CompactionResult{}, err
+	}
+
+	s.logRecords.Store(int64(tail))
+
+	result := CompactionResult{
+		SnapshotRecords: len(records),
+		TailRecords:     tail,
+		LastSeq:         lastSeq,
+		Duration:        time.Since(started),
+	}
+
+	logger.Logger.Info("Журнал хранилища компактирован",
+		zap.Int("snapshot_records", result.SnapshotRecords),
+		zap.Int("tail_records", result.TailRecords),
+		zap.Int("last_seq", result.LastSeq),
+		zap.Duration("duration", result.Duration))
+
+	return result, nil
+}
+
+// snapshotRecordsLocked формирует записи снимка, вызывающий должен удерживать мьютекс.
+// URL пользователей записываются в порядке добавления, чтобы после
+// восстановления GetUserURLs возвращал их в том же порядке.
+func (s *MemoryStorage) snapshotRecordsLocked() ([]models.URLRecord, int) {
+	records := make([]models.URLRecord, 0, len(s.urls)+len(s.deletedURLs)+len(s.disabledURLs))
+	written := make(map[string]bool, len(s.urls))
+
+	addRecord := func(shortURL, userID string) {
+		if written[shortURL] {
+			return
+		}
+		originalURL, ok := s.urls[shortURL]
+		if !ok {
+			return
+		}
+		written[shortURL] = true
+
+		record := models.URLRecord{
+			UUID:        strconv.Itoa(len(records) + 1),
+			ShortURL:    shortURL,
+			OriginalURL: originalURL,
+			UserID:      userID,
+		}
+		if expiresAt, ok := s.expiresAt[shortURL]; ok {
+			record.ExpiresAt = &expiresAt
+		}
+		if createdAt, ok := s.createdAt[shortURL]; ok {
+			record.CreatedAt = &createdAt
+		}
+		records = append(records, record)
+	}
+
+	for userID, shortURLs := range s.userURLs {
+		for _, shortURL := range shortURLs {
+			addRecord(shortURL, userID)
+		}
+	}
+	for shortURL := range s.urls {
+		addRecord(shortURL, "")
+	}
+
+	for shortURL := range s.deletedURLs {
+		records = append(records, models.URLRecord{
+			UUID:     strconv.Itoa(len(records) + 1),
+			ShortURL: shortURL,
+			Event:    models.URLRecordEventDelete,
+		})
+	}
+	for shortURL := range s.disabledURLs {
+		records = append(records, models.URLRecord{
+			UUID:     strconv.Itoa(len(records) + 1),
+			ShortURL: shortURL,
+			Event:    models.URLRecordEventDisable,
+		})
+	}
+
+	if s.idCounter > 0 {
+		records = append(records, models.URLRecord{
+			UUID:    strconv.Itoa(len(records) + 1),
+			Event:   models.URLRecordEventCounter,
+			Counter: s.idCounter,
+		})
+	}
+
+	return records, s.nextID - 1
+}
+
+// writeSnapshot атомарно записывает файл снимка
+func (s *MemoryStorage) writeSnapshot(records []models.URLRecord, lastSeq int) error {
+	header := snapshotHeader{
+		Version:   snapshotVersion,
+		LastSeq:   lastSeq,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	return writeFileAtomic(s.snapshotPath(), func(writer *bufio.Writer) error {
+		encoder := json.NewEncoder(writer)
+		if err := encoder.Encode(header); err != nil {
+			return fmt.Errorf("ошибка записи заголовка снимка: %w", err)
+		}
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return fmt.Errorf("ошибка записи снимка: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// truncateLog оставляет в журнале только записи с номером больше lastSeq.
+// Вызывающий должен удерживать batchMu. Возвращает количество оставшихся записей.
+func (s *MemoryStorage) truncateLog(lastSeq int) (int, error) {
+	file, err := os.Open(s.storagePath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка открытия журнала: %w", err)
+	}
+	defer file.Close()
+
+	var tail [][]byte
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var record models.URLRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			continue
+		}
+		if parseID(record.UUID) > lastSeq {
+			tail = append(tail, append([]byte(nil), line...))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка чтения журнала: %w", err)
+	}
+
+	err = writeFileAtomic(s.storagePath, func(writer *bufio.Writer) error {
+		for _, line := range tail {
+			if _, err := writer.Write(line); err != nil {
+				return err
+			}
+			if err := writer.WriteByte('\n'); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ошибка перезаписи журнала: %w", err)
+	}
+
+	return len(tail), nil
+}
+
+// loadSnapshot загружает снимок в память.
+// Возвращает номер последней записи журнала, вошедшей в снимок,
+// или 0, если снимка нет.
+func (s *MemoryStorage) loadSnapshot() (int, error) {
+	file, err := os.Open(s.snapshotPath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка открытия снимка: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return 0, fmt.Errorf("ошибка чтения снимка: %w", err)
+		}
+		return 0, fmt.Errorf("снимок %s пуст", s.snapshotPath())
+	}
+
+	var header snapshotHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return 0, fmt.Errorf("ошибка декодирования заголовка снимка: %w", err)
+	}
+	if header.Version != snapshotVersion {
+		return 0, fmt.Errorf("неподдерживаемая версия снимка: %d", header.Version)
+	}
+
+	count := 0
+	for scanner.Scan() {
+		var record models.URLRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return 0, fmt.Errorf("ошибка декодирования записи снимка: %w", err)
+		}
+		s.applyRecord(record)
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка чтения снимка: %w", err)
+	}
+
+	logger.Logger.Info("Загружен снимок хранилища",
+		zap.Int("records", count),
+		zap.Int("last_seq", header.LastSeq),
+		zap.Time("created_at", header.CreatedAt))
+
+	return header.LastSeq, nil
+}
+
+// maybeCompact запускает фоновое компактирование, если журнал
+// превысил заданный порог и компактирование еще не выполняется
+func (s *MemoryStorage) maybeCompact() {
+	if s.compactionThreshold <= 0 || s.logRecords.Load() < int64(s.compactionThreshold) {
+		return
+	}
+	if !s.compacting.CompareAndSwap(false, true) {
+		return
+	}
+
+	s.compactWG.Add(1)
+	go func() {
+		defer s.compactWG.Done()
+		defer s.compacting.Store(false)
+
+		if _, err := s.Compact(); err != nil {
+			logger.Logger.Error("Ошибка автоматического компактирования журнала", zap.Error(err))
+		}
+	}()
+}
+
+// writeFileAtomic записывает файл через временный файл, fsync и переименование
+func writeFileAtomic(path string, write func(writer *bufio.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("ошибка создания директории: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("ошибка создания временного файла: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // после успешного переименования файла уже нет
+
+	writer := bufio.NewWriter(tmp)
+	if err := write(writer); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка сброса буфера: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка синхронизации файла: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка закрытия файла: %w", err)
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		return fmt.Errorf("ошибка установки прав файла: %w", err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("ошибка переименования файла: %w", err)
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir сбрасывает на диск запись каталога, чтобы переименование пережило сбой питания
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия директории: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("ошибка синхронизации директории: %w", err)
+	}
+	return nil
+}
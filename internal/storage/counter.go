@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// ErrCounterUnsupported возвращается, когда хранилище не хранит счетчик генератора ID
+var ErrCounterUnsupported = errors.New("хранилище не поддерживает счетчик генератора ID")
+
+// CounterStorage хранит границу номеров, выданных генераторам ID counter
+// и sqids (см. shortener.CounterReserver). Реализуется MemoryStorage
+// и DatabaseStorage.
+//
+// Граница только растет и не зависит от количества ссылок, поэтому
+// после удаления ссылок и перезапуска номера не выдаются повторно.
+type CounterStorage interface {
+	// ReserveCounter резервирует count номеров и возвращает первый из них.
+	// При count = 0 возвращает текущую границу, ничего не резервируя.
+	ReserveCounter(ctx context.Context, count uint64) (uint64, error)
+}
+
+// ReserveCounter резервирует count номеров генератора ID и возвращает первый.
+//
+// В файловом режиме новая граница записывается в журнал раньше ссылок
+// с выданными номерами; в режиме DurabilitySync метод возвращает
+// управление после записи на диск.
+func (s *MemoryStorage) ReserveCounter(ctx context.Context, count uint64) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrStorageClosed
+	}
+
+	// Журналы, записанные до появления событий counter, границу не хранят:
+	// ее оценивает количество записей журнала
+	if s.idCounter == 0 {
+		s.idCounter = uint64(s.nextID - 1)
+	}
+
+	start := s.idCounter
+	s.idCounter += count
+
+	var pending []<-chan error
+	if count > 0 {
+		pending = s.journalLocked(pending, models.URLRecord{
+			Event:   models.URLRecordEventCounter,
+			Counter: s.idCounter,
+		})
+	}
+	s.mu.Unlock()
+
+	if err := waitAllPersisted(pending); err != nil {
+		return 0, err
+	}
+	return start, nil
+}
+
+// ReserveCounter резервирует count номеров генератора ID и возвращает первый
+func (s *DatabaseStorage) ReserveCounter(ctx context.Context, count uint64) (_ uint64, err error) {
+	ctx, span := startQuery(ctx, "ReserveCounter")
+	defer func() { endQuery(span, err) }()
+
+	// Строка создается миграцией; INSERT восстанавливает ее, если она удалена
+	var end int64
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO id_counter (value) VALUES ($1)
+		ON CONFLICT (id) DO UPDATE SET value = id_counter.value + EXCLUDED.value
+		RETURNING value
+	`, int64(count)).Scan(&end)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(end) - count, nil
+}
+
+// counterStorage возвращает хранилище счетчика или ErrCounterUnsupported
+func counterStorage(store URLStorageV2) (CounterStorage, error) {
+	counters, ok := store.(CounterStorage)
+	if !ok {
+		return nil, ErrCounterUnsupported
+	}
+	return counters, nil
+}
+
+// raiseCounter поднимает границу счетчика хранилища to до границы from,
+// чтобы перенесенные ссылки не совпали с номерами, выдаваемыми после
+// переноса. Хранилища без счетчика пропускаются.
+func raiseCounter(ctx context.Context, from, to URLStorageV2) error {
+	source, err := counterStorage(from)
+	if err != nil {
+		return nil
+	}
+	target, err := counterStorage(to)
+	if err != nil {
+		return nil
+	}
+
+	want, err := source.ReserveCounter(ctx, 0)
+	if errors.Is(err, ErrCounterUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	have, err := target.ReserveCounter(ctx, 0)
+	if errors.Is(err, ErrCounterUnsupported) {
+		return nil
+	}
+	if err != nil || have >= want {
+		return err
+	}
+	_, err = target.ReserveCounter(ctx, want-have)
+	return err
+}
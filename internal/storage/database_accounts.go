@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CreateAccount создает учетную запись
+func (s *DatabaseStorage) CreateAccount(ctx context.Context, account Account) (err error) {
+	ctx, span := startQuery(ctx, "CreateAccount")
+	defer func() { endQuery(span, err) }()
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO users (id, login, password_hash, role, created_at)
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'user'), $5)
+	`, account.ID, account.Login, account.PasswordHash, account.Role, account.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return fmt.Errorf("%w: логин %q занят", ErrConflict, account.Login)
+	}
+	return err
+}
+
+// GetAccount возвращает учетную запись по ID
+func (s *DatabaseStorage) GetAccount(ctx context.Context, id string) (_ Account, err error) {
+	ctx, span := startQuery(ctx, "GetAccount")
+	defer func() { endQuery(span, err) }()
+
+	return s.queryAccount(ctx, `
+		SELECT id, login, password_hash, role, created_at FROM users WHERE id = $1
+	`, id)
+}
+
+// GetAccountByLogin возвращает учетную запись по логину
+func (s *DatabaseStorage) GetAccountByLogin(ctx context.Context, login string) (_ Account, err error) {
+	ctx, span := startQuery(ctx, "GetAccountByLogin")
+	defer func() { endQuery(span, err) }()
+
+	return s.queryAccount(ctx, `
+		SELECT id, login, password_hash, role, created_at FROM users WHERE login = $1
+	`, login)
+}
+
+// queryAccount выполняет запрос одной учетной записи
+func (s *DatabaseStorage) queryAccount(ctx context.Context, query string, arg string) (Account, error) {
+	var account Account
+	err := s.db.QueryRowContext(ctx, query, arg).
+		Scan(&account.ID, &account.Login, &account.PasswordHash, &account.Role, &account.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Account{}, ErrNotFound
+	}
+	return account, err
+}
+
+// SetAccountRole меняет роль учетной записи
+func (s *DatabaseStorage) SetAccountRole(ctx context.Context, id string, role string) (err error) {
+	ctx, span := startQuery(ctx, "SetAccountRole")
+	defer func() { endQuery(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE users SET role = $2 WHERE id = $1`, id, role)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateCredential сохраняет учетные данные
+func (s *DatabaseStorage) CreateCredential(ctx context.Context, credential Credential) (err error) {
+	ctx, span := startQuery(ctx, "CreateCredential")
+	defer func() { endQuery(span, err) }()
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO credentials (id, user_id, kind, name, token_hash, prefix, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, credential.ID, credential.UserID, string(credential.Kind), credential.Name,
+		credential.TokenHash, credential.Prefix, credential.CreatedAt, nullTime(credential.ExpiresAt))
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return fmt.Errorf("%w: токен уже выдан", ErrConflict)
+	}
+	return err
+}
+
+// credentialColumns - столбцы учетных данных в порядке scanCredential
+const credentialColumns = `id, user_id, kind, name, token_hash, prefix, created_at, expires_at, revoked_at`
+
+// GetCredential возвращает учетные данные по хешу токена
+func (s *DatabaseStorage) GetCredential(ctx context.Context, tokenHash string) (_ Credential, err error) {
+	ctx, span := startQuery(ctx, "GetCredential")
+	defer func() { endQuery(span, err) }()
+
+	credential, err := scanCredential(s.db.QueryRowContext(ctx, `
+		SELECT `+credentialColumns+` FROM credentials WHERE token_hash = $1
+	`, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Credential{}, ErrNotFound
+	}
+	return credential, err
+}
+
+// ListCredentials возвращает учетные данные пользователя заданного типа
+func (s *DatabaseStorage) ListCredentials(ctx context.Context, userID string, kind CredentialKind) (_ []Credential, err error) {
+	ctx, span := startQuery(ctx, "ListCredentials")
+	defer func() { endQuery(span, err) }()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+credentialColumns+` FROM credentials
+		WHERE user_id = $1 AND kind = $2
+		ORDER BY created_at, id
+	`, userID, string(kind))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []Credential{}
+	for rows.Next() {
+		credential, err := scanCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, credential)
+	}
+	return result, rows.Err()
+}
+
+// RevokeCredential отзывает учетные данные пользователя
+func (s *DatabaseStorage) RevokeCredential(ctx context.Context, userID string, id string, at time.Time) (err error) {
+	ctx, span := startQuery(ctx, "RevokeCredential")
+	defer func() { endQuery(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE credentials SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE id = $1 AND user_id = $2
+	`, id, userID, at)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReassignUserURLs передает все URL пользователя fromUserID пользователю toUserID
+// одним запросом. Ссылки, дублирующие URL из группы дедупликации нового
+// владельца, исключаются из дедупликации (dedup_owner = NULL).
+func (s *DatabaseStorage) ReassignUserURLs(ctx context.Context, fromUserID string, toUserID string) (_ int, err error) {
+	if fromUserID == "" || toUserID == "" || fromUserID == toUserID {
+		return 0, nil
+	}
+
+	ctx, span := startQuery(ctx, "ReassignUserURLs")
+	defer func() { endQuery(span, err) }()
+
+	newOwner := s.dedupOwner(toUserID)
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE urls AS u SET
+			user_id = $2,
+			dedup_owner = CASE
+				WHEN u.dedup_owner IS DISTINCT FROM $1 THEN u.dedup_owner
+				WHEN EXISTS (
+					SELECT 1 FROM urls AS t
+					WHERE t.original_url = u.original_url AND t.dedup_owner = $3
+				) THEN NULL
+				ELSE $3
+			END
+		WHERE u.user_id = $1
+	`, fromUserID, toUserID, newOwner)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// rowScanner - общий интерфейс sql.Row и sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanCredential читает учетные данные из строки результата
+func scanCredential(row rowScanner) (Credential, error) {
+	var credential Credential
+	var kind string
+	var expiresAt, revokedAt sql.NullTime
+	err := row.Scan(&credential.ID, &credential.UserID, &kind, &credential.Name,
+		&credential.TokenHash, &credential.Prefix, &credential.CreatedAt, &expiresAt, &revokedAt)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	credential.Kind = CredentialKind(kind)
+	credential.ExpiresAt = expiresAt.Time
+	credential.RevokedAt = revokedAt.Time
+	return credential, nil
+}
+
+// nullTime преобразует нулевое время в NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// linkHostSQL извлекает хост оригинального URL в нижнем регистре
+// (адрес IPv6 - без квадратных скобок), как linkHost
+const linkHostSQL = `btrim(lower(substring(original_url from '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/?#]*@)?(\[[^]]*\]|[^:/?#]*)')), '[].')`
+
+// sqlConditions формирует условие WHERE для фильтра и его параметры
+func (f LinkFilter) sqlConditions() (string, []any) {
+	conditions := []string{"true"}
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if f.ShortID != "" {
+		conditions = append(conditions, "short_id = "+arg(f.ShortID))
+	}
+	if f.UserID != "" {
+		conditions = append(conditions, "user_id = "+arg(f.UserID))
+	}
+	if f.URLContains != "" {
+		conditions = append(conditions, "strpos(lower(original_url), lower("+arg(f.URLContains)+")) > 0")
+	}
+	if f.Host != "" {
+		host := arg(f.Host)
+		conditions = append(conditions, fmt.Sprintf("(%[1]s = %[2]s OR right(%[1]s, length(%[2]s) + 1) = '.' || %[2]s)", linkHostSQL, host))
+	}
+	if !f.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(f.CreatedFrom))
+	}
+	if !f.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(f.CreatedTo))
+	}
+
+	switch f.Status {
+	case LinkStatusActive:
+		conditions = append(conditions, "COALESCE(is_deleted, false) = false AND is_disabled = false")
+	case LinkStatusDisabled:
+		conditions = append(conditions, "is_disabled = true")
+	case LinkStatusDeleted:
+		conditions = append(conditions, "COALESCE(is_deleted, false) = true")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// SearchLinks ищет ссылки по фильтру: количество ссылок и страница
+// определяются двумя запросами
+func (s *DatabaseStorage) SearchLinks(ctx context.Context, filter LinkFilter, offset int, limit int) (_ LinkPage, err error) {
+	ctx, span := startQuery(ctx, "SearchLinks")
+	defer func() { endQuery(span, err) }()
+
+	where, args := filter.sqlConditions()
+
+	page := LinkPage{Links: []LinkInfo{}}
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM urls WHERE `+where, args...).Scan(&page.Total)
+	if err != nil {
+		return LinkPage{}, err
+	}
+
+	n := len(args)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT short_id, original_url, COALESCE(user_id, ''), created_at, expires_at,
+			COALESCE(is_deleted, false), is_disabled
+		FROM urls
+		WHERE `+where+`
+		ORDER BY created_at DESC NULLS LAST, short_id
+		LIMIT $`+strconv.Itoa(n+1)+` OFFSET $`+strconv.Itoa(n+2),
+		append(args, limit, offset)...)
+	if err != nil {
+		return LinkPage{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link LinkInfo
+		var createdAt, expiresAt sql.NullTime
+		err := rows.Scan(&link.ShortID, &link.OriginalURL, &link.UserID,
+			&createdAt, &expiresAt, &link.Deleted, &link.Disabled)
+		if err != nil {
+			return LinkPage{}, err
+		}
+		link.CreatedAt = createdAt.Time
+		link.ExpiresAt = expiresAt.Time
+		page.Links = append(page.Links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return LinkPage{}, err
+	}
+
+	return page, nil
+}
+
+// SetLinksDisabled отключает или включает ссылки одним запросом
+func (s *DatabaseStorage) SetLinksDisabled(ctx context.Context, ids []string, disabled bool) (_ int, err error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	ctx, span := startQuery(ctx, "SetLinksDisabled")
+	defer func() { endQuery(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE urls SET is_disabled = $2
+		WHERE short_id = ANY($1) AND is_disabled <> $2
+	`, ids, disabled)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// PurgeLinks удаляет ссылки и события перехода по ним в одной транзакции
+func (s *DatabaseStorage) PurgeLinks(ctx context.Context, ids []string) (_ int, err error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	ctx, span := startQuery(ctx, "PurgeLinks")
+	defer func() { endQuery(span, err) }()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM clicks WHERE short_id = ANY($1)`, ids); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM urls WHERE short_id = ANY($1)`, ids)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// ReassignLinks передает ссылки пользователю userID одним запросом.
+// Ссылка переходит в группу дедупликации нового владельца, только если
+// она входила в группу прежнего владельца (область DedupPerUser); ссылка,
+// дублирующая URL из группы нового владельца, исключается из дедупликации
+// (dedup_owner = NULL).
+func (s *DatabaseStorage) ReassignLinks(ctx context.Context, ids []string, userID string) (_ int, err error) {
+	if len(ids) == 0 || userID == "" {
+		return 0, nil
+	}
+
+	ctx, span := startQuery(ctx, "ReassignLinks")
+	defer func() { endQuery(span, err) }()
+
+	newOwner := s.dedupOwner(userID)
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE urls AS u SET
+			user_id = $2,
+			dedup_owner = CASE
+				WHEN u.dedup_owner IS DISTINCT FROM COALESCE(u.user_id, '')
+					OR u.dedup_owner IS NOT DISTINCT FROM $3 THEN u.dedup_owner
+				WHEN EXISTS (
+					SELECT 1 FROM urls AS t
+					WHERE t.original_url = u.original_url AND t.dedup_owner = $3
+				) THEN NULL
+				ELSE $3
+			END
+		WHERE u.short_id = ANY($1) AND u.user_id IS DISTINCT FROM $2
+	`, ids, userID, newOwner)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// RecordClicks сохраняет пакет событий перехода одним запросом
+func (s *DatabaseStorage) RecordClicks(clicks []models.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	shortIDs := make([]string, len(clicks))
+	clickedAt := make([]time.Time, len(clicks))
+	referrers := make([]string, len(clicks))
+	userAgents := make([]string, len(clicks))
+	for i, click := range clicks {
+		shortIDs[i] = click.ShortID
+		clickedAt[i] = click.ClickedAt
+		referrers[i] = click.Referrer
+		userAgents[i] = click.UserAgentFamily
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO clicks (short_id, clicked_at, referrer, user_agent_family)
+		SELECT * FROM unnest($1::varchar[], $2::timestamptz[], $3::text[], $4::varchar[])
+	`, shortIDs, clickedAt, referrers, userAgents)
+
+	return err
+}
+
+// GetClickStats возвращает статистику переходов по ссылке пользователя.
+// Почасовая история включает только часы начиная с since.
+// Возвращает false, если ссылка не найдена или не принадлежит пользователю.
+func (s *DatabaseStorage) GetClickStats(userID string, shortURL string, since time.Time) (models.URLStats, bool, error) {
+	var owned bool
+	err := s.db.QueryRow(`
+		SELECT true
+		FROM urls
+		WHERE short_id = $1 AND user_id = $2 AND COALESCE(is_deleted, false) = false
+	`, shortURL, userID).Scan(&owned)
+	if err == sql.ErrNoRows {
+		return models.URLStats{}, false, nil
+	}
+	if err != nil {
+		return models.URLStats{}, false, err
+	}
+
+	stats := models.URLStats{}
+
+	var first, last sql.NullTime
+	err = s.db.QueryRow(`
+		SELECT COUNT(*), MIN(clicked_at), MAX(clicked_at)
+		FROM clicks
+		WHERE short_id = $1
+	`, shortURL).Scan(&stats.Clicks, &first, &last)
+	if err != nil {
+		return models.URLStats{}, false, err
+	}
+	if first.Valid {
+		stats.FirstClickAt = &first.Time
+		stats.LastClickAt = &last.Time
+	}
+
+	stats.Referrers, err = s.queryCounters(`
+		SELECT referrer, COUNT(*) AS clicks
+		FROM clicks
+		WHERE short_id = $1 AND referrer <> ''
+		GROUP BY referrer
+		ORDER BY clicks DESC, referrer
+		LIMIT $2
+	`, shortURL, maxStatsEntries)
+	if err != nil {
+		return models.URLStats{}, false, err
+	}
+
+	stats.UserAgents, err = s.queryCounters(`
+		SELECT user_agent_family, COUNT(*) AS clicks
+		FROM clicks
+		WHERE short_id = $1
+		GROUP BY user_agent_family
+		ORDER BY clicks DESC, user_agent_family
+		LIMIT $2
+	`, shortURL, maxStatsEntries)
+	if err != nil {
+		return models.URLStats{}, false, err
+	}
+
+	stats.Hourly, err = s.queryHourly(shortURL, since.UTC().Truncate(time.Hour))
+	if err != nil {
+		return models.URLStats{}, false, err
+	}
+
+	return stats, true, nil
+}
+
+// queryCounters выполняет запрос, возвращающий пары значение-количество
+func (s *DatabaseStorage) queryCounters(query string, args ...interface{}) ([]models.StatsCounter, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.StatsCounter{}
+	for rows.Next() {
+		var counter models.StatsCounter
+		if err := rows.Scan(&counter.Value, &counter.Clicks); err != nil {
+			return nil, err
+		}
+		result = append(result, counter)
+	}
+
+	return result, rows.Err()
+}
+
+// queryHourly возвращает количество переходов по часам начиная с since
+func (s *DatabaseStorage) queryHourly(shortURL string, since time.Time) ([]models.HourlyClicks, error) {
+	rows, err := s.db.Query(`
+		SELECT date_trunc('hour', clicked_at AT TIME ZONE 'UTC') AS hour, COUNT(*)
+		FROM clicks
+		WHERE short_id = $1 AND clicked_at >= $2
+		GROUP BY hour
+		ORDER BY hour
+	`, shortURL, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.HourlyClicks{}
+	for rows.Next() {
+		var item models.HourlyClicks
+		if err := rows.Scan(&item.Hour, &item.Clicks); err != nil {
+			return nil, err
+		}
+		item.Hour = time.Date(item.Hour.Year(), item.Hour.Month(), item.Hour.Day(),
+			item.Hour.Hour(), 0, 0, 0, time.UTC)
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/tracing"
+)
+
+// ScanRecords передает в fn записи ссылок, читая их страницами по limit
+// в порядке short_id (по индексу коротких ID)
+func (s *DatabaseStorage) ScanRecords(ctx context.Context, after string, limit int, fn func([]models.URLRecord) error) error {
+	for {
+		links, err := s.scanLinksPage(ctx, after, limit)
+		if err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+
+		records := make([]models.URLRecord, 0, len(links))
+		for _, link := range links {
+			records = append(records, linkRecords(link)...)
+		}
+		if err := fn(records); err != nil {
+			return err
+		}
+		after = links[len(links)-1].ShortID
+	}
+}
+
+// scanLinksPage возвращает не более limit ссылок с короткими ID больше after
+func (s *DatabaseStorage) scanLinksPage(ctx context.Context, after string, limit int) (_ []LinkInfo, err error) {
+	ctx, span := startQuery(ctx, "ScanRecords")
+	defer func() { endQuery(span, err) }()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT short_id, original_url, COALESCE(user_id, ''), created_at, expires_at,
+			COALESCE(is_deleted, false), is_disabled
+		FROM urls
+		WHERE short_id > $1
+		ORDER BY short_id
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]LinkInfo, 0, limit)
+	for rows.Next() {
+		var link LinkInfo
+		var createdAt, expiresAt sql.NullTime
+		err := rows.Scan(&link.ShortID, &link.OriginalURL, &link.UserID,
+			&createdAt, &expiresAt, &link.Deleted, &link.Disabled)
+		if err != nil {
+			return nil, err
+		}
+		link.CreatedAt = createdAt.Time
+		link.ExpiresAt = expiresAt.Time
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// RestoreRecords сохраняет перенесенные ссылки в одной транзакции.
+//
+// Ссылка вставляется с INSERT ... ON CONFLICT (short_id) DO NOTHING; если
+// оригинальный URL уже сокращен в группе дедупликации, dedup_owner ссылки
+// равен NULL. Для невставленной ссылки результат определяется по URL,
+// сохраненному с тем же коротким ID. При сбое транзакция откатывается целиком.
+func (s *DatabaseStorage) RestoreRecords(ctx context.Context, records []models.URLRecord) (results []RecordResult, err error) {
+	ctx, span := startQuery(ctx, "RestoreRecords")
+	defer func() { endQuery(span, err) }()
+
+	links, err := recordLinks(records)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(tracing.Int("batch.size", len(links)))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	results = make([]RecordResult, len(links))
+	for i, link := range links {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO urls (short_id, original_url, user_id, created_at, expires_at,
+				is_deleted, is_disabled, dedup_owner)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, CASE
+				WHEN EXISTS (SELECT 1 FROM urls WHERE original_url = $2 AND dedup_owner = $8) THEN NULL
+				ELSE $8 END)
+			ON CONFLICT (short_id) DO NOTHING
+		`, link.ShortID, link.OriginalURL, link.UserID,
+			sql.NullTime{Time: link.CreatedAt, Valid: !link.CreatedAt.IsZero()},
+			sql.NullTime{Time: link.ExpiresAt, Valid: !link.ExpiresAt.IsZero()},
+			link.Deleted, link.Disabled, s.dedupOwner(link.UserID))
+		if err != nil {
+			return nil, err
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if inserted > 0 {
+			results[i] = RecordResult{ID: link.ShortID, Status: RecordCreated}
+			continue
+		}
+
+		var originalURL string
+		err = tx.QueryRowContext(ctx, `SELECT original_url FROM urls WHERE short_id = $1`, link.ShortID).Scan(&originalURL)
+		if err != nil {
+			return nil, err
+		}
+		if originalURL == link.OriginalURL {
+			results[i] = RecordResult{ID: link.ShortID, Status: RecordExisting}
+		} else {
+			results[i] = RecordResult{Status: RecordFailed, Err: shortIDConflict()}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
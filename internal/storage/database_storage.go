@@ -1,140 +1,307 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Adigezalov/shortener/internal/database"
 	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/Adigezalov/shortener/internal/tracing"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // DatabaseStorage реализует хранилище URL в PostgreSQL
 type DatabaseStorage struct {
-	db *database.DB
+	db    *database.DB
+	dedup DedupScope // область дедупликации оригинальных URL
 }
 
 // NewDatabaseStorage создает новое хранилище URL в PostgreSQL
-func NewDatabaseStorage(db *database.DB) *DatabaseStorage {
+// с заданной областью дедупликации (пустое значение - DedupGlobal)
+func NewDatabaseStorage(db *database.DB, dedup DedupScope) *DatabaseStorage {
 	return &DatabaseStorage{
-		db: db,
+		db:    db,
+		dedup: dedup,
 	}
 }
 
-// Add добавляет новый URL в хранилище
-func (s *DatabaseStorage) Add(id string, url string) (string, bool, error) {
-	// Проверяем, существует ли уже такой URL
-	if existingID, exists := s.FindByOriginalURL(url); exists {
-		return existingID, true, database.ErrURLConflict
+// dedupOwner возвращает значение столбца dedup_owner для ссылки пользователя.
+// NULL отключает дедупликацию: уникальный индекс не сравнивает NULL.
+func (s *DatabaseStorage) dedupOwner(userID string) sql.NullString {
+	owner, ok := s.dedup.owner(userID)
+	return sql.NullString{String: owner, Valid: ok}
+}
+
+// Add добавляет новый URL с привязкой к пользователю и сроком действия.
+// Нулевое значение expiresAt сохраняется как NULL (бессрочная ссылка).
+func (s *DatabaseStorage) Add(ctx context.Context, id string, url string, userID string, expiresAt time.Time) (_ string, err error) {
+	ctx, span := startQuery(ctx, "Add")
+	defer func() { endQuery(span, err) }()
+
+	// Проверяем, существует ли уже такой URL в области дедупликации
+	existingID, err := s.FindByOriginalURL(ctx, url, userID)
+	if err == nil {
+		return existingID, urlConflict()
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return "", err
 	}
 
-	// Добавляем новый URL
-	_, err := s.db.Exec(`
-		INSERT INTO urls (short_id, original_url)
-		VALUES ($1, $2)
-	`, id, url)
+	// Добавляем новый URL с привязкой к пользователю
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO urls (short_id, original_url, user_id, expires_at, dedup_owner)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, url, userID, sql.NullTime{Time: expiresAt, Valid: !expiresAt.IsZero()}, s.dedupOwner(userID))
 
 	if err != nil {
 		// Проверяем, является ли ошибка нарушением уникальности
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgerrcode.UniqueViolation {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
 			// Если произошел конфликт, проверяем по какому полю
-			existingID, exists := s.FindByOriginalURL(url)
-			if exists {
-				// Конфликт по original_url - возвращаем существующий ID
-				return existingID, true, nil
+			existingID, findErr := s.FindByOriginalURL(ctx, url, userID)
+			if findErr == nil {
+				// Конфликт по original_url - URL успели добавить параллельно
+				return existingID, urlConflict()
+			}
+			if !errors.Is(findErr, ErrNotFound) {
+				return "", findErr
 			}
-			// Конфликт по short_id - возвращаем ошибку конфликта
-			return "", false, database.ErrURLConflict
+			// Конфликт по short_id - ID уже занят другим URL
+			return "", shortIDConflict()
 		}
-		return "", false, err
+		return "", err
 	}
 
-	return id, false, nil
+	return id, nil
 }
 
-// AddWithUser добавляет новый URL в хранилище с привязкой к пользователю
-func (s *DatabaseStorage) AddWithUser(id string, url string, userID string) (string, bool, error) {
-	// Проверяем, существует ли уже такой URL
-	if existingID, exists := s.FindByOriginalURL(url); exists {
-		return existingID, true, database.ErrURLConflict
+// batchInsertRows - количество строк в одном многострочном INSERT.
+// Ограничивает число параметров запроса (5 на строку) пределом PostgreSQL.
+const batchInsertRows = 1000
+
+// AddBatch добавляет пакет URL в одной транзакции.
+//
+// Записи вставляются многострочными INSERT ... ON CONFLICT DO NOTHING,
+// после чего одним запросом определяются ID уже сокращенных URL.
+// Вместо двух запросов на запись выполняется два запроса на каждые
+// batchInsertRows записей. Запись, короткий ID которой занят другим URL,
+// получает статус RecordFailed. При сбое транзакция откатывается целиком.
+func (s *DatabaseStorage) AddBatch(ctx context.Context, records []Record, userID string) (results []RecordResult, err error) {
+	ctx, span := startQuery(ctx, "AddBatch")
+	defer func() { endQuery(span, err) }()
+	span.SetAttributes(tracing.Int("batch.size", len(records)))
+
+	results = make([]RecordResult, len(records))
+	if len(records) == 0 {
+		return results, nil
 	}
 
-	// Добавляем новый URL с привязкой к пользователю
-	_, err := s.db.Exec(`
-		INSERT INTO urls (short_id, original_url, user_id)
-		VALUES ($1, $2, $3)
-	`, id, url, userID)
-
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		// Проверяем, является ли ошибка нарушением уникальности
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgerrcode.UniqueViolation {
-			// Если произошел конфликт, проверяем по какому полю
-			existingID, exists := s.FindByOriginalURL(url)
-			if exists {
-				// Конфликт по original_url - возвращаем существующий ID
-				return existingID, true, nil
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Вставляем записи частями и запоминаем фактически добавленные
+	owner := s.dedupOwner(userID)
+	inserted := make(map[string]string, len(records)) // short_id -> original_url
+	for start := 0; start < len(records); start += batchInsertRows {
+		end := min(start+batchInsertRows, len(records))
+		if err = insertBatchChunk(ctx, tx, records[start:end], userID, owner, inserted); err != nil {
+			return nil, err
+		}
+	}
+
+	// Для невставленных записей ищем существующие ID в области дедупликации.
+	// Запрос внутри транзакции видит и строки, вставленные этим пакетом
+	existing := make(map[string]string) // original_url -> short_id
+	if owner.Valid {
+		var missing []string
+		for _, record := range records {
+			if inserted[record.ID] != record.OriginalURL {
+				missing = append(missing, record.OriginalURL)
+			}
+		}
+		if len(missing) > 0 {
+			if err = findExistingURLs(ctx, tx, missing, owner.String, existing); err != nil {
+				return nil, err
 			}
-			// Конфликт по short_id - возвращаем ошибку конфликта
-			return "", false, database.ErrURLConflict
 		}
-		return "", false, err
 	}
 
-	return id, false, nil
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// Одна и та же запись могла встретиться в пакете несколько раз:
+	// созданной считается только первая запись со вставленным ID
+	claimed := make(map[string]bool, len(inserted))
+	for i, record := range records {
+		if inserted[record.ID] == record.OriginalURL && !claimed[record.ID] {
+			claimed[record.ID] = true
+			results[i] = RecordResult{ID: record.ID, Status: RecordCreated}
+			continue
+		}
+		if id, ok := existing[record.OriginalURL]; ok {
+			results[i] = RecordResult{ID: id, Status: RecordExisting}
+			continue
+		}
+		// URL не вставлен и не найден - конфликт по short_id
+		results[i] = RecordResult{Status: RecordFailed, Err: shortIDConflict()}
+	}
+
+	return results, nil
+}
+
+// insertBatchChunk выполняет многострочный INSERT и дополняет inserted
+// ID и оригинальными URL фактически добавленных строк
+func insertBatchChunk(ctx context.Context, tx *sql.Tx, records []Record, userID string, owner sql.NullString, inserted map[string]string) (err error) {
+	ctx, span := startQuery(ctx, "insertBatchChunk")
+	defer func() { endQuery(span, err) }()
+
+	var query strings.Builder
+	query.WriteString(`INSERT INTO urls (short_id, original_url, user_id, expires_at, dedup_owner) VALUES `)
+
+	args := make([]any, 0, len(records)*5)
+	for i, record := range records {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5)
+		args = append(args, record.ID, record.OriginalURL, userID,
+			sql.NullTime{Time: record.ExpiresAt, Valid: !record.ExpiresAt.IsZero()}, owner)
+	}
+	query.WriteString(` ON CONFLICT DO NOTHING RETURNING short_id, original_url`)
+
+	rows, err := tx.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortID, originalURL string
+		if err := rows.Scan(&shortID, &originalURL); err != nil {
+			return err
+		}
+		inserted[shortID] = originalURL
+	}
+
+	return rows.Err()
+}
+
+// findExistingURLs находит ID уже сокращенных URL группы дедупликации owner
+// и дополняет ими existing
+func findExistingURLs(ctx context.Context, tx *sql.Tx, urls []string, owner string, existing map[string]string) (err error) {
+	ctx, span := startQuery(ctx, "findExistingURLs")
+	defer func() { endQuery(span, err) }()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT original_url, short_id
+		FROM urls
+		WHERE original_url = ANY($1) AND dedup_owner = $2
+	`, urls, owner)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var originalURL, shortID string
+		if err := rows.Scan(&originalURL, &shortID); err != nil {
+			return err
+		}
+		existing[originalURL] = shortID
+	}
+
+	return rows.Err()
 }
 
 // Get возвращает оригинальный URL по идентификатору
-func (s *DatabaseStorage) Get(id string) (string, bool) {
-	var url string
-	var isDeleted bool
-	err := s.db.QueryRow(`
-		SELECT original_url, COALESCE(is_deleted, false)
+func (s *DatabaseStorage) Get(ctx context.Context, id string) (string, error) {
+	link, err := s.GetLink(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return link.Resolve(time.Now())
+}
+
+// GetLink возвращает сохраненную ссылку по идентификатору
+func (s *DatabaseStorage) GetLink(ctx context.Context, id string) (_ Link, err error) {
+	ctx, span := startQuery(ctx, "GetLink")
+	defer func() { endQuery(span, err) }()
+
+	var link Link
+	var expiresAt sql.NullTime
+	err = s.db.QueryRowContext(ctx, `
+		SELECT original_url, COALESCE(is_deleted, false), is_disabled, expires_at
 		FROM urls
 		WHERE short_id = $1
-	`, id).Scan(&url, &isDeleted)
+	`, id).Scan(&link.OriginalURL, &link.Deleted, &link.Disabled, &expiresAt)
 
-	if err == sql.ErrNoRows {
-		return "", false
+	if errors.Is(err, sql.ErrNoRows) {
+		return Link{}, ErrNotFound
 	}
 
 	if err != nil {
-		return "", false
+		return Link{}, err
 	}
 
-	// Если URL помечен как удаленный, возвращаем false
-	if isDeleted {
-		return "", false
+	if expiresAt.Valid {
+		link.ExpiresAt = expiresAt.Time
 	}
 
-	return url, true
+	return link, nil
 }
 
-// FindByOriginalURL ищет ID по оригинальному URL
-func (s *DatabaseStorage) FindByOriginalURL(url string) (string, bool) {
+// FindByOriginalURL ищет ID ссылки на URL в области дедупликации пользователя
+func (s *DatabaseStorage) FindByOriginalURL(ctx context.Context, url string, userID string) (_ string, err error) {
+	owner := s.dedupOwner(userID)
+	if !owner.Valid {
+		return "", ErrNotFound
+	}
+
+	ctx, span := startQuery(ctx, "FindByOriginalURL")
+	defer func() { endQuery(span, err) }()
+
 	var id string
-	err := s.db.QueryRow(`
+	err = s.db.QueryRowContext(ctx, `
 		SELECT short_id
 		FROM urls
-		WHERE original_url = $1
-	`, url).Scan(&id)
+		WHERE original_url = $1 AND dedup_owner = $2
+	`, url, owner.String).Scan(&id)
 
-	if err == sql.ErrNoRows {
-		return "", false
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
 	}
 
 	if err != nil {
-		return "", false
+		return "", err
 	}
 
-	return id, true
+	return id, nil
 }
 
 // GetUserURLs возвращает все URL пользователя (исключая удаленные)
-func (s *DatabaseStorage) GetUserURLs(userID string) ([]models.UserURL, error) {
-	rows, err := s.db.Query(`
+func (s *DatabaseStorage) GetUserURLs(ctx context.Context, userID string) (_ []models.UserURL, err error) {
+	ctx, span := startQuery(ctx, "GetUserURLs")
+	defer func() { endQuery(span, err) }()
+
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT short_id, original_url
 		FROM urls
 		WHERE user_id = $1 AND COALESCE(is_deleted, false) = false
+			AND (expires_at IS NULL OR expires_at > now())
 		ORDER BY created_at DESC
 	`, userID)
 	if err != nil {
@@ -161,12 +328,86 @@ func (s *DatabaseStorage) GetUserURLs(userID string) ([]models.UserURL, error) {
 	return result, nil
 }
 
+// userURLCreatedSQL - момент создания ссылки для сортировки и курсора;
+// ссылки с неизвестным моментом создания считаются созданными в начале эпохи
+const userURLCreatedSQL = `COALESCE(created_at, to_timestamp(0))`
+
+// ListUserURLs возвращает страницу URL пользователя: количество URL
+// и страница определяются двумя запросами, страница выбирается по
+// курсору (keyset) с использованием индекса (user_id, created_at, short_id)
+func (s *DatabaseStorage) ListUserURLs(ctx context.Context, userID string, query UserURLQuery) (_ UserURLPage, err error) {
+	ctx, span := startQuery(ctx, "ListUserURLs")
+	defer func() { endQuery(span, err) }()
+
+	where, args := query.linkFilter(userID).sqlConditions()
+	where += ` AND COALESCE(is_deleted, false) = false AND (expires_at IS NULL OR expires_at > now())`
+
+	page := UserURLPage{URLs: []models.UserURL{}}
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM urls WHERE `+where, args...).Scan(&page.Total)
+	if err != nil {
+		return UserURLPage{}, err
+	}
+
+	direction, comparison := "DESC", "<"
+	if query.Order == SortOldest {
+		direction, comparison = "ASC", ">"
+	}
+	if query.After != nil {
+		args = append(args, query.After.CreatedAt, query.After.ShortID)
+		where += fmt.Sprintf(` AND (%s, short_id) %s ($%d, $%d)`,
+			userURLCreatedSQL, comparison, len(args)-1, len(args))
+	}
+	limit := ""
+	if query.Limit > 0 {
+		// Лишняя строка показывает, что за страницей есть продолжение
+		args = append(args, query.Limit+1)
+		limit = fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT short_id, original_url, `+userURLCreatedSQL+`
+		FROM urls
+		WHERE `+where+`
+		ORDER BY `+userURLCreatedSQL+` `+direction+`, short_id `+direction+limit,
+		args...)
+	if err != nil {
+		return UserURLPage{}, err
+	}
+	defer rows.Close()
+
+	var last Cursor
+	for rows.Next() {
+		var userURL models.UserURL
+		var createdAt time.Time
+		if err := rows.Scan(&userURL.ShortURL, &userURL.OriginalURL, &createdAt); err != nil {
+			return UserURLPage{}, err
+		}
+		if query.Limit > 0 && len(page.URLs) == query.Limit {
+			page.Next = &last
+			break
+		}
+		if createdAt.Unix() != 0 {
+			userURL.CreatedAt = &createdAt
+		}
+		page.URLs = append(page.URLs, userURL)
+		last = Cursor{CreatedAt: createdAt, ShortID: userURL.ShortURL}
+	}
+	if err := rows.Err(); err != nil {
+		return UserURLPage{}, err
+	}
+
+	return page, nil
+}
+
 // DeleteUserURLs помечает URL как удаленные для указанного пользователя
-func (s *DatabaseStorage) DeleteUserURLs(userID string, shortURLs []string) error {
+func (s *DatabaseStorage) DeleteUserURLs(ctx context.Context, userID string, shortURLs []string) (err error) {
 	if len(shortURLs) == 0 {
 		return nil
 	}
 
+	ctx, span := startQuery(ctx, "DeleteUserURLs")
+	defer func() { endQuery(span, err) }()
+
 	// Помечаем URL как удаленные вместо физического удаления
 	query := `
 		UPDATE urls 
@@ -174,36 +415,41 @@ func (s *DatabaseStorage) DeleteUserURLs(userID string, shortURLs []string) erro
 		WHERE user_id = $1 AND short_id = ANY($2)
 	`
 
-	_, err := s.db.Exec(query, userID, shortURLs)
+	_, err = s.db.ExecContext(ctx, query, userID, shortURLs)
 	return err
 }
 
-// IsDeleted проверяет, помечен ли URL как удаленный
-func (s *DatabaseStorage) IsDeleted(shortURL string) (bool, error) {
-	var isDeleted bool
-	err := s.db.QueryRow(`
-		SELECT COALESCE(is_deleted, false)
-		FROM urls
-		WHERE short_id = $1
-	`, shortURL).Scan(&isDeleted)
+// PurgeExpired помечает как удаленные все URL с истекшим к моменту now сроком действия
+func (s *DatabaseStorage) PurgeExpired(ctx context.Context, now time.Time) (_ int, err error) {
+	ctx, span := startQuery(ctx, "PurgeExpired")
+	defer func() { endQuery(span, err) }()
 
-	if err == sql.ErrNoRows {
-		return false, nil // URL не найден, считаем не удаленным
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE urls
+		SET is_deleted = true
+		WHERE expires_at <= $1 AND COALESCE(is_deleted, false) = false
+	`, now)
+	if err != nil {
+		return 0, err
 	}
 
+	purged, err := result.RowsAffected()
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 
-	return isDeleted, nil
+	return int(purged), nil
 }
 
 // Stats возвращает статистику хранилища
-func (s *DatabaseStorage) Stats() (Stats, error) {
+func (s *DatabaseStorage) Stats(ctx context.Context) (_ Stats, err error) {
+	ctx, span := startQuery(ctx, "Stats")
+	defer func() { endQuery(span, err) }()
+
 	var urlsCount, usersCount int
 
 	// Подсчитываем количество URL
-	err := s.db.QueryRow(`
+	err = s.db.QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM urls
 	`).Scan(&urlsCount)
@@ -212,7 +458,7 @@ func (s *DatabaseStorage) Stats() (Stats, error) {
 	}
 
 	// Подсчитываем количество уникальных пользователей
-	err = s.db.QueryRow(`
+	err = s.db.QueryRowContext(ctx, `
 		SELECT COUNT(DISTINCT user_id)
 		FROM urls
 		WHERE user_id IS NOT NULL
@@ -224,6 +470,25 @@ func (s *DatabaseStorage) Stats() (Stats, error) {
 	return Stats{URLs: urlsCount, Users: usersCount}, nil
 }
 
+// startQuery создает span запроса к PostgreSQL
+func startQuery(ctx context.Context, operation string) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, "DatabaseStorage."+operation,
+		tracing.WithKind(tracing.SpanKindClient),
+		tracing.WithAttributes(
+			tracing.String("db.system", "postgresql"),
+			tracing.String("db.operation", operation),
+		))
+}
+
+// endQuery завершает span запроса. Типизированные ошибки хранилища
+// (ErrNotFound, ErrGone, ErrConflict) сбоем запроса не считаются.
+func endQuery(span *tracing.Span, err error) {
+	if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrGone) && !errors.Is(err, ErrConflict) {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
 // Close закрывает соединение с базой данных
 func (s *DatabaseStorage) Close() error {
 	return nil // DB закрывается на уровне приложения
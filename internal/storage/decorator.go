@@ -0,0 +1,48 @@
+package storage
+
+// Storage объединяет URLStorageV2 с необязательными интерфейсами, которые
+// реализуют и MemoryStorage, и DatabaseStorage.
+//
+// Декораторы хранилища (CachedStorage, InstrumentedStorage) встраивают
+// Storage и переопределяют только методы, поведение которых меняют:
+// остальные методы, включая необязательные интерфейсы, передаются
+// обернутому хранилищу встраиванием.
+type Storage interface {
+	URLStorageV2
+	BatchAdder
+	LinkGetter
+	ClickStorage
+	AccountStorage
+	AdminStorage
+	RecordStorage
+	CounterStorage
+}
+
+// compact запускает компактирование журнала или возвращает ErrCompactionUnsupported.
+// Compactor реализует только MemoryStorage, поэтому декораторы передают
+// Compact явно.
+func compact(store URLStorageV2) (CompactionResult, error) {
+	compactor, ok := store.(Compactor)
+	if !ok {
+		return CompactionResult{}, ErrCompactionUnsupported
+	}
+	return compactor.Compact()
+}
+
+// adminStorage возвращает хранилище модерации ссылок или ErrAdminUnsupported
+func adminStorage(store URLStorageV2) (AdminStorage, error) {
+	admin, ok := store.(AdminStorage)
+	if !ok {
+		return nil, ErrAdminUnsupported
+	}
+	return admin, nil
+}
+
+// recordStorage возвращает хранилище переноса ссылок или ErrMigrationUnsupported
+func recordStorage(store URLStorageV2) (RecordStorage, error) {
+	records, ok := store.(RecordStorage)
+	if !ok {
+		return nil, ErrMigrationUnsupported
+	}
+	return records, nil
+}
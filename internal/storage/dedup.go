@@ -0,0 +1,60 @@
+package storage
+
+import "fmt"
+
+// DedupScope определяет, в каких пределах повторное сокращение
+// одного и того же URL возвращает уже существующую ссылку
+type DedupScope string
+
+// Области дедупликации оригинальных URL.
+const (
+	// DedupGlobal - одна ссылка на URL для всех пользователей. Пользователь,
+	// сокращающий чужой URL, получает существующую ссылку, но не становится
+	// ее владельцем. Поведение по умолчанию для нулевого значения.
+	DedupGlobal DedupScope = "global"
+
+	// DedupPerUser - у каждого пользователя своя ссылка на URL: повторное
+	// сокращение возвращает ссылку этого же пользователя. Ссылки без
+	// владельца в дедупликации не участвуют.
+	DedupPerUser DedupScope = "per-user"
+
+	// DedupNone - каждое сокращение создает новую ссылку.
+	DedupNone DedupScope = "none"
+)
+
+// ParseDedupScope проверяет и преобразует строковое значение области дедупликации
+func ParseDedupScope(value string) (DedupScope, error) {
+	switch d := DedupScope(value); d {
+	case DedupGlobal, DedupPerUser, DedupNone:
+		return d, nil
+	default:
+		return "", fmt.Errorf("неизвестная область дедупликации %q (допустимо: global, per-user, none)", value)
+	}
+}
+
+// owner возвращает владельца группы дедупликации для пользователя userID.
+// Ссылки на один URL с одинаковым владельцем группы считаются дубликатами.
+// Второе значение false означает, что дедупликация отключена.
+//
+// В области DedupPerUser ссылка без владельца исключается из дедупликации:
+// пустой владелец группы совпал бы с глобальной группой и ссылками,
+// созданными до появления областей дедупликации.
+func (d DedupScope) owner(userID string) (string, bool) {
+	switch d {
+	case DedupPerUser:
+		return userID, userID != ""
+	case DedupNone:
+		return "", false
+	default:
+		return "", true
+	}
+}
+
+// key возвращает ключ индекса дедупликации для URL пользователя
+func (d DedupScope) key(userID, url string) (string, bool) {
+	owner, ok := d.owner(userID)
+	if !ok {
+		return "", false
+	}
+	return owner + "\x00" + url, true
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"go.uber.org/zap"
+)
+
+// Durability определяет режим надежности записи журнала файлового хранилища
+type Durability string
+
+// Режимы надежности записи журнала.
+const (
+	// DurabilitySync - запись подтверждается вызывающему только после
+	// записи в файл и fsync. Ответ 201 Created означает, что ссылка на диске.
+	DurabilitySync Durability = "sync"
+
+	// DurabilityBatch - записи пишутся асинхронно пакетами, каждый пакет
+	// синхронизируется с диском. При сбое теряются только записи из очереди.
+	DurabilityBatch Durability = "batch"
+
+	// DurabilityAsync - записи пишутся асинхронно без fsync (поведение по умолчанию).
+	// При сбое питания могут быть потеряны записи, еще не сброшенные ОС на диск.
+	DurabilityAsync Durability = "async"
+)
+
+// flushBatchLimit - максимальное количество записей журнала в одном пакете
+const flushBatchLimit = 256
+
+// ErrStorageClosed возвращается при попытке записи в закрытое хранилище
+var ErrStorageClosed = errors.New("хранилище закрыто")
+
+// ParseDurability проверяет и преобразует строковое значение режима надежности
+func ParseDurability(value string) (Durability, error) {
+	switch d := Durability(value); d {
+	case DurabilitySync, DurabilityBatch, DurabilityAsync:
+		return d, nil
+	default:
+		return "", fmt.Errorf("неизвестный режим надежности %q (допустимо: sync, batch, async)", value)
+	}
+}
+
+// RecoveryReport содержит результат восстановления журнала при запуске
+type RecoveryReport struct {
+	Records        int   // Количество прочитанных записей журнала
+	SkippedLines   int   // Количество поврежденных строк в середине журнала
+	TornTail       bool  // Обнаружена оборванная последняя запись
+	TruncatedBytes int64 // Количество байт, отрезанных от конца журнала
+}
+
+// flushRequest - запись журнала в очереди на сохранение
+type flushRequest struct {
+	record models.URLRecord
+	done   chan error // получает результат записи (только в режиме DurabilitySync)
+}
+
+// Recovery возвращает результат восстановления журнала при запуске
+func (s *MemoryStorage) Recovery() RecoveryReport {
+	return s.recovery
+}
+
+// FlushQueueDepth возвращает количество записей в очереди записи журнала
+// (0 для хранилища без файла)
+func (s *MemoryStorage) FlushQueueDepth() int {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	return len(s.flushQueue)
+}
+
+// enqueueLocked ставит запись в очередь на сохранение, вызывающий должен
+// удерживать мьютекс, чтобы порядок записей в журнале совпадал с порядком изменений.
+// В режиме DurabilitySync возвращает канал, в который придет результат записи.
+//
+// Очередь не ограничена, поэтому постановка в нее не ждет записи на диск:
+// медленный fsync задерживает только ожидающих подтверждения записи,
+// а не всех, кому нужен мьютекс хранилища.
+func (s *MemoryStorage) enqueueLocked(record models.URLRecord) <-chan error {
+	req := flushRequest{record: record}
+	if s.durability == DurabilitySync {
+		req.done = make(chan error, 1)
+	}
+
+	s.flushMu.Lock()
+	s.flushQueue = append(s.flushQueue, req)
+	s.flushMu.Unlock()
+
+	// Будим flushWorker; если сигнал уже ожидает, worker заберет и эту запись
+	select {
+	case s.flushSignal <- struct{}{}:
+	default:
+	}
+
+	return req.done
+}
+
+// takeBatch забирает из очереди записи не более flushBatchLimit первых записей
+func (s *MemoryStorage) takeBatch() []flushRequest {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	n := min(len(s.flushQueue), flushBatchLimit)
+	if n == 0 {
+		return nil
+	}
+	batch := make([]flushRequest, n)
+	copy(batch, s.flushQueue)
+	s.flushQueue = s.flushQueue[n:]
+	if len(s.flushQueue) == 0 {
+		s.flushQueue = nil
+	}
+	return batch
+}
+
+// waitPersisted дожидается записи на диск; nil канал означает, что ждать не нужно
+func waitPersisted(done <-chan error) error {
+	if done == nil {
+		return nil
+	}
+	if err := <-done; err != nil {
+		return fmt.Errorf("ошибка сохранения записи на диск: %w", err)
+	}
+	return nil
+}
+
+// readLog читает журнал и передает каждую корректную запись в apply.
+//
+// Последняя строка без завершающего перевода строки, которую не удается
+// декодировать, считается оборванной при сбое во время записи: файл
+// обрезается до конца последней целой записи. Поврежденные строки
+// в середине журнала пропускаются.
+func (s *MemoryStorage) readLog(apply func(record models.URLRecord)) (RecoveryReport, error) {
+	var report RecoveryReport
+
+	file, err := os.OpenFile(s.storagePath, os.O_RDWR, 0)
+	if err != nil {
+		return report, fmt.Errorf("ошибка открытия файла хранения: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64        // конец последней целой строки
+	missingNewline := false // последняя запись целая, но без перевода строки
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return report, fmt.Errorf("ошибка чтения файла хранения: %w", readErr)
+		}
+		if len(line) == 0 {
+			break
+		}
+
+		complete := line[len(line)-1] == '\n'
+		data := bytes.TrimSpace(line)
+
+		var record models.URLRecord
+		decodeErr := json.Unmarshal(data, &record)
+
+		switch {
+		case len(data) == 0:
+			// Пустая строка
+		case decodeErr == nil:
+			apply(record)
+			report.Records++
+			missingNewline = !complete
+		case !complete:
+			// Оборванная последняя запись
+			report.TornTail = true
+		default:
+			report.SkippedLines++
+			logger.Logger.Error("Ошибка декодирования записи URL",
+				zap.ByteString("line", data),
+				zap.Error(decodeErr))
+		}
+
+		if report.TornTail {
+			break
+		}
+		offset += int64(len(line))
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	// Дописываем перевод строки, чтобы следующая запись не склеилась с последней
+	if missingNewline {
+		if _, err := file.WriteAt([]byte{'\n'}, offset); err != nil {
+			return report, fmt.Errorf("ошибка восстановления конца журнала: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			return report, fmt.Errorf("ошибка синхронизации файла: %w", err)
+		}
+	}
+
+	if report.TornTail {
+		info, err := file.Stat()
+		if err != nil {
+			return report, fmt.Errorf("ошибка получения размера файла: %w", err)
+		}
+		report.TruncatedBytes = info.Size() - offset
+
+		if err := file.Truncate(offset); err != nil {
+			return report, fmt.Errorf("ошибка обрезки оборванной записи: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			return report, fmt.Errorf("ошибка синхронизации файла: %w", err)
+		}
+
+		logger.Logger.Warn("Обнаружена оборванная запись в конце журнала, файл обрезан",
+			zap.String("path", s.storagePath),
+			zap.Int64("truncated_bytes", report.TruncatedBytes))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Adigezalov/shortener/internal/database"
+)
+
+// Типизированные ошибки хранилища URLStorageV2.
+//
+// Проверяются через errors.Is. Любая другая ошибка означает сбой
+// хранилища (например, недоступность базы данных) и не должна
+// трактоваться как отсутствие URL.
+var (
+	// ErrNotFound возвращается, когда URL не найден.
+	ErrNotFound = errors.New("URL не найден")
+
+	// ErrGone возвращается, когда URL был удален.
+	ErrGone = errors.New("URL удален")
+
+	// ErrExpired возвращается, когда срок действия URL истек.
+	// Удовлетворяет errors.Is(err, ErrGone).
+	ErrExpired = fmt.Errorf("%w: срок действия истек", ErrGone)
+
+	// ErrDisabled возвращается, когда ссылка отключена модератором.
+	// Удовлетворяет errors.Is(err, ErrGone).
+	ErrDisabled = fmt.Errorf("%w: ссылка отключена", ErrGone)
+
+	// ErrConflict возвращается при конфликте уникальности.
+	// Конкретная причина проверяется через database.ErrURLConflict
+	// (оригинальный URL уже сокращен) или database.ErrShortIDConflict
+	// (короткий ID занят).
+	ErrConflict = errors.New("конфликт уникальности")
+)
+
+// urlConflict формирует ошибку конфликта по оригинальному URL
+func urlConflict() error {
+	return fmt.Errorf("%w: %w", ErrConflict, database.ErrURLConflict)
+}
+
+// shortIDConflict формирует ошибку конфликта по короткому ID
+func shortIDConflict() error {
+	return fmt.Errorf("%w: %w", ErrConflict, database.ErrShortIDConflict)
+}
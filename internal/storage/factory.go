@@ -2,19 +2,57 @@ package storage
 
 import (
 	"github.com/Adigezalov/shortener/internal/database"
+	"github.com/Adigezalov/shortener/internal/metrics"
 )
 
-// Factory создает хранилище URL в зависимости от конфигурации
-func Factory(dbDSN, filePath string) (URLStorage, error) {
+// Factory создает хранилище URL в зависимости от конфигурации.
+// Область дедупликации opts.Dedup применяется к любому хранилищу,
+// кэш ссылок opts.Cache - только к хранилищу PostgreSQL,
+// остальные параметры opts - только к файловому.
+//
+// Операции хранилища измеряются InstrumentedStorage; Factory также
+// регистрирует метрики пула соединений, очереди записи журнала и кэша.
+func Factory(dbDSN, filePath string, opts Options) (URLStorageV2, error) {
 	// Пробуем создать хранилище в PostgreSQL
 	if dbDSN != "" {
+		cache, err := newCache(opts.Cache)
+		if err != nil {
+			return nil, err
+		}
+
 		db, err := database.New(dbDSN)
 		if err != nil {
 			return nil, err
 		}
-		return NewDatabaseStorage(db), nil
+		metrics.RegisterDBStats(db.Stats)
+
+		var store Storage = NewInstrumentedStorage(NewDatabaseStorage(db, opts.Dedup), BackendPostgres)
+		if cache != nil {
+			cached := NewCachedStorage(store, cache)
+			registerCacheMetrics(cached)
+			store = cached
+		}
+		return store, nil
 	}
 
 	// Если нет DSN, создаем хранилище в памяти с опциональным сохранением в файл
-	return NewMemoryStorage(filePath), nil
+	if filePath == "" {
+		return NewInstrumentedStorage(NewMemoryStorageWithOptions("", opts), BackendMemory), nil
+	}
+
+	file := NewFileStorageWithOptions(filePath, opts)
+	metrics.Register(metrics.NewGaugeFunc("shortener_file_flush_queue_depth",
+		"Количество записей в очереди записи журнала файлового хранилища",
+		func() float64 { return float64(file.FlushQueueDepth()) }))
+	return NewInstrumentedStorage(file, BackendFile), nil
+}
+
+// registerCacheMetrics регистрирует счетчики обращений к кэшу ссылок
+func registerCacheMetrics(cached *CachedStorage) {
+	metrics.Register(metrics.NewCounterFunc("shortener_cache_hits_total",
+		"Количество переходов, обслуженных из кэша ссылок",
+		func() float64 { return float64(cached.CacheStats().Hits) }))
+	metrics.Register(metrics.NewCounterFunc("shortener_cache_misses_total",
+		"Количество переходов, потребовавших обращения к хранилищу",
+		func() float64 { return float64(cached.CacheStats().Misses) }))
 }
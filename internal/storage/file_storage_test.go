@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage_Restore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewFileStorageWithOptions(path, Options{Durability: DurabilitySync})
+	_, err := store.Add(ctx, "abc123", "https://example.com/1", "user1", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// Файл хранилища совместим с MemoryStorage
+	restored := NewFileStorage(path)
+	defer restored.Close()
+	url, err := restored.Get(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", url)
+
+	// Необязательные возможности MemoryStorage доступны через FileStorage
+	assert.Implements(t, (*Compactor)(nil), restored)
+	assert.Implements(t, (*AdminStorage)(nil), restored)
+	assert.Implements(t, (*RecordStorage)(nil), restored)
+	assert.Implements(t, (*CounterStorage)(nil), restored)
+}
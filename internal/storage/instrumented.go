@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/metrics"
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// Имена хранилищ в метриках
+const (
+	BackendPostgres = "postgres" // PostgreSQL
+	BackendFile     = "file"     // память с журналом в файле
+	BackendMemory   = "memory"   // только память
+)
+
+// operationDuration - длительность операций хранилища
+var operationDuration = metrics.Register(metrics.NewHistogramVec(
+	"shortener_storage_operation_duration_seconds",
+	"Длительность операций хранилища URL в секундах",
+	nil, "backend", "operation"))
+
+// InstrumentedStorage - декоратор хранилища, измеряющий длительность операций
+// в метрике shortener_storage_operation_duration_seconds.
+//
+// Измеряются операции с ссылками и переходами (URLStorageV2, BatchAdder,
+// LinkGetter, ClickStorage, Compactor). Методы учетных записей, модерации,
+// переноса ссылок и счетчика генератора ID передаются хранилищу
+// встраиванием Storage без измерения.
+type InstrumentedStorage struct {
+	Storage
+	backend string
+}
+
+// NewInstrumentedStorage создает декоратор, измеряющий операции хранилища backend
+func NewInstrumentedStorage(store Storage, backend string) *InstrumentedStorage {
+	return &InstrumentedStorage{Storage: store, backend: backend}
+}
+
+// observe записывает длительность операции, начатой в момент start
+func (s *InstrumentedStorage) observe(operation string, start time.Time) {
+	operationDuration.WithLabelValues(s.backend, operation).Observe(time.Since(start).Seconds())
+}
+
+// Add добавляет URL в хранилище
+func (s *InstrumentedStorage) Add(ctx context.Context, id string, url string, userID string, expiresAt time.Time) (string, error) {
+	defer s.observe("add", time.Now())
+	return s.Storage.Add(ctx, id, url, userID, expiresAt)
+}
+
+// AddBatch добавляет пакет URL в хранилище
+func (s *InstrumentedStorage) AddBatch(ctx context.Context, records []Record, userID string) ([]RecordResult, error) {
+	defer s.observe("add_batch", time.Now())
+	return s.Storage.AddBatch(ctx, records, userID)
+}
+
+// Get возвращает оригинальный URL по идентификатору
+func (s *InstrumentedStorage) Get(ctx context.Context, id string) (string, error) {
+	defer s.observe("get", time.Now())
+	return s.Storage.Get(ctx, id)
+}
+
+// GetLink возвращает сохраненную ссылку по идентификатору
+func (s *InstrumentedStorage) GetLink(ctx context.Context, id string) (Link, error) {
+	defer s.observe("get_link", time.Now())
+	return s.Storage.GetLink(ctx, id)
+}
+
+// FindByOriginalURL ищет ID ссылки на URL в области дедупликации пользователя
+func (s *InstrumentedStorage) FindByOriginalURL(ctx context.Context, url string, userID string) (string, error) {
+	defer s.observe("find_by_original_url", time.Now())
+	return s.Storage.FindByOriginalURL(ctx, url, userID)
+}
+
+// GetUserURLs возвращает действующие URL пользователя
+func (s *InstrumentedStorage) GetUserURLs(ctx context.Context, userID string) ([]models.UserURL, error) {
+	defer s.observe("get_user_urls", time.Now())
+	return s.Storage.GetUserURLs(ctx, userID)
+}
+
+// ListUserURLs возвращает страницу URL пользователя
+func (s *InstrumentedStorage) ListUserURLs(ctx context.Context, userID string, query UserURLQuery) (UserURLPage, error) {
+	defer s.observe("list_user_urls", time.Now())
+	return s.Storage.ListUserURLs(ctx, userID, query)
+}
+
+// DeleteUserURLs помечает URL пользователя как удаленные
+func (s *InstrumentedStorage) DeleteUserURLs(ctx context.Context, userID string, shortURLs []string) error {
+	defer s.observe("delete_user_urls", time.Now())
+	return s.Storage.DeleteUserURLs(ctx, userID, shortURLs)
+}
+
+// PurgeExpired помечает истекшие URL как удаленные
+func (s *InstrumentedStorage) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	defer s.observe("purge_expired", time.Now())
+	return s.Storage.PurgeExpired(ctx, now)
+}
+
+// Stats возвращает статистику хранилища
+func (s *InstrumentedStorage) Stats(ctx context.Context) (Stats, error) {
+	defer s.observe("stats", time.Now())
+	return s.Storage.Stats(ctx)
+}
+
+// RecordClicks сохраняет пакет событий перехода
+func (s *InstrumentedStorage) RecordClicks(clicks []models.Click) error {
+	defer s.observe("record_clicks", time.Now())
+	return s.Storage.RecordClicks(clicks)
+}
+
+// GetClickStats возвращает статистику переходов по ссылке пользователя
+func (s *InstrumentedStorage) GetClickStats(userID string, shortURL string, since time.Time) (models.URLStats, bool, error) {
+	defer s.observe("get_click_stats", time.Now())
+	return s.Storage.GetClickStats(userID, shortURL, since)
+}
+
+// Compact запускает компактирование журнала хранилища
+func (s *InstrumentedStorage) Compact() (CompactionResult, error) {
+	defer s.observe("compact", time.Now())
+	return compact(s.Storage)
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedStorage_ObservesOperations(t *testing.T) {
+	ctx := context.Background()
+	store := NewInstrumentedStorage(NewMemoryStorage(""), "test")
+	defer store.Close()
+
+	_, err := store.Add(ctx, "a", "https://example.com/a", "alice", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Необязательные интерфейсы передаются обернутому хранилищу
+	link, err := store.GetLink(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a", link.OriginalURL)
+	_, err = store.Compact()
+	assert.ErrorIs(t, err, ErrCompactionUnsupported)
+
+	var buf bytes.Buffer
+	_, err = metrics.Default.WriteTo(&buf)
+	require.NoError(t, err)
+	for _, op := range []string{"add", "get", "get_link", "compact"} {
+		assert.Contains(t, buf.String(),
+			`shortener_storage_operation_duration_seconds_count{backend="test",operation="`+op+`"} 1`)
+	}
+}
+
+func TestFactory_FileStorageMetrics(t *testing.T) {
+	store, err := Factory("", filepath.Join(t.TempDir(), "storage.json"), Options{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, ok := store.(ClickStorage)
+	assert.True(t, ok)
+
+	var buf bytes.Buffer
+	_, err = metrics.Default.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "shortener_file_flush_queue_depth ")
+}
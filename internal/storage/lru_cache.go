@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRUCache - кэш ссылок в памяти процесса с ограничением размера и TTL.
+// При переполнении вытесняется ссылка, к которой дольше всего не обращались.
+type LRUCache struct {
+	size  int
+	ttl   time.Duration
+	now   func() time.Time // источник времени (подменяется в тестах)
+	mu    sync.Mutex
+	order *list.List               // элементы lruEntry, в начале - последние использованные
+	items map[string]*list.Element // id -> элемент списка order
+}
+
+// lruEntry - элемент LRU-кэша
+type lruEntry struct {
+	id       string
+	link     Link
+	storedAt time.Time
+}
+
+// NewLRUCache создает LRU-кэш на size ссылок со временем жизни ttl
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	return &LRUCache{
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// Get возвращает ссылку из кэша. Устаревшая запись удаляется и считается промахом.
+func (c *LRUCache) Get(_ context.Context, id string) (Link, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[id]
+	if !ok {
+		return Link{}, false, nil
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if c.now().Sub(entry.storedAt) >= c.ttl {
+		c.removeLocked(elem)
+		return Link{}, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.link, true, nil
+}
+
+// Set сохраняет ссылку в кэш, при необходимости вытесняя самую старую
+func (c *LRUCache) Set(_ context.Context, id string, link Link) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[id]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.link = link
+		entry.storedAt = c.now()
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[id] = c.order.PushFront(&lruEntry{id: id, link: link, storedAt: c.now()})
+	for c.order.Len() > c.size {
+		c.removeLocked(c.order.Back())
+	}
+	return nil
+}
+
+// Delete удаляет ссылки из кэша
+func (c *LRUCache) Delete(_ context.Context, ids ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range ids {
+		if elem, ok := c.items[id]; ok {
+			c.removeLocked(elem)
+		}
+	}
+	return nil
+}
+
+// Len возвращает количество ссылок в кэше
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// removeLocked удаляет элемент из кэша, вызывающий должен удерживать мьютекс
+func (c *LRUCache) removeLocked(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry).id)
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// linkClicks содержит агрегированную статистику переходов по одной ссылке
+type linkClicks struct {
+	total      int64
+	first      time.Time
+	last       time.Time
+	referrers  map[string]int64    // хост источника -> количество
+	userAgents map[string]int64    // семейство клиента -> количество
+	hourly     map[time.Time]int64 // начало часа (UTC) -> количество
+}
+
+// clickStats хранит статистику переходов в памяти.
+// Защищена отдельным мьютексом, чтобы запись переходов не конкурировала
+// с основными операциями хранилища.
+type clickStats struct {
+	mu    sync.RWMutex
+	links map[string]*linkClicks // shortURL -> статистика
+}
+
+// newClickStats создает пустое хранилище статистики переходов
+func newClickStats() *clickStats {
+	return &clickStats{links: make(map[string]*linkClicks)}
+}
+
+// RecordClicks сохраняет пакет событий перехода
+func (s *MemoryStorage) RecordClicks(clicks []models.Click) error {
+	s.clicks.mu.Lock()
+	defer s.clicks.mu.Unlock()
+
+	for _, click := range clicks {
+		link, ok := s.clicks.links[click.ShortID]
+		if !ok {
+			link = &linkClicks{
+				first:      click.ClickedAt,
+				referrers:  make(map[string]int64),
+				userAgents: make(map[string]int64),
+				hourly:     make(map[time.Time]int64),
+			}
+			s.clicks.links[click.ShortID] = link
+		}
+
+		link.total++
+		if click.ClickedAt.Before(link.first) {
+			link.first = click.ClickedAt
+		}
+		if click.ClickedAt.After(link.last) {
+			link.last = click.ClickedAt
+		}
+		if click.Referrer != "" {
+			link.referrers[click.Referrer]++
+		}
+		link.userAgents[click.UserAgentFamily]++
+		link.hourly[click.ClickedAt.UTC().Truncate(time.Hour)]++
+	}
+
+	return nil
+}
+
+// GetClickStats возвращает статистику переходов по ссылке пользователя.
+// Почасовая история включает только часы начиная с since.
+// Возвращает false, если ссылка не найдена или не принадлежит пользователю.
+func (s *MemoryStorage) GetClickStats(userID string, shortURL string, since time.Time) (models.URLStats, bool, error) {
+	if !s.ownsURL(userID, shortURL) {
+		return models.URLStats{}, false, nil
+	}
+
+	s.clicks.mu.RLock()
+	defer s.clicks.mu.RUnlock()
+
+	stats := models.URLStats{
+		Referrers:  []models.StatsCounter{},
+		UserAgents: []models.StatsCounter{},
+		Hourly:     []models.HourlyClicks{},
+	}
+
+	link, ok := s.clicks.links[shortURL]
+	if !ok {
+		return stats, true, nil
+	}
+
+	first, last := link.first, link.last
+	stats.Clicks = link.total
+	stats.FirstClickAt = &first
+	stats.LastClickAt = &last
+	stats.Referrers = topCounters(link.referrers, maxStatsEntries)
+	stats.UserAgents = topCounters(link.userAgents, maxStatsEntries)
+
+	since = since.UTC().Truncate(time.Hour)
+	for hour, clicks := range link.hourly {
+		if hour.Before(since) {
+			continue
+		}
+		stats.Hourly = append(stats.Hourly, models.HourlyClicks{Hour: hour, Clicks: clicks})
+	}
+	sort.Slice(stats.Hourly, func(i, j int) bool {
+		return stats.Hourly[i].Hour.Before(stats.Hourly[j].Hour)
+	})
+
+	return stats, true, nil
+}
+
+// ownsURL проверяет, принадлежит ли URL пользователю и не удален ли он
+func (s *MemoryStorage) ownsURL(userID string, shortURL string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.deletedURLs[shortURL] {
+		return false
+	}
+
+	for _, id := range s.userURLs[userID] {
+		if id == shortURL {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maxStatsEntries ограничивает количество источников и клиентов в статистике
+const maxStatsEntries = 20
+
+// topCounters возвращает не более limit значений с наибольшим количеством переходов
+func topCounters(counts map[string]int64, limit int) []models.StatsCounter {
+	result := make([]models.StatsCounter, 0, len(counts))
+	for value, clicks := range counts {
+		result = append(result, models.StatsCounter{Value: value, Clicks: clicks})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Clicks != result[j].Clicks {
+			return result[i].Clicks > result[j].Clicks
+		}
+		return result[i].Value < result[j].Value
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result
+}
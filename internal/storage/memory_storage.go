@@ -2,15 +2,17 @@ package storage
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
-	"github.com/Adigezalov/shortener/internal/database"
 	"github.com/Adigezalov/shortener/internal/logger"
 	"github.com/Adigezalov/shortener/internal/models"
 	"go.uber.org/zap"
@@ -18,45 +20,99 @@ import (
 
 // MemoryStorage реализует хранилище URL с опциональным сохранением в файл
 type MemoryStorage struct {
-	urls        map[string]string   // id -> original_url
-	urlToID     map[string]string   // original_url -> id (обратный индекс)
-	userURLs    map[string][]string // userID -> []shortURL (URL пользователя)
-	deletedURLs map[string]bool     // shortURL -> deleted flag
-	mu          sync.RWMutex        // мьютекс для защиты данных
-	nextID      int                 // счетчик ID для новых записей
+	urls         map[string]string    // id -> original_url
+	dedupIndex   map[string]string    // ключ дедупликации -> id (обратный индекс)
+	dedup        DedupScope           // область дедупликации оригинальных URL
+	userURLs     map[string][]string  // userID -> []shortURL (URL пользователя)
+	deletedURLs  map[string]bool      // shortURL -> deleted flag
+	disabledURLs map[string]bool      // shortURL -> ссылка отключена модератором
+	expiresAt    map[string]time.Time // shortURL -> момент истечения срока действия
+	createdAt    map[string]time.Time // shortURL -> момент создания
+	mu           sync.RWMutex         // мьютекс для защиты данных
+	nextID       int                  // счетчик ID для новых записей
+	idCounter    uint64               // граница номеров генератора ID (см. ReserveCounter)
+	clicks       *clickStats          // статистика переходов (не сохраняется в файл)
+	accounts     *accountStore        // учетные записи (сохраняются в файл <путь>.accounts)
 
 	// Поля для работы с файлом (используются только если storagePath не пустой)
-	storagePath string                // путь к файлу хранения
-	flushQueue  chan models.URLRecord // канал для асинхронной записи
-	fileLock    *os.File              // файловый дескриптор для блокировки
-	batchSize   int                   // размер пакета для записи
-	batchBuffer []models.URLRecord    // буфер для пакетной записи
-	batchMu     sync.Mutex            // мьютекс для буфера
-	fileMode    bool                  // флаг работы с файлом
+	storagePath string         // путь к файлу хранения
+	flushMu     sync.Mutex     // мьютекс очереди записи
+	flushQueue  []flushRequest // очередь асинхронной записи в порядке изменений
+	flushSignal chan struct{}  // сигнал flushWorker о новых записях в очереди
+	flushDone   chan struct{}  // закрывается по завершении flushWorker
+	fileLock    *os.File       // файловый дескриптор для блокировки
+	batchMu     sync.Mutex     // мьютекс записи в журнал
+	fileMode    bool           // флаг работы с файлом
+	durability  Durability     // режим надежности записи
+	recovery    RecoveryReport // результат восстановления журнала при запуске
+	closed      bool           // хранилище закрыто, запись невозможна
+
+	// Поля для компактирования журнала
+	compactionThreshold int            // порог записей журнала для автоматического компактирования
+	logRecords          atomic.Int64   // количество записей в журнале после последнего снимка
+	compacting          atomic.Bool    // флаг выполняющегося автоматического компактирования
+	compactMu           sync.Mutex     // сериализует компактирования
+	compactWG           sync.WaitGroup // ожидание фонового компактирования при закрытии
+}
+
+// Options содержит параметры хранилища
+type Options struct {
+	// Dedup - область дедупликации оригинальных URL. Применяется ко всем
+	// хранилищам; пустое значение соответствует DedupGlobal.
+	Dedup DedupScope
+
+	// Cache - параметры кэша ссылок перед хранилищем PostgreSQL.
+	// Хранилище в памяти не кэшируется.
+	Cache CacheOptions
+
+	// Durability - режим надежности записи журнала файлового хранилища. Пустое значение
+	// соответствует DurabilityAsync.
+	Durability Durability
+
+	// CompactionThreshold - количество записей в журнале файлового хранилища, после которого
+	// автоматически создается снимок и журнал обрезается. 0 отключает
+	// автоматическое компактирование.
+	CompactionThreshold int
 }
 
 // NewMemoryStorage создает новое хранилище URL
 // Если путь к файлу не пустой, данные будут сохраняться в файл
 // и восстанавливаться из него при запуске
 func NewMemoryStorage(storagePath string) *MemoryStorage {
+	return NewMemoryStorageWithOptions(storagePath, Options{})
+}
+
+// NewMemoryStorageWithOptions создает новое хранилище URL с параметрами
+func NewMemoryStorageWithOptions(storagePath string, opts Options) *MemoryStorage {
 	// Предварительно выделяем память для map'ов с ожидаемой емкостью
 	const initialCapacity = 1000
 
 	storage := &MemoryStorage{
-		urls:        make(map[string]string, initialCapacity),
-		urlToID:     make(map[string]string, initialCapacity),
-		userURLs:    make(map[string][]string, initialCapacity/10), // Меньше пользователей
-		deletedURLs: make(map[string]bool, initialCapacity/20),     // Еще меньше удаленных URL
-		nextID:      1,
-		storagePath: storagePath,
-		fileMode:    storagePath != "",
+		urls:         make(map[string]string, initialCapacity),
+		dedupIndex:   make(map[string]string, initialCapacity),
+		dedup:        opts.Dedup,
+		userURLs:     make(map[string][]string, initialCapacity/10), // Меньше пользователей
+		deletedURLs:  make(map[string]bool, initialCapacity/20),     // Еще меньше удаленных URL
+		disabledURLs: make(map[string]bool),
+		expiresAt:    make(map[string]time.Time),
+		createdAt:    make(map[string]time.Time),
+		clicks:       newClickStats(),
+		accounts:     newAccountStore(),
+		nextID:       1,
+		storagePath:  storagePath,
+		fileMode:     storagePath != "",
+		durability:   opts.Durability,
+
+		compactionThreshold: opts.CompactionThreshold,
+	}
+	if storage.durability == "" {
+		storage.durability = DurabilityAsync
 	}
 
 	// Если указан путь к файлу, инициализируем файловое хранилище
 	if storage.fileMode {
-		storage.flushQueue = make(chan models.URLRecord, 100)
-		storage.batchSize = 1
-		storage.batchBuffer = make([]models.URLRecord, 0, 10)
+		storage.flushSignal = make(chan struct{}, 1)
+		storage.flushDone = make(chan struct{})
 
 		// Создаем блокировку файла
 		if err := storage.acquireLock(); err != nil {
@@ -67,12 +123,16 @@ func NewMemoryStorage(storagePath string) *MemoryStorage {
 		if err := storage.restore(); err != nil {
 			logger.Logger.Error("Ошибка восстановления данных", zap.Error(err))
 		}
+		if err := storage.loadAccounts(); err != nil {
+			logger.Logger.Error("Ошибка восстановления учетных записей", zap.Error(err))
+		}
 
 		// Запускаем горутину для асинхронной записи
 		go storage.flushWorker()
 
 		logger.Logger.Info("Создано хранилище URL с сохранением в файл",
-			zap.String("path", storagePath))
+			zap.String("path", storagePath),
+			zap.String("durability", string(storage.durability)))
 	} else {
 		logger.Logger.Info("Создано хранилище URL в памяти")
 	}
@@ -80,90 +140,145 @@ func NewMemoryStorage(storagePath string) *MemoryStorage {
 	return storage
 }
 
-// Add добавляет новый URL в хранилище или возвращает существующий ID
-func (s *MemoryStorage) Add(id string, url string) (string, bool, error) {
+// Add добавляет новый URL с привязкой к пользователю и сроком действия.
+// Нулевое значение expiresAt означает бессрочную ссылку.
+//
+// В режиме DurabilitySync метод возвращает управление только после того,
+// как запись сохранена в файл и синхронизирована с диском.
+func (s *MemoryStorage) Add(ctx context.Context, id string, url string, userID string, expiresAt time.Time) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	id, done, err := s.addLocked(id, url, userID, expiresAt)
+	s.mu.Unlock()
 
-	// Проверяем, есть ли уже такой URL
-	if existingID, found := s.urlToID[url]; found {
-		return existingID, true, database.ErrURLConflict
+	if err != nil {
+		return id, err
 	}
 
-	// Добавляем новый URL
-	s.urls[id] = url
-	s.urlToID[url] = id
-
-	// Если включен режим файла, добавляем запись в очередь на сохранение
-	if s.fileMode {
-		uuid := strconv.Itoa(s.nextID)
-		s.flushQueue <- models.URLRecord{
-			UUID:        uuid,
-			ShortURL:    id,
-			OriginalURL: url,
-		}
+	// Дожидаемся записи на диск (только в синхронном режиме)
+	if err := waitPersisted(done); err != nil {
+		return "", err
 	}
 
-	s.nextID++
-	return id, false, nil
+	return id, nil
 }
 
 // Get возвращает оригинальный URL по идентификатору
-func (s *MemoryStorage) Get(id string) (string, bool) {
+func (s *MemoryStorage) Get(ctx context.Context, id string) (string, error) {
+	link, err := s.GetLink(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return link.Resolve(time.Now())
+}
+
+// GetLink возвращает сохраненную ссылку по идентификатору
+func (s *MemoryStorage) GetLink(ctx context.Context, id string) (Link, error) {
+	if err := ctx.Err(); err != nil {
+		return Link{}, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// Проверяем, не удален ли URL
-	if s.deletedURLs[id] {
-		return "", false
+	url, ok := s.urls[id]
+	if !ok {
+		return Link{}, ErrNotFound
 	}
 
-	url, ok := s.urls[id]
-	return url, ok
+	return Link{
+		OriginalURL: url,
+		ExpiresAt:   s.expiresAt[id],
+		Deleted:     s.deletedURLs[id],
+		Disabled:    s.disabledURLs[id],
+	}, nil
 }
 
-// FindByOriginalURL ищет ID по оригинальному URL
-func (s *MemoryStorage) FindByOriginalURL(url string) (string, bool) {
+// FindByOriginalURL ищет ID ссылки на URL в области дедупликации пользователя
+func (s *MemoryStorage) FindByOriginalURL(ctx context.Context, url string, userID string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	key, ok := s.dedup.key(userID, url)
+	if !ok {
+		return "", ErrNotFound
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	id, ok := s.urlToID[url]
-	return id, ok
+	id, ok := s.dedupIndex[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return id, nil
 }
 
-// AddWithUser добавляет новый URL в хранилище с привязкой к пользователю
-func (s *MemoryStorage) AddWithUser(id string, url string, userID string) (string, bool, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// addLocked добавляет URL, вызывающий должен удерживать мьютекс.
+// Возвращает канал ожидания записи на диск (nil, если ждать не нужно).
+func (s *MemoryStorage) addLocked(id string, url string, userID string, expiresAt time.Time) (string, <-chan error, error) {
+	if s.closed {
+		return "", nil, ErrStorageClosed
+	}
+
+	// Проверяем, есть ли уже такой URL в области дедупликации
+	key, dedup := s.dedup.key(userID, url)
+	if existingID, found := s.dedupIndex[key]; dedup && found {
+		return existingID, nil, urlConflict()
+	}
 
-	// Проверяем, есть ли уже такой URL
-	if existingID, found := s.urlToID[url]; found {
-		return existingID, true, database.ErrURLConflict
+	// Проверяем, не занят ли короткий ID
+	if _, taken := s.urls[id]; taken {
+		return "", nil, shortIDConflict()
 	}
 
 	// Добавляем новый URL
 	s.urls[id] = url
-	s.urlToID[url] = id
+	if dedup {
+		s.dedupIndex[key] = id
+	}
 
 	// Добавляем URL к пользователю
-	s.userURLs[userID] = append(s.userURLs[userID], id)
+	if userID != "" {
+		s.userURLs[userID] = append(s.userURLs[userID], id)
+	}
+
+	// Запоминаем момент создания и срок действия ссылки
+	created := time.Now().UTC()
+	s.createdAt[id] = created
+	var expiry *time.Time
+	if !expiresAt.IsZero() {
+		s.expiresAt[id] = expiresAt
+		expiry = &expiresAt
+	}
 
 	// Если включен режим файла, добавляем запись в очередь на сохранение
+	var done <-chan error
 	if s.fileMode {
-		uuid := strconv.Itoa(s.nextID)
-		s.flushQueue <- models.URLRecord{
-			UUID:        uuid,
+		done = s.enqueueLocked(models.URLRecord{
+			UUID:        strconv.Itoa(s.nextID),
 			ShortURL:    id,
 			OriginalURL: url,
-		}
+			ExpiresAt:   expiry,
+			CreatedAt:   &created,
+			UserID:      userID,
+		})
 	}
 
 	s.nextID++
-	return id, false, nil
+	return id, done, nil
 }
 
 // GetUserURLs возвращает все URL пользователя (исключая удаленные)
-func (s *MemoryStorage) GetUserURLs(userID string) ([]models.UserURL, error) {
+func (s *MemoryStorage) GetUserURLs(ctx context.Context, userID string) ([]models.UserURL, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -173,9 +288,10 @@ func (s *MemoryStorage) GetUserURLs(userID string) ([]models.UserURL, error) {
 	}
 
 	result := make([]models.UserURL, 0, len(shortURLs))
+	now := time.Now()
 	for _, shortURL := range shortURLs {
-		// Пропускаем удаленные URL
-		if s.deletedURLs[shortURL] {
+		// Пропускаем удаленные URL и URL с истекшим сроком действия
+		if s.deletedURLs[shortURL] || s.isExpiredLocked(shortURL, now) {
 			continue
 		}
 
@@ -221,6 +337,14 @@ func (s *MemoryStorage) acquireLock() error {
 
 // restore восстанавливает данные из файла
 func (s *MemoryStorage) restore() error {
+	// Загружаем снимок, если он есть: из журнала воспроизводятся
+	// только записи, не вошедшие в снимок
+	lastSeq, err := s.loadSnapshot()
+	if err != nil {
+		return err
+	}
+	s.nextID = lastSeq + 1
+
 	// Проверяем существование файла
 	if _, err := os.Stat(s.storagePath); os.IsNotExist(err) {
 		logger.Logger.Info("Файл хранения не найден, создаем новое хранилище",
@@ -228,81 +352,126 @@ func (s *MemoryStorage) restore() error {
 		return nil
 	}
 
-	// Открываем файл
-	file, err := os.Open(s.storagePath)
-	if err != nil {
-		return fmt.Errorf("ошибка открытия файла хранения: %w", err)
-	}
-	defer file.Close()
-
-	// Создаем сканер для чтения файла построчно
-	scanner := bufio.NewScanner(file)
-	maxID := 0
+	maxID := lastSeq
+	tail := 0
 
-	// Читаем и обрабатываем каждую строку
-	for scanner.Scan() {
-		line := scanner.Text()
-		var record models.URLRecord
-
-		// Декодируем JSON
-		if err := json.Unmarshal([]byte(line), &record); err != nil {
-			logger.Logger.Error("Ошибка декодирования записи URL",
-				zap.String("line", line),
-				zap.Error(err))
-			continue
+	// Читаем журнал, обрезая оборванную при сбое последнюю запись
+	report, err := s.readLog(func(record models.URLRecord) {
+		// Пропускаем записи, уже вошедшие в снимок
+		id := parseID(record.UUID)
+		if lastSeq > 0 && id <= lastSeq {
+			return
 		}
 
-		// Сохраняем URL в памяти
-		s.urls[record.ShortURL] = record.OriginalURL
-		s.urlToID[record.OriginalURL] = record.ShortURL
+		// Воспроизводим событие журнала
+		s.applyRecord(record)
+		tail++
 
 		// Обновляем счетчик ID
-		if id := parseID(record.UUID); id > maxID {
+		if id > maxID {
 			maxID = id
 		}
-	}
-
-	// Проверяем ошибки сканирования
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("ошибка чтения файла хранения: %w", err)
+	})
+	s.recovery = report
+	if err != nil {
+		return err
 	}
 
 	// Устанавливаем следующий ID
 	s.nextID = maxID + 1
+	s.logRecords.Store(int64(tail))
 
 	logger.Logger.Info("Данные успешно восстановлены из файла",
 		zap.Int("records", len(s.urls)),
+		zap.Int("deleted", len(s.deletedURLs)),
+		zap.Int("log_records", tail),
+		zap.Int("skipped_lines", report.SkippedLines),
+		zap.Bool("torn_tail", report.TornTail),
 		zap.String("path", s.storagePath))
 
 	return nil
 }
 
-// flushWorker асинхронно записывает URL в файл
+// applyRecord применяет запись журнала к данным в памяти.
+// Вызывается при восстановлении, вызывающий отвечает за синхронизацию.
+func (s *MemoryStorage) applyRecord(record models.URLRecord) {
+	switch record.Event {
+	case "", models.URLRecordEventAdd:
+		s.urls[record.ShortURL] = record.OriginalURL
+		// Индекс строится по текущей области дедупликации:
+		// при дубликатах побеждает первая ссылка
+		if key, ok := s.dedup.key(record.UserID, record.OriginalURL); ok {
+			if _, exists := s.dedupIndex[key]; !exists {
+				s.dedupIndex[key] = record.ShortURL
+			}
+		}
+		if record.ExpiresAt != nil {
+			s.expiresAt[record.ShortURL] = *record.ExpiresAt
+		}
+		if record.CreatedAt != nil {
+			s.createdAt[record.ShortURL] = *record.CreatedAt
+		}
+		if record.UserID != "" {
+			s.userURLs[record.UserID] = append(s.userURLs[record.UserID], record.ShortURL)
+		}
+	case models.URLRecordEventDelete:
+		s.deletedURLs[record.ShortURL] = true
+	case models.URLRecordEventReassign:
+		s.reassignLocked(record.PreviousUserID, record.UserID)
+	case models.URLRecordEventMove:
+		s.moveLocked(record.ShortURL, record.PreviousUserID, record.UserID)
+	case models.URLRecordEventDisable:
+		s.disabledURLs[record.ShortURL] = true
+	case models.URLRecordEventEnable:
+		delete(s.disabledURLs, record.ShortURL)
+	case models.URLRecordEventPurge:
+		s.purgeLocked(record.ShortURL, record.UserID)
+	case models.URLRecordEventCounter:
+		s.idCounter = max(s.idCounter, record.Counter)
+	default:
+		logger.Logger.Warn("Неизвестный тип записи в файле хранения",
+			zap.String("event", record.Event),
+			zap.String("short_url", record.ShortURL))
+	}
+}
+
+// flushWorker асинхронно записывает URL в файл.
+//
+// Записи, накопившиеся в очереди, записываются одним пакетом (group commit):
+// в режимах DurabilitySync и DurabilityBatch один fsync подтверждает
+// сразу все записи пакета.
 func (s *MemoryStorage) flushWorker() {
-	for record := range s.flushQueue {
-		s.batchMu.Lock()
-		// Добавляем запись в буфер
-		s.batchBuffer = append(s.batchBuffer, record)
+	defer close(s.flushDone)
 
-		// Если буфер заполнен, записываем пакет
-		if len(s.batchBuffer) >= s.batchSize {
-			if err := s.writeBatch(s.batchBuffer); err != nil {
-				logger.Logger.Error("Ошибка записи пакета URL в файл", zap.Error(err))
-			}
-			// Очищаем буфер
-			s.batchBuffer = make([]models.URLRecord, 0, s.batchSize)
+	// После закрытия сигнального канала дописываем оставшиеся записи
+	for range s.flushSignal {
+		s.flushPending()
+	}
+	s.flushPending()
+}
+
+// flushPending записывает пакетами все записи, находящиеся в очереди
+func (s *MemoryStorage) flushPending() {
+	for batch := s.takeBatch(); len(batch) > 0; batch = s.takeBatch() {
+		records := make([]models.URLRecord, len(batch))
+		for i, r := range batch {
+			records[i] = r.record
 		}
+
+		s.batchMu.Lock()
+		err := s.writeBatch(records)
 		s.batchMu.Unlock()
-	}
+		if err != nil {
+			logger.Logger.Error("Ошибка записи пакета URL в файл", zap.Error(err))
+		}
 
-	// Записываем оставшиеся записи при завершении
-	s.batchMu.Lock()
-	if len(s.batchBuffer) > 0 {
-		if err := s.writeBatch(s.batchBuffer); err != nil {
-			logger.Logger.Error("Ошибка записи оставшихся URL в файл", zap.Error(err))
+		// Сообщаем ожидающим результат записи
+		for _, r := range batch {
+			if r.done != nil {
+				r.done <- err
+			}
 		}
 	}
-	s.batchMu.Unlock()
 }
 
 // writeBatch записывает пакет записей в файл
@@ -344,27 +513,64 @@ func (s *MemoryStorage) writeBatch(records []models.URLRecord) error {
 		}
 	}
 
-	// Сбрасываем буфер на диск
+	// Сбрасываем буфер в файл
 	if err := writer.Flush(); err != nil {
 		return fmt.Errorf("ошибка сброса буфера: %w", err)
 	}
 
+	// Синхронизируем файл с диском, если этого требует режим надежности
+	if s.durability != DurabilityAsync {
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("ошибка синхронизации файла: %w", err)
+		}
+	}
+
 	logger.Logger.Info("Записан пакет URL в файл",
 		zap.Int("count", len(records)),
 		zap.String("path", s.storagePath))
 
+	// Проверяем, не пора ли компактировать журнал
+	s.logRecords.Add(int64(len(records)))
+	s.maybeCompact()
+
 	return nil
 }
 
 // DeleteUserURLs помечает URL как удаленные для указанного пользователя
-func (s *MemoryStorage) DeleteUserURLs(userID string, shortURLs []string) error {
+func (s *MemoryStorage) DeleteUserURLs(ctx context.Context, userID string, shortURLs []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	pending, err := s.deleteLocked(userID, shortURLs)
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	// Дожидаемся записи на диск (только в синхронном режиме)
+	for _, done := range pending {
+		if err := waitPersisted(done); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteLocked помечает URL пользователя как удаленные, вызывающий должен удерживать мьютекс.
+// Возвращает каналы ожидания записи на диск.
+func (s *MemoryStorage) deleteLocked(userID string, shortURLs []string) ([]<-chan error, error) {
+	if s.closed {
+		return nil, ErrStorageClosed
+	}
 
 	// Получаем список URL пользователя
 	userShortURLs, exists := s.userURLs[userID]
 	if !exists {
-		return nil // Пользователь не найден, ничего не делаем
+		return nil, nil // Пользователь не найден, ничего не делаем
 	}
 
 	// Создаем карту URL пользователя для быстрого поиска
@@ -374,25 +580,88 @@ func (s *MemoryStorage) DeleteUserURLs(userID string, shortURLs []string) error
 	}
 
 	// Помечаем URL как удаленные только если они принадлежат пользователю
+	var pending []<-chan error
 	for _, shortURL := range shortURLs {
-		if userURLMap[shortURL] {
-			s.deletedURLs[shortURL] = true
+		if !userURLMap[shortURL] || s.deletedURLs[shortURL] {
+			continue
+		}
+		s.deletedURLs[shortURL] = true
+
+		// Сохраняем запись об удалении, чтобы она пережила перезапуск
+		if s.fileMode {
+			done := s.enqueueLocked(models.URLRecord{
+				UUID:     strconv.Itoa(s.nextID),
+				ShortURL: shortURL,
+				Event:    models.URLRecordEventDelete,
+				UserID:   userID,
+			})
+			if done != nil {
+				pending = append(pending, done)
+			}
+			s.nextID++
 		}
 	}
 
-	return nil
+	return pending, nil
 }
 
-// IsDeleted проверяет, помечен ли URL как удаленный
-func (s *MemoryStorage) IsDeleted(shortURL string) (bool, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// PurgeExpired помечает как удаленные все URL, срок действия которых истек к моменту now.
+// Удаление сохраняется в журнал, как и при DeleteUserURLs.
+// Возвращает количество помеченных URL.
+func (s *MemoryStorage) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
 
-	return s.deletedURLs[shortURL], nil
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrStorageClosed
+	}
+
+	var owners map[string]string
+	var pending []<-chan error
+	purged := 0
+	for shortURL := range s.expiresAt {
+		if s.deletedURLs[shortURL] || !s.isExpiredLocked(shortURL, now) {
+			continue
+		}
+		s.deletedURLs[shortURL] = true
+		purged++
+
+		if s.fileMode {
+			if owners == nil {
+				owners = s.ownersLocked()
+			}
+			pending = s.journalLocked(pending, models.URLRecord{
+				ShortURL: shortURL,
+				Event:    models.URLRecordEventDelete,
+				UserID:   owners[shortURL],
+			})
+		}
+	}
+	s.mu.Unlock()
+
+	// Дожидаемся записи на диск (только в синхронном режиме)
+	if err := waitAllPersisted(pending); err != nil {
+		return purged, err
+	}
+
+	return purged, nil
+}
+
+// isExpiredLocked проверяет срок действия URL, вызывающий должен удерживать мьютекс
+func (s *MemoryStorage) isExpiredLocked(shortURL string, now time.Time) bool {
+	expiresAt, ok := s.expiresAt[shortURL]
+	return ok && !now.Before(expiresAt)
 }
 
 // Stats возвращает статистику хранилища
-func (s *MemoryStorage) Stats() (Stats, error) {
+func (s *MemoryStorage) Stats(ctx context.Context) (Stats, error) {
+	if err := ctx.Err(); err != nil {
+		return Stats{}, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -412,8 +681,21 @@ func (s *MemoryStorage) Stats() (Stats, error) {
 func (s *MemoryStorage) Close() error {
 	// Если работаем с файлом, закрываем файловые ресурсы
 	if s.fileMode {
-		// Закрываем канал flush
-		close(s.flushQueue)
+		// Запрещаем новые записи и останавливаем flushWorker
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil
+		}
+		s.closed = true
+		close(s.flushSignal)
+		s.mu.Unlock()
+
+		// Дожидаемся записи оставшихся событий
+		<-s.flushDone
+
+		// Дожидаемся завершения фонового компактирования
+		s.compactWG.Wait()
 
 		// Снимаем блокировку файла
 		if s.fileLock != nil {
@@ -440,3 +722,43 @@ func parseID(id string) int {
 	}
 	return result
 }
+
+// AddBatch добавляет пакет URL под одним захватом мьютекса.
+//
+// Конфликт по короткому ID отражается статусом RecordFailed записи.
+// В режиме DurabilitySync метод дожидается сохранения всех записей на диск;
+// запись, которую не удалось сохранить, также получает статус RecordFailed.
+func (s *MemoryStorage) AddBatch(ctx context.Context, records []Record, userID string) ([]RecordResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]RecordResult, len(records))
+	dones := make([]<-chan error, len(records))
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrStorageClosed
+	}
+	for i, record := range records {
+		id, done, err := s.addLocked(record.ID, record.OriginalURL, userID, record.ExpiresAt)
+		result, err := recordResult(id, err)
+		if err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+		results[i] = result
+		dones[i] = done
+	}
+	s.mu.Unlock()
+
+	// Дожидаемся записи на диск (только в синхронном режиме)
+	for i, done := range dones {
+		if err := waitPersisted(done); err != nil {
+			results[i] = RecordResult{Status: RecordFailed, Err: err}
+		}
+	}
+
+	return results, nil
+}
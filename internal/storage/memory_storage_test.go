@@ -0,0 +1,526 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/database"
+	"github.com/Adigezalov/shortener/internal/logger"
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+func TestMemoryStorage_RestoreOwnershipAndDeletions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorage(path)
+	_, err := store.Add(ctx, "abc123", "https://example.com/1", "user1", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Add(ctx, "def456", "https://example.com/2", "user1", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Add(ctx, "ghi789", "https://example.com/3", "user2", time.Time{})
+	require.NoError(t, err)
+
+	// Чужой URL не должен удаляться
+	require.NoError(t, store.DeleteUserURLs(ctx, "user1", []string{"abc123", "ghi789"}))
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+
+	urls, err := restored.GetUserURLs(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, urls, 1)
+	assert.Equal(t, "def456", urls[0].ShortURL)
+
+	_, err = restored.Get(ctx, "abc123")
+	assert.ErrorIs(t, err, ErrGone)
+
+	_, err = restored.Get(ctx, "ghi789")
+	assert.NoError(t, err)
+
+	urls, err = restored.GetUserURLs(ctx, "user2")
+	require.NoError(t, err)
+	assert.Len(t, urls, 1)
+}
+
+func TestMemoryStorage_PurgeExpiredSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+	now := time.Now().UTC()
+
+	store := NewMemoryStorageWithOptions(path, Options{Durability: DurabilitySync})
+	_, err := store.Add(ctx, "abc123", "https://example.com/1", "user1", now.Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = store.Add(ctx, "def456", "https://example.com/2", "user1", now.Add(time.Hour))
+	require.NoError(t, err)
+
+	purged, err := store.PurgeExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	stats, err := store.Stats(ctx)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+
+	// Удаление восстановлено из журнала: повторная очистка ничего не находит
+	purged, err = restored.PurgeExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	_, err = restored.Get(ctx, "abc123")
+	assert.ErrorIs(t, err, ErrGone)
+	urls, err := restored.GetUserURLs(ctx, "user1")
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Equal(t, "def456", urls[0].ShortURL)
+
+	restoredStats, err := restored.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, stats, restoredStats)
+}
+
+func TestMemoryStorage_RestoreLegacyFormat(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	// Файл в формате до появления владельцев и записей удаления
+	legacy := `{"uuid":"1","short_url":"abc123","original_url":"https://example.com/1"}
+{"uuid":"2","short_url":"def456","original_url":"https://example.com/2"}
+`
+	require.NoError(t, os.WriteFile(path, []byte(legacy), 0644))
+
+	store := NewMemoryStorage(path)
+	defer store.Close()
+
+	originalURL, err := store.Get(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", originalURL)
+
+	// Новый ID не должен пересекаться с восстановленными записями
+	assert.Equal(t, 3, store.nextID)
+}
+
+func TestMemoryStorage_CompactAndRestore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorage(path)
+	_, err := store.Add(ctx, "abc123", "https://example.com/1", "user1", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Add(ctx, "def456", "https://example.com/2", "user1", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteUserURLs(ctx, "user1", []string{"abc123"}))
+
+	// Дожидаемся записи журнала, чтобы компактирование видело все события
+	require.Eventually(t, func() bool { return store.logRecords.Load() == 3 },
+		time.Second, 10*time.Millisecond)
+
+	result, err := store.Compact()
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.TailRecords)
+	assert.Equal(t, 3, result.LastSeq)
+
+	// Записи после снимка попадают в хвост журнала
+	_, err = store.Add(ctx, "ghi789", "https://example.com/3", "user1", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+
+	urls, err := restored.GetUserURLs(ctx, "user1")
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+	assert.Equal(t, "def456", urls[0].ShortURL)
+	assert.Equal(t, "ghi789", urls[1].ShortURL)
+
+	_, err = restored.Get(ctx, "abc123")
+	assert.ErrorIs(t, err, ErrGone)
+
+	assert.Equal(t, int64(1), restored.logRecords.Load())
+	assert.Equal(t, 5, restored.nextID)
+}
+
+func TestMemoryStorage_AutoCompaction(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorageWithOptions(path, Options{CompactionThreshold: 2})
+	_, err := store.Add(ctx, "abc123", "https://example.com/1", "user1", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Add(ctx, "def456", "https://example.com/2", "user1", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	_, err = os.Stat(store.snapshotPath())
+	require.NoError(t, err)
+
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+
+	urls, err := restored.GetUserURLs(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+}
+
+func TestMemoryStorage_CompactWithoutFile(t *testing.T) {
+	store := NewMemoryStorage("")
+
+	_, err := store.Compact()
+	assert.ErrorIs(t, err, ErrCompactionUnsupported)
+}
+
+func TestMemoryStorage_SyncDurabilityPersistsBeforeReturn(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorageWithOptions(path, Options{Durability: DurabilitySync})
+	defer store.Close()
+
+	_, err := store.Add(ctx, "abc123", "https://example.com/1", "user1", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteUserURLs(ctx, "user1", []string{"abc123"}))
+
+	// Запись должна быть в файле сразу после возврата, без Close
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"short_url":"abc123"`)
+	assert.Contains(t, string(data), `"event":"delete"`)
+}
+
+func TestMemoryStorage_SlowFlushDoesNotBlockWrites(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorageWithOptions(path, Options{Durability: DurabilityAsync})
+
+	// Имитируем медленную запись на диск: воркер не может записать пакет,
+	// пока batchMu занят, а очередь журнала растет сверх размера пакета
+	const total = 3 * flushBatchLimit
+	store.batchMu.Lock()
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < total; i++ {
+			id := fmt.Sprintf("id%d", i)
+			if _, err := store.Add(ctx, id, "https://example.com/"+id, "user1", time.Time{}); err != nil {
+				done <- err
+				return
+			}
+		}
+		_, err := store.Get(ctx, "id0")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		store.batchMu.Unlock()
+		t.Fatal("запись заблокирована медленным сохранением журнала")
+	}
+	store.batchMu.Unlock()
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+	urls, err := restored.GetUserURLs(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, urls, total)
+}
+
+func TestMemoryStorage_CrashMidWrite(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "storage.json")
+
+	store := NewMemoryStorageWithOptions(path, Options{Durability: DurabilitySync})
+	_, err := store.Add(ctx, "abc123", "https://example.com/1", "user1", time.Time{})
+	require.NoError(t, err)
+	_, err = store.Add(ctx, "def456", "https://example.com/2", "user1", time.Time{})
+	require.NoError(t, err)
+
+	// Имитируем сбой: копируем файл в момент, когда следующая запись
+	// успела попасть на диск лишь частично, и не вызываем Close
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	torn := `{"uuid":"3","short_url":"ghi789","original_u`
+	crashed := filepath.Join(dir, "crashed.json")
+	require.NoError(t, os.WriteFile(crashed, append(data, torn...), 0644))
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorageWithOptions(crashed, Options{Durability: DurabilitySync})
+
+	report := restored.Recovery()
+	assert.True(t, report.TornTail)
+	assert.Equal(t, int64(len(torn)), report.TruncatedBytes)
+	assert.Equal(t, 2, report.Records)
+
+	urls, err := restored.GetUserURLs(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+
+	// Файл обрезан до последней целой записи
+	after, err := os.ReadFile(crashed)
+	require.NoError(t, err)
+	assert.Equal(t, data, after)
+
+	// Новые записи продолжают журнал с корректной строки
+	_, err = restored.Add(ctx, "ghi789", "https://example.com/3", "user1", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, restored.Close())
+
+	again := NewMemoryStorage(crashed)
+	defer again.Close()
+
+	assert.False(t, again.Recovery().TornTail)
+	urls, err = again.GetUserURLs(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, urls, 3)
+}
+
+func TestMemoryStorage_CrashKeepsCompleteLastRecord(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	// Последняя запись целая, но перевод строки не успел записаться
+	content := `{"uuid":"1","short_url":"abc123","original_url":"https://example.com/1"}
+{"uuid":"2","short_url":"def456","original_url":"https://example.com/2"}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	store := NewMemoryStorage(path)
+	defer store.Close()
+
+	assert.False(t, store.Recovery().TornTail)
+	_, err := store.Get(ctx, "def456")
+	assert.NoError(t, err)
+
+	// Следующая запись не должна склеиться с последней строкой
+	_, err = store.Add(ctx, "ghi789", "https://example.com/3", "user1", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+
+	assert.Equal(t, 0, restored.Recovery().SkippedLines)
+	_, err = restored.Get(ctx, "ghi789")
+	assert.NoError(t, err)
+}
+
+func TestMemoryStorage_ReserveCounter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorageWithOptions(path, Options{Durability: DurabilitySync})
+	start, err := store.ReserveCounter(ctx, 100)
+	require.NoError(t, err)
+	assert.Zero(t, start)
+	_, err = store.Add(ctx, "0", "https://example.com/1", "user1", time.Time{})
+	require.NoError(t, err)
+	start, err = store.ReserveCounter(ctx, 100)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, start)
+
+	// Удаление ссылок не уменьшает границу
+	_, err = store.PurgeLinks(ctx, []string{"0"})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStorage(path)
+	start, err = restored.ReserveCounter(ctx, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 200, start)
+
+	// Граница сохраняется в снимке
+	_, err = restored.Compact()
+	require.NoError(t, err)
+	require.NoError(t, restored.Close())
+	compacted := NewMemoryStorage(path)
+	defer compacted.Close()
+	start, err = compacted.ReserveCounter(ctx, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 200, start)
+
+	// Перенос поднимает границу целевого хранилища
+	target := NewMemoryStorage("")
+	defer target.Close()
+	_, err = Migrate(ctx, compacted, target, MigrateOptions{})
+	require.NoError(t, err)
+	start, err = target.ReserveCounter(ctx, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 200, start)
+}
+
+func TestMemoryStorage_ReserveCounterLegacyJournal(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	// В журнале без событий counter граница оценивается количеством записей
+	content := `{"uuid":"1","short_url":"0","original_url":"https://example.com/1"}
+{"uuid":"2","short_url":"1","original_url":"https://example.com/2"}
+{"uuid":"3","short_url":"1","original_url":"","event":"delete"}
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	store := NewMemoryStorage(path)
+	defer store.Close()
+	start, err := store.ReserveCounter(ctx, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, start)
+}
+
+func TestMemoryStorage_CloseRejectsWrites(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store := NewMemoryStorage(path)
+	require.NoError(t, store.Close())
+
+	_, err := store.Add(ctx, "abc123", "https://example.com/1", "user1", time.Time{})
+	assert.ErrorIs(t, err, ErrStorageClosed)
+	assert.NoError(t, store.Close())
+}
+
+func TestMemoryStorage_TypedErrors(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage("")
+	defer store.Close()
+
+	_, err := store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = store.Add(ctx, "abc123", "https://example.com/1", "user1", time.Time{})
+	require.NoError(t, err)
+
+	existingID, err := store.Add(ctx, "other", "https://example.com/1", "user1", time.Time{})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorIs(t, err, database.ErrURLConflict)
+	assert.Equal(t, "abc123", existingID)
+
+	_, err = store.Add(ctx, "abc123", "https://example.com/2", "user1", time.Time{})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorIs(t, err, database.ErrShortIDConflict)
+
+	_, err = store.Add(ctx, "expired", "https://example.com/3", "user1", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "expired")
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.ErrorIs(t, err, ErrGone)
+
+	require.NoError(t, store.DeleteUserURLs(ctx, "user1", []string{"abc123"}))
+	_, err = store.Get(ctx, "abc123")
+	assert.ErrorIs(t, err, ErrGone)
+	assert.NotErrorIs(t, err, ErrExpired)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = store.Get(canceled, "abc123")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMemoryStorage_DedupScopes(t *testing.T) {
+	tests := []struct {
+		name      string
+		scope     DedupScope
+		otherUser bool // второй пользователь получает существующую ссылку
+		sameUser  bool // тот же пользователь получает существующую ссылку
+	}{
+		{name: "global", scope: DedupGlobal, otherUser: true, sameUser: true},
+		{name: "per-user", scope: DedupPerUser, otherUser: false, sameUser: true},
+		{name: "none", scope: DedupNone, otherUser: false, sameUser: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "storage.json")
+			store := NewMemoryStorageWithOptions(path, Options{Dedup: tt.scope, Durability: DurabilitySync})
+
+			_, err := store.Add(ctx, "alice1", "https://example.com", "alice", time.Time{})
+			require.NoError(t, err)
+
+			id, err := store.Add(ctx, "bob1", "https://example.com", "bob", time.Time{})
+			if tt.otherUser {
+				assert.ErrorIs(t, err, database.ErrURLConflict)
+				assert.Equal(t, "alice1", id)
+			} else {
+				require.NoError(t, err)
+			}
+
+			id, err = store.Add(ctx, "alice2", "https://example.com", "alice", time.Time{})
+			if tt.sameUser {
+				assert.ErrorIs(t, err, database.ErrURLConflict)
+				assert.Equal(t, "alice1", id)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, store.Close())
+
+			// После перезапуска индекс дедупликации восстанавливается
+			restored := NewMemoryStorageWithOptions(path, Options{Dedup: tt.scope})
+			defer restored.Close()
+
+			id, err = restored.FindByOriginalURL(ctx, "https://example.com", "alice")
+			if tt.scope == DedupNone {
+				assert.ErrorIs(t, err, ErrNotFound)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "alice1", id)
+			}
+
+			urls, err := restored.GetUserURLs(ctx, "bob")
+			require.NoError(t, err)
+			if tt.otherUser {
+				assert.Empty(t, urls, "чужая ссылка не попадает в список пользователя")
+			} else {
+				assert.Equal(t, []models.UserURL{{ShortURL: "bob1", OriginalURL: "https://example.com"}}, urls)
+			}
+		})
+	}
+}
+
+func TestMemoryStorage_DedupPerUserWithoutOwner(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	// Ссылка без владельца из журнала, созданного с глобальной дедупликацией
+	content := `{"uuid":"1","short_url":"legacy","original_url":"https://example.com"}
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	store := NewMemoryStorageWithOptions(path, Options{Dedup: DedupPerUser})
+	defer store.Close()
+
+	// Сокращения без владельца не совпадают ни между собой, ни со старой ссылкой
+	id, err := store.Add(ctx, "anon1", "https://example.com", "", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "anon1", id)
+	id, err = store.Add(ctx, "anon2", "https://example.com", "", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "anon2", id)
+
+	_, err = store.FindByOriginalURL(ctx, "https://example.com", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Пользователь по-прежнему получает свою ссылку
+	_, err = store.Add(ctx, "alice1", "https://example.com", "alice", time.Time{})
+	require.NoError(t, err)
+	id, err = store.Add(ctx, "alice2", "https://example.com", "alice", time.Time{})
+	assert.ErrorIs(t, err, database.ErrURLConflict)
+	assert.Equal(t, "alice1", id)
+}
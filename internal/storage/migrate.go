@@ -0,0 +1,412 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// ErrMigrationUnsupported возвращается, когда хранилище не поддерживает перенос ссылок
+var ErrMigrationUnsupported = errors.New("хранилище не поддерживает перенос ссылок")
+
+// Параметры переноса по умолчанию
+const (
+	DefaultMigrateBatchSize = 500 // Количество ссылок, записываемых одной операцией
+	DefaultMigrateSamples   = 100 // Количество ссылок, сверяемых при проверке
+)
+
+// RecordStorage интерфейс переноса ссылок между хранилищами
+// (shortener storage migrate). Реализуется MemoryStorage и DatabaseStorage.
+//
+// Ссылка передается записью models.URLRecord добавления (короткий ID,
+// оригинальный URL, владелец, моменты создания и истечения срока действия),
+// за которой следуют записи событий delete и disable, если ссылка удалена
+// или отключена, - как в снимке файлового хранилища.
+type RecordStorage interface {
+	// ScanRecords передает в fn записи ссылок с короткими ID больше after
+	// в порядке короткого ID, частями не более чем по limit ссылок.
+	// Ошибка fn прерывает просмотр и возвращается вызывающему.
+	ScanRecords(ctx context.Context, after string, limit int, fn func([]models.URLRecord) error) error
+
+	// RestoreRecords сохраняет ссылки из записей с их короткими ID,
+	// владельцами, моментами создания и состоянием и возвращает результат
+	// каждой ссылки в порядке записей добавления. Ссылка, уже сохраненная с тем же
+	// оригинальным URL, не изменяется (RecordExisting), поэтому повторный
+	// перенос безопасен; короткий ID, занятый другим URL, дает RecordFailed.
+	// Если оригинальный URL уже сокращен в области дедупликации другой
+	// ссылкой, новая ссылка исключается из дедупликации.
+	RestoreRecords(ctx context.Context, records []models.URLRecord) ([]RecordResult, error)
+}
+
+// linkRecords возвращает записи переноса ссылки
+func linkRecords(link LinkInfo) []models.URLRecord {
+	record := models.URLRecord{
+		ShortURL:    link.ShortID,
+		OriginalURL: link.OriginalURL,
+		UserID:      link.UserID,
+	}
+	if !link.CreatedAt.IsZero() {
+		createdAt := link.CreatedAt
+		record.CreatedAt = &createdAt
+	}
+	if !link.ExpiresAt.IsZero() {
+		expiresAt := link.ExpiresAt
+		record.ExpiresAt = &expiresAt
+	}
+
+	records := []models.URLRecord{record}
+	if link.Deleted {
+		records = append(records, models.URLRecord{ShortURL: link.ShortID, Event: models.URLRecordEventDelete})
+	}
+	if link.Disabled {
+		records = append(records, models.URLRecord{ShortURL: link.ShortID, Event: models.URLRecordEventDisable})
+	}
+	return records
+}
+
+// recordLinks собирает ссылки из записей переноса (см. RecordStorage)
+func recordLinks(records []models.URLRecord) ([]LinkInfo, error) {
+	links := make([]LinkInfo, 0, len(records))
+	index := make(map[string]int, len(records))
+	for _, record := range records {
+		if record.Event == "" || record.Event == models.URLRecordEventAdd {
+			if record.ShortURL == "" || record.OriginalURL == "" {
+				return nil, fmt.Errorf("запись добавления без короткого ID или оригинального URL: %q", record.ShortURL)
+			}
+			link := LinkInfo{ShortID: record.ShortURL, OriginalURL: record.OriginalURL, UserID: record.UserID}
+			if record.CreatedAt != nil {
+				link.CreatedAt = *record.CreatedAt
+			}
+			if record.ExpiresAt != nil {
+				link.ExpiresAt = *record.ExpiresAt
+			}
+			index[record.ShortURL] = len(links)
+			links = append(links, link)
+			continue
+		}
+
+		i, ok := index[record.ShortURL]
+		if !ok {
+			return nil, fmt.Errorf("событие %s для ссылки %q без записи добавления", record.Event, record.ShortURL)
+		}
+		switch record.Event {
+		case models.URLRecordEventDelete:
+			links[i].Deleted = true
+		case models.URLRecordEventDisable:
+			links[i].Disabled = true
+		default:
+			return nil, fmt.Errorf("событие %s не поддерживается при переносе", record.Event)
+		}
+	}
+	return links, nil
+}
+
+// scanLinks передает в fn ссылки с короткими ID больше after частями по limit
+func scanLinks(links []LinkInfo, after string, limit int, fn func([]models.URLRecord) error) error {
+	sort.Slice(links, func(i, j int) bool { return links[i].ShortID < links[j].ShortID })
+	start := sort.Search(len(links), func(i int) bool { return links[i].ShortID > after })
+
+	for start < len(links) {
+		end := min(start+limit, len(links))
+		records := make([]models.URLRecord, 0, end-start)
+		for _, link := range links[start:end] {
+			records = append(records, linkRecords(link)...)
+		}
+		if err := fn(records); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+// MigrateOptions содержит параметры переноса ссылок
+type MigrateOptions struct {
+	// BatchSize - количество ссылок, записываемых одной операцией
+	// (0 - DefaultMigrateBatchSize)
+	BatchSize int
+
+	// After - короткий ID, после которого продолжается прерванный перенос
+	// (пустой - перенос с начала)
+	After string
+
+	// Progress вызывается после записи каждой части с накопленным итогом;
+	// report.LastID можно сохранить и передать в After при перезапуске.
+	// Ошибка Progress прерывает перенос.
+	Progress func(report MigrateReport) error
+}
+
+// MigrateReport - итог переноса ссылок
+type MigrateReport struct {
+	Created  int      // Ссылки добавлены в целевое хранилище
+	Existing int      // Ссылки были перенесены ранее
+	Failed   []string // Короткие ID, занятые в целевом хранилище другим URL
+	LastID   string   // Последний обработанный короткий ID
+}
+
+// Migrate переносит ссылки из хранилища from в хранилище to в порядке
+// короткого ID, сохраняя короткие ID, владельцев, моменты создания, сроки
+// действия и состояние (удалена, отключена). Оба хранилища должны
+// реализовывать RecordStorage.
+//
+// Уже перенесенные ссылки пропускаются, поэтому прерванный перенос можно
+// повторить целиком или продолжить с opts.After. Ссылка, короткий ID которой
+// в целевом хранилище занят другим URL, не переносится и попадает
+// в MigrateReport.Failed. Граница счетчика генератора ID целевого хранилища
+// поднимается до границы исходного (см. CounterStorage). Статистика
+// переходов и учетные записи не переносятся.
+func Migrate(ctx context.Context, from, to URLStorageV2, opts MigrateOptions) (MigrateReport, error) {
+	source, err := recordStorage(from)
+	if err != nil {
+		return MigrateReport{}, err
+	}
+	target, err := recordStorage(to)
+	if err != nil {
+		return MigrateReport{}, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultMigrateBatchSize
+	}
+
+	// Номера генератора ID, занятые перенесенными ссылками, не выдаются повторно
+	if err := raiseCounter(ctx, from, to); err != nil {
+		return MigrateReport{}, err
+	}
+
+	report := MigrateReport{LastID: opts.After}
+	err = source.ScanRecords(ctx, opts.After, opts.BatchSize, func(records []models.URLRecord) error {
+		results, err := target.RestoreRecords(ctx, records)
+		if err != nil {
+			return err
+		}
+
+		i := 0
+		for _, record := range records {
+			if record.Event != "" && record.Event != models.URLRecordEventAdd {
+				continue
+			}
+			switch results[i].Status {
+			case RecordCreated:
+				report.Created++
+			case RecordExisting:
+				report.Existing++
+			default:
+				report.Failed = append(report.Failed, record.ShortURL)
+			}
+			report.LastID = record.ShortURL
+			i++
+		}
+
+		if opts.Progress != nil {
+			return opts.Progress(report)
+		}
+		return nil
+	})
+	return report, err
+}
+
+// LinkCounts - количество ссылок хранилища по состояниям
+type LinkCounts struct {
+	Total    int // Все ссылки
+	Deleted  int // Удаленные владельцем или очисткой истекших
+	Disabled int // Отключенные модератором
+}
+
+// VerifyReport - результат проверки переноса
+type VerifyReport struct {
+	Source  LinkCounts // Количество ссылок исходного хранилища
+	Target  LinkCounts // Количество ссылок целевого хранилища
+	Sampled int        // Количество сверенных ссылок
+
+	// Mismatches - описания расхождений: количества и сверенные ссылки,
+	// отсутствующие или отличающиеся в целевом хранилище
+	Mismatches []string
+}
+
+// OK сообщает, что расхождений не найдено
+func (r VerifyReport) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// VerifyMigration сверяет хранилище to с хранилищем from после переноса:
+// сравнивает количество ссылок по состояниям и не более samples случайно
+// выбранных ссылок исходного хранилища по всем полям. Оба хранилища должны
+// реализовывать AdminStorage.
+//
+// Моменты времени сравниваются с точностью до микросекунды - точности
+// хранения в PostgreSQL.
+func VerifyMigration(ctx context.Context, from, to URLStorageV2, samples int) (VerifyReport, error) {
+	source, err := adminStorage(from)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+	target, err := adminStorage(to)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	var report VerifyReport
+	if report.Source, err = countLinks(ctx, source); err != nil {
+		return VerifyReport{}, err
+	}
+	if report.Target, err = countLinks(ctx, target); err != nil {
+		return VerifyReport{}, err
+	}
+	if report.Source != report.Target {
+		report.Mismatches = append(report.Mismatches, fmt.Sprintf(
+			"количество ссылок (всего/удалено/отключено): в исходном %d/%d/%d, в целевом %d/%d/%d",
+			report.Source.Total, report.Source.Deleted, report.Source.Disabled,
+			report.Target.Total, report.Target.Deleted, report.Target.Disabled))
+	}
+
+	// Выбираем ссылки по случайным позициям без повторов
+	offsets := make(map[int]bool, samples)
+	for len(offsets) < min(samples, report.Source.Total) {
+		offsets[rand.IntN(report.Source.Total)] = true
+	}
+	for offset := range offsets {
+		page, err := source.SearchLinks(ctx, LinkFilter{}, offset, 1)
+		if err != nil {
+			return VerifyReport{}, err
+		}
+		if len(page.Links) == 0 {
+			continue
+		}
+		want := page.Links[0]
+
+		page, err = target.SearchLinks(ctx, LinkFilter{ShortID: want.ShortID}, 0, 1)
+		if err != nil {
+			return VerifyReport{}, err
+		}
+		report.Sampled++
+		if len(page.Links) == 0 {
+			report.Mismatches = append(report.Mismatches, fmt.Sprintf("%s: нет в целевом хранилище", want.ShortID))
+			continue
+		}
+		if diff := linkDiff(want, page.Links[0]); diff != "" {
+			report.Mismatches = append(report.Mismatches, want.ShortID+": "+diff)
+		}
+	}
+
+	return report, nil
+}
+
+// countLinks подсчитывает ссылки хранилища по состояниям
+func countLinks(ctx context.Context, store AdminStorage) (LinkCounts, error) {
+	var counts LinkCounts
+	for status, count := range map[LinkStatus]*int{
+		LinkStatusAny:      &counts.Total,
+		LinkStatusDeleted:  &counts.Deleted,
+		LinkStatusDisabled: &counts.Disabled,
+	} {
+		page, err := store.SearchLinks(ctx, LinkFilter{Status: status}, 0, 0)
+		if err != nil {
+			return LinkCounts{}, err
+		}
+		*count = page.Total
+	}
+	return counts, nil
+}
+
+// linkDiff описывает отличия перенесенной ссылки got от исходной want
+func linkDiff(want, got LinkInfo) string {
+	sameTime := func(a, b time.Time) bool {
+		return a.Truncate(time.Microsecond).Equal(b.Truncate(time.Microsecond))
+	}
+
+	switch {
+	case want.OriginalURL != got.OriginalURL:
+		return fmt.Sprintf("оригинальный URL %q, ожидался %q", got.OriginalURL, want.OriginalURL)
+	case want.UserID != got.UserID:
+		return fmt.Sprintf("владелец %q, ожидался %q", got.UserID, want.UserID)
+	case !sameTime(want.CreatedAt, got.CreatedAt):
+		return fmt.Sprintf("момент создания %s, ожидался %s", got.CreatedAt, want.CreatedAt)
+	case !sameTime(want.ExpiresAt, got.ExpiresAt):
+		return fmt.Sprintf("срок действия %s, ожидался %s", got.ExpiresAt, want.ExpiresAt)
+	case want.Deleted != got.Deleted:
+		return fmt.Sprintf("удалена: %t, ожидалось %t", got.Deleted, want.Deleted)
+	case want.Disabled != got.Disabled:
+		return fmt.Sprintf("отключена: %t, ожидалось %t", got.Disabled, want.Disabled)
+	default:
+		return ""
+	}
+}
+
+// ScanRecords передает в fn записи ссылок из снимка данных в памяти
+func (s *MemoryStorage) ScanRecords(ctx context.Context, after string, limit int, fn func([]models.URLRecord) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	owners := s.ownersLocked()
+	links := make([]LinkInfo, 0, len(s.urls))
+	for id, originalURL := range s.urls {
+		if id <= after {
+			continue
+		}
+		links = append(links, LinkInfo{
+			ShortID:     id,
+			OriginalURL: originalURL,
+			UserID:      owners[id],
+			CreatedAt:   s.createdAt[id],
+			ExpiresAt:   s.expiresAt[id],
+			Deleted:     s.deletedURLs[id],
+			Disabled:    s.disabledURLs[id],
+		})
+	}
+	s.mu.RUnlock()
+
+	return scanLinks(links, after, limit, func(records []models.URLRecord) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(records)
+	})
+}
+
+// RestoreRecords сохраняет перенесенные ссылки.
+//
+// В файловом режиме ссылка сохраняется в журнал записью добавления
+// и записями models.URLRecordEventDelete и models.URLRecordEventDisable.
+func (s *MemoryStorage) RestoreRecords(ctx context.Context, records []models.URLRecord) ([]RecordResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	links, err := recordLinks(records)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrStorageClosed
+	}
+	results := make([]RecordResult, len(links))
+	var pending []<-chan error
+	for i, link := range links {
+		if originalURL, ok := s.urls[link.ShortID]; ok {
+			if originalURL == link.OriginalURL {
+				results[i] = RecordResult{ID: link.ShortID, Status: RecordExisting}
+			} else {
+				results[i] = RecordResult{Status: RecordFailed, Err: shortIDConflict()}
+			}
+			continue
+		}
+
+		for _, record := range linkRecords(link) {
+			s.applyRecord(record)
+			pending = s.journalLocked(pending, record)
+		}
+		results[i] = RecordResult{ID: link.ShortID, Status: RecordCreated}
+	}
+	s.mu.Unlock()
+
+	return results, waitAllPersisted(pending)
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMigrationSource создает хранилище со ссылками во всех состояниях
+func newMigrationSource(t *testing.T) *MemoryStorage {
+	t.Helper()
+	ctx := context.Background()
+	store := NewMemoryStorageWithOptions("", Options{Dedup: DedupNone})
+	t.Cleanup(func() { store.Close() })
+
+	addLinks(t, store, "alice", map[string]string{
+		"a1": "https://example.com/page",
+		"a2": "https://example.com/deleted",
+		"a3": "https://example.com/disabled",
+	})
+	// Тот же URL у другого пользователя: без дедупликации это отдельная ссылка
+	addLinks(t, store, "bob", map[string]string{"b1": "https://example.com/page"})
+	_, err := store.Add(ctx, "n1", "https://example.com/anonymous", "", time.Now().UTC().Add(time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteUserURLs(ctx, "alice", []string{"a2"}))
+	_, err = store.SetLinksDisabled(ctx, []string{"a3"}, true)
+	require.NoError(t, err)
+	return store
+}
+
+// allLinks возвращает все ссылки хранилища по коротким ID
+func allLinks(t *testing.T, store AdminStorage) map[string]LinkInfo {
+	t.Helper()
+	page, err := store.SearchLinks(context.Background(), LinkFilter{}, 0, 1000)
+	require.NoError(t, err)
+	links := make(map[string]LinkInfo, len(page.Links))
+	for _, link := range page.Links {
+		links[link.ShortID] = link
+	}
+	return links
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	source := newMigrationSource(t)
+	path := filepath.Join(t.TempDir(), "target.json")
+	target := NewMemoryStorageWithOptions(path, Options{Durability: DurabilitySync})
+
+	var progress []string
+	report, err := Migrate(ctx, NewInstrumentedStorage(source, BackendMemory), target, MigrateOptions{
+		BatchSize: 2,
+		Progress: func(report MigrateReport) error {
+			progress = append(progress, report.LastID)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, MigrateReport{Created: 5, LastID: "n1"}, report)
+	assert.Equal(t, []string{"a2", "b1", "n1"}, progress)
+
+	// Ссылки переносятся со всеми полями и состоянием
+	assert.Equal(t, allLinks(t, source), allLinks(t, target))
+	_, err = target.Get(ctx, "a2")
+	assert.ErrorIs(t, err, ErrGone)
+	_, err = target.Get(ctx, "a3")
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	// Первая ссылка на URL участвует в дедупликации, повторная исключается из нее
+	id, err := target.FindByOriginalURL(ctx, "https://example.com/page", "carol")
+	require.NoError(t, err)
+	assert.Equal(t, "a1", id)
+
+	verify, err := VerifyMigration(ctx, source, target, DefaultMigrateSamples)
+	require.NoError(t, err)
+	assert.True(t, verify.OK(), verify.Mismatches)
+	assert.Equal(t, LinkCounts{Total: 5, Deleted: 1, Disabled: 1}, verify.Target)
+	assert.Equal(t, 5, verify.Sampled)
+
+	// Повторный перенос ничего не меняет, продолжение пропускает начало
+	report, err = Migrate(ctx, source, target, MigrateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, MigrateReport{Existing: 5, LastID: "n1"}, report)
+	report, err = Migrate(ctx, source, target, MigrateOptions{After: "b1"})
+	require.NoError(t, err)
+	assert.Equal(t, MigrateReport{Existing: 1, LastID: "n1"}, report)
+
+	// Перенесенные ссылки сохранены в журнал целевого хранилища
+	want := allLinks(t, target)
+	require.NoError(t, target.Close())
+	restored := NewMemoryStorage(path)
+	defer restored.Close()
+	assert.Equal(t, want, allLinks(t, restored))
+}
+
+func TestMigrate_Conflicts(t *testing.T) {
+	ctx := context.Background()
+	source := newMigrationSource(t)
+	target := NewMemoryStorage("")
+	defer target.Close()
+
+	// Короткий ID занят в целевом хранилище другим URL
+	_, err := target.Add(ctx, "a1", "https://other.org", "eve", time.Time{})
+	require.NoError(t, err)
+
+	report, err := Migrate(ctx, source, target, MigrateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Created)
+	assert.Equal(t, []string{"a1"}, report.Failed)
+
+	verify, err := VerifyMigration(ctx, source, target, DefaultMigrateSamples)
+	require.NoError(t, err)
+	assert.False(t, verify.OK())
+	assert.Contains(t, verify.Mismatches, `a1: оригинальный URL "https://other.org", ожидался "https://example.com/page"`)
+
+	// Различие количества ссылок обнаруживается без сверки ссылок
+	_, err = target.Add(ctx, "x1", "https://extra.org", "", time.Time{})
+	require.NoError(t, err)
+	verify, err = VerifyMigration(ctx, source, target, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, verify.Sampled)
+	assert.Len(t, verify.Mismatches, 1)
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/logger"
+	"go.uber.org/zap"
+)
+
+// ExpiredPurger описывает хранилище, умеющее помечать истекшие URL как удаленные
+type ExpiredPurger interface {
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// Reaper периодически помечает URL с истекшим сроком действия как удаленные.
+//
+// Запускается через Start и останавливается через Stop при корректном
+// завершении работы приложения. Stop дожидается завершения текущего прохода.
+type Reaper struct {
+	purger   ExpiredPurger
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewReaper создает фоновый процесс очистки истекших URL с заданным интервалом
+func NewReaper(purger ExpiredPurger, interval time.Duration) *Reaper {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Reaper{
+		purger:   purger,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start запускает горутину очистки
+func (r *Reaper) Start() {
+	go r.run()
+
+	logger.Logger.Info("Запущена фоновая очистка истекших URL",
+		zap.Duration("interval", r.interval))
+}
+
+// Stop останавливает горутину очистки и дожидается ее завершения.
+// Выполняющийся проход прерывается через отмену контекста.
+func (r *Reaper) Stop() {
+	r.cancel()
+	<-r.done
+}
+
+// run выполняет очистку по таймеру до получения сигнала остановки
+func (r *Reaper) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.purge(now)
+		}
+	}
+}
+
+// purge выполняет один проход очистки
+func (r *Reaper) purge(now time.Time) {
+	purged, err := r.purger.PurgeExpired(r.ctx, now)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		logger.Logger.Error("Ошибка очистки истекших URL", zap.Error(err))
+		return
+	}
+
+	if purged > 0 {
+		logger.Logger.Info("Истекшие URL помечены как удаленные",
+			zap.Int("count", purged))
+	}
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Параметры клиента RESP
+const (
+	respKeyPrefix = "shortener:link:" // Префикс ключей ссылок
+	respTimeout   = time.Second       // Таймаут подключения и выполнения команды
+	respMaxIdle   = 8                 // Максимальное количество простаивающих соединений
+)
+
+// errRESPClosed возвращается при обращении к закрытому кэшу
+var errRESPClosed = errors.New("кэш RESP закрыт")
+
+// respError - ответ сервера с ошибкой (-ERR ...). Соединение после него остается рабочим.
+type respError string
+
+func (e respError) Error() string {
+	return "RESP: " + string(e)
+}
+
+// RESPCache - кэш ссылок во внешнем хранилище с протоколом RESP
+// (Redis, Valkey, KeyDB). Использует команды GET, SET с PX и DEL,
+// поэтому время жизни записей отслеживает сам сервер.
+//
+// Соединения открываются по требованию и переиспользуются. Соединение,
+// на котором произошла сетевая ошибка, закрывается.
+type RESPCache struct {
+	addr   string
+	ttl    time.Duration
+	mu     sync.Mutex
+	idle   []*respConn // простаивающие соединения
+	closed bool
+}
+
+// respConn - соединение с сервером RESP
+type respConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewRESPCache создает кэш на сервере addr со временем жизни записей ttl.
+// Подключение выполняется при первом обращении.
+func NewRESPCache(addr string, ttl time.Duration) *RESPCache {
+	return &RESPCache{
+		addr: addr,
+		ttl:  ttl,
+	}
+}
+
+// Get возвращает ссылку из кэша
+func (c *RESPCache) Get(ctx context.Context, id string) (Link, bool, error) {
+	reply, err := c.do(ctx, "GET", respKeyPrefix+id)
+	if err != nil {
+		return Link{}, false, err
+	}
+	if reply == nil {
+		return Link{}, false, nil
+	}
+
+	data, ok := reply.([]byte)
+	if !ok {
+		return Link{}, false, fmt.Errorf("RESP: неожиданный ответ на GET: %T", reply)
+	}
+
+	var link Link
+	if err := json.Unmarshal(data, &link); err != nil {
+		return Link{}, false, fmt.Errorf("RESP: некорректная запись кэша %q: %w", id, err)
+	}
+	return link, true, nil
+}
+
+// Set сохраняет ссылку в кэш со временем жизни ttl
+func (c *RESPCache) Set(ctx context.Context, id string, link Link) error {
+	data, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+
+	reply, err := c.do(ctx, "SET", respKeyPrefix+id, string(data),
+		"PX", strconv.FormatInt(c.ttl.Milliseconds(), 10))
+	if err != nil {
+		return err
+	}
+	if reply != "OK" {
+		return fmt.Errorf("RESP: неожиданный ответ на SET: %v", reply)
+	}
+	return nil
+}
+
+// Delete удаляет ссылки из кэша
+func (c *RESPCache) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]string, 0, len(ids)+1)
+	args = append(args, "DEL")
+	for _, id := range ids {
+		args = append(args, respKeyPrefix+id)
+	}
+
+	_, err := c.do(ctx, args...)
+	return err
+}
+
+// Close закрывает простаивающие соединения.
+// Соединения, занятые выполняющимися командами, закрываются по их завершении.
+func (c *RESPCache) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.closed = true
+	c.mu.Unlock()
+
+	for _, rc := range idle {
+		rc.conn.Close()
+	}
+	return nil
+}
+
+// do выполняет команду и возвращает разобранный ответ сервера
+func (c *RESPCache) do(ctx context.Context, args ...string) (any, error) {
+	rc, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(respTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := rc.conn.SetDeadline(deadline); err != nil {
+		rc.conn.Close()
+		return nil, err
+	}
+
+	if err := writeRESPCommand(rc.conn, args); err != nil {
+		rc.conn.Close()
+		return nil, err
+	}
+
+	reply, err := readRESPReply(rc.r)
+	var replyErr respError
+	if err != nil && !errors.As(err, &replyErr) {
+		rc.conn.Close()
+		return nil, err
+	}
+
+	c.release(rc)
+	return reply, err
+}
+
+// acquire возвращает простаивающее соединение или открывает новое
+func (c *RESPCache) acquire(ctx context.Context) (*respConn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errRESPClosed
+	}
+	if n := len(c.idle); n > 0 {
+		rc := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return rc, nil
+	}
+	c.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: respTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+	return &respConn{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// release возвращает соединение в пул или закрывает его
+func (c *RESPCache) release(rc *respConn) {
+	c.mu.Lock()
+	if !c.closed && len(c.idle) < respMaxIdle {
+		c.idle = append(c.idle, rc)
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+	rc.conn.Close()
+}
+
+// writeRESPCommand записывает команду как массив bulk-строк
+func writeRESPCommand(w io.Writer, args []string) error {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// readRESPReply читает один ответ сервера. Простая строка возвращается
+// как string, целое - как int64, bulk-строка - как []byte (nil для
+// отсутствующего значения), массив - как []any. Ответ с ошибкой
+// возвращается как respError.
+func readRESPReply(r *bufio.Reader) (any, error) {
+	line, err := readRESPLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("RESP: пустой ответ")
+	}
+
+	payload := string(line[1:])
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, respError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("RESP: некорректная длина строки %q", payload)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("RESP: некорректная длина массива %q", payload)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]any, count)
+		for i := range items {
+			if items[i], err = readRESPReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("RESP: неизвестный тип ответа %q", line[0])
+	}
+}
+
+// readRESPLine читает строку ответа без завершающего CRLF
+func readRESPLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, errors.New("RESP: строка ответа без CRLF")
+	}
+	return line[:len(line)-2], nil
+}
@@ -1,41 +1,80 @@
 package storage
 
-import "github.com/Adigezalov/shortener/internal/models"
+import (
+	"context"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
 
 // Stats представляет статистику хранилища
 type Stats struct {
 	URLs  int // Количество сокращённых URL в сервисе
 	Users int // Количество пользователей в сервисе
+
+	// Cache - счетчики кэша ссылок (nil, если кэш отключен)
+	Cache *CacheStats
 }
 
-// URLStorage интерфейс для хранения URL
-type URLStorage interface {
-	// Add добавляет новый URL в хранилище
-	// Возвращает ID, признак того, был ли URL уже в хранилище, и ошибку
-	Add(id string, url string) (string, bool, error)
+// URLStorageV2 интерфейс для хранения URL с поддержкой контекста.
+//
+// Все методы принимают context.Context, поэтому отмена HTTP запроса
+// или gRPC вызова доходит до базы данных. Отсутствие данных и сбой
+// хранилища различаются: методы возвращают типизированные ошибки
+// ErrNotFound, ErrGone, ErrExpired, ErrDisabled и ErrConflict, а любая
+// другая ошибка означает сбой хранилища.
+type URLStorageV2 interface {
+	// Add добавляет URL с привязкой к пользователю (userID может быть пустым)
+	// и сроком действия (нулевое значение expiresAt означает бессрочную ссылку).
+	// Если оригинальный URL уже сокращен в области дедупликации пользователя
+	// (см. DedupScope), возвращает существующий ID и ошибку ErrConflict,
+	// обертывающую database.ErrURLConflict.
+	// Если короткий ID занят, возвращает ErrConflict, обертывающую database.ErrShortIDConflict.
+	Add(ctx context.Context, id string, url string, userID string, expiresAt time.Time) (string, error)
 
-	// AddWithUser добавляет новый URL в хранилище с привязкой к пользователю
-	// Возвращает ID, признак того, был ли URL уже в хранилище, и ошибку
-	AddWithUser(id string, url string, userID string) (string, bool, error)
+	// Get возвращает оригинальный URL по идентификатору.
+	// Возвращает ErrNotFound, ErrGone для удаленного URL, ErrExpired
+	// для URL с истекшим сроком действия и ErrDisabled для ссылки,
+	// отключенной модератором.
+	Get(ctx context.Context, id string) (string, error)
 
-	// Get возвращает оригинальный URL по идентификатору
-	Get(id string) (string, bool)
+	// FindByOriginalURL ищет ID ссылки на URL, с которой Add пользователя userID
+	// считает URL дубликатом (см. DedupScope). Возвращает ErrNotFound, если
+	// такой ссылки нет или дедупликация отключена.
+	FindByOriginalURL(ctx context.Context, url string, userID string) (string, error)
 
-	// FindByOriginalURL ищет ID по оригинальному URL
-	FindByOriginalURL(url string) (string, bool)
+	// GetUserURLs возвращает действующие URL пользователя
+	GetUserURLs(ctx context.Context, userID string) ([]models.UserURL, error)
 
-	// GetUserURLs возвращает все URL пользователя
-	GetUserURLs(userID string) ([]models.UserURL, error)
+	// ListUserURLs возвращает страницу действующих URL пользователя,
+	// удовлетворяющих фильтру query, в порядке момента создания (при равном
+	// моменте - короткого ID) вместе с общим количеством подходящих URL.
+	// Страница продолжается с позиции query.After; ссылки, добавленные или
+	// удаленные между запросами, не сдвигают следующие страницы.
+	ListUserURLs(ctx context.Context, userID string, query UserURLQuery) (UserURLPage, error)
 
 	// DeleteUserURLs помечает URL как удаленные для указанного пользователя
-	DeleteUserURLs(userID string, shortURLs []string) error
+	DeleteUserURLs(ctx context.Context, userID string, shortURLs []string) error
 
-	// IsDeleted проверяет, помечен ли URL как удаленный
-	IsDeleted(shortURL string) (bool, error)
+	// PurgeExpired помечает как удаленные URL, срок действия которых истек к моменту now
+	// Возвращает количество помеченных URL
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
 
 	// Stats возвращает статистику хранилища
-	Stats() (Stats, error)
+	Stats(ctx context.Context) (Stats, error)
 
 	// Close закрывает хранилище и освобождает ресурсы
 	Close() error
 }
+
+// ClickStorage интерфейс хранилища статистики переходов.
+// Реализуется MemoryStorage и DatabaseStorage.
+type ClickStorage interface {
+	// RecordClicks сохраняет пакет событий перехода
+	RecordClicks(clicks []models.Click) error
+
+	// GetClickStats возвращает статистику переходов по ссылке пользователя
+	// с почасовой историей начиная с since
+	// Возвращает false, если ссылка не найдена или не принадлежит пользователю
+	GetClickStats(userID string, shortURL string, since time.Time) (models.URLStats, bool, error)
+}
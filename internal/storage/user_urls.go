@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+)
+
+// ErrInvalidCursor возвращается для курсора, который не удалось разобрать
+var ErrInvalidCursor = errors.New("некорректный курсор")
+
+// SortOrder задает порядок URL пользователя по моменту создания
+type SortOrder string
+
+const (
+	SortNewest SortOrder = "desc" // Сначала новые (по умолчанию)
+	SortOldest SortOrder = "asc"  // Сначала старые
+)
+
+// ParseSortOrder проверяет и преобразует строковое значение порядка сортировки.
+// Пустое значение соответствует SortNewest.
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch order := SortOrder(value); order {
+	case "":
+		return SortNewest, nil
+	case SortNewest, SortOldest:
+		return order, nil
+	default:
+		return "", fmt.Errorf("неизвестный порядок сортировки %q (допустимо: desc, asc)", value)
+	}
+}
+
+// Cursor - позиция в списке URL пользователя: момент создания и короткий ID
+// последнего URL предыдущей страницы
+type Cursor struct {
+	CreatedAt time.Time
+	ShortID   string
+}
+
+// String кодирует курсор в непрозрачную строку для клиента
+func (c Cursor) String() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ShortID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor разбирает курсор, полученный от Cursor.String
+func ParseCursor(value string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	createdAt, shortID, ok := strings.Cut(string(raw), "|")
+	if !ok || shortID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{CreatedAt: t, ShortID: shortID}, nil
+}
+
+// after сообщает, что URL (createdAt, shortID) идет после курсора в порядке order
+func (c Cursor) after(createdAt time.Time, shortID string, order SortOrder) bool {
+	cmp := compareUserURL(createdAt, shortID, c.CreatedAt, c.ShortID)
+	if order == SortOldest {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// compareUserURL сравнивает URL по моменту создания, а при равенстве -
+// по короткому ID
+func compareUserURL(aCreated time.Time, aID string, bCreated time.Time, bID string) int {
+	if c := aCreated.Compare(bCreated); c != 0 {
+		return c
+	}
+	return strings.Compare(aID, bID)
+}
+
+// UserURLQuery задает страницу, порядок и фильтр списка URL пользователя.
+// Нулевое значение возвращает все URL, начиная с новых.
+type UserURLQuery struct {
+	URLContains string    // Подстрока оригинального URL без учета регистра
+	Host        string    // Хост оригинального URL или любой его поддомен (в нижнем регистре)
+	CreatedFrom time.Time // URL создан не раньше этого момента
+	CreatedTo   time.Time // URL создан раньше этого момента
+
+	Order SortOrder // Порядок по моменту создания (пустой - SortNewest)
+	After *Cursor   // Продолжить после курсора (nil - с начала списка)
+	Limit int       // Размер страницы (0 - без ограничения)
+}
+
+// linkFilter возвращает условия фильтра для поиска URL пользователя userID
+func (q UserURLQuery) linkFilter(userID string) LinkFilter {
+	return LinkFilter{
+		UserID:      userID,
+		URLContains: q.URLContains,
+		Host:        q.Host,
+		CreatedFrom: q.CreatedFrom,
+		CreatedTo:   q.CreatedTo,
+	}
+}
+
+// UserURLPage - страница списка URL пользователя
+type UserURLPage struct {
+	URLs  []models.UserURL // Короткий ID в поле ShortURL, как в GetUserURLs
+	Total int              // Количество URL, удовлетворяющих фильтру, на всех страницах
+
+	// Next - курсор следующей страницы (nil - страница последняя)
+	Next *Cursor
+}
+
+// ListUserURLs возвращает страницу действующих URL пользователя.
+// Под блокировкой на чтение отбираются только короткие ID подходящих
+// ссылок, сами URL копируются лишь для возвращаемой страницы.
+func (s *MemoryStorage) ListUserURLs(ctx context.Context, userID string, query UserURLQuery) (UserURLPage, error) {
+	if err := ctx.Err(); err != nil {
+		return UserURLPage{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filter := query.linkFilter(userID)
+	now := time.Now()
+	page := UserURLPage{URLs: []models.UserURL{}}
+
+	var candidates []string
+	for _, id := range s.userURLs[userID] {
+		if s.deletedURLs[id] || s.isExpiredLocked(id, now) {
+			continue
+		}
+		originalURL, exists := s.urls[id]
+		if !exists {
+			continue
+		}
+		link := LinkInfo{ShortID: id, OriginalURL: originalURL, UserID: userID, CreatedAt: s.createdAt[id]}
+		if !filter.match(link) {
+			continue
+		}
+		page.Total++
+		if query.After != nil && !query.After.after(link.CreatedAt, id, query.Order) {
+			continue
+		}
+		candidates = append(candidates, id)
+	}
+
+	slices.SortFunc(candidates, func(a, b string) int {
+		cmp := compareUserURL(s.createdAt[a], a, s.createdAt[b], b)
+		if query.Order == SortOldest {
+			return cmp
+		}
+		return -cmp
+	})
+
+	if query.Limit > 0 && len(candidates) > query.Limit {
+		candidates = candidates[:query.Limit]
+		last := candidates[len(candidates)-1]
+		page.Next = &Cursor{CreatedAt: s.createdAt[last], ShortID: last}
+	}
+
+	for _, id := range candidates {
+		created := s.createdAt[id]
+		userURL := models.UserURL{ShortURL: id, OriginalURL: s.urls[id]}
+		if !created.IsZero() {
+			userURL.CreatedAt = &created
+		}
+		page.URLs = append(page.URLs, userURL)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Adigezalov/shortener/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userURLIDs возвращает короткие ID страницы URL пользователя
+func userURLIDs(page UserURLPage) []string {
+	ids := make([]string, len(page.URLs))
+	for i, userURL := range page.URLs {
+		ids[i] = userURL.ShortURL
+	}
+	return ids
+}
+
+// newUserURLsStore создает хранилище с URL пользователя alice, созданными
+// с интервалом в час: u1 - самый старый, u5 - самый новый
+func newUserURLsStore(t *testing.T) (*MemoryStorage, time.Time) {
+	t.Helper()
+	store := NewMemoryStorage("")
+	t.Cleanup(func() { store.Close() })
+
+	addLinks(t, store, "alice", map[string]string{
+		"u1": "https://example.com/first",
+		"u2": "https://news.example.com/",
+		"u3": "https://other.org/Example",
+		"u4": "https://example.com/gone",
+		"u5": "https://example.com/last",
+	})
+	addLinks(t, store, "bob", map[string]string{"b1": "https://example.com/bob"})
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"u1", "u2", "u3", "u4", "u5"} {
+		store.createdAt[id] = base.Add(time.Duration(i) * time.Hour)
+	}
+	require.NoError(t, store.DeleteUserURLs(context.Background(), "alice", []string{"u4"}))
+	return store, base
+}
+
+func TestMemoryStorage_ListUserURLs(t *testing.T) {
+	store, base := newUserURLsStore(t)
+
+	tests := []struct {
+		name      string
+		query     UserURLQuery
+		want      []string
+		wantTotal int
+	}{
+		{name: "все URL от новых к старым", query: UserURLQuery{}, want: []string{"u5", "u3", "u2", "u1"}, wantTotal: 4},
+		{name: "от старых к новым", query: UserURLQuery{Order: SortOldest}, want: []string{"u1", "u2", "u3", "u5"}, wantTotal: 4},
+		{name: "по подстроке URL", query: UserURLQuery{URLContains: "OTHER.org/ex"}, want: []string{"u3"}, wantTotal: 1},
+		{name: "по хосту с поддоменами", query: UserURLQuery{Host: "example.com"}, want: []string{"u5", "u2", "u1"}, wantTotal: 3},
+		{
+			name:      "по периоду создания",
+			query:     UserURLQuery{CreatedFrom: base.Add(time.Hour), CreatedTo: base.Add(3 * time.Hour)},
+			want:      []string{"u3", "u2"},
+			wantTotal: 2,
+		},
+		{name: "первая страница", query: UserURLQuery{Limit: 2}, want: []string{"u5", "u3"}, wantTotal: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := store.ListUserURLs(context.Background(), "alice", tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, userURLIDs(page))
+			assert.Equal(t, tt.wantTotal, page.Total)
+		})
+	}
+}
+
+func TestMemoryStorage_ListUserURLs_Pages(t *testing.T) {
+	store, base := newUserURLsStore(t)
+	ctx := context.Background()
+
+	for _, order := range []SortOrder{SortNewest, SortOldest} {
+		t.Run(string(order), func(t *testing.T) {
+			var got []string
+			query := UserURLQuery{Order: order, Limit: 3}
+			for {
+				page, err := store.ListUserURLs(ctx, "alice", query)
+				require.NoError(t, err)
+				assert.Equal(t, 4, page.Total)
+				got = append(got, userURLIDs(page)...)
+				if page.Next == nil {
+					break
+				}
+				query.After = page.Next
+			}
+
+			full, err := store.ListUserURLs(ctx, "alice", UserURLQuery{Order: order})
+			require.NoError(t, err)
+			assert.Equal(t, userURLIDs(full), got)
+		})
+	}
+
+	// URL, добавленный после выдачи курсора, не сдвигает следующую страницу
+	first, err := store.ListUserURLs(ctx, "alice", UserURLQuery{Limit: 2})
+	require.NoError(t, err)
+	require.NotNil(t, first.Next)
+	addLinks(t, store, "alice", map[string]string{"u6": "https://example.com/new"})
+
+	next, err := store.ListUserURLs(ctx, "alice", UserURLQuery{Limit: 2, After: first.Next})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u1"}, userURLIDs(next))
+	assert.Nil(t, next.Next)
+	assert.Equal(t, 5, next.Total)
+
+	created := base.Add(time.Hour)
+	assert.Equal(t, models.UserURL{ShortURL: "u2", OriginalURL: "https://news.example.com/", CreatedAt: &created}, next.URLs[0])
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 890, time.UTC), ShortID: "abc|def"}
+
+	parsed, err := ParseCursor(cursor.String())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+	assert.Equal(t, cursor.ShortID, parsed.ShortID)
+
+	for _, value := range []string{"", "not a cursor", "bm8tc2VwYXJhdG9y", "MjAyNnxhYmM"} {
+		_, err := ParseCursor(value)
+		assert.ErrorIs(t, err, ErrInvalidCursor, value)
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := ParseSortOrder("")
+	require.NoError(t, err)
+	assert.Equal(t, SortNewest, order)
+
+	order, err = ParseSortOrder("asc")
+	require.NoError(t, err)
+	assert.Equal(t, SortOldest, order)
+
+	_, err = ParseSortOrder("random")
+	assert.Error(t, err)
+}
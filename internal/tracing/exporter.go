@@ -0,0 +1,231 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Exporter выгружает завершенные span'ы
+type Exporter interface {
+	// ExportSpans выгружает пакет span'ов
+	ExportSpans(ctx context.Context, spans []SpanData) error
+
+	// Shutdown освобождает ресурсы экспортера
+	Shutdown(ctx context.Context) error
+}
+
+// Экспортеры span'ов, выбираемые конфигурацией
+const (
+	ExporterNone   = "none"   // Выгрузка отключена
+	ExporterStdout = "stdout" // JSON в стандартный вывод
+	ExporterOTLP   = "otlp"   // OTLP/HTTP коллектору OpenTelemetry
+)
+
+// NewExporter создает экспортер по имени. Для ExporterOTLP используется адрес
+// коллектора endpoint. Для ExporterNone возвращает nil.
+func NewExporter(name, endpoint, serviceName string) (Exporter, error) {
+	switch name {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return NewStdoutExporter(os.Stdout), nil
+	case ExporterOTLP:
+		if endpoint == "" {
+			return nil, fmt.Errorf("не указан адрес коллектора для экспортера %s", name)
+		}
+		return NewOTLPExporter(endpoint, serviceName)
+	default:
+		return nil, fmt.Errorf("неизвестный экспортер трассировки %q (допустимо: none, stdout, otlp)", name)
+	}
+}
+
+// Параметры пакетной выгрузки span'ов
+const (
+	queueSize     = 2048             // Емкость очереди span'ов; при переполнении span'ы отбрасываются
+	batchSize     = 512              // Максимальное количество span'ов в одном пакете
+	batchInterval = time.Second      // Период выгрузки неполного пакета
+	exportTimeout = 10 * time.Second // Таймаут выгрузки одного пакета
+)
+
+// processor накапливает span'ы и выгружает их пакетами в фоновой горутине
+type processor struct {
+	exporter Exporter
+	queue    chan SpanData
+	flush    chan chan struct{} // запросы немедленной выгрузки
+	done     chan struct{}      // закрывается по завершении фоновой горутины
+}
+
+var (
+	mu      sync.RWMutex
+	current *processor
+
+	// errorHandler получает ошибки выгрузки span'ов
+	errorHandler = func(err error) {
+		fmt.Fprintln(os.Stderr, "tracing:", err)
+	}
+)
+
+// SetErrorHandler задает обработчик ошибок выгрузки span'ов
+func SetErrorHandler(handler func(error)) {
+	mu.Lock()
+	defer mu.Unlock()
+	errorHandler = handler
+}
+
+// handleError передает ошибку обработчику
+func handleError(err error) {
+	mu.RLock()
+	handler := errorHandler
+	mu.RUnlock()
+	handler(err)
+}
+
+// SetExporter подключает экспортер span'ов. Предыдущий экспортер
+// завершается после выгрузки накопленных span'ов; nil отключает выгрузку.
+func SetExporter(exporter Exporter) {
+	var p *processor
+	if exporter != nil {
+		p = &processor{
+			exporter: exporter,
+			queue:    make(chan SpanData, queueSize),
+			flush:    make(chan chan struct{}),
+			done:     make(chan struct{}),
+		}
+		go p.run()
+	}
+
+	mu.Lock()
+	previous := current
+	current = p
+	mu.Unlock()
+
+	if previous != nil {
+		if err := previous.shutdown(context.Background()); err != nil {
+			handleError(err)
+		}
+	}
+}
+
+// Shutdown выгружает накопленные span'ы и отключает экспортер
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	p := current
+	current = nil
+	mu.Unlock()
+
+	if p == nil {
+		return nil
+	}
+	return p.shutdown(ctx)
+}
+
+// ForceFlush выгружает накопленные span'ы, не отключая экспортер
+func ForceFlush(ctx context.Context) {
+	mu.RLock()
+	p := current
+	mu.RUnlock()
+
+	if p == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	select {
+	case p.flush <- done:
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	case <-p.done:
+	case <-ctx.Done():
+	}
+}
+
+// exporterConfigured сообщает, подключен ли экспортер
+func exporterConfigured() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current != nil
+}
+
+// export ставит завершенный span в очередь выгрузки
+func export(data SpanData) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil {
+		return
+	}
+	select {
+	case current.queue <- data:
+	default:
+		// Очередь переполнена: трассировка не должна замедлять запросы
+	}
+}
+
+// run накапливает span'ы и выгружает пакетами по размеру и таймеру
+func (p *processor) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, batchSize)
+	exportBatch := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		if err := p.exporter.ExportSpans(ctx, batch); err != nil {
+			handleError(fmt.Errorf("ошибка выгрузки %d span'ов: %w", len(batch), err))
+		}
+		cancel()
+		batch = make([]SpanData, 0, batchSize)
+	}
+
+	for {
+		select {
+		case data, ok := <-p.queue:
+			if !ok {
+				exportBatch()
+				return
+			}
+			batch = append(batch, data)
+			if len(batch) >= batchSize {
+				exportBatch()
+			}
+		case done := <-p.flush:
+			// Забираем уже поставленные в очередь span'ы
+			for drained := false; !drained; {
+				select {
+				case data, ok := <-p.queue:
+					if !ok {
+						drained = true
+						break
+					}
+					batch = append(batch, data)
+				default:
+					drained = true
+				}
+			}
+			exportBatch()
+			close(done)
+		case <-ticker.C:
+			exportBatch()
+		}
+	}
+}
+
+// shutdown выгружает накопленные span'ы и завершает экспортер.
+// Вызывается после отключения процессора, поэтому новые span'ы в очередь не попадают.
+func (p *processor) shutdown(ctx context.Context) error {
+	close(p.queue)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.exporter.Shutdown(ctx)
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+// TraceparentHeader - заголовок HTTP и ключ метаданных gRPC с контекстом трассировки W3C
+const TraceparentHeader = "traceparent"
+
+// flagSampled - флаг выгрузки трассировки в trace-flags
+const flagSampled = 0x01
+
+// ParseTraceparent разбирает значение заголовка traceparent
+// (https://www.w3.org/TR/trace-context/). Возвращает false для
+// некорректного значения и нулевых идентификаторов.
+//
+// Формат: 00-<trace-id, 32 hex>-<parent-id, 16 hex>-<trace-flags, 2 hex>.
+// Заголовки будущих версий разбираются по первым четырем полям.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, false
+	}
+
+	version, ok := decodeHex(parts[0], 1)
+	if !ok || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+
+	traceID, ok := decodeHex(parts[1], 16)
+	if !ok {
+		return SpanContext{}, false
+	}
+	spanID, ok := decodeHex(parts[2], 8)
+	if !ok {
+		return SpanContext{}, false
+	}
+	flags, ok := decodeHex(parts[3], 1)
+	if !ok {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Sampled = flags[0]&flagSampled != 0
+	sc.Remote = true
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// Traceparent возвращает значение заголовка traceparent для контекста
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// Extract возвращает контекст с удаленным родителем из значения traceparent.
+// Некорректное значение игнорируется.
+func Extract(ctx context.Context, traceparent string) context.Context {
+	if sc, ok := ParseTraceparent(traceparent); ok {
+		return ContextWithRemote(ctx, sc)
+	}
+	return ctx
+}
+
+// decodeHex разбирает строку из n байт в нижнем регистре
+func decodeHex(s string, n int) ([]byte, bool) {
+	if len(s) != 2*n || strings.ToLower(s) != s {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
@@ -0,0 +1,252 @@
+// Package tracing реализует распределенную трассировку запросов в стиле
+// OpenTelemetry: контекст трассировки W3C (traceparent), span'ы и их
+// выгрузку через подключаемый Exporter.
+//
+// Span создается вызовом Start и завершается End:
+//
+//	ctx, span := tracing.Start(ctx, "ShortenerService.GetOriginalURL")
+//	defer span.End()
+//
+// Идентификаторы трассировки создаются всегда, даже без настроенного
+// экспортера: они передаются между сервисами и попадают в логи.
+// Выгружаются только span'ы с флагом sampled.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID - идентификатор трассировки (16 байт)
+type TraceID [16]byte
+
+// String возвращает идентификатор в шестнадцатеричном виде
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid сообщает, что идентификатор не нулевой
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID - идентификатор span'а (8 байт)
+type SpanID [8]byte
+
+// String возвращает идентификатор в шестнадцатеричном виде
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid сообщает, что идентификатор не нулевой
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext - передаваемая между процессами часть span'а
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool // span выгружается экспортером
+	Remote  bool // контекст получен из входящего запроса
+}
+
+// IsValid сообщает, что контекст содержит идентификаторы трассировки и span'а
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind - роль span'а в обработке запроса
+type SpanKind int
+
+// Роли span'ов (значения совпадают с OTLP).
+const (
+	SpanKindInternal SpanKind = 1 // Внутренняя операция
+	SpanKindServer   SpanKind = 2 // Обработка входящего запроса
+	SpanKindClient   SpanKind = 3 // Исходящий запрос (в том числе к базе данных)
+)
+
+// Attribute - атрибут span'а. Значение - string, int64 или bool.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String создает строковый атрибут
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int создает целочисленный атрибут
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool создает логический атрибут
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanData - завершенный span, передаваемый экспортеру
+type SpanData struct {
+	Name       string
+	Kind       SpanKind
+	Context    SpanContext
+	ParentID   SpanID // нулевой для корневого span'а
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	Error      string // описание ошибки (пустое для успешной операции)
+}
+
+// Span - выполняющаяся операция трассировки.
+// Методы безопасны для вызова на nil.
+type Span struct {
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanOption настраивает span при создании
+type SpanOption func(*SpanData)
+
+// WithKind задает роль span'а (по умолчанию SpanKindInternal)
+func WithKind(kind SpanKind) SpanOption {
+	return func(d *SpanData) { d.Kind = kind }
+}
+
+// WithAttributes добавляет атрибуты span'а
+func WithAttributes(attrs ...Attribute) SpanOption {
+	return func(d *SpanData) { d.Attributes = append(d.Attributes, attrs...) }
+}
+
+// contextKey - ключ span'а в контексте
+type contextKey struct{}
+
+// Start создает span, дочерний к span'у или удаленному контексту из ctx,
+// и возвращает контекст с новым span'ом. Без родителя создается новая
+// трассировка, которая выгружается, если настроен экспортер.
+func Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	data := SpanData{
+		Name:  name,
+		Kind:  SpanKindInternal,
+		Start: time.Now(),
+	}
+	if parent.IsValid() {
+		data.Context.TraceID = parent.TraceID
+		data.Context.Sampled = parent.Sampled
+		data.ParentID = parent.SpanID
+	} else {
+		data.Context.TraceID = newTraceID()
+		data.Context.Sampled = exporterConfigured()
+	}
+	data.Context.SpanID = newSpanID()
+
+	for _, opt := range opts {
+		opt(&data)
+	}
+
+	span := &Span{data: data}
+	return context.WithValue(ctx, contextKey{}, span), span
+}
+
+// ContextWithRemote возвращает контекст с удаленным родительским span'ом,
+// полученным из входящего запроса
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, contextKey{}, &Span{data: SpanData{Context: sc}, ended: true})
+}
+
+// SpanFromContext возвращает текущий span или nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(contextKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext возвращает контекст текущего span'а
+// (нулевой, если трассировка не начата)
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return SpanFromContext(ctx).SpanContext()
+}
+
+// SpanContext возвращает идентификаторы span'а
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.Context
+}
+
+// SetName заменяет имя span'а (например, после определения маршрута)
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+// SetAttributes добавляет атрибуты span'а
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// RecordError отмечает span как завершившийся ошибкой
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End завершает span и передает его экспортеру. Повторные вызовы игнорируются.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attributes = append([]Attribute(nil), s.data.Attributes...)
+	s.mu.Unlock()
+
+	if data.Context.Sampled {
+		export(data)
+	}
+}
+
+// newTraceID создает случайный идентификатор трассировки
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// newSpanID создает случайный идентификатор span'а
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
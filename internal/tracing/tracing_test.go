@@ -0,0 +1,229 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		valid   bool
+		sampled bool
+	}{
+		{"С_флагом_sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"Без_флага_sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"Будущая_версия_с_доп_полями", "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, true},
+		{"Пустое_значение", "", false, false},
+		{"Нулевой_trace_id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"Нулевой_span_id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"Верхний_регистр", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false},
+		{"Короткий_trace_id", "00-4bf92f3577b34da6-00f067aa0ba902b7-01", false, false},
+		{"Версия_ff", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"Лишние_поля_версии_00", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, false},
+		{"Не_hex", "00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tt.value)
+			require.Equal(t, tt.valid, ok)
+			if !tt.valid {
+				return
+			}
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+			assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+			assert.Equal(t, tt.sampled, sc.Sampled)
+			assert.True(t, sc.Remote)
+		})
+	}
+}
+
+func TestSpanContext_Traceparent(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(value)
+	require.True(t, ok)
+	assert.Equal(t, value, sc.Traceparent())
+
+	sc.Sampled = false
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", sc.Traceparent())
+}
+
+func TestStart(t *testing.T) {
+	t.Run("Новая_трассировка", func(t *testing.T) {
+		ctx, span := Start(context.Background(), "root")
+		defer span.End()
+
+		sc := span.SpanContext()
+		assert.True(t, sc.IsValid())
+		assert.False(t, sc.Sampled, "без экспортера span'ы не выгружаются")
+		assert.Equal(t, sc, SpanContextFromContext(ctx))
+	})
+
+	t.Run("Дочерний_span", func(t *testing.T) {
+		ctx, parent := Start(context.Background(), "parent")
+		_, child := Start(ctx, "child")
+
+		assert.Equal(t, parent.SpanContext().TraceID, child.SpanContext().TraceID)
+		assert.NotEqual(t, parent.SpanContext().SpanID, child.SpanContext().SpanID)
+		assert.Equal(t, parent.SpanContext().SpanID, child.data.ParentID)
+	})
+
+	t.Run("Удаленный_родитель", func(t *testing.T) {
+		ctx := Extract(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		_, span := Start(ctx, "server")
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID.String())
+		assert.Equal(t, "00f067aa0ba902b7", span.data.ParentID.String())
+		assert.True(t, span.SpanContext().Sampled, "решение о выгрузке наследуется от родителя")
+		assert.False(t, span.SpanContext().Remote)
+	})
+
+	t.Run("Некорректный_traceparent_игнорируется", func(t *testing.T) {
+		ctx := Extract(context.Background(), "garbage")
+		assert.False(t, SpanContextFromContext(ctx).IsValid())
+	})
+
+	t.Run("Nil_span", func(t *testing.T) {
+		var span *Span
+		span.SetName("x")
+		span.SetAttributes(String("k", "v"))
+		span.RecordError(errors.New("ошибка"))
+		span.End()
+		assert.False(t, span.SpanContext().IsValid())
+	})
+}
+
+// useExporter подключает экспортер на время теста
+func useExporter(t *testing.T, exporter Exporter) {
+	t.Helper()
+	SetExporter(exporter)
+	t.Cleanup(func() { SetExporter(nil) })
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	useExporter(t, NewStdoutExporter(&buf))
+
+	ctx, parent := Start(context.Background(), "parent", WithKind(SpanKindServer))
+	_, child := Start(ctx, "child", WithAttributes(String("url.id", "abc"), Int("batch.size", 3), Bool("cached", true)))
+	child.RecordError(errors.New("сбой запроса"))
+	child.End()
+	child.End() // повторный вызов игнорируется
+	parent.End()
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ForceFlush(flushCtx)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var spans []stdoutSpan
+	for _, line := range lines {
+		var span stdoutSpan
+		require.NoError(t, json.Unmarshal([]byte(line), &span))
+		spans = append(spans, span)
+	}
+
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "internal", spans[0].Kind)
+	assert.Equal(t, parent.SpanContext().SpanID.String(), spans[0].ParentSpanID)
+	assert.Equal(t, "сбой запроса", spans[0].Error)
+	assert.Equal(t, map[string]any{"url.id": "abc", "batch.size": float64(3), "cached": true}, spans[0].Attributes)
+
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Equal(t, "server", spans[1].Kind)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Equal(t, spans[0].TraceID, spans[1].TraceID)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan otlpRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var req otlpRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests <- req
+	}))
+	defer collector.Close()
+
+	exporter, err := NewExporter(ExporterOTLP, collector.URL, "shortener-test")
+	require.NoError(t, err)
+	useExporter(t, exporter)
+
+	ctx := Extract(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := Start(ctx, "GET /{id}", WithKind(SpanKindServer), WithAttributes(Int("http.status_code", 500)))
+	span.RecordError(errors.New("HTTP 500"))
+	span.End()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, Shutdown(shutdownCtx))
+
+	var req otlpRequest
+	select {
+	case req = <-requests:
+	default:
+		t.Fatal("коллектор не получил span'ы")
+	}
+
+	require.Len(t, req.ResourceSpans, 1)
+	resource := req.ResourceSpans[0]
+	require.Len(t, resource.Resource.Attributes, 1)
+	assert.Equal(t, "service.name", resource.Resource.Attributes[0].Key)
+	assert.Equal(t, "shortener-test", *resource.Resource.Attributes[0].Value.StringValue)
+
+	require.Len(t, resource.ScopeSpans, 1)
+	require.Len(t, resource.ScopeSpans[0].Spans, 1)
+	got := resource.ScopeSpans[0].Spans[0]
+	assert.Equal(t, "GET /{id}", got.Name)
+	assert.Equal(t, SpanKindServer, got.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", got.ParentSpanID)
+	assert.Equal(t, otlpStatus{Code: otlpStatusError, Message: "HTTP 500"}, got.Status)
+	require.Len(t, got.Attributes, 1)
+	assert.Equal(t, "500", *got.Attributes[0].Value.IntValue)
+}
+
+func TestNewExporter(t *testing.T) {
+	exporter, err := NewExporter(ExporterNone, "", "shortener")
+	require.NoError(t, err)
+	assert.Nil(t, exporter)
+
+	_, err = NewExporter(ExporterOTLP, "", "shortener")
+	assert.Error(t, err)
+
+	_, err = NewExporter(ExporterOTLP, "localhost:4318", "shortener")
+	assert.Error(t, err, "адрес без схемы")
+
+	_, err = NewExporter("jaeger", "", "shortener")
+	assert.Error(t, err)
+}
+
+func TestOTLPExporter_CollectorError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL+"/custom/traces", "shortener")
+	require.NoError(t, err)
+
+	err = exporter.ExportSpans(context.Background(), []SpanData{{Name: "x"}})
+	assert.ErrorContains(t, err, "503")
+}
@@ -0,0 +1,351 @@
+// Package urlpolicy проверяет и нормализует оригинальные URL перед сокращением.
+//
+// Политика отклоняет URL, переход по которым небезопасен или бессмыслен:
+// недопустимые схемы (javascript:, file:, data:), адреса внутренних сетей,
+// ссылки на сам сервис (бесконечный цикл перенаправлений) и хосты из списка
+// запрета. Каждое нарушение возвращается как *Error с машиночитаемым кодом,
+// который транспорты передают клиенту.
+package urlpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Code - машиночитаемый код нарушения политики
+type Code string
+
+// Коды нарушений политики.
+const (
+	CodeInvalidURL       Code = "invalid_url"        // URL не разбирается или не содержит хоста
+	CodeURLTooLong       Code = "url_too_long"       // URL длиннее допустимого
+	CodeSchemeNotAllowed Code = "scheme_not_allowed" // Схема не входит в список разрешенных
+	CodeInvalidHost      Code = "invalid_host"       // Недопустимое имя хоста
+	CodePrivateAddress   Code = "private_address"    // Хост во внутренней сети
+	CodeSelfReference    Code = "self_reference"     // URL указывает на сам сервис
+	CodeHostDenied       Code = "host_denied"        // Хост запрещен списками хостов
+)
+
+// ErrRejected - общая причина всех нарушений политики (errors.Is)
+var ErrRejected = errors.New("URL отклонен политикой")
+
+// Error описывает нарушение политики
+type Error struct {
+	Code    Code
+	Message string
+}
+
+// Error возвращает описание нарушения
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap позволяет проверять нарушение через errors.Is(err, ErrRejected)
+func (e *Error) Unwrap() error {
+	return ErrRejected
+}
+
+func reject(code Code, format string, args ...any) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Значения по умолчанию.
+const (
+	DefaultMaxLength = 2048         // Максимальная длина URL
+	DefaultSchemes   = "http,https" // Разрешенные схемы
+)
+
+// Options задает политику. Нулевое значение соответствует Default.
+type Options struct {
+	Schemes      []string // Разрешенные схемы (пустой - DefaultSchemes)
+	MaxLength    int      // Максимальная длина URL (0 - DefaultMaxLength)
+	AllowPrivate bool     // Разрешить адреса внутренних сетей и localhost
+	SelfHosts    []string // Хосты самого сервиса (например, хост BASE_URL)
+	AllowHosts   []string // Если задан, разрешены только эти хосты
+	DenyHosts    []string // Запрещенные хосты (проверяются до AllowHosts)
+	ResolveHosts bool     // Разрешать имя хоста через DNS и проверять его адреса
+}
+
+// Policy проверяет URL по заданным правилам. Безопасна для
+// одновременного использования.
+type Policy struct {
+	schemes      map[string]bool
+	maxLength    int
+	allowPrivate bool
+	selfHosts    map[string]bool
+	allow        []hostPattern
+	deny         []hostPattern
+	resolve      bool
+
+	// lookup разрешает имя хоста (подменяется в тестах)
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// New создает политику. Возвращает ошибку для некорректной схемы
+// или шаблона хоста.
+func New(opts Options) (*Policy, error) {
+	p := &Policy{
+		schemes:      make(map[string]bool),
+		maxLength:    opts.MaxLength,
+		allowPrivate: opts.AllowPrivate,
+		selfHosts:    make(map[string]bool),
+		resolve:      opts.ResolveHosts,
+		lookup:       net.DefaultResolver.LookupIPAddr,
+	}
+	if p.maxLength <= 0 {
+		p.maxLength = DefaultMaxLength
+	}
+
+	schemes := opts.Schemes
+	if len(schemes) == 0 {
+		schemes = ParseList(DefaultSchemes)
+	}
+	for _, scheme := range schemes {
+		scheme = strings.ToLower(strings.TrimSuffix(scheme, ":"))
+		if !isScheme(scheme) {
+			return nil, fmt.Errorf("некорректная схема URL %q", scheme)
+		}
+		p.schemes[scheme] = true
+	}
+
+	for _, host := range opts.SelfHosts {
+		normalized, err := NormalizeHost(host)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный хост сервиса %q: %w", host, err)
+		}
+		p.selfHosts[normalized] = true
+	}
+
+	var err error
+	if p.allow, err = parsePatterns(opts.AllowHosts); err != nil {
+		return nil, err
+	}
+	if p.deny, err = parsePatterns(opts.DenyHosts); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Default возвращает политику по умолчанию: схемы http и https, длина
+// до DefaultMaxLength, адреса внутренних сетей запрещены.
+func Default() *Policy {
+	p, _ := New(Options{})
+	return p
+}
+
+// ParseList разбирает список значений через запятую, пропуская пустые
+func ParseList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Check проверяет URL и возвращает его нормализованную форму: схема
+// и хост в нижнем регистре, интернационализированное имя хоста
+// в punycode. Остальные части URL не изменяются.
+func (p *Policy) Check(ctx context.Context, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", reject(CodeInvalidURL, "URL не может быть пустым")
+	}
+	if len(raw) > p.maxLength {
+		return "", reject(CodeURLTooLong, "длина URL превышает %d символов", p.maxLength)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", reject(CodeInvalidURL, "некорректный URL")
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "" {
+		return "", reject(CodeInvalidURL, "URL должен быть абсолютным")
+	}
+	if !p.schemes[scheme] {
+		return "", reject(CodeSchemeNotAllowed, "схема %q не разрешена", scheme)
+	}
+	if u.Opaque != "" || u.Host == "" {
+		return "", reject(CodeInvalidURL, "URL должен содержать хост")
+	}
+	if u.User != nil {
+		// https://bank.example@evil.example выглядит как адрес банка
+		return "", reject(CodeInvalidURL, "URL не должен содержать учетные данные")
+	}
+
+	host, err := NormalizeHost(u.Hostname())
+	if err != nil {
+		return "", reject(CodeInvalidHost, "недопустимое имя хоста")
+	}
+	ip := net.ParseIP(host)
+
+	if !p.allowPrivate && (isLocalName(host) || ip != nil && isPrivateIP(ip)) {
+		return "", reject(CodePrivateAddress, "адреса внутренних сетей не разрешены")
+	}
+	if p.selfHosts[host] {
+		return "", reject(CodeSelfReference, "URL не может указывать на сам сервис")
+	}
+	if matchAny(p.deny, host) {
+		return "", reject(CodeHostDenied, "хост %s запрещен", host)
+	}
+	if len(p.allow) > 0 && !matchAny(p.allow, host) {
+		return "", reject(CodeHostDenied, "хост %s не входит в список разрешенных", host)
+	}
+
+	if p.resolve && !p.allowPrivate && ip == nil {
+		addrs, err := p.lookup(ctx, host)
+		if err != nil {
+			return "", reject(CodeInvalidHost, "не удалось разрешить имя хоста %s", host)
+		}
+		for _, addr := range addrs {
+			if isPrivateIP(addr.IP) {
+				return "", reject(CodePrivateAddress, "хост %s разрешается в адрес внутренней сети", host)
+			}
+		}
+	}
+
+	// Заменяем только схему и хост, чтобы не менять кодирование пути
+	// и параметров. Учетных данных нет, поэтому authority - это хост и порт
+	if scheme != u.Scheme || host != u.Hostname() {
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		if port := u.Port(); port != "" {
+			host += ":" + port
+		}
+		rest := raw[len(u.Scheme)+len("://"):]
+		end := strings.IndexAny(rest, "/?#")
+		if end < 0 {
+			end = len(rest)
+		}
+		raw = scheme + "://" + host + rest[end:]
+		if len(raw) > p.maxLength {
+			return "", reject(CodeURLTooLong, "длина URL превышает %d символов", p.maxLength)
+		}
+	}
+	return raw, nil
+}
+
+// NormalizeHost приводит имя хоста к нижнему регистру и punycode.
+// IP-адреса возвращаются без изменений, кроме регистра.
+func NormalizeHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return "", errors.New("пустое имя хоста")
+	}
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+	if endsInNumber(host) {
+		// Формы вроде 2130706433, 0x7f.1 или 127.1 браузеры считают
+		// IPv4-адресом, что позволяет обойти проверку внутренних сетей
+		return "", errors.New("нестандартная запись IP-адреса")
+	}
+	return idna.Lookup.ToASCII(host)
+}
+
+// endsInNumber сообщает, что последняя метка хоста - число
+// (десятичное или шестнадцатеричное), как в правилах разбора WHATWG URL
+func endsInNumber(host string) bool {
+	label := host[strings.LastIndex(host, ".")+1:]
+	if hex, ok := strings.CutPrefix(label, "0x"); ok {
+		return strings.Trim(hex, "0123456789abcdef") == ""
+	}
+	return label != "" && strings.Trim(label, "0123456789") == ""
+}
+
+// isScheme проверяет синтаксис схемы по RFC 3986
+func isScheme(scheme string) bool {
+	if scheme == "" || scheme[0] < 'a' || scheme[0] > 'z' {
+		return false
+	}
+	return strings.Trim(scheme, "abcdefghijklmnopqrstuvwxyz0123456789+-.") == ""
+}
+
+// isLocalName сообщает, что имя хоста указывает на локальную машину
+func isLocalName(host string) bool {
+	return host == "localhost" || strings.HasSuffix(host, ".localhost")
+}
+
+// reservedNets - диапазоны, не покрытые методами net.IP: "этот" хост,
+// разделяемое адресное пространство (CGNAT), протокольные назначения IETF,
+// тестирование производительности и зарезервированные адреса
+var reservedNets = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// isPrivateIP сообщает, что адрес недоступен из интернета
+func isPrivateIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostPattern - шаблон хоста: "example.com" совпадает только с самим
+// хостом, "*.example.com" - только с его поддоменами
+type hostPattern struct {
+	host     string
+	wildcard bool
+}
+
+func parsePatterns(values []string) ([]hostPattern, error) {
+	patterns := make([]hostPattern, 0, len(values))
+	for _, value := range values {
+		host, wildcard := strings.CutPrefix(value, "*.")
+		normalized, err := NormalizeHost(host)
+		if err != nil || strings.Contains(normalized, "*") {
+			return nil, fmt.Errorf("некорректный шаблон хоста %q", value)
+		}
+		patterns = append(patterns, hostPattern{host: normalized, wildcard: wildcard})
+	}
+	return patterns, nil
+}
+
+func (p hostPattern) match(host string) bool {
+	if p.wildcard {
+		return strings.HasSuffix(host, "."+p.host)
+	}
+	return host == p.host
+}
+
+func matchAny(patterns []hostPattern, host string) bool {
+	for _, p := range patterns {
+		if p.match(host) {
+			return true
+		}
+	}
+	return false
+}
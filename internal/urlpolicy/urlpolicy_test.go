@@ -0,0 +1,161 @@
+package urlpolicy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	p, err := New(Options{
+		SelfHosts: []string{"short.test"},
+		DenyHosts: []string{"evil.example", "*.tracker.example"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		raw  string
+		want string
+		code Code
+	}{
+		{raw: "https://example.com/a?b=c#d", want: "https://example.com/a?b=c#d"},
+		{raw: "  http://example.com/  ", want: "http://example.com/"},
+		{raw: "HTTPS://Example.COM./Path", want: "https://example.com/Path"},
+		{raw: "https://пример.рф/путь", want: "https://xn--e1afmkfd.xn--p1ai/путь"},
+		{raw: "https://例え.jp:8443/x", want: "https://xn--r8jz45g.jp:8443/x"},
+		{raw: "https://8.8.8.8/dns", want: "https://8.8.8.8/dns"},
+		{raw: "https://[2001:4860:4860::8888]/", want: "https://[2001:4860:4860::8888]/"},
+		{raw: "https://tracker.example/", want: "https://tracker.example/"},
+
+		{raw: "javascript:alert(1)", code: CodeSchemeNotAllowed},
+		{raw: "file:///etc/passwd", code: CodeSchemeNotAllowed},
+		{raw: "data:text/html,<b>x</b>", code: CodeSchemeNotAllowed},
+		{raw: "ftp://example.com/file", code: CodeSchemeNotAllowed},
+		{raw: "example.com/path", code: CodeInvalidURL},
+		{raw: "http:///path", code: CodeInvalidURL},
+		{raw: "http://%zz", code: CodeInvalidURL},
+		{raw: "https://bank.example@evil.test/", code: CodeInvalidURL},
+		{raw: "https://" + strings.Repeat("a", DefaultMaxLength), code: CodeURLTooLong},
+		{raw: "http://localhost:8080/", code: CodePrivateAddress},
+		{raw: "http://api.localhost/", code: CodePrivateAddress},
+		{raw: "http://127.0.0.1/", code: CodePrivateAddress},
+		{raw: "http://10.1.2.3/", code: CodePrivateAddress},
+		{raw: "http://172.16.0.1/", code: CodePrivateAddress},
+		{raw: "http://192.168.1.1/", code: CodePrivateAddress},
+		{raw: "http://169.254.169.254/latest/meta-data", code: CodePrivateAddress},
+		{raw: "http://100.64.0.1/", code: CodePrivateAddress},
+		{raw: "http://0.0.0.0/", code: CodePrivateAddress},
+		{raw: "http://[::1]/", code: CodePrivateAddress},
+		{raw: "http://[fd00::1]/", code: CodePrivateAddress},
+		{raw: "http://[::ffff:127.0.0.1]/", code: CodePrivateAddress},
+		{raw: "http://2130706433/", code: CodeInvalidHost},
+		{raw: "http://0x7f.1/", code: CodeInvalidHost},
+		{raw: "http://127.1/", code: CodeInvalidHost},
+		{raw: "http://exa_mple.com/", code: CodeInvalidHost},
+		{raw: "https://short.test/abc", code: CodeSelfReference},
+		{raw: "https://SHORT.test./abc", code: CodeSelfReference},
+		{raw: "https://evil.example/", code: CodeHostDenied},
+		{raw: "https://a.b.tracker.example/", code: CodeHostDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := p.Check(context.Background(), tt.raw)
+			if tt.code == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				return
+			}
+
+			var policyErr *Error
+			require.True(t, errors.As(err, &policyErr), "ожидалось нарушение политики, получено %v", err)
+			assert.Equal(t, tt.code, policyErr.Code)
+			assert.ErrorIs(t, err, ErrRejected)
+		})
+	}
+}
+
+func TestPolicy_Options(t *testing.T) {
+	ctx := context.Background()
+
+	// Список разрешенных хостов и дополнительные схемы
+	p, err := New(Options{
+		Schemes:      []string{"https", "FTP"},
+		AllowHosts:   []string{"example.com", "*.пример.рф"},
+		AllowPrivate: true,
+		MaxLength:    40,
+	})
+	require.NoError(t, err)
+
+	for _, raw := range []string{"ftp://example.com/f", "https://docs.xn--e1afmkfd.xn--p1ai/"} {
+		_, err := p.Check(ctx, raw)
+		assert.NoError(t, err, raw)
+	}
+	for raw, code := range map[string]Code{
+		"http://example.com/":                            CodeSchemeNotAllowed,
+		"https://www.example.com/":                       CodeHostDenied,
+		"https://пример.рф/":                             CodeHostDenied,
+		"https://localhost/":                             CodeHostDenied,
+		"https://example.com/" + strings.Repeat("x", 40): CodeURLTooLong,
+	} {
+		_, err := p.Check(ctx, raw)
+		var policyErr *Error
+		require.True(t, errors.As(err, &policyErr), raw)
+		assert.Equal(t, code, policyErr.Code, raw)
+	}
+
+	// Адреса внутренних сетей разрешены явно
+	p, err = New(Options{AllowPrivate: true})
+	require.NoError(t, err)
+	_, err = p.Check(ctx, "http://127.0.0.1:9000/")
+	assert.NoError(t, err)
+
+	// Некорректные параметры политики
+	for _, opts := range []Options{
+		{Schemes: []string{"1http"}},
+		{DenyHosts: []string{"*"}},
+		{AllowHosts: []string{"a.*.example.com"}},
+		{SelfHosts: []string{"bad host"}},
+	} {
+		_, err := New(opts)
+		assert.Error(t, err, "%+v", opts)
+	}
+}
+
+func TestPolicy_ResolveHosts(t *testing.T) {
+	p, err := New(Options{ResolveHosts: true})
+	require.NoError(t, err)
+	p.lookup = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "public.example":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+		case "internal.example":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("10.0.0.5")}}, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	}
+	ctx := context.Background()
+
+	_, err = p.Check(ctx, "https://public.example/")
+	assert.NoError(t, err)
+
+	for raw, code := range map[string]Code{
+		"https://internal.example/": CodePrivateAddress,
+		"https://missing.example/":  CodeInvalidHost,
+	} {
+		_, err := p.Check(ctx, raw)
+		var policyErr *Error
+		require.True(t, errors.As(err, &policyErr), raw)
+		assert.Equal(t, code, policyErr.Code, raw)
+	}
+}
+
+func TestParseList(t *testing.T) {
+	assert.Equal(t, []string{"http", "https"}, ParseList(" http, ,https,"))
+	assert.Empty(t, ParseList(""))
+}
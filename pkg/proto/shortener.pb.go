@@ -9,8 +9,10 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -22,11 +24,10 @@ const (
 
 // CreateShortURLRequest - запрос на создание короткого URL из текста
 type CreateShortURLRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"` // Оригинальный URL
 	unknownFields protoimpl.UnknownFields
-
-	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"` // Оригинальный URL
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateShortURLRequest) Reset() {
@@ -68,12 +69,11 @@ func (x *CreateShortURLRequest) GetUrl() string {
 
 // CreateShortURLResponse - ответ с коротким URL
 type CreateShortURLResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"` // Короткий URL
+	Conflict      bool                   `protobuf:"varint,2,opt,name=conflict,proto3" json:"conflict,omitempty"`                // URL уже существует
 	unknownFields protoimpl.UnknownFields
-
-	ShortUrl string `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"` // Короткий URL
-	Conflict bool   `protobuf:"varint,2,opt,name=conflict,proto3" json:"conflict,omitempty"`                // URL уже существует
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateShortURLResponse) Reset() {
@@ -122,11 +122,13 @@ func (x *CreateShortURLResponse) GetConflict() bool {
 
 // ShortenURLRequest - запрос на сокращение URL
 type ShortenURLRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                                  // Оригинальный URL
+	Alias         string                 `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`                              // Желаемый короткий ID (опционально)
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`     // Момент истечения срока действия (опционально)
+	TtlSeconds    int64                  `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // Срок действия в секундах (опционально)
 	unknownFields protoimpl.UnknownFields
-
-	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"` // Оригинальный URL
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ShortenURLRequest) Reset() {
@@ -166,14 +168,34 @@ func (x *ShortenURLRequest) GetUrl() string {
 	return ""
 }
 
+func (x *ShortenURLRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ShortenURLRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ShortenURLRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
 // ShortenURLResponse - ответ с сокращенным URL
 type ShortenURLResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`      // Короткий URL
+	Conflict      bool                   `protobuf:"varint,2,opt,name=conflict,proto3" json:"conflict,omitempty"` // URL уже существует
 	unknownFields protoimpl.UnknownFields
-
-	Result   string `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`      // Короткий URL
-	Conflict bool   `protobuf:"varint,2,opt,name=conflict,proto3" json:"conflict,omitempty"` // URL уже существует
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ShortenURLResponse) Reset() {
@@ -222,12 +244,14 @@ func (x *ShortenURLResponse) GetConflict() bool {
 
 // BatchShortenItem - элемент пакетного запроса
 type BatchShortenItem struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"` // Идентификатор для связи
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`       // Оригинальный URL
+	Alias         string                 `protobuf:"bytes,3,opt,name=alias,proto3" json:"alias,omitempty"`                                      // Желаемый короткий ID (опционально)
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`             // Момент истечения срока действия (опционально)
+	TtlSeconds    int64                  `protobuf:"varint,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`         // Срок действия в секундах (опционально)
 	unknownFields protoimpl.UnknownFields
-
-	CorrelationId string `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"` // Идентификатор для связи
-	OriginalUrl   string `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`       // Оригинальный URL
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BatchShortenItem) Reset() {
@@ -274,14 +298,37 @@ func (x *BatchShortenItem) GetOriginalUrl() string {
 	return ""
 }
 
+func (x *BatchShortenItem) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *BatchShortenItem) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *BatchShortenItem) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
 // BatchShortenResultItem - элемент пакетного ответа
 type BatchShortenResultItem struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"` // Идентификатор из запроса
+	ShortUrl      string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`                // Короткий URL (пустой для элемента с ошибкой)
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                                    // Результат: created, existing или error
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`                                      // Описание ошибки для статуса error
+	ErrorCode     string                 `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`             // Код нарушения политики URL (например, private_address)
 	unknownFields protoimpl.UnknownFields
-
-	CorrelationId string `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"` // Идентификатор из запроса
-	ShortUrl      string `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`                // Короткий URL
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BatchShortenResultItem) Reset() {
@@ -328,13 +375,33 @@ func (x *BatchShortenResultItem) GetShortUrl() string {
 	return ""
 }
 
+func (x *BatchShortenResultItem) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BatchShortenResultItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BatchShortenResultItem) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
 // ShortenBatchRequest - запрос на пакетное сокращение
 type ShortenBatchRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*BatchShortenItem    `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // Список URL для сокращения
 	unknownFields protoimpl.UnknownFields
-
-	Items []*BatchShortenItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // Список URL для сокращения
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ShortenBatchRequest) Reset() {
@@ -376,11 +443,10 @@ func (x *ShortenBatchRequest) GetItems() []*BatchShortenItem {
 
 // ShortenBatchResponse - ответ на пакетное сокращение
 type ShortenBatchResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Items         []*BatchShortenResultItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // Список результатов
 	unknownFields protoimpl.UnknownFields
-
-	Items []*BatchShortenResultItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // Список результатов
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ShortenBatchResponse) Reset() {
@@ -422,11 +488,10 @@ func (x *ShortenBatchResponse) GetItems() []*BatchShortenResultItem {
 
 // GetOriginalURLRequest - запрос на получение оригинального URL
 type GetOriginalURLRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // Короткий ID
 	unknownFields protoimpl.UnknownFields
-
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // Короткий ID
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetOriginalURLRequest) Reset() {
@@ -468,12 +533,11 @@ func (x *GetOriginalURLRequest) GetId() string {
 
 // GetOriginalURLResponse - ответ с оригинальным URL
 type GetOriginalURLResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OriginalUrl   string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"` // Оригинальный URL
+	Deleted       bool                   `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`                           // URL удален
 	unknownFields protoimpl.UnknownFields
-
-	OriginalUrl string `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"` // Оригинальный URL
-	Deleted     bool   `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`                           // URL удален
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetOriginalURLResponse) Reset() {
@@ -522,12 +586,12 @@ func (x *GetOriginalURLResponse) GetDeleted() bool {
 
 // UserURLItem - элемент списка URL пользователя
 type UserURLItem struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`          // Короткий URL
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"` // Оригинальный URL
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`       // Момент создания (если известен)
 	unknownFields protoimpl.UnknownFields
-
-	ShortUrl    string `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`          // Короткий URL
-	OriginalUrl string `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"` // Оригинальный URL
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UserURLItem) Reset() {
@@ -574,11 +638,26 @@ func (x *UserURLItem) GetOriginalUrl() string {
 	return ""
 }
 
+func (x *UserURLItem) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
 // GetUserURLsRequest - запрос на получение URL пользователя
+// (user_id берется из метаданных, все поля необязательные)
 type GetUserURLsRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`                                     // Размер страницы (0 - все URL, не более 1000)
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`                                    // Курсор next_cursor предыдущей страницы
+	Order         string                 `protobuf:"bytes,3,opt,name=order,proto3" json:"order,omitempty"`                                      // desc (сначала новые, по умолчанию) или asc
+	Query         string                 `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`                                      // Подстрока оригинального URL
+	Host          string                 `protobuf:"bytes,5,opt,name=host,proto3" json:"host,omitempty"`                                        // Хост оригинального URL, включая поддомены
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`    // URL создан не раньше этого момента
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"` // URL создан раньше этого момента
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetUserURLsRequest) Reset() {
@@ -611,13 +690,63 @@ func (*GetUserURLsRequest) Descriptor() ([]byte, []int) {
 	return file_api_proto_shortener_proto_rawDescGZIP(), []int{11}
 }
 
-// GetUserURLsResponse - ответ со списком URL пользователя
+func (x *GetUserURLsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetUserURLsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetUserURLsRequest) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+func (x *GetUserURLsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *GetUserURLsRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *GetUserURLsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *GetUserURLsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+// GetUserURLsResponse - ответ со страницей URL пользователя
 type GetUserURLsResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Urls          []*UserURLItem         `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`                               // Список URL пользователя
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`                            // Количество URL, удовлетворяющих фильтру
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Курсор следующей страницы (пустой - страница последняя)
 	unknownFields protoimpl.UnknownFields
-
-	Urls []*UserURLItem `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"` // Список URL пользователя
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetUserURLsResponse) Reset() {
@@ -657,18 +786,109 @@ func (x *GetUserURLsResponse) GetUrls() []*UserURLItem {
 	return nil
 }
 
+func (x *GetUserURLsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetUserURLsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// ExportUserURLsRequest - запрос на выгрузку URL пользователя
+// (user_id берется из метаданных, все поля необязательные)
+type ExportUserURLsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         string                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`                                      // desc (сначала новые, по умолчанию) или asc
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`                                      // Подстрока оригинального URL
+	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`                                        // Хост оригинального URL, включая поддомены
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`    // URL создан не раньше этого момента
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"` // URL создан раньше этого момента
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUserURLsRequest) Reset() {
+	*x = ExportUserURLsRequest{}
+	mi := &file_api_proto_shortener_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUserURLsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUserURLsRequest) ProtoMessage() {}
+
+func (x *ExportUserURLsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUserURLsRequest.ProtoReflect.Descriptor instead.
+func (*ExportUserURLsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ExportUserURLsRequest) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+func (x *ExportUserURLsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ExportUserURLsRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *ExportUserURLsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ExportUserURLsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
 // DeleteUserURLsRequest - запрос на удаление URL пользователя
 type DeleteUserURLsRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrls     []string               `protobuf:"bytes,1,rep,name=short_urls,json=shortUrls,proto3" json:"short_urls,omitempty"` // Список коротких ID для удаления
 	unknownFields protoimpl.UnknownFields
-
-	ShortUrls []string `protobuf:"bytes,1,rep,name=short_urls,json=shortUrls,proto3" json:"short_urls,omitempty"` // Список коротких ID для удаления
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeleteUserURLsRequest) Reset() {
 	*x = DeleteUserURLsRequest{}
-	mi := &file_api_proto_shortener_proto_msgTypes[13]
+	mi := &file_api_proto_shortener_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -680,7 +900,7 @@ func (x *DeleteUserURLsRequest) String() string {
 func (*DeleteUserURLsRequest) ProtoMessage() {}
 
 func (x *DeleteUserURLsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_shortener_proto_msgTypes[13]
+	mi := &file_api_proto_shortener_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -693,7 +913,7 @@ func (x *DeleteUserURLsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteUserURLsRequest.ProtoReflect.Descriptor instead.
 func (*DeleteUserURLsRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_shortener_proto_rawDescGZIP(), []int{13}
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *DeleteUserURLsRequest) GetShortUrls() []string {
@@ -705,16 +925,15 @@ func (x *DeleteUserURLsRequest) GetShortUrls() []string {
 
 // DeleteUserURLsResponse - ответ на удаление URL
 type DeleteUserURLsResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accepted      bool                   `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"` // Запрос принят
 	unknownFields protoimpl.UnknownFields
-
-	Accepted bool `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"` // Запрос принят
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeleteUserURLsResponse) Reset() {
 	*x = DeleteUserURLsResponse{}
-	mi := &file_api_proto_shortener_proto_msgTypes[14]
+	mi := &file_api_proto_shortener_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -726,7 +945,7 @@ func (x *DeleteUserURLsResponse) String() string {
 func (*DeleteUserURLsResponse) ProtoMessage() {}
 
 func (x *DeleteUserURLsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_shortener_proto_msgTypes[14]
+	mi := &file_api_proto_shortener_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -739,7 +958,7 @@ func (x *DeleteUserURLsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteUserURLsResponse.ProtoReflect.Descriptor instead.
 func (*DeleteUserURLsResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_shortener_proto_rawDescGZIP(), []int{14}
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *DeleteUserURLsResponse) GetAccepted() bool {
@@ -751,14 +970,14 @@ func (x *DeleteUserURLsResponse) GetAccepted() bool {
 
 // PingRequest - запрос проверки состояния БД
 type PingRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PingRequest) Reset() {
 	*x = PingRequest{}
-	mi := &file_api_proto_shortener_proto_msgTypes[15]
+	mi := &file_api_proto_shortener_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -770,7 +989,7 @@ func (x *PingRequest) String() string {
 func (*PingRequest) ProtoMessage() {}
 
 func (x *PingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_shortener_proto_msgTypes[15]
+	mi := &file_api_proto_shortener_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -783,21 +1002,20 @@ func (x *PingRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
 func (*PingRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_shortener_proto_rawDescGZIP(), []int{15}
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{16}
 }
 
 // PingResponse - ответ проверки состояния БД
 type PingResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"` // База данных доступна
 	unknownFields protoimpl.UnknownFields
-
-	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"` // База данных доступна
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PingResponse) Reset() {
 	*x = PingResponse{}
-	mi := &file_api_proto_shortener_proto_msgTypes[16]
+	mi := &file_api_proto_shortener_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -809,7 +1027,7 @@ func (x *PingResponse) String() string {
 func (*PingResponse) ProtoMessage() {}
 
 func (x *PingResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_shortener_proto_msgTypes[16]
+	mi := &file_api_proto_shortener_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -822,7 +1040,7 @@ func (x *PingResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
 func (*PingResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_shortener_proto_rawDescGZIP(), []int{16}
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *PingResponse) GetOk() bool {
@@ -834,14 +1052,14 @@ func (x *PingResponse) GetOk() bool {
 
 // GetStatsRequest - запрос статистики
 type GetStatsRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetStatsRequest) Reset() {
 	*x = GetStatsRequest{}
-	mi := &file_api_proto_shortener_proto_msgTypes[17]
+	mi := &file_api_proto_shortener_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -853,7 +1071,7 @@ func (x *GetStatsRequest) String() string {
 func (*GetStatsRequest) ProtoMessage() {}
 
 func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_shortener_proto_msgTypes[17]
+	mi := &file_api_proto_shortener_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -866,22 +1084,23 @@ func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
 func (*GetStatsRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_shortener_proto_rawDescGZIP(), []int{17}
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{18}
 }
 
 // GetStatsResponse - ответ со статистикой
 type GetStatsResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Urls          int32                  `protobuf:"varint,1,opt,name=urls,proto3" json:"urls,omitempty"`                                  // Количество URL
+	Users         int32                  `protobuf:"varint,2,opt,name=users,proto3" json:"users,omitempty"`                                // Количество пользователей
+	CacheHits     int64                  `protobuf:"varint,3,opt,name=cache_hits,json=cacheHits,proto3" json:"cache_hits,omitempty"`       // Переходы, обслуженные из кэша ссылок
+	CacheMisses   int64                  `protobuf:"varint,4,opt,name=cache_misses,json=cacheMisses,proto3" json:"cache_misses,omitempty"` // Переходы, потребовавшие обращения к базе данных
 	unknownFields protoimpl.UnknownFields
-
-	Urls  int32 `protobuf:"varint,1,opt,name=urls,proto3" json:"urls,omitempty"`   // Количество URL
-	Users int32 `protobuf:"varint,2,opt,name=users,proto3" json:"users,omitempty"` // Количество пользователей
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetStatsResponse) Reset() {
 	*x = GetStatsResponse{}
-	mi := &file_api_proto_shortener_proto_msgTypes[18]
+	mi := &file_api_proto_shortener_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -893,7 +1112,7 @@ func (x *GetStatsResponse) String() string {
 func (*GetStatsResponse) ProtoMessage() {}
 
 func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_shortener_proto_msgTypes[18]
+	mi := &file_api_proto_shortener_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -906,7 +1125,7 @@ func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
 func (*GetStatsResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_shortener_proto_rawDescGZIP(), []int{18}
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *GetStatsResponse) GetUrls() int32 {
@@ -923,141 +1142,1051 @@ func (x *GetStatsResponse) GetUsers() int32 {
 	return 0
 }
 
-var File_api_proto_shortener_proto protoreflect.FileDescriptor
+func (x *GetStatsResponse) GetCacheHits() int64 {
+	if x != nil {
+		return x.CacheHits
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetCacheMisses() int64 {
+	if x != nil {
+		return x.CacheMisses
+	}
+	return 0
+}
+
+// GetURLStatsRequest - запрос статистики переходов по URL пользователя
+type GetURLStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`        // Короткий ID
+	Hours         int32                  `protobuf:"varint,2,opt,name=hours,proto3" json:"hours,omitempty"` // Глубина почасовой истории (0 - 168 часов)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-var file_api_proto_shortener_proto_rawDesc = []byte{
-	0x0a, 0x19, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x68, 0x6f, 0x72,
-	0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x73, 0x68, 0x6f,
-	0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x22, 0x29, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72,
-	0x6c, 0x22, 0x51, 0x0a, 0x16, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x6f, 0x72, 0x74,
-	0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x73,
-	0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x66,
-	0x6c, 0x69, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x66,
-	0x6c, 0x69, 0x63, 0x74, 0x22, 0x25, 0x0a, 0x11, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55,
-	0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x22, 0x4c, 0x0a, 0x12, 0x53,
-	0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e,
-	0x66, 0x6c, 0x69, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x63, 0x6f, 0x6e,
-	0x66, 0x6c, 0x69, 0x63, 0x74, 0x22, 0x5a, 0x0a, 0x10, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x68,
-	0x6f, 0x72, 0x74, 0x65, 0x6e, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x72,
-	0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0d, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64,
-	0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75, 0x72, 0x6c,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c,
-	0x55, 0x72, 0x6c, 0x22, 0x5e, 0x0a, 0x16, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x68, 0x6f, 0x72,
-	0x74, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x25, 0x0a,
-	0x0e, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72,
-	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72,
-	0x6c, 0x22, 0x4d, 0x0a, 0x13, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63,
-	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65,
-	0x6e, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
-	0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x53, 0x0a, 0x14, 0x53,
-	0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x21, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x42,
-	0x61, 0x74, 0x63, 0x68, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c,
-	0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x27, 0x0a, 0x15,
-	0x47, 0x65, 0x74, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x52, 0x4c, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x59, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x69, 0x67,
-	0x69, 0x6e, 0x61, 0x6c, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75, 0x72, 0x6c, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55,
-	0x72, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0x53, 0x0a, 0x0b,
-	0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x1b, 0x0a, 0x09, 0x73,
-	0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67,
-	0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
-	0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x22, 0x14, 0x0a, 0x12, 0x47,
-	0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x22, 0x46, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
-	0x65, 0x72, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x04,
-	0x75, 0x72, 0x6c, 0x73, 0x22, 0x38, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73,
-	0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a,
-	0x0a, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x09, 0x52, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x73, 0x22, 0x34, 0x0a, 0x16,
-	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74,
-	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74,
-	0x65, 0x64, 0x22, 0x0d, 0x0a, 0x0b, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x22, 0x1e, 0x0a, 0x0c, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f,
-	0x6b, 0x22, 0x11, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x22, 0x3e, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x12, 0x14, 0x0a, 0x05,
-	0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x75, 0x73, 0x65,
-	0x72, 0x73, 0x32, 0xd4, 0x04, 0x0a, 0x10, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72,
-	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x59, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x12, 0x20, 0x2e, 0x73, 0x68, 0x6f, 0x72,
-	0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x6f, 0x72,
-	0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x73, 0x68,
-	0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68,
-	0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x12, 0x4d, 0x0a, 0x0a, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x12, 0x1c,
-	0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74,
-	0x65, 0x6e, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73,
-	0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
-	0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x53, 0x0a,
-	0x0c, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1e, 0x2e,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65,
-	0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65,
-	0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x12, 0x59, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55,
-	0x52, 0x4c, 0x12, 0x20, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47,
-	0x65, 0x74, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72,
-	0x2e, 0x47, 0x65, 0x74, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x52, 0x4c, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x50, 0x0a, 0x0b, 0x47, 0x65, 0x74,
-	0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x1d, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74,
-	0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65,
-	0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x59, 0x0a, 0x0e, 0x44, 0x65, 0x6c,
-	0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x20, 0x2e, 0x73, 0x68,
-	0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73,
-	0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x00, 0x12, 0x3b, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x2e, 0x73, 0x68, 0x6f,
-	0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x17, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x50,
-	0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x47, 0x0a,
-	0x08, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1a, 0x2e, 0x73, 0x68, 0x6f, 0x72,
-	0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65,
-	0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
-	0x6f, 0x6d, 0x2f, 0x41, 0x64, 0x69, 0x67, 0x65, 0x7a, 0x61, 0x6c, 0x6f, 0x76, 0x2f, 0x73, 0x68,
-	0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *GetURLStatsRequest) Reset() {
+	*x = GetURLStatsRequest{}
+	mi := &file_api_proto_shortener_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
+func (*GetURLStatsRequest) ProtoMessage() {}
+
+func (x *GetURLStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetURLStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetURLStatsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetURLStatsRequest) GetHours() int32 {
+	if x != nil {
+		return x.Hours
+	}
+	return 0
+}
+
+// StatsCounter - значение с количеством переходов
+type StatsCounter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`    // Хост источника или семейство клиента
+	Clicks        int64                  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"` // Количество переходов
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsCounter) Reset() {
+	*x = StatsCounter{}
+	mi := &file_api_proto_shortener_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsCounter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsCounter) ProtoMessage() {}
+
+func (x *StatsCounter) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsCounter.ProtoReflect.Descriptor instead.
+func (*StatsCounter) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StatsCounter) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *StatsCounter) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+// HourlyClicks - количество переходов за час
+type HourlyClicks struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hour          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=hour,proto3" json:"hour,omitempty"`      // Начало часа (UTC)
+	Clicks        int64                  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"` // Количество переходов
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HourlyClicks) Reset() {
+	*x = HourlyClicks{}
+	mi := &file_api_proto_shortener_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HourlyClicks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HourlyClicks) ProtoMessage() {}
+
+func (x *HourlyClicks) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HourlyClicks.ProtoReflect.Descriptor instead.
+func (*HourlyClicks) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *HourlyClicks) GetHour() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Hour
+	}
+	return nil
+}
+
+func (x *HourlyClicks) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+// GetURLStatsResponse - ответ со статистикой переходов
+type GetURLStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`               // Короткий URL
+	Clicks        int64                  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`                                  // Общее количество переходов
+	FirstClickAt  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=first_click_at,json=firstClickAt,proto3" json:"first_click_at,omitempty"` // Первый переход
+	LastClickAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_click_at,json=lastClickAt,proto3" json:"last_click_at,omitempty"`    // Последний переход
+	Referrers     []*StatsCounter        `protobuf:"bytes,5,rep,name=referrers,proto3" json:"referrers,omitempty"`                             // Источники переходов
+	UserAgents    []*StatsCounter        `protobuf:"bytes,6,rep,name=user_agents,json=userAgents,proto3" json:"user_agents,omitempty"`         // Семейства клиентов
+	Hourly        []*HourlyClicks        `protobuf:"bytes,7,rep,name=hourly,proto3" json:"hourly,omitempty"`                                   // Почасовая история
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetURLStatsResponse) Reset() {
+	*x = GetURLStatsResponse{}
+	mi := &file_api_proto_shortener_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLStatsResponse) ProtoMessage() {}
+
+func (x *GetURLStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetURLStatsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetURLStatsResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetURLStatsResponse) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+func (x *GetURLStatsResponse) GetFirstClickAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstClickAt
+	}
+	return nil
+}
+
+func (x *GetURLStatsResponse) GetLastClickAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastClickAt
+	}
+	return nil
+}
+
+func (x *GetURLStatsResponse) GetReferrers() []*StatsCounter {
+	if x != nil {
+		return x.Referrers
+	}
+	return nil
+}
+
+func (x *GetURLStatsResponse) GetUserAgents() []*StatsCounter {
+	if x != nil {
+		return x.UserAgents
+	}
+	return nil
+}
+
+func (x *GetURLStatsResponse) GetHourly() []*HourlyClicks {
+	if x != nil {
+		return x.Hourly
+	}
+	return nil
+}
+
+// SearchLinksRequest - запрос поиска ссылок (все поля необязательные)
+type SearchLinksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortId       string                 `protobuf:"bytes,1,opt,name=short_id,json=shortId,proto3" json:"short_id,omitempty"`             // Короткий ID
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`                                // Подстрока оригинального URL
+	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`                                  // Хост оригинального URL, включая поддомены
+	UserId        string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                // ID владельца
+	Login         string                 `protobuf:"bytes,5,opt,name=login,proto3" json:"login,omitempty"`                                // Логин владельца
+	CreatedFrom   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"` // Начало периода создания
+	CreatedTo     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`       // Конец периода создания (не включая)
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`                              // Состояние: active, disabled или deleted
+	Offset        int32                  `protobuf:"varint,9,opt,name=offset,proto3" json:"offset,omitempty"`                             // Количество пропускаемых ссылок
+	Limit         int32                  `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`                              // Размер страницы (0 - 50)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchLinksRequest) Reset() {
+	*x = SearchLinksRequest{}
+	mi := &file_api_proto_shortener_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchLinksRequest) ProtoMessage() {}
+
+func (x *SearchLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchLinksRequest.ProtoReflect.Descriptor instead.
+func (*SearchLinksRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SearchLinksRequest) GetShortId() string {
+	if x != nil {
+		return x.ShortId
+	}
+	return ""
+}
+
+func (x *SearchLinksRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchLinksRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *SearchLinksRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SearchLinksRequest) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *SearchLinksRequest) GetCreatedFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return nil
+}
+
+func (x *SearchLinksRequest) GetCreatedTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return nil
+}
+
+func (x *SearchLinksRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SearchLinksRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SearchLinksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// AdminLink - ссылка с владельцем и состоянием
+type AdminLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortId       string                 `protobuf:"bytes,1,opt,name=short_id,json=shortId,proto3" json:"short_id,omitempty"`             // Короткий ID
+	ShortUrl      string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`          // Короткий URL
+	OriginalUrl   string                 `protobuf:"bytes,3,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"` // Оригинальный URL
+	UserId        string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                // ID владельца
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`       // Момент создания
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`       // Момент истечения срока действия
+	Deleted       bool                   `protobuf:"varint,7,opt,name=deleted,proto3" json:"deleted,omitempty"`                           // Ссылка удалена владельцем
+	Disabled      bool                   `protobuf:"varint,8,opt,name=disabled,proto3" json:"disabled,omitempty"`                         // Ссылка отключена модератором
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminLink) Reset() {
+	*x = AdminLink{}
+	mi := &file_api_proto_shortener_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminLink) ProtoMessage() {}
+
+func (x *AdminLink) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminLink.ProtoReflect.Descriptor instead.
+func (*AdminLink) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AdminLink) GetShortId() string {
+	if x != nil {
+		return x.ShortId
+	}
+	return ""
+}
+
+func (x *AdminLink) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *AdminLink) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *AdminLink) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AdminLink) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *AdminLink) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *AdminLink) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *AdminLink) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+// SearchLinksResponse - страница результатов поиска
+type SearchLinksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Links         []*AdminLink           `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`    // Ссылки
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`   // Количество найденных ссылок на всех страницах
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"` // Смещение страницы
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`   // Размер страницы
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchLinksResponse) Reset() {
+	*x = SearchLinksResponse{}
+	mi := &file_api_proto_shortener_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchLinksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchLinksResponse) ProtoMessage() {}
+
+func (x *SearchLinksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchLinksResponse.ProtoReflect.Descriptor instead.
+func (*SearchLinksResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SearchLinksResponse) GetLinks() []*AdminLink {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *SearchLinksResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchLinksResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SearchLinksResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// GetLinkRequest - запрос ссылки для модерации
+type GetLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // Короткий ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLinkRequest) Reset() {
+	*x = GetLinkRequest{}
+	mi := &file_api_proto_shortener_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLinkRequest) ProtoMessage() {}
+
+func (x *GetLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLinkRequest.ProtoReflect.Descriptor instead.
+func (*GetLinkRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetLinkRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// AdminLinksRequest - групповая операция над ссылками
+type AdminLinksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"` // Короткие ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminLinksRequest) Reset() {
+	*x = AdminLinksRequest{}
+	mi := &file_api_proto_shortener_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminLinksRequest) ProtoMessage() {}
+
+func (x *AdminLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminLinksRequest.ProtoReflect.Descriptor instead.
+func (*AdminLinksRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *AdminLinksRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+// ReassignLinksRequest - запрос передачи ссылок
+type ReassignLinksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`                     // Короткие ID
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // ID нового владельца
+	Login         string                 `protobuf:"bytes,3,opt,name=login,proto3" json:"login,omitempty"`                 // Логин нового владельца (вместо user_id)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReassignLinksRequest) Reset() {
+	*x = ReassignLinksRequest{}
+	mi := &file_api_proto_shortener_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReassignLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReassignLinksRequest) ProtoMessage() {}
+
+func (x *ReassignLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReassignLinksRequest.ProtoReflect.Descriptor instead.
+func (*ReassignLinksRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ReassignLinksRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *ReassignLinksRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ReassignLinksRequest) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+// AdminLinksResponse - результат групповой операции
+type AdminLinksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Affected      int32                  `protobuf:"varint,1,opt,name=affected,proto3" json:"affected,omitempty"` // Количество измененных ссылок
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminLinksResponse) Reset() {
+	*x = AdminLinksResponse{}
+	mi := &file_api_proto_shortener_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminLinksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminLinksResponse) ProtoMessage() {}
+
+func (x *AdminLinksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminLinksResponse.ProtoReflect.Descriptor instead.
+func (*AdminLinksResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AdminLinksResponse) GetAffected() int32 {
+	if x != nil {
+		return x.Affected
+	}
+	return 0
+}
+
+// SetAccountRoleRequest - запрос назначения роли
+type SetAccountRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Login         string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"` // Логин учетной записи
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`   // Роль: user или admin
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAccountRoleRequest) Reset() {
+	*x = SetAccountRoleRequest{}
+	mi := &file_api_proto_shortener_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAccountRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAccountRoleRequest) ProtoMessage() {}
+
+func (x *SetAccountRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAccountRoleRequest.ProtoReflect.Descriptor instead.
+func (*SetAccountRoleRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SetAccountRoleRequest) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *SetAccountRoleRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+// SetAccountRoleResponse - учетная запись с новой ролью
+type SetAccountRoleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // ID пользователя
+	Login         string                 `protobuf:"bytes,2,opt,name=login,proto3" json:"login,omitempty"`                 // Логин
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`                   // Роль
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAccountRoleResponse) Reset() {
+	*x = SetAccountRoleResponse{}
+	mi := &file_api_proto_shortener_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAccountRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAccountRoleResponse) ProtoMessage() {}
+
+func (x *SetAccountRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shortener_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAccountRoleResponse.ProtoReflect.Descriptor instead.
+func (*SetAccountRoleResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_shortener_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SetAccountRoleResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetAccountRoleResponse) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *SetAccountRoleResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+var File_api_proto_shortener_proto protoreflect.FileDescriptor
+
+const file_api_proto_shortener_proto_rawDesc = "" +
+	"\n" +
+	"\x19api/proto/shortener.proto\x12\tshortener\x1a\x1fgoogle/protobuf/timestamp.proto\")\n" +
+	"\x15CreateShortURLRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\"Q\n" +
+	"\x16CreateShortURLResponse\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x1a\n" +
+	"\bconflict\x18\x02 \x01(\bR\bconflict\"\x97\x01\n" +
+	"\x11ShortenURLRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05alias\x18\x02 \x01(\tR\x05alias\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1f\n" +
+	"\vttl_seconds\x18\x04 \x01(\x03R\n" +
+	"ttlSeconds\"H\n" +
+	"\x12ShortenURLResponse\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\x12\x1a\n" +
+	"\bconflict\x18\x02 \x01(\bR\bconflict\"\xce\x01\n" +
+	"\x10BatchShortenItem\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x14\n" +
+	"\x05alias\x18\x03 \x01(\tR\x05alias\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1f\n" +
+	"\vttl_seconds\x18\x05 \x01(\x03R\n" +
+	"ttlSeconds\"\xa9\x01\n" +
+	"\x16BatchShortenResultItem\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12\x1b\n" +
+	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\tR\terrorCode\"H\n" +
+	"\x13ShortenBatchRequest\x121\n" +
+	"\x05items\x18\x01 \x03(\v2\x1b.shortener.BatchShortenItemR\x05items\"O\n" +
+	"\x14ShortenBatchResponse\x127\n" +
+	"\x05items\x18\x01 \x03(\v2!.shortener.BatchShortenResultItemR\x05items\"'\n" +
+	"\x15GetOriginalURLRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"U\n" +
+	"\x16GetOriginalURLResponse\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\x12\x18\n" +
+	"\adeleted\x18\x02 \x01(\bR\adeleted\"\x88\x01\n" +
+	"\vUserURLItem\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x86\x02\n" +
+	"\x12GetUserURLsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05order\x18\x03 \x01(\tR\x05order\x12\x14\n" +
+	"\x05query\x18\x04 \x01(\tR\x05query\x12\x12\n" +
+	"\x04host\x18\x05 \x01(\tR\x04host\x12?\n" +
+	"\rcreated_after\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\"x\n" +
+	"\x13GetUserURLsResponse\x12*\n" +
+	"\x04urls\x18\x01 \x03(\v2\x16.shortener.UserURLItemR\x04urls\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"\xdb\x01\n" +
+	"\x15ExportUserURLsRequest\x12\x14\n" +
+	"\x05order\x18\x01 \x01(\tR\x05order\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12?\n" +
+	"\rcreated_after\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\"6\n" +
+	"\x15DeleteUserURLsRequest\x12\x1d\n" +
+	"\n" +
+	"short_urls\x18\x01 \x03(\tR\tshortUrls\"4\n" +
+	"\x16DeleteUserURLsResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\bR\baccepted\"\r\n" +
+	"\vPingRequest\"\x1e\n" +
+	"\fPingResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"\x11\n" +
+	"\x0fGetStatsRequest\"~\n" +
+	"\x10GetStatsResponse\x12\x12\n" +
+	"\x04urls\x18\x01 \x01(\x05R\x04urls\x12\x14\n" +
+	"\x05users\x18\x02 \x01(\x05R\x05users\x12\x1d\n" +
+	"\n" +
+	"cache_hits\x18\x03 \x01(\x03R\tcacheHits\x12!\n" +
+	"\fcache_misses\x18\x04 \x01(\x03R\vcacheMisses\":\n" +
+	"\x12GetURLStatsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05hours\x18\x02 \x01(\x05R\x05hours\"<\n" +
+	"\fStatsCounter\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\"V\n" +
+	"\fHourlyClicks\x12.\n" +
+	"\x04hour\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04hour\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\"\xee\x02\n" +
+	"\x13GetURLStatsResponse\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x03R\x06clicks\x12@\n" +
+	"\x0efirst_click_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ffirstClickAt\x12>\n" +
+	"\rlast_click_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vlastClickAt\x125\n" +
+	"\treferrers\x18\x05 \x03(\v2\x17.shortener.StatsCounterR\treferrers\x128\n" +
+	"\vuser_agents\x18\x06 \x03(\v2\x17.shortener.StatsCounterR\n" +
+	"userAgents\x12/\n" +
+	"\x06hourly\x18\a \x03(\v2\x17.shortener.HourlyClicksR\x06hourly\"\xc8\x02\n" +
+	"\x12SearchLinksRequest\x12\x19\n" +
+	"\bshort_id\x18\x01 \x01(\tR\ashortId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05login\x18\x05 \x01(\tR\x05login\x12=\n" +
+	"\fcreated_from\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedFrom\x129\n" +
+	"\n" +
+	"created_to\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedTo\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x16\n" +
+	"\x06offset\x18\t \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\n" +
+	" \x01(\x05R\x05limit\"\xab\x02\n" +
+	"\tAdminLink\x12\x19\n" +
+	"\bshort_id\x18\x01 \x01(\tR\ashortId\x12\x1b\n" +
+	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\x12!\n" +
+	"\foriginal_url\x18\x03 \x01(\tR\voriginalUrl\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x18\n" +
+	"\adeleted\x18\a \x01(\bR\adeleted\x12\x1a\n" +
+	"\bdisabled\x18\b \x01(\bR\bdisabled\"\x85\x01\n" +
+	"\x13SearchLinksResponse\x12*\n" +
+	"\x05links\x18\x01 \x03(\v2\x14.shortener.AdminLinkR\x05links\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\" \n" +
+	"\x0eGetLinkRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"%\n" +
+	"\x11AdminLinksRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"W\n" +
+	"\x14ReassignLinksRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05login\x18\x03 \x01(\tR\x05login\"0\n" +
+	"\x12AdminLinksResponse\x12\x1a\n" +
+	"\baffected\x18\x01 \x01(\x05R\baffected\"A\n" +
+	"\x15SetAccountRoleRequest\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\"[\n" +
+	"\x16SetAccountRoleResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05login\x18\x02 \x01(\tR\x05login\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role2\xf0\x06\n" +
+	"\x10ShortenerService\x12U\n" +
+	"\x0eCreateShortURL\x12 .shortener.CreateShortURLRequest\x1a!.shortener.CreateShortURLResponse\x12I\n" +
+	"\n" +
+	"ShortenURL\x12\x1c.shortener.ShortenURLRequest\x1a\x1d.shortener.ShortenURLResponse\x12O\n" +
+	"\fShortenBatch\x12\x1e.shortener.ShortenBatchRequest\x1a\x1f.shortener.ShortenBatchResponse\x12S\n" +
+	"\rShortenStream\x12\x1b.shortener.BatchShortenItem\x1a!.shortener.BatchShortenResultItem(\x010\x01\x12U\n" +
+	"\x0eGetOriginalURL\x12 .shortener.GetOriginalURLRequest\x1a!.shortener.GetOriginalURLResponse\x12L\n" +
+	"\vGetUserURLs\x12\x1d.shortener.GetUserURLsRequest\x1a\x1e.shortener.GetUserURLsResponse\x12L\n" +
+	"\x0eExportUserURLs\x12 .shortener.ExportUserURLsRequest\x1a\x16.shortener.UserURLItem0\x01\x12U\n" +
+	"\x0eDeleteUserURLs\x12 .shortener.DeleteUserURLsRequest\x1a!.shortener.DeleteUserURLsResponse\x127\n" +
+	"\x04Ping\x12\x16.shortener.PingRequest\x1a\x17.shortener.PingResponse\x12C\n" +
+	"\bGetStats\x12\x1a.shortener.GetStatsRequest\x1a\x1b.shortener.GetStatsResponse\x12L\n" +
+	"\vGetURLStats\x12\x1d.shortener.GetURLStatsRequest\x1a\x1e.shortener.GetURLStatsResponse2\xa5\x04\n" +
+	"\fAdminService\x12L\n" +
+	"\vSearchLinks\x12\x1d.shortener.SearchLinksRequest\x1a\x1e.shortener.SearchLinksResponse\x12:\n" +
+	"\aGetLink\x12\x19.shortener.GetLinkRequest\x1a\x14.shortener.AdminLink\x12K\n" +
+	"\fDisableLinks\x12\x1c.shortener.AdminLinksRequest\x1a\x1d.shortener.AdminLinksResponse\x12J\n" +
+	"\vEnableLinks\x12\x1c.shortener.AdminLinksRequest\x1a\x1d.shortener.AdminLinksResponse\x12J\n" +
+	"\vDeleteLinks\x12\x1c.shortener.AdminLinksRequest\x1a\x1d.shortener.AdminLinksResponse\x12O\n" +
+	"\rReassignLinks\x12\x1f.shortener.ReassignLinksRequest\x1a\x1d.shortener.AdminLinksResponse\x12U\n" +
+	"\x0eSetAccountRole\x12 .shortener.SetAccountRoleRequest\x1a!.shortener.SetAccountRoleResponseB+Z)github.com/Adigezalov/shortener/pkg/protob\x06proto3"
+
 var (
 	file_api_proto_shortener_proto_rawDescOnce sync.Once
-	file_api_proto_shortener_proto_rawDescData = file_api_proto_shortener_proto_rawDesc
+	file_api_proto_shortener_proto_rawDescData []byte
 )
 
 func file_api_proto_shortener_proto_rawDescGZIP() []byte {
 	file_api_proto_shortener_proto_rawDescOnce.Do(func() {
-		file_api_proto_shortener_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_proto_shortener_proto_rawDescData)
+		file_api_proto_shortener_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_shortener_proto_rawDesc), len(file_api_proto_shortener_proto_rawDesc)))
 	})
 	return file_api_proto_shortener_proto_rawDescData
 }
 
-var file_api_proto_shortener_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_api_proto_shortener_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_api_proto_shortener_proto_goTypes = []any{
 	(*CreateShortURLRequest)(nil),  // 0: shortener.CreateShortURLRequest
 	(*CreateShortURLResponse)(nil), // 1: shortener.CreateShortURLResponse
@@ -1072,38 +2201,91 @@ var file_api_proto_shortener_proto_goTypes = []any{
 	(*UserURLItem)(nil),            // 10: shortener.UserURLItem
 	(*GetUserURLsRequest)(nil),     // 11: shortener.GetUserURLsRequest
 	(*GetUserURLsResponse)(nil),    // 12: shortener.GetUserURLsResponse
-	(*DeleteUserURLsRequest)(nil),  // 13: shortener.DeleteUserURLsRequest
-	(*DeleteUserURLsResponse)(nil), // 14: shortener.DeleteUserURLsResponse
-	(*PingRequest)(nil),            // 15: shortener.PingRequest
-	(*PingResponse)(nil),           // 16: shortener.PingResponse
-	(*GetStatsRequest)(nil),        // 17: shortener.GetStatsRequest
-	(*GetStatsResponse)(nil),       // 18: shortener.GetStatsResponse
+	(*ExportUserURLsRequest)(nil),  // 13: shortener.ExportUserURLsRequest
+	(*DeleteUserURLsRequest)(nil),  // 14: shortener.DeleteUserURLsRequest
+	(*DeleteUserURLsResponse)(nil), // 15: shortener.DeleteUserURLsResponse
+	(*PingRequest)(nil),            // 16: shortener.PingRequest
+	(*PingResponse)(nil),           // 17: shortener.PingResponse
+	(*GetStatsRequest)(nil),        // 18: shortener.GetStatsRequest
+	(*GetStatsResponse)(nil),       // 19: shortener.GetStatsResponse
+	(*GetURLStatsRequest)(nil),     // 20: shortener.GetURLStatsRequest
+	(*StatsCounter)(nil),           // 21: shortener.StatsCounter
+	(*HourlyClicks)(nil),           // 22: shortener.HourlyClicks
+	(*GetURLStatsResponse)(nil),    // 23: shortener.GetURLStatsResponse
+	(*SearchLinksRequest)(nil),     // 24: shortener.SearchLinksRequest
+	(*AdminLink)(nil),              // 25: shortener.AdminLink
+	(*SearchLinksResponse)(nil),    // 26: shortener.SearchLinksResponse
+	(*GetLinkRequest)(nil),         // 27: shortener.GetLinkRequest
+	(*AdminLinksRequest)(nil),      // 28: shortener.AdminLinksRequest
+	(*ReassignLinksRequest)(nil),   // 29: shortener.ReassignLinksRequest
+	(*AdminLinksResponse)(nil),     // 30: shortener.AdminLinksResponse
+	(*SetAccountRoleRequest)(nil),  // 31: shortener.SetAccountRoleRequest
+	(*SetAccountRoleResponse)(nil), // 32: shortener.SetAccountRoleResponse
+	(*timestamppb.Timestamp)(nil),  // 33: google.protobuf.Timestamp
 }
 var file_api_proto_shortener_proto_depIdxs = []int32{
-	4,  // 0: shortener.ShortenBatchRequest.items:type_name -> shortener.BatchShortenItem
-	5,  // 1: shortener.ShortenBatchResponse.items:type_name -> shortener.BatchShortenResultItem
-	10, // 2: shortener.GetUserURLsResponse.urls:type_name -> shortener.UserURLItem
-	0,  // 3: shortener.ShortenerService.CreateShortURL:input_type -> shortener.CreateShortURLRequest
-	2,  // 4: shortener.ShortenerService.ShortenURL:input_type -> shortener.ShortenURLRequest
-	6,  // 5: shortener.ShortenerService.ShortenBatch:input_type -> shortener.ShortenBatchRequest
-	8,  // 6: shortener.ShortenerService.GetOriginalURL:input_type -> shortener.GetOriginalURLRequest
-	11, // 7: shortener.ShortenerService.GetUserURLs:input_type -> shortener.GetUserURLsRequest
-	13, // 8: shortener.ShortenerService.DeleteUserURLs:input_type -> shortener.DeleteUserURLsRequest
-	15, // 9: shortener.ShortenerService.Ping:input_type -> shortener.PingRequest
-	17, // 10: shortener.ShortenerService.GetStats:input_type -> shortener.GetStatsRequest
-	1,  // 11: shortener.ShortenerService.CreateShortURL:output_type -> shortener.CreateShortURLResponse
-	3,  // 12: shortener.ShortenerService.ShortenURL:output_type -> shortener.ShortenURLResponse
-	7,  // 13: shortener.ShortenerService.ShortenBatch:output_type -> shortener.ShortenBatchResponse
-	9,  // 14: shortener.ShortenerService.GetOriginalURL:output_type -> shortener.GetOriginalURLResponse
-	12, // 15: shortener.ShortenerService.GetUserURLs:output_type -> shortener.GetUserURLsResponse
-	14, // 16: shortener.ShortenerService.DeleteUserURLs:output_type -> shortener.DeleteUserURLsResponse
-	16, // 17: shortener.ShortenerService.Ping:output_type -> shortener.PingResponse
-	18, // 18: shortener.ShortenerService.GetStats:output_type -> shortener.GetStatsResponse
-	11, // [11:19] is the sub-list for method output_type
-	3,  // [3:11] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	33, // 0: shortener.ShortenURLRequest.expires_at:type_name -> google.protobuf.Timestamp
+	33, // 1: shortener.BatchShortenItem.expires_at:type_name -> google.protobuf.Timestamp
+	4,  // 2: shortener.ShortenBatchRequest.items:type_name -> shortener.BatchShortenItem
+	5,  // 3: shortener.ShortenBatchResponse.items:type_name -> shortener.BatchShortenResultItem
+	33, // 4: shortener.UserURLItem.created_at:type_name -> google.protobuf.Timestamp
+	33, // 5: shortener.GetUserURLsRequest.created_after:type_name -> google.protobuf.Timestamp
+	33, // 6: shortener.GetUserURLsRequest.created_before:type_name -> google.protobuf.Timestamp
+	10, // 7: shortener.GetUserURLsResponse.urls:type_name -> shortener.UserURLItem
+	33, // 8: shortener.ExportUserURLsRequest.created_after:type_name -> google.protobuf.Timestamp
+	33, // 9: shortener.ExportUserURLsRequest.created_before:type_name -> google.protobuf.Timestamp
+	33, // 10: shortener.HourlyClicks.hour:type_name -> google.protobuf.Timestamp
+	33, // 11: shortener.GetURLStatsResponse.first_click_at:type_name -> google.protobuf.Timestamp
+	33, // 12: shortener.GetURLStatsResponse.last_click_at:type_name -> google.protobuf.Timestamp
+	21, // 13: shortener.GetURLStatsResponse.referrers:type_name -> shortener.StatsCounter
+	21, // 14: shortener.GetURLStatsResponse.user_agents:type_name -> shortener.StatsCounter
+	22, // 15: shortener.GetURLStatsResponse.hourly:type_name -> shortener.HourlyClicks
+	33, // 16: shortener.SearchLinksRequest.created_from:type_name -> google.protobuf.Timestamp
+	33, // 17: shortener.SearchLinksRequest.created_to:type_name -> google.protobuf.Timestamp
+	33, // 18: shortener.AdminLink.created_at:type_name -> google.protobuf.Timestamp
+	33, // 19: shortener.AdminLink.expires_at:type_name -> google.protobuf.Timestamp
+	25, // 20: shortener.SearchLinksResponse.links:type_name -> shortener.AdminLink
+	0,  // 21: shortener.ShortenerService.CreateShortURL:input_type -> shortener.CreateShortURLRequest
+	2,  // 22: shortener.ShortenerService.ShortenURL:input_type -> shortener.ShortenURLRequest
+	6,  // 23: shortener.ShortenerService.ShortenBatch:input_type -> shortener.ShortenBatchRequest
+	4,  // 24: shortener.ShortenerService.ShortenStream:input_type -> shortener.BatchShortenItem
+	8,  // 25: shortener.ShortenerService.GetOriginalURL:input_type -> shortener.GetOriginalURLRequest
+	11, // 26: shortener.ShortenerService.GetUserURLs:input_type -> shortener.GetUserURLsRequest
+	13, // 27: shortener.ShortenerService.ExportUserURLs:input_type -> shortener.ExportUserURLsRequest
+	14, // 28: shortener.ShortenerService.DeleteUserURLs:input_type -> shortener.DeleteUserURLsRequest
+	16, // 29: shortener.ShortenerService.Ping:input_type -> shortener.PingRequest
+	18, // 30: shortener.ShortenerService.GetStats:input_type -> shortener.GetStatsRequest
+	20, // 31: shortener.ShortenerService.GetURLStats:input_type -> shortener.GetURLStatsRequest
+	24, // 32: shortener.AdminService.SearchLinks:input_type -> shortener.SearchLinksRequest
+	27, // 33: shortener.AdminService.GetLink:input_type -> shortener.GetLinkRequest
+	28, // 34: shortener.AdminService.DisableLinks:input_type -> shortener.AdminLinksRequest
+	28, // 35: shortener.AdminService.EnableLinks:input_type -> shortener.AdminLinksRequest
+	28, // 36: shortener.AdminService.DeleteLinks:input_type -> shortener.AdminLinksRequest
+	29, // 37: shortener.AdminService.ReassignLinks:input_type -> shortener.ReassignLinksRequest
+	31, // 38: shortener.AdminService.SetAccountRole:input_type -> shortener.SetAccountRoleRequest
+	1,  // 39: shortener.ShortenerService.CreateShortURL:output_type -> shortener.CreateShortURLResponse
+	3,  // 40: shortener.ShortenerService.ShortenURL:output_type -> shortener.ShortenURLResponse
+	7,  // 41: shortener.ShortenerService.ShortenBatch:output_type -> shortener.ShortenBatchResponse
+	5,  // 42: shortener.ShortenerService.ShortenStream:output_type -> shortener.BatchShortenResultItem
+	9,  // 43: shortener.ShortenerService.GetOriginalURL:output_type -> shortener.GetOriginalURLResponse
+	12, // 44: shortener.ShortenerService.GetUserURLs:output_type -> shortener.GetUserURLsResponse
+	10, // 45: shortener.ShortenerService.ExportUserURLs:output_type -> shortener.UserURLItem
+	15, // 46: shortener.ShortenerService.DeleteUserURLs:output_type -> shortener.DeleteUserURLsResponse
+	17, // 47: shortener.ShortenerService.Ping:output_type -> shortener.PingResponse
+	19, // 48: shortener.ShortenerService.GetStats:output_type -> shortener.GetStatsResponse
+	23, // 49: shortener.ShortenerService.GetURLStats:output_type -> shortener.GetURLStatsResponse
+	26, // 50: shortener.AdminService.SearchLinks:output_type -> shortener.SearchLinksResponse
+	25, // 51: shortener.AdminService.GetLink:output_type -> shortener.AdminLink
+	30, // 52: shortener.AdminService.DisableLinks:output_type -> shortener.AdminLinksResponse
+	30, // 53: shortener.AdminService.EnableLinks:output_type -> shortener.AdminLinksResponse
+	30, // 54: shortener.AdminService.DeleteLinks:output_type -> shortener.AdminLinksResponse
+	30, // 55: shortener.AdminService.ReassignLinks:output_type -> shortener.AdminLinksResponse
+	32, // 56: shortener.AdminService.SetAccountRole:output_type -> shortener.SetAccountRoleResponse
+	39, // [39:57] is the sub-list for method output_type
+	21, // [21:39] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_shortener_proto_init() }
@@ -1115,18 +2297,17 @@ func file_api_proto_shortener_proto_init() {
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_api_proto_shortener_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_shortener_proto_rawDesc), len(file_api_proto_shortener_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   19,
+			NumMessages:   33,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_api_proto_shortener_proto_goTypes,
 		DependencyIndexes: file_api_proto_shortener_proto_depIdxs,
 		MessageInfos:      file_api_proto_shortener_proto_msgTypes,
 	}.Build()
 	File_api_proto_shortener_proto = out.File
-	file_api_proto_shortener_proto_rawDesc = nil
 	file_api_proto_shortener_proto_goTypes = nil
 	file_api_proto_shortener_proto_depIdxs = nil
 }
@@ -22,11 +22,14 @@ const (
 	ShortenerService_CreateShortURL_FullMethodName = "/shortener.ShortenerService/CreateShortURL"
 	ShortenerService_ShortenURL_FullMethodName     = "/shortener.ShortenerService/ShortenURL"
 	ShortenerService_ShortenBatch_FullMethodName   = "/shortener.ShortenerService/ShortenBatch"
+	ShortenerService_ShortenStream_FullMethodName  = "/shortener.ShortenerService/ShortenStream"
 	ShortenerService_GetOriginalURL_FullMethodName = "/shortener.ShortenerService/GetOriginalURL"
 	ShortenerService_GetUserURLs_FullMethodName    = "/shortener.ShortenerService/GetUserURLs"
+	ShortenerService_ExportUserURLs_FullMethodName = "/shortener.ShortenerService/ExportUserURLs"
 	ShortenerService_DeleteUserURLs_FullMethodName = "/shortener.ShortenerService/DeleteUserURLs"
 	ShortenerService_Ping_FullMethodName           = "/shortener.ShortenerService/Ping"
 	ShortenerService_GetStats_FullMethodName       = "/shortener.ShortenerService/GetStats"
+	ShortenerService_GetURLStats_FullMethodName    = "/shortener.ShortenerService/GetURLStats"
 )
 
 // ShortenerServiceClient is the client API for ShortenerService service.
@@ -36,21 +39,39 @@ const (
 // ShortenerService предоставляет методы для работы с сокращением URL
 type ShortenerServiceClient interface {
 	// Создать короткий URL из текста
+	// Для URL, нарушающего политику, возвращает INVALID_ARGUMENT
+	// с деталями google.rpc.ErrorInfo (reason - код нарушения, например PRIVATE_ADDRESS)
 	CreateShortURL(ctx context.Context, in *CreateShortURLRequest, opts ...grpc.CallOption) (*CreateShortURLResponse, error)
 	// Сократить URL (JSON API аналог)
+	// Нарушение политики URL возвращается так же, как в CreateShortURL
 	ShortenURL(ctx context.Context, in *ShortenURLRequest, opts ...grpc.CallOption) (*ShortenURLResponse, error)
 	// Пакетное сокращение URL
 	ShortenBatch(ctx context.Context, in *ShortenBatchRequest, opts ...grpc.CallOption) (*ShortenBatchResponse, error)
+	// Потоковое сокращение URL без ограничения размера пакета.
+	// Клиент передает элементы потоком, сервер возвращает результат каждого
+	// элемента по мере создания ссылок (порядок результатов совпадает
+	// с порядком элементов). Ошибка элемента возвращается в поле status
+	// и не прерывает поток.
+	ShortenStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BatchShortenItem, BatchShortenResultItem], error)
 	// Получить оригинальный URL по короткому ID
+	// Для ссылки с истекшим сроком действия возвращает FAILED_PRECONDITION
+	// с деталями google.rpc.ErrorInfo (reason = LINK_EXPIRED), для ссылки,
+	// отключенной модератором, - с reason = LINK_DISABLED
 	GetOriginalURL(ctx context.Context, in *GetOriginalURLRequest, opts ...grpc.CallOption) (*GetOriginalURLResponse, error)
-	// Получить все URL пользователя
+	// Получить URL пользователя постранично
+	// Некорректные параметры возвращают INVALID_ARGUMENT
 	GetUserURLs(ctx context.Context, in *GetUserURLsRequest, opts ...grpc.CallOption) (*GetUserURLsResponse, error)
+	// Выгрузить все URL пользователя потоком
+	// Некорректные параметры возвращают INVALID_ARGUMENT
+	ExportUserURLs(ctx context.Context, in *ExportUserURLsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UserURLItem], error)
 	// Удалить URL пользователя
 	DeleteUserURLs(ctx context.Context, in *DeleteUserURLsRequest, opts ...grpc.CallOption) (*DeleteUserURLsResponse, error)
 	// Проверить состояние базы данных
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
 	// Получить статистику сервиса
 	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// Получить статистику переходов по URL пользователя
+	GetURLStats(ctx context.Context, in *GetURLStatsRequest, opts ...grpc.CallOption) (*GetURLStatsResponse, error)
 }
 
 type shortenerServiceClient struct {
@@ -91,6 +112,19 @@ func (c *shortenerServiceClient) ShortenBatch(ctx context.Context, in *ShortenBa
 	return out, nil
 }
 
+func (c *shortenerServiceClient) ShortenStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BatchShortenItem, BatchShortenResultItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ShortenerService_ServiceDesc.Streams[0], ShortenerService_ShortenStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchShortenItem, BatchShortenResultItem]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShortenerService_ShortenStreamClient = grpc.BidiStreamingClient[BatchShortenItem, BatchShortenResultItem]
+
 func (c *shortenerServiceClient) GetOriginalURL(ctx context.Context, in *GetOriginalURLRequest, opts ...grpc.CallOption) (*GetOriginalURLResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetOriginalURLResponse)
@@ -111,6 +145,25 @@ func (c *shortenerServiceClient) GetUserURLs(ctx context.Context, in *GetUserURL
 	return out, nil
 }
 
+func (c *shortenerServiceClient) ExportUserURLs(ctx context.Context, in *ExportUserURLsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UserURLItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ShortenerService_ServiceDesc.Streams[1], ShortenerService_ExportUserURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportUserURLsRequest, UserURLItem]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShortenerService_ExportUserURLsClient = grpc.ServerStreamingClient[UserURLItem]
+
 func (c *shortenerServiceClient) DeleteUserURLs(ctx context.Context, in *DeleteUserURLsRequest, opts ...grpc.CallOption) (*DeleteUserURLsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeleteUserURLsResponse)
@@ -141,6 +194,16 @@ func (c *shortenerServiceClient) GetStats(ctx context.Context, in *GetStatsReque
 	return out, nil
 }
 
+func (c *shortenerServiceClient) GetURLStats(ctx context.Context, in *GetURLStatsRequest, opts ...grpc.CallOption) (*GetURLStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetURLStatsResponse)
+	err := c.cc.Invoke(ctx, ShortenerService_GetURLStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShortenerServiceServer is the server API for ShortenerService service.
 // All implementations must embed UnimplementedShortenerServiceServer
 // for forward compatibility.
@@ -148,21 +211,39 @@ func (c *shortenerServiceClient) GetStats(ctx context.Context, in *GetStatsReque
 // ShortenerService предоставляет методы для работы с сокращением URL
 type ShortenerServiceServer interface {
 	// Создать короткий URL из текста
+	// Для URL, нарушающего политику, возвращает INVALID_ARGUMENT
+	// с деталями google.rpc.ErrorInfo (reason - код нарушения, например PRIVATE_ADDRESS)
 	CreateShortURL(context.Context, *CreateShortURLRequest) (*CreateShortURLResponse, error)
 	// Сократить URL (JSON API аналог)
+	// Нарушение политики URL возвращается так же, как в CreateShortURL
 	ShortenURL(context.Context, *ShortenURLRequest) (*ShortenURLResponse, error)
 	// Пакетное сокращение URL
 	ShortenBatch(context.Context, *ShortenBatchRequest) (*ShortenBatchResponse, error)
+	// Потоковое сокращение URL без ограничения размера пакета.
+	// Клиент передает элементы потоком, сервер возвращает результат каждого
+	// элемента по мере создания ссылок (порядок результатов совпадает
+	// с порядком элементов). Ошибка элемента возвращается в поле status
+	// и не прерывает поток.
+	ShortenStream(grpc.BidiStreamingServer[BatchShortenItem, BatchShortenResultItem]) error
 	// Получить оригинальный URL по короткому ID
+	// Для ссылки с истекшим сроком действия возвращает FAILED_PRECONDITION
+	// с деталями google.rpc.ErrorInfo (reason = LINK_EXPIRED), для ссылки,
+	// отключенной модератором, - с reason = LINK_DISABLED
 	GetOriginalURL(context.Context, *GetOriginalURLRequest) (*GetOriginalURLResponse, error)
-	// Получить все URL пользователя
+	// Получить URL пользователя постранично
+	// Некорректные параметры возвращают INVALID_ARGUMENT
 	GetUserURLs(context.Context, *GetUserURLsRequest) (*GetUserURLsResponse, error)
+	// Выгрузить все URL пользователя потоком
+	// Некорректные параметры возвращают INVALID_ARGUMENT
+	ExportUserURLs(*ExportUserURLsRequest, grpc.ServerStreamingServer[UserURLItem]) error
 	// Удалить URL пользователя
 	DeleteUserURLs(context.Context, *DeleteUserURLsRequest) (*DeleteUserURLsResponse, error)
 	// Проверить состояние базы данных
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
 	// Получить статистику сервиса
 	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// Получить статистику переходов по URL пользователя
+	GetURLStats(context.Context, *GetURLStatsRequest) (*GetURLStatsResponse, error)
 	mustEmbedUnimplementedShortenerServiceServer()
 }
 
@@ -182,12 +263,18 @@ func (UnimplementedShortenerServiceServer) ShortenURL(context.Context, *ShortenU
 func (UnimplementedShortenerServiceServer) ShortenBatch(context.Context, *ShortenBatchRequest) (*ShortenBatchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ShortenBatch not implemented")
 }
+func (UnimplementedShortenerServiceServer) ShortenStream(grpc.BidiStreamingServer[BatchShortenItem, BatchShortenResultItem]) error {
+	return status.Errorf(codes.Unimplemented, "method ShortenStream not implemented")
+}
 func (UnimplementedShortenerServiceServer) GetOriginalURL(context.Context, *GetOriginalURLRequest) (*GetOriginalURLResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOriginalURL not implemented")
 }
 func (UnimplementedShortenerServiceServer) GetUserURLs(context.Context, *GetUserURLsRequest) (*GetUserURLsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserURLs not implemented")
 }
+func (UnimplementedShortenerServiceServer) ExportUserURLs(*ExportUserURLsRequest, grpc.ServerStreamingServer[UserURLItem]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportUserURLs not implemented")
+}
 func (UnimplementedShortenerServiceServer) DeleteUserURLs(context.Context, *DeleteUserURLsRequest) (*DeleteUserURLsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserURLs not implemented")
 }
@@ -197,6 +284,9 @@ func (UnimplementedShortenerServiceServer) Ping(context.Context, *PingRequest) (
 func (UnimplementedShortenerServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
 }
+func (UnimplementedShortenerServiceServer) GetURLStats(context.Context, *GetURLStatsRequest) (*GetURLStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetURLStats not implemented")
+}
 func (UnimplementedShortenerServiceServer) mustEmbedUnimplementedShortenerServiceServer() {}
 func (UnimplementedShortenerServiceServer) testEmbeddedByValue()                          {}
 
@@ -272,6 +362,13 @@ func _ShortenerService_ShortenBatch_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_ShortenStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShortenerServiceServer).ShortenStream(&grpc.GenericServerStream[BatchShortenItem, BatchShortenResultItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShortenerService_ShortenStreamServer = grpc.BidiStreamingServer[BatchShortenItem, BatchShortenResultItem]
+
 func _ShortenerService_GetOriginalURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetOriginalURLRequest)
 	if err := dec(in); err != nil {
@@ -308,6 +405,17 @@ func _ShortenerService_GetUserURLs_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_ExportUserURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportUserURLsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortenerServiceServer).ExportUserURLs(m, &grpc.GenericServerStream[ExportUserURLsRequest, UserURLItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShortenerService_ExportUserURLsServer = grpc.ServerStreamingServer[UserURLItem]
+
 func _ShortenerService_DeleteUserURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteUserURLsRequest)
 	if err := dec(in); err != nil {
@@ -362,6 +470,24 @@ func _ShortenerService_GetStats_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortenerService_GetURLStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetURLStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServiceServer).GetURLStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortenerService_GetURLStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServiceServer).GetURLStats(ctx, req.(*GetURLStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ShortenerService_ServiceDesc is the grpc.ServiceDesc for ShortenerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -401,6 +527,374 @@ var ShortenerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetStats",
 			Handler:    _ShortenerService_GetStats_Handler,
 		},
+		{
+			MethodName: "GetURLStats",
+			Handler:    _ShortenerService_GetURLStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ShortenStream",
+			Handler:       _ShortenerService_ShortenStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportUserURLs",
+			Handler:       _ShortenerService_ExportUserURLs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/proto/shortener.proto",
+}
+
+const (
+	AdminService_SearchLinks_FullMethodName    = "/shortener.AdminService/SearchLinks"
+	AdminService_GetLink_FullMethodName        = "/shortener.AdminService/GetLink"
+	AdminService_DisableLinks_FullMethodName   = "/shortener.AdminService/DisableLinks"
+	AdminService_EnableLinks_FullMethodName    = "/shortener.AdminService/EnableLinks"
+	AdminService_DeleteLinks_FullMethodName    = "/shortener.AdminService/DeleteLinks"
+	AdminService_ReassignLinks_FullMethodName  = "/shortener.AdminService/ReassignLinks"
+	AdminService_SetAccountRole_FullMethodName = "/shortener.AdminService/SetAccountRole"
+)
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AdminService предоставляет методы модерации ссылок.
+// Все методы требуют токена учетной записи с ролью admin: без токена
+// возвращается UNAUTHENTICATED, без роли - PERMISSION_DENIED.
+type AdminServiceClient interface {
+	// Найти ссылки по фильтру
+	SearchLinks(ctx context.Context, in *SearchLinksRequest, opts ...grpc.CallOption) (*SearchLinksResponse, error)
+	// Получить ссылку по короткому ID
+	GetLink(ctx context.Context, in *GetLinkRequest, opts ...grpc.CallOption) (*AdminLink, error)
+	// Отключить ссылки: переход по ним возвращает 410 Gone
+	DisableLinks(ctx context.Context, in *AdminLinksRequest, opts ...grpc.CallOption) (*AdminLinksResponse, error)
+	// Включить ранее отключенные ссылки
+	EnableLinks(ctx context.Context, in *AdminLinksRequest, opts ...grpc.CallOption) (*AdminLinksResponse, error)
+	// Безвозвратно удалить ссылки вместе со статистикой переходов
+	DeleteLinks(ctx context.Context, in *AdminLinksRequest, opts ...grpc.CallOption) (*AdminLinksResponse, error)
+	// Передать ссылки другому владельцу
+	ReassignLinks(ctx context.Context, in *ReassignLinksRequest, opts ...grpc.CallOption) (*AdminLinksResponse, error)
+	// Назначить роль учетной записи
+	SetAccountRole(ctx context.Context, in *SetAccountRoleRequest, opts ...grpc.CallOption) (*SetAccountRoleResponse, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) SearchLinks(ctx context.Context, in *SearchLinksRequest, opts ...grpc.CallOption) (*SearchLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchLinksResponse)
+	err := c.cc.Invoke(ctx, AdminService_SearchLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetLink(ctx context.Context, in *GetLinkRequest, opts ...grpc.CallOption) (*AdminLink, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminLink)
+	err := c.cc.Invoke(ctx, AdminService_GetLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) DisableLinks(ctx context.Context, in *AdminLinksRequest, opts ...grpc.CallOption) (*AdminLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminLinksResponse)
+	err := c.cc.Invoke(ctx, AdminService_DisableLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) EnableLinks(ctx context.Context, in *AdminLinksRequest, opts ...grpc.CallOption) (*AdminLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminLinksResponse)
+	err := c.cc.Invoke(ctx, AdminService_EnableLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) DeleteLinks(ctx context.Context, in *AdminLinksRequest, opts ...grpc.CallOption) (*AdminLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminLinksResponse)
+	err := c.cc.Invoke(ctx, AdminService_DeleteLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ReassignLinks(ctx context.Context, in *ReassignLinksRequest, opts ...grpc.CallOption) (*AdminLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminLinksResponse)
+	err := c.cc.Invoke(ctx, AdminService_ReassignLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) SetAccountRole(ctx context.Context, in *SetAccountRoleRequest, opts ...grpc.CallOption) (*SetAccountRoleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAccountRoleResponse)
+	err := c.cc.Invoke(ctx, AdminService_SetAccountRole_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility.
+//
+// AdminService предоставляет методы модерации ссылок.
+// Все методы требуют токена учетной записи с ролью admin: без токена
+// возвращается UNAUTHENTICATED, без роли - PERMISSION_DENIED.
+type AdminServiceServer interface {
+	// Найти ссылки по фильтру
+	SearchLinks(context.Context, *SearchLinksRequest) (*SearchLinksResponse, error)
+	// Получить ссылку по короткому ID
+	GetLink(context.Context, *GetLinkRequest) (*AdminLink, error)
+	// Отключить ссылки: переход по ним возвращает 410 Gone
+	DisableLinks(context.Context, *AdminLinksRequest) (*AdminLinksResponse, error)
+	// Включить ранее отключенные ссылки
+	EnableLinks(context.Context, *AdminLinksRequest) (*AdminLinksResponse, error)
+	// Безвозвратно удалить ссылки вместе со статистикой переходов
+	DeleteLinks(context.Context, *AdminLinksRequest) (*AdminLinksResponse, error)
+	// Передать ссылки другому владельцу
+	ReassignLinks(context.Context, *ReassignLinksRequest) (*AdminLinksResponse, error)
+	// Назначить роль учетной записи
+	SetAccountRole(context.Context, *SetAccountRoleRequest) (*SetAccountRoleResponse, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServiceServer struct{}
+
+func (UnimplementedAdminServiceServer) SearchLinks(context.Context, *SearchLinksRequest) (*SearchLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchLinks not implemented")
+}
+func (UnimplementedAdminServiceServer) GetLink(context.Context, *GetLinkRequest) (*AdminLink, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLink not implemented")
+}
+func (UnimplementedAdminServiceServer) DisableLinks(context.Context, *AdminLinksRequest) (*AdminLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableLinks not implemented")
+}
+func (UnimplementedAdminServiceServer) EnableLinks(context.Context, *AdminLinksRequest) (*AdminLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableLinks not implemented")
+}
+func (UnimplementedAdminServiceServer) DeleteLinks(context.Context, *AdminLinksRequest) (*AdminLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLinks not implemented")
+}
+func (UnimplementedAdminServiceServer) ReassignLinks(context.Context, *ReassignLinksRequest) (*AdminLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReassignLinks not implemented")
+}
+func (UnimplementedAdminServiceServer) SetAccountRole(context.Context, *SetAccountRoleRequest) (*SetAccountRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAccountRole not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+func (UnimplementedAdminServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_SearchLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SearchLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_SearchLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SearchLinks(ctx, req.(*SearchLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetLink(ctx, req.(*GetLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_DisableLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).DisableLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_DisableLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).DisableLinks(ctx, req.(*AdminLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_EnableLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).EnableLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_EnableLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).EnableLinks(ctx, req.(*AdminLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_DeleteLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).DeleteLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_DeleteLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).DeleteLinks(ctx, req.(*AdminLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ReassignLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReassignLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ReassignLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ReassignLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ReassignLinks(ctx, req.(*ReassignLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_SetAccountRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAccountRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetAccountRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_SetAccountRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetAccountRole(ctx, req.(*SetAccountRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "shortener.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchLinks",
+			Handler:    _AdminService_SearchLinks_Handler,
+		},
+		{
+			MethodName: "GetLink",
+			Handler:    _AdminService_GetLink_Handler,
+		},
+		{
+			MethodName: "DisableLinks",
+			Handler:    _AdminService_DisableLinks_Handler,
+		},
+		{
+			MethodName: "EnableLinks",
+			Handler:    _AdminService_EnableLinks_Handler,
+		},
+		{
+			MethodName: "DeleteLinks",
+			Handler:    _AdminService_DeleteLinks_Handler,
+		},
+		{
+			MethodName: "ReassignLinks",
+			Handler:    _AdminService_ReassignLinks_Handler,
+		},
+		{
+			MethodName: "SetAccountRole",
+			Handler:    _AdminService_SetAccountRole_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/shortener.proto",